@@ -0,0 +1,63 @@
+package norm
+
+import (
+	"sync"
+	"time"
+)
+
+// AdaptiveBatchConfig bounds the chunk sizes chosen by WithAdaptiveBatchSize. Zero fields use the defaults
+// (100 to 10000 rows, 200ms per chunk).
+type AdaptiveBatchConfig struct {
+	MinRows       int
+	MaxRows       int
+	TargetLatency time.Duration
+}
+
+// batchTuner tracks a chunk size per table, steering each chunk towards the target latency. Per-row cost is
+// measured from completed chunks, so wide rows and slow links shrink chunks and fast ones grow them.
+type batchTuner struct {
+	cfg   AdaptiveBatchConfig
+	mu    sync.Mutex
+	sizes map[string]int
+}
+
+func newBatchTuner(cfg *AdaptiveBatchConfig) *batchTuner {
+	if cfg == nil {
+		return nil
+	}
+	c := *cfg
+	c.MinRows = defaultIfZeroInt(c.MinRows, 100)
+	c.MaxRows = max(defaultIfZeroInt(c.MaxRows, 10000), c.MinRows)
+	c.TargetLatency = defaultIfZeroDuration(c.TargetLatency, 200*time.Millisecond)
+	return &batchTuner{cfg: c, sizes: make(map[string]int)}
+}
+
+// size returns the current chunk size for table, starting from defaultBatchSize
+func (t *batchTuner) size(table string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if n, ok := t.sizes[table]; ok {
+		return n
+	}
+	return t.clamp(defaultBatchSize)
+}
+
+// observe records that rows rows took d and moves the table's size halfway towards the size that would
+// have hit the target latency, at most doubling per step so one fast tail chunk cannot overshoot
+func (t *batchTuner) observe(table string, rows int, d time.Duration) {
+	if rows <= 0 || d <= 0 {
+		return
+	}
+	ideal := int(float64(rows) * float64(t.cfg.TargetLatency) / float64(d))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cur, ok := t.sizes[table]
+	if !ok {
+		cur = t.clamp(defaultBatchSize)
+	}
+	t.sizes[table] = t.clamp(min((cur+ideal)/2, cur*2))
+}
+
+func (t *batchTuner) clamp(n int) int {
+	return min(max(n, t.cfg.MinRows), t.cfg.MaxRows)
+}
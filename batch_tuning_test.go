@@ -0,0 +1,80 @@
+package norm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestBatchTuner_StepsTowardsTargetLatency(t *testing.T) {
+	bt := newBatchTuner(&AdaptiveBatchConfig{MinRows: 10, MaxRows: 5000, TargetLatency: 100 * time.Millisecond})
+	if got := bt.size("t"); got != defaultBatchSize {
+		t.Fatalf("initial=%d", got)
+	}
+	// 1000 rows in 10ms: ideal is 10000 rows, growth is capped at doubling
+	bt.observe("t", 1000, 10*time.Millisecond)
+	if got := bt.size("t"); got != 2000 {
+		t.Fatalf("after fast chunk=%d", got)
+	}
+	// 2000 rows in 1s: ideal is 200 rows, move halfway
+	bt.observe("t", 2000, time.Second)
+	if got := bt.size("t"); got != 1100 {
+		t.Fatalf("after slow chunk=%d", got)
+	}
+	for range 10 {
+		bt.observe("t", 10, time.Second)
+	}
+	if got := bt.size("t"); got != 10 {
+		t.Fatalf("min bound=%d", got)
+	}
+	if got := bt.size("other"); got != defaultBatchSize {
+		t.Fatalf("tables must be tuned separately, got %d", got)
+	}
+	if newBatchTuner(nil) != nil {
+		t.Fatalf("nil config must disable tuning")
+	}
+}
+
+func TestRepo_BatchSize_UsesTunerAndParamLimit(t *testing.T) {
+	kn := &KintsNorm{batchSize: 7, batchTuner: newBatchTuner(&AdaptiveBatchConfig{MinRows: 50000, MaxRows: 50000})}
+	r := &repo[repUser]{kn: kn}
+	if got := r.batchSize(0); got != 50000 {
+		t.Fatalf("tuned=%d", got)
+	}
+	if got := r.batchSize(2); got != maxBindParams/2 {
+		t.Fatalf("param limit=%d", got)
+	}
+}
+
+type recCopier struct{ chunks []int }
+
+func (c *recCopier) CopyFrom(_ context.Context, _ pgx.Identifier, _ []string, src pgx.CopyFromSource) (int64, error) {
+	n := 0
+	for src.Next() {
+		n++
+	}
+	c.chunks = append(c.chunks, n)
+	return int64(n), nil
+}
+
+func TestRepo_CopyRows_ChunksWhenAdaptive(t *testing.T) {
+	rows := make([][]any, 250)
+	for i := range rows {
+		rows[i] = []any{"n"}
+	}
+	c := &recCopier{}
+	r := &repo[repUser]{kn: &KintsNorm{}}
+	if n, err := r.copyRows(context.Background(), c, []string{"name"}, rows); err != nil || n != 250 || len(c.chunks) != 1 {
+		t.Fatalf("n=%d err=%v chunks=%v", n, err, c.chunks)
+	}
+	c = &recCopier{}
+	r.kn.batchTuner = newBatchTuner(&AdaptiveBatchConfig{MinRows: 100, MaxRows: 100})
+	if n, err := r.copyRows(context.Background(), c, []string{"name"}, rows); err != nil || n != 250 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	if len(c.chunks) != 3 || c.chunks[2] != 50 {
+		t.Fatalf("chunks=%v", c.chunks)
+	}
+}
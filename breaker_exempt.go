@@ -0,0 +1,52 @@
+package norm
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// breakerExemptKey marks a context whose statements bypass the circuit breaker
+type breakerExemptKey struct{}
+
+// WithoutCircuitBreaker returns a context whose statements bypass the circuit breaker: they run while the
+// circuit is open and their outcome does not count towards opening or closing it. Use it for recovery
+// probes and operational work (health checks, migrations) that must not be rejected with user traffic.
+func WithoutCircuitBreaker(ctx context.Context) context.Context {
+	return context.WithValue(ctx, breakerExemptKey{}, true)
+}
+
+// BypassCircuitBreaker exempts the statements of this builder from the circuit breaker (see
+// WithoutCircuitBreaker)
+//
+//	err := db.Query().BypassCircuitBreaker().Raw("SELECT 1").Exec(ctx) // readiness probe
+func (qb *QueryBuilder) BypassCircuitBreaker() *QueryBuilder {
+	qb.exec = breakerExemptExecuter{exec: qb.exec}
+	return qb
+}
+
+// breakerFor returns the circuit breaker of kn, or nil when it is disabled or ctx is exempt
+func (kn *KintsNorm) breakerFor(ctx context.Context) *circuitBreaker {
+	if kn == nil || ctx.Value(breakerExemptKey{}) != nil {
+		return nil
+	}
+	return kn.breaker
+}
+
+// breakerExemptExecuter runs every statement with a breaker-exempt context
+type breakerExemptExecuter struct{ exec dbExecuter }
+
+func (e breakerExemptExecuter) unwrap() dbExecuter { return e.exec }
+
+func (e breakerExemptExecuter) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return e.exec.Exec(WithoutCircuitBreaker(ctx), sql, arguments...)
+}
+
+func (e breakerExemptExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return e.exec.Query(WithoutCircuitBreaker(ctx), sql, args...)
+}
+
+func (e breakerExemptExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return e.exec.QueryRow(WithoutCircuitBreaker(ctx), sql, args...)
+}
@@ -0,0 +1,38 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBreakerExemptions(t *testing.T) {
+	kn := &KintsNorm{}
+	kn.breaker = newCircuitBreaker(circuitBreakerConfig{failureThreshold: 1, openTimeout: time.Hour})
+	kn.breaker.after(errors.New("boom"))
+	ctx := context.Background()
+	be := breakerExecuter{kn: kn, exec: okExec{}}
+	if _, err := be.Exec(ctx, "select 1"); !isCircuitOpenError(err) {
+		t.Fatalf("open circuit let user traffic through: %v", err)
+	}
+
+	if _, err := be.Exec(WithoutCircuitBreaker(ctx), "select 1"); err != nil {
+		t.Fatalf("exempt exec: %v", err)
+	}
+	f := &fakeExec{}
+	qb := (&QueryBuilder{kn: kn, exec: withBreaker(kn, f)}).BypassCircuitBreaker()
+	if err := qb.Raw("SELECT 1").Exec(ctx); err != nil {
+		t.Fatalf("exempt builder: %v", err)
+	}
+	if f.lastSQL != "SELECT 1" {
+		t.Fatalf("sql=%s", f.lastSQL)
+	}
+	if _, ok := baseExec(ctx, qb.exec).(*fakeExec); !ok {
+		t.Fatalf("base=%T", baseExec(ctx, qb.exec))
+	}
+	// exempt outcomes do not move the breaker
+	if kn.breaker.stateName() != "open" {
+		t.Fatalf("state=%s", kn.breaker.stateName())
+	}
+}
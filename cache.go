@@ -12,6 +12,14 @@ type Cache interface {
 	Invalidate(ctx context.Context, keys ...string) error
 }
 
+// LoadingCache can optionally be implemented by a Cache to protect against cache stampedes: on a miss,
+// GetOrLoad runs load once for all concurrent callers of the same key, stores the result for ttl and returns
+// it to each of them. Read-through paths (WithCacheKey, Repository.Cached) use it instead of Get and Set.
+type LoadingCache interface {
+	Cache
+	GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) ([]byte, error)) ([]byte, error)
+}
+
 // NoopCache is a default no-op cache implementation
 type NoopCache struct{}
 
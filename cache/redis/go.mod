@@ -0,0 +1,23 @@
+module github.com/kintsdev/norm/cache/redis
+
+go 1.26
+
+require (
+	github.com/kintsdev/norm v0.0.0-00010101000000-000000000000
+	github.com/redis/go-redis/v9 v9.22.0
+	golang.org/x/sync v0.22.0
+)
+
+require (
+	github.com/cespare/xxhash/v2 v2.3.0 // indirect
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/pgx/v5 v5.9.1 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
+	go.uber.org/atomic v1.11.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+)
+
+// resolve norm from this checkout during local development
+replace github.com/kintsdev/norm => ../..
@@ -0,0 +1,174 @@
+// Package redis is a Redis implementation of norm.Cache (and norm.LoadingCache) on go-redis.
+//
+//	rdb := goredis.NewClient(&goredis.Options{Addr: "localhost:6379"})
+//	db, _ := norm.New(cfg, norm.WithCache(redis.New(rdb, redis.WithPrefix("app:"), redis.WithTTLJitter(0.1))))
+//
+// Concurrent misses of a key in the process share one database load (singleflight), expirations are
+// spread by a random jitter so entries written together do not expire together, and a Codec can compress
+// or encrypt the stored values.
+package redis
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/kintsdev/norm"
+)
+
+// Codec transforms values on their way into and out of Redis, e.g. to compress or encrypt them
+type Codec interface {
+	Encode(value []byte) ([]byte, error)
+	Decode(data []byte) ([]byte, error)
+}
+
+// client is the part of goredis.UniversalClient the cache uses
+type client interface {
+	Get(ctx context.Context, key string) *goredis.StringCmd
+	Set(ctx context.Context, key string, value any, expiration time.Duration) *goredis.StatusCmd
+	Del(ctx context.Context, keys ...string) *goredis.IntCmd
+}
+
+// pipeliner is implemented by go-redis clients; Invalidate pipelines one DEL per key through it, which
+// also works when the keys live on different cluster slots
+type pipeliner interface {
+	Pipelined(ctx context.Context, fn func(goredis.Pipeliner) error) ([]goredis.Cmder, error)
+}
+
+// Cache stores norm cache entries in Redis
+type Cache struct {
+	client client
+	prefix string
+	jitter float64
+	codec  Codec
+	group  singleflight.Group
+}
+
+var (
+	_ norm.Cache        = (*Cache)(nil)
+	_ norm.LoadingCache = (*Cache)(nil)
+)
+
+// Option configures a Cache
+type Option func(*Cache)
+
+// WithPrefix prepends prefix to every key, to share a Redis database between applications
+func WithPrefix(prefix string) Option { return func(c *Cache) { c.prefix = prefix } }
+
+// WithTTLJitter extends each TTL by a random amount up to fraction of it (0.1 = up to 10% longer), so
+// entries cached at the same moment do not all expire, and reload, at once
+func WithTTLJitter(fraction float64) Option {
+	return func(c *Cache) { c.jitter = max(fraction, 0) }
+}
+
+// WithCodec passes stored values through codec
+func WithCodec(codec Codec) Option { return func(c *Cache) { c.codec = codec } }
+
+// New returns a Cache on rdb (a *goredis.Client, *goredis.ClusterClient or any other UniversalClient)
+func New(rdb goredis.UniversalClient, opts ...Option) *Cache {
+	return newCache(rdb, opts...)
+}
+
+func newCache(cl client, opts ...Option) *Cache {
+	c := &Cache{client: cl}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Get returns the value of key; a missing key is not an error
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if c.codec != nil {
+		if b, err = c.codec.Decode(b); err != nil {
+			return nil, false, err
+		}
+	}
+	return b, true, nil
+}
+
+// Set stores value under key for ttl plus jitter; ttl <= 0 stores it without expiration
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if c.codec != nil {
+		var err error
+		if value, err = c.codec.Encode(value); err != nil {
+			return err
+		}
+	}
+	return c.client.Set(ctx, c.prefix+key, value, c.expiration(ttl)).Err()
+}
+
+// Invalidate deletes keys
+func (c *Cache) Invalidate(ctx context.Context, keys ...string) error {
+	switch {
+	case len(keys) == 0:
+		return nil
+	case len(keys) == 1:
+		return c.client.Del(ctx, c.prefix+keys[0]).Err()
+	}
+	if p, ok := c.client.(pipeliner); ok {
+		_, err := p.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+			for _, k := range keys {
+				pipe.Del(ctx, c.prefix+k)
+			}
+			return nil
+		})
+		return err
+	}
+	for _, k := range keys {
+		if err := c.client.Del(ctx, c.prefix+k).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetOrLoad returns the value of key, running load on a miss once for all concurrent callers in this
+// process and storing its result for ttl. A Redis error counts as a miss, so the database still answers
+// when Redis is down. load runs detached from the cancellation of the caller that started it, so one
+// caller giving up does not fail the others; each caller stops waiting when its own ctx is done.
+func (c *Cache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if b, ok, err := c.Get(ctx, key); err == nil && ok {
+		return b, nil
+	}
+	ch := c.group.DoChan(key, func() (any, error) {
+		lctx := context.WithoutCancel(ctx)
+		b, err := load(lctx)
+		if err != nil {
+			return nil, err
+		}
+		_ = c.Set(lctx, key, b, ttl)
+		return b, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]byte), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// expiration is ttl extended by a random part of the jitter fraction
+func (c *Cache) expiration(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return 0
+	}
+	if c.jitter > 0 {
+		ttl += time.Duration(rand.Float64() * c.jitter * float64(ttl))
+	}
+	return ttl
+}
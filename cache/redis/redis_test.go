@@ -0,0 +1,170 @@
+package redis
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// fakeClient is an in-memory client recording expirations
+type fakeClient struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	ttl  map[string]time.Duration
+	err  error
+}
+
+func newFakeClient() *fakeClient {
+	return &fakeClient{data: map[string][]byte{}, ttl: map[string]time.Duration{}}
+}
+
+func (f *fakeClient) Get(_ context.Context, key string) *goredis.StringCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return goredis.NewStringResult("", f.err)
+	}
+	v, ok := f.data[key]
+	if !ok {
+		return goredis.NewStringResult("", goredis.Nil)
+	}
+	return goredis.NewStringResult(string(v), nil)
+}
+
+func (f *fakeClient) Set(_ context.Context, key string, value any, expiration time.Duration) *goredis.StatusCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data[key], f.ttl[key] = value.([]byte), expiration
+	return goredis.NewStatusResult("OK", nil)
+}
+
+func (f *fakeClient) Del(_ context.Context, keys ...string) *goredis.IntCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, k := range keys {
+		delete(f.data, k)
+	}
+	return goredis.NewIntResult(int64(len(keys)), nil)
+}
+
+// xorCodec flips every byte, standing in for compression or encryption
+type xorCodec struct{}
+
+func (xorCodec) Encode(v []byte) ([]byte, error) { return xorBytes(v), nil }
+func (xorCodec) Decode(v []byte) ([]byte, error) { return xorBytes(v), nil }
+
+func xorBytes(v []byte) []byte {
+	out := make([]byte, len(v))
+	for i, b := range v {
+		out[i] = b ^ 0xff
+	}
+	return out
+}
+
+func TestCache_GetSetInvalidate(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeClient()
+	c := newCache(f, WithPrefix("app:"), WithCodec(xorCodec{}))
+
+	if _, ok, err := c.Get(ctx, "users:1"); ok || err != nil {
+		t.Fatalf("missing key: ok=%v err=%v", ok, err)
+	}
+	if err := c.Set(ctx, "users:1", []byte("ann"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if raw := f.data["app:users:1"]; bytes.Equal(raw, []byte("ann")) || f.ttl["app:users:1"] != time.Minute {
+		t.Fatalf("stored %q ttl=%s", raw, f.ttl["app:users:1"])
+	}
+	if v, ok, err := c.Get(ctx, "users:1"); !ok || err != nil || string(v) != "ann" {
+		t.Fatalf("Get=%q ok=%v err=%v", v, ok, err)
+	}
+	_ = c.Set(ctx, "users:2", []byte("bob"), 0)
+	if f.ttl["app:users:2"] != 0 {
+		t.Fatalf("ttl<=0 expires: %s", f.ttl["app:users:2"])
+	}
+	if err := c.Invalidate(ctx, "users:1", "users:2"); err != nil || len(f.data) != 0 {
+		t.Fatalf("err=%v left=%v", err, f.data)
+	}
+}
+
+func TestCache_TTLJitter(t *testing.T) {
+	c := newCache(newFakeClient(), WithTTLJitter(0.5))
+	for range 100 {
+		if d := c.expiration(time.Minute); d < time.Minute || d > 90*time.Second {
+			t.Fatalf("expiration %s outside [1m, 1m30s]", d)
+		}
+	}
+}
+
+func TestCache_GetOrLoadSingleflight(t *testing.T) {
+	ctx := context.Background()
+	f := newFakeClient()
+	c := newCache(f)
+	var loads atomic.Int32
+	release := make(chan struct{})
+	load := func(context.Context) ([]byte, error) {
+		loads.Add(1)
+		<-release
+		return []byte("rows"), nil
+	}
+
+	const callers = 8
+	var wg sync.WaitGroup
+	results := make([][]byte, callers)
+	for i := range callers {
+		wg.Go(func() {
+			b, err := c.GetOrLoad(ctx, "k", time.Minute, load)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = b
+		})
+	}
+	// a caller giving up does not cancel the shared load
+	cctx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() { _, err := c.GetOrLoad(cctx, "k", time.Minute, load); done <- err }()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled caller: %v", err)
+	}
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("loads=%d", n)
+	}
+	for _, b := range results {
+		if string(b) != "rows" {
+			t.Fatalf("results=%q", results)
+		}
+	}
+	if string(f.data["k"]) != "rows" {
+		t.Fatal("loaded value not stored")
+	}
+	// now it is a hit
+	if _, err := c.GetOrLoad(ctx, "k", time.Minute, load); err != nil || loads.Load() != 1 {
+		t.Fatalf("hit: err=%v loads=%d", err, loads.Load())
+	}
+}
+
+func TestCache_GetOrLoadRedisDown(t *testing.T) {
+	f := newFakeClient()
+	f.err = errors.New("connection refused")
+	c := newCache(f)
+	b, err := c.GetOrLoad(context.Background(), "k", time.Minute, func(context.Context) ([]byte, error) { return []byte("db"), nil })
+	if err != nil || string(b) != "db" {
+		t.Fatalf("b=%q err=%v", b, err)
+	}
+	boom := errors.New("query failed")
+	if _, err := c.GetOrLoad(context.Background(), "k2", time.Minute, func(context.Context) ([]byte, error) { return nil, boom }); !errors.Is(err, boom) {
+		t.Fatalf("load error: %v", err)
+	}
+}
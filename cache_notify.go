@@ -0,0 +1,176 @@
+package norm
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// maxNotifyPayload keeps invalidation payloads below PostgreSQL's 8000 byte NOTIFY limit
+const maxNotifyPayload = 7900
+
+// WithCacheInvalidationChannel fans out cache invalidations to every instance over Postgres LISTEN/NOTIFY.
+// Invalidate still drops the keys from the local Cache, then publishes them on channel. Each instance
+// also listens on channel from startup on a dedicated connection, and drops the keys published by other
+// instances. Inside a transaction carried by the context (WithTx) the keys are published at commit. Has no
+// effect without WithCache.
+func WithCacheInvalidationChannel(channel string) Option {
+	return func(o *options) { o.cacheChannel = channel }
+}
+
+// invalidation is the NOTIFY payload: the publishing instance and the keys
+type invalidation struct {
+	Origin string   `json:"o"`
+	Keys   []string `json:"k"`
+}
+
+// notifyCache publishes the invalidations of a local Cache on a NOTIFY channel
+type notifyCache struct {
+	Cache
+	channel string
+	origin  string     // identifies this instance's own notifications
+	exec    dbExecuter // publishes when the context carries no transaction
+}
+
+// Invalidate drops keys locally and publishes them to the other instances
+func (c *notifyCache) Invalidate(ctx context.Context, keys ...string) error {
+	err := c.Cache.Invalidate(ctx, keys...)
+	if len(keys) == 0 {
+		return err
+	}
+	exec := c.exec
+	if tx, ok := TxFromContext(ctx); ok {
+		// NOTIFY in a transaction is delivered on commit, and dropped on rollback
+		exec = tx.Exec()
+	}
+	for _, payload := range invalidationPayloads(c.origin, keys) {
+		if _, perr := exec.Exec(ctx, "SELECT pg_notify($1, $2)", c.channel, payload); perr != nil {
+			return errors.Join(err, perr)
+		}
+	}
+	return err
+}
+
+// GetOrLoad keeps the stampede protection of a LoadingCache underneath; other caches get Get, then load
+// and Set on a miss
+func (c *notifyCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if lc, ok := c.Cache.(LoadingCache); ok {
+		return lc.GetOrLoad(ctx, key, ttl, load)
+	}
+	if data, ok, err := c.Cache.Get(ctx, key); err == nil && ok {
+		return data, nil
+	}
+	data, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_ = c.Cache.Set(ctx, key, data, ttl)
+	return data, nil
+}
+
+// invalidationPayloads packs keys into as few payloads as maxNotifyPayload allows
+func invalidationPayloads(origin string, keys []string) []string {
+	var out []string
+	batch := invalidation{Origin: origin}
+	size := 0
+	flush := func() {
+		if len(batch.Keys) > 0 {
+			b, _ := json.Marshal(batch)
+			out = append(out, string(b))
+		}
+		batch.Keys, size = nil, 0
+	}
+	base, _ := json.Marshal(invalidation{Origin: origin, Keys: []string{}})
+	for _, k := range keys {
+		kb, _ := json.Marshal(k)
+		if len(batch.Keys) > 0 && len(base)+size+len(kb)+1 > maxNotifyPayload {
+			flush()
+		}
+		batch.Keys = append(batch.Keys, k)
+		size += len(kb) + 1
+	}
+	flush()
+	return out
+}
+
+// apply drops the keys of a payload published by another instance
+func (c *notifyCache) apply(ctx context.Context, payload string) error {
+	var inv invalidation
+	if err := json.Unmarshal([]byte(payload), &inv); err != nil {
+		return err
+	}
+	if inv.Origin == c.origin || len(inv.Keys) == 0 {
+		return nil
+	}
+	return c.Cache.Invalidate(ctx, inv.Keys...)
+}
+
+// startCacheListener wraps the cache of kn in a notifyCache and listens on channel until Close
+// (no-op without a channel or a cache)
+func (kn *KintsNorm) startCacheListener(channel string) {
+	if channel == "" || kn.cache == nil {
+		return
+	}
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+	c := &notifyCache{Cache: kn.cache, channel: channel, origin: hex.EncodeToString(id), exec: kn.pool}
+	kn.cache = c
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	kn.stopCacheListener = func() {
+		cancel()
+		<-done
+	}
+	go func() {
+		defer close(done)
+		backoff := time.Second
+		for {
+			started := time.Now()
+			err := kn.listenInvalidations(ctx, c)
+			if ctx.Err() != nil {
+				return
+			}
+			if time.Since(started) > time.Minute {
+				backoff = time.Second
+			}
+			if kn.logger != nil {
+				// notifications sent while disconnected are lost; their keys expire by TTL only
+				kn.logger.Warn("cache invalidation listener disconnected", Field{Key: "channel", Value: channel}, Field{Key: "error", Value: err.Error()}, Field{Key: "retry_in", Value: backoff.String()})
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, 30*time.Second)
+		}
+	}()
+}
+
+// listenInvalidations holds one connection out of the pool, LISTENing on the channel of c
+func (kn *KintsNorm) listenInvalidations(ctx context.Context, c *notifyCache) error {
+	pc, err := kn.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// the session keeps LISTENing, so it must not return to the pool
+	conn := pc.Hijack()
+	defer func() { _ = conn.Close(context.WithoutCancel(ctx)) }()
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{c.channel}.Sanitize()); err != nil {
+		return err
+	}
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		if err := c.apply(ctx, n.Payload); err != nil && kn.logger != nil {
+			kn.logger.Warn("cache invalidation dropped", Field{Key: "channel", Value: c.channel}, Field{Key: "error", Value: err.Error()})
+		}
+	}
+}
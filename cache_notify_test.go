@@ -0,0 +1,130 @@
+package norm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// keyCache records invalidated keys
+type keyCache struct{ dropped []string }
+
+func (c *keyCache) Get(context.Context, string) ([]byte, bool, error)        { return nil, false, nil }
+func (c *keyCache) Set(context.Context, string, []byte, time.Duration) error { return nil }
+func (c *keyCache) Invalidate(_ context.Context, keys ...string) error {
+	c.dropped = append(c.dropped, keys...)
+	return nil
+}
+
+func TestInvalidationPayloads_Chunked(t *testing.T) {
+	var keys []string
+	for i := range 2000 {
+		keys = append(keys, fmt.Sprintf("users:%d", i))
+	}
+	payloads := invalidationPayloads("me", keys)
+	if len(payloads) < 2 {
+		t.Fatalf("expected several payloads, got %d", len(payloads))
+	}
+	var got []string
+	for _, p := range payloads {
+		if len(p) > maxNotifyPayload {
+			t.Fatalf("payload of %d bytes", len(p))
+		}
+		var inv invalidation
+		if err := json.Unmarshal([]byte(p), &inv); err != nil || inv.Origin != "me" {
+			t.Fatalf("payload %q: %v", p, err)
+		}
+		got = append(got, inv.Keys...)
+	}
+	if !slices.Equal(got, keys) {
+		t.Fatalf("keys lost: %d of %d", len(got), len(keys))
+	}
+}
+
+func TestNotifyCache_InvalidatePublishes(t *testing.T) {
+	local := &keyCache{}
+	pool := &recExecRepo{}
+	c := &notifyCache{Cache: local, channel: "norm_cache", origin: "me", exec: pool}
+	if err := c.Invalidate(context.Background(), "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(local.dropped, []string{"a", "b"}) {
+		t.Fatalf("local: %v", local.dropped)
+	}
+	if pool.lastSQL != "SELECT pg_notify($1, $2)" || pool.lastArgs[0] != "norm_cache" || pool.lastArgs[1] != `{"o":"me","k":["a","b"]}` {
+		t.Fatalf("publish: %s %v", pool.lastSQL, pool.lastArgs)
+	}
+
+	// inside WithTx the notification goes through the transaction, so it is sent on commit
+	txEx := &recExecRepo{}
+	pool.lastSQL = ""
+	_ = c.Invalidate(WithTx(context.Background(), ctxTx{ex: txEx}), "c")
+	if txEx.lastSQL != "SELECT pg_notify($1, $2)" || pool.lastSQL != "" {
+		t.Fatalf("tx publish: %q, pool: %q", txEx.lastSQL, pool.lastSQL)
+	}
+}
+
+func TestNotifyCache_ApplySkipsOwnNotifications(t *testing.T) {
+	local := &keyCache{}
+	c := &notifyCache{Cache: local, origin: "me"}
+	_ = c.apply(context.Background(), `{"o":"me","k":["mine"]}`)
+	_ = c.apply(context.Background(), `{"o":"other","k":["theirs"]}`)
+	if !slices.Equal(local.dropped, []string{"theirs"}) {
+		t.Fatalf("dropped: %v", local.dropped)
+	}
+	if err := c.apply(context.Background(), "not json"); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestStartCacheListener_NoopWithoutCache(t *testing.T) {
+	kn := &KintsNorm{}
+	kn.startCacheListener("norm_cache")
+	if kn.stopCacheListener != nil || kn.cache != nil {
+		t.Fatal("listener started without a cache")
+	}
+}
+
+func TestStartCacheListener_KeepsLoadingCache(t *testing.T) {
+	// the pool connects lazily: the listener keeps retrying in the background until stopped
+	pool, err := pgxpool.New(context.Background(), "postgres://u@127.0.0.1:1/db?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	inner := &loadingMapCache{mapCache: mapCache{data: map[string][]byte{}}}
+	kn := &KintsNorm{pool: pool, cache: inner}
+	kn.startCacheListener("norm_cache")
+	defer kn.stopCacheListener()
+	if _, ok := kn.cache.(LoadingCache); !ok {
+		t.Fatalf("cache %T lost GetOrLoad", kn.cache)
+	}
+	f := &fakeExec{rows: [][]any{{int64(2)}}, fields: []string{"id"}}
+	for range 2 {
+		var out []map[string]any
+		if err := (&QueryBuilder{kn: kn, exec: f}).Table("users").WithCacheKey("users", time.Minute).Find(context.Background(), &out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if inner.loads != 1 {
+		t.Fatalf("loads=%d, want the inner GetOrLoad to run once", inner.loads)
+	}
+
+	// a plain cache underneath still reads through
+	plain := &notifyCache{Cache: &mapCache{data: map[string][]byte{}}}
+	loads := 0
+	load := func(context.Context) ([]byte, error) { loads++; return []byte("v"), nil }
+	for range 2 {
+		if b, err := plain.GetOrLoad(context.Background(), "k", time.Minute, load); err != nil || string(b) != "v" {
+			t.Fatalf("b=%q err=%v", b, err)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("loads=%d", loads)
+	}
+}
@@ -70,6 +70,19 @@ func (cb *circuitBreaker) setState(s circuitState) {
 	}
 }
 
+// stateName returns the current state as reported to Metrics.CircuitStateChanged
+func (cb *circuitBreaker) stateName() string {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	switch cb.state {
+	case stateOpen:
+		return "open"
+	case stateHalfOpen:
+		return "half_open"
+	}
+	return "closed"
+}
+
 // before must be called right before an operation is attempted
 func (cb *circuitBreaker) before() error {
 	cb.mu.Lock()
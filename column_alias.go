@@ -0,0 +1,42 @@
+package norm
+
+import (
+	"reflect"
+
+	"github.com/kintsdev/norm/internal/core"
+)
+
+// ColumnOf returns the quoted physical column of name on model T, resolving a db:"name,column:Physical"
+// alias, for conditions and raw SQL against legacy columns:
+//
+//	type Customer struct {
+//		Email string `db:"email,column:EmailAddress"`
+//	}
+//	rows, err := customers.Find(ctx, norm.Eq(norm.ColumnOf[Customer]("email"), addr)) // "EmailAddress" = $1
+func ColumnOf[T any](name string) string {
+	return quoteQualified(core.StructMapper(reflect.TypeFor[T]()).Resolve(name))
+}
+
+// resolveColumns maps the aliases among names to their physical columns; names without an alias are kept
+func resolveColumns(m core.StructMapping, names []string) []string {
+	if len(m.Aliases) == 0 || len(names) == 0 {
+		return names
+	}
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = m.Resolve(n)
+	}
+	return out
+}
+
+// resolveFields is resolveColumns for the keys of a column -> value map
+func resolveFields(m core.StructMapping, fields map[string]any) map[string]any {
+	if len(m.Aliases) == 0 {
+		return fields
+	}
+	out := make(map[string]any, len(fields))
+	for k, v := range fields {
+		out[m.Resolve(k)] = v
+	}
+	return out
+}
@@ -0,0 +1,47 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type legacyCustomer struct {
+	ID    int64  `db:"id,column:CustomerID" norm:"primary_key"`
+	Email string `db:"email,column:EmailAddress"`
+	Name  string `db:"name"`
+}
+
+func TestColumnAlias_TargetsPhysicalColumns(t *testing.T) {
+	ctx := context.Background()
+	rec := &recExecRepo{}
+	customers := NewRepositoryWithExecutor[legacyCustomer](&KintsNorm{}, rec)
+
+	if err := customers.UpdatePartial(ctx, 7, map[string]any{"email": "a@b.c"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := `UPDATE legacy_customers SET "EmailAddress" = $1 WHERE "CustomerID" = $2`; rec.lastSQL != want {
+		t.Fatalf("sql=%s", rec.lastSQL)
+	}
+	if err := customers.Delete(ctx, 7); err != nil {
+		t.Fatal(err)
+	}
+	if want := `DELETE FROM legacy_customers WHERE "CustomerID" = $1`; rec.lastSQL != want {
+		t.Fatalf("sql=%s", rec.lastSQL)
+	}
+	if _, err := customers.GetByID(ctx, 7); err == nil || !strings.Contains(rec.lastSQL, `WHERE "CustomerID" = $1`) {
+		t.Fatalf("sql=%s err=%v", rec.lastSQL, err)
+	}
+	if err := customers.Upsert(ctx, &legacyCustomer{ID: 7, Email: "a@b.c"}, []string{"email"}, []string{"name"}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(rec.lastSQL, `ON CONFLICT ("EmailAddress")`) {
+		t.Fatalf("sql=%s", rec.lastSQL)
+	}
+	if got := ColumnOf[legacyCustomer]("email"); got != `"EmailAddress"` {
+		t.Fatalf("ColumnOf=%s", got)
+	}
+	if got := ColumnOf[legacyCustomer]("name"); got != `"name"` {
+		t.Fatalf("ColumnOf=%s", got)
+	}
+}
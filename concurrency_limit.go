@@ -0,0 +1,109 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// concurrency slots are polled with exponential backoff between these bounds while all are taken
+const (
+	concurrencyPollMin = 20 * time.Millisecond
+	concurrencyPollMax = time.Second
+)
+
+// WithConcurrencyKey limits how many reads (Find, First, Last, Count, FindEach/Iterate) tagged with key run at once
+// across every instance sharing the database. Each run holds one of maxParallel transaction-scoped advisory locks
+// (pg_try_advisory_xact_lock(hashtext(key), slot)); when all are taken it waits until one frees up or ctx is done.
+// The lock transaction is opened on the pool the read is routed to, so with a read pool the slots and the
+// query both live on the replica.
+//
+//	db.Query().Table("orders").Select(...).GroupBy(...).WithConcurrencyKey("report:heavy", 2).Find(ctx, &rows)
+func (qb *QueryBuilder) WithConcurrencyKey(key string, maxParallel int) *QueryBuilder {
+	if maxParallel < 1 {
+		qb.setError(fmt.Errorf("WithConcurrencyKey: maxParallel must be >= 1, got %d", maxParallel))
+		return qb
+	}
+	qb.concurrencyKey = key
+	qb.concurrencyMax = maxParallel
+	return qb
+}
+
+// acquireConcurrencySlot opens a transaction holding one of the key's slots and returns a copy of the builder
+// bound to it. The caller must roll the transaction back (the query is read-only) to release the slot.
+func (qb *QueryBuilder) acquireConcurrencySlot(ctx context.Context) (*QueryBuilder, pgx.Tx, error) {
+	tx, err := qb.beginReadTx(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	const try = `SELECT pg_try_advisory_xact_lock(hashtext($1), $2)`
+	wait := concurrencyPollMin
+	for {
+		for slot := range qb.concurrencyMax {
+			var ok bool
+			if err := tx.QueryRow(ctx, try, qb.concurrencyKey, slot).Scan(&ok); err != nil {
+				_ = tx.Rollback(ctx)
+				return nil, nil, wrapPgError(err, try, []any{qb.concurrencyKey, slot})
+			}
+			if ok {
+				q := *qb
+				q.concurrencyKey = ""
+				q.exec = wrapExec(qb.kn, tx)
+				return &q, tx, nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+			return nil, nil, &ORMError{Code: ErrCodeConnection, Message: fmt.Sprintf("waiting for concurrency slot %q: %v", qb.concurrencyKey, ctx.Err()), Internal: ctx.Err()}
+		case <-time.After(wait):
+		}
+		wait = min(wait*2, concurrencyPollMax)
+	}
+}
+
+// beginReadTx opens the slot transaction where the read would have run: on the read pool when the builder
+// routes reads there, or on the primary when WaitForLSN gives up waiting for the replica
+func (qb *QueryBuilder) beginReadTx(ctx context.Context) (pgx.Tx, error) {
+	exec := qb.exec
+	for {
+		switch e := exec.(type) {
+		case ctxTxExecuter:
+			exec = e.pick(ctx)
+		case lsnExecuter:
+			exec = e.pick(ctx, "SELECT")
+		case execWrapper:
+			exec = e.unwrap()
+		case routingExecuter:
+			return e.kn.poolExec(e.kn.ReadPool()).Begin(ctx)
+		case txBeginner:
+			return e.Begin(ctx)
+		default:
+			return nil, &ORMError{Code: ErrCodeTransaction, Message: "executor does not support transactions"}
+		}
+	}
+}
+
+// runLimited runs read on a builder holding a concurrency slot, releasing it afterwards
+func (qb *QueryBuilder) runLimited(ctx context.Context, read func(q *QueryBuilder) error) error {
+	q, tx, err := qb.acquireConcurrencySlot(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(context.WithoutCancel(ctx)) }()
+	return read(q)
+}
+
+// slotRows releases the concurrency slot once a streaming caller closes the rows
+type slotRows struct {
+	pgx.Rows
+	ctx context.Context
+	tx  pgx.Tx
+}
+
+func (r *slotRows) Close() {
+	r.Rows.Close()
+	_ = r.tx.Rollback(context.WithoutCancel(r.ctx))
+}
@@ -0,0 +1,124 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// slotDB emulates transaction-scoped advisory locks shared by all transactions
+type slotDB struct {
+	idemDB
+	held map[int]bool
+}
+
+func (d *slotDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	d.begins++
+	return &slotTx{db: d}, nil
+}
+
+type slotTx struct {
+	pgx.Tx
+	db   *slotDB
+	mine []int
+	sqls []string
+}
+
+type boolRow bool
+
+func (b boolRow) Scan(dest ...any) error {
+	*(dest[0].(*bool)) = bool(b)
+	return nil
+}
+
+func (t *slotTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	slot := args[1].(int)
+	if t.db.held[slot] {
+		return boolRow(false)
+	}
+	t.db.held[slot] = true
+	t.mine = append(t.mine, slot)
+	return boolRow(true)
+}
+func (t *slotTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	t.sqls = append(t.sqls, sql)
+	return &fakeRowsRU{rows: [][]any{{int64(3)}}, fields: []string{"count"}}, nil
+}
+func (t *slotTx) Rollback(ctx context.Context) error {
+	for _, s := range t.mine {
+		delete(t.db.held, s)
+	}
+	t.mine = nil
+	return nil
+}
+
+func TestWithConcurrencyKey_TakesFreeSlotAndReleases(t *testing.T) {
+	db := &slotDB{held: map[int]bool{0: true}}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: db}).Table("orders").WithConcurrencyKey("report:heavy", 2)
+	n, err := qb.Count(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	if len(db.held) != 1 || !db.held[0] {
+		t.Fatalf("slot 1 should be released, held=%v", db.held)
+	}
+}
+
+func TestWithConcurrencyKey_WaitsUntilContextDone(t *testing.T) {
+	db := &slotDB{held: map[int]bool{0: true}}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var rows []map[string]any
+	err := (&QueryBuilder{kn: &KintsNorm{}, exec: db}).Table("orders").WithConcurrencyKey("k", 1).Find(ctx, &rows)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline error, got %v", err)
+	}
+}
+
+func TestWithConcurrencyKey_StreamReleasesOnClose(t *testing.T) {
+	db := &slotDB{held: map[int]bool{}}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: db}).Table("orders").WithConcurrencyKey("k", 1)
+	for _, err := range Iterate[map[string]any](context.Background(), qb) {
+		if err != nil {
+			t.Fatalf("err=%v", err)
+		}
+		if !db.held[0] {
+			t.Fatalf("slot should be held while streaming")
+		}
+	}
+	if len(db.held) != 0 {
+		t.Fatalf("slot not released: %v", db.held)
+	}
+	if (&QueryBuilder{}).WithConcurrencyKey("k", 0).queryError() == nil {
+		t.Fatalf("expected validation error for maxParallel 0")
+	}
+}
+
+func TestWithConcurrencyKey_HoldsSlotOnReadPool(t *testing.T) {
+	ctx := context.Background()
+	// the pools connect lazily to closed ports: the dial error names the pool the slot was taken on
+	primary, err := pgxpool.New(ctx, "postgres://u@127.0.0.1:1/db?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer primary.Close()
+	replica, err := pgxpool.New(ctx, "postgres://u@127.0.0.1:2/db?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer replica.Close()
+	kn := &KintsNorm{pool: primary, readPool: replica}
+	_, err = kn.Query().Table("orders").WithConcurrencyKey("report:heavy", 2).Count(ctx)
+	if err == nil || !strings.Contains(err.Error(), "127.0.0.1:2") {
+		t.Fatalf("slot transaction should dial the replica, got %v", err)
+	}
+	_, err = kn.Query().UsePrimary().Table("orders").WithConcurrencyKey("report:heavy", 2).Count(ctx)
+	if err == nil || !strings.Contains(err.Error(), "127.0.0.1:1") {
+		t.Fatalf("UsePrimary should keep the slot on the primary, got %v", err)
+	}
+}
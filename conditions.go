@@ -1,6 +1,7 @@
 package norm
 
 import (
+	"fmt"
 	"strings"
 	"time"
 )
@@ -38,6 +39,75 @@ func In(col string, vals []any) Condition {
 	return Condition{Expr: sb.String(), Args: args}
 }
 
+// tupleCompare builds a row-value comparison like "(a, b) > (?, ?)"
+func tupleCompare(cols []string, op string, vals []any) Condition {
+	if len(cols) == 0 || len(cols) != len(vals) {
+		return Condition{Expr: "1=0"}
+	}
+	var sb strings.Builder
+	sb.WriteByte('(')
+	sb.WriteString(strings.Join(cols, ", "))
+	sb.WriteString(") ")
+	sb.WriteString(op)
+	sb.WriteString(" (")
+	for i := range vals {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('?')
+	}
+	sb.WriteByte(')')
+	args := make([]any, len(vals))
+	copy(args, vals)
+	return Condition{Expr: sb.String(), Args: args}
+}
+
+// TupleEq builds a row-value equality "(a, b) = (?, ?)"; mismatched lengths produce an always-false condition
+func TupleEq(cols []string, vals []any) Condition { return tupleCompare(cols, "=", vals) }
+
+// TupleGt builds "(a, b) > (?, ?)", useful for multi-column keyset pagination
+func TupleGt(cols []string, vals []any) Condition { return tupleCompare(cols, ">", vals) }
+
+// TupleLt builds "(a, b) < (?, ?)"
+func TupleLt(cols []string, vals []any) Condition { return tupleCompare(cols, "<", vals) }
+
+// TupleIn builds "(a, b) IN ((?, ?), (?, ?))" for composite-key lookups.
+// Rows whose length does not match cols are skipped; no rows produce an always-false condition.
+func TupleIn(cols []string, rows [][]any) Condition {
+	if len(cols) == 0 {
+		return Condition{Expr: "1=0"}
+	}
+	args := make([]any, 0, len(rows)*len(cols))
+	var sb strings.Builder
+	sb.WriteByte('(')
+	sb.WriteString(strings.Join(cols, ", "))
+	sb.WriteString(") IN (")
+	n := 0
+	for _, r := range rows {
+		if len(r) != len(cols) {
+			continue
+		}
+		if n > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteByte('(')
+		for i := range r {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteByte('?')
+		}
+		sb.WriteByte(')')
+		args = append(args, r...)
+		n++
+	}
+	if n == 0 {
+		return Condition{Expr: "1=0"}
+	}
+	sb.WriteByte(')')
+	return Condition{Expr: sb.String(), Args: args}
+}
+
 func RawCond(expr string, args ...any) Condition { return Condition{Expr: expr, Args: args} }
 
 // Between builds a generic BETWEEN condition inclusive of both ends
@@ -103,3 +173,41 @@ func Or(conds ...Condition) Condition {
 	}
 	return Condition{Expr: sb.String(), Args: args}
 }
+
+// JSONContains matches rows where the jsonb column contains the given document (col @> value).
+// value may be raw JSON (string/[]byte/json.RawMessage) or any value pgx can marshal to JSON (map, struct, slice).
+func JSONContains(col string, value any) Condition {
+	return Condition{Expr: col + " @> ?::jsonb", Args: []any{value}}
+}
+
+// JSONPathExists matches rows where the SQL/JSON path returns at least one item for the jsonb column
+func JSONPathExists(col string, path string) Condition {
+	return Condition{Expr: "jsonb_path_exists(" + col + ", ?::jsonpath)", Args: []any{path}}
+}
+
+// JSONHasKey matches rows where the jsonb column has the given top-level key
+// (uses jsonb_exists because the ? operator clashes with placeholders)
+func JSONHasKey(col string, key string) Condition {
+	return Condition{Expr: "jsonb_exists(" + col + ", ?)", Args: []any{key}}
+}
+
+// JSONEq compares the text value at a dotted path (e.g. "address.city") of a json/jsonb column
+func JSONEq(col string, path string, value any) Condition {
+	if s, ok := value.(string); !ok {
+		value = fmt.Sprint(value)
+	} else {
+		value = s
+	}
+	return Condition{Expr: col + " #>> ? = ?", Args: []any{strings.Split(path, "."), value}}
+}
+
+// AnyOf matches rows where col equals any element of the given slice (col = ANY(?)).
+// Unlike In, the whole slice is bound as a single array parameter, so the SQL shape is stable regardless of its length.
+func AnyOf(col string, values any) Condition {
+	return Condition{Expr: col + " = ANY(?)", Args: []any{values}}
+}
+
+// Contains matches rows where the array column contains every element of values (col @> ?)
+func Contains(col string, values any) Condition {
+	return Condition{Expr: col + " @> ?", Args: []any{values}}
+}
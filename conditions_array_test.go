@@ -0,0 +1,16 @@
+package norm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestArrayConditions(t *testing.T) {
+	tags := []string{"go", "sql"}
+	if c := AnyOf("id", []int64{1, 2}); c.Expr != "id = ANY(?)" || !reflect.DeepEqual(c.Args, []any{[]int64{1, 2}}) {
+		t.Fatalf("any=%+v", c)
+	}
+	if c := Contains("tags", tags); c.Expr != "tags @> ?" || len(c.Args) != 1 {
+		t.Fatalf("contains=%+v", c)
+	}
+}
@@ -0,0 +1,198 @@
+package norm
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FilterOp is an operator usable in URL query filters (?age=gte:18)
+type FilterOp string
+
+const (
+	FilterEq    FilterOp = "eq"
+	FilterNe    FilterOp = "ne"
+	FilterGt    FilterOp = "gt"
+	FilterGte   FilterOp = "gte"
+	FilterLt    FilterOp = "lt"
+	FilterLte   FilterOp = "lte"
+	FilterLike  FilterOp = "like"  // '*' matches any run of characters
+	FilterILike FilterOp = "ilike" // case-insensitive like
+	FilterIn    FilterOp = "in"    // comma-separated values
+	FilterNull  FilterOp = "null"  // null:true -> IS NULL, null:false -> IS NOT NULL
+)
+
+var filterOps = []FilterOp{FilterEq, FilterNe, FilterGt, FilterGte, FilterLt, FilterLte, FilterLike, FilterILike, FilterIn, FilterNull}
+
+// FilterType controls how filter values are parsed before binding
+type FilterType int
+
+const (
+	FilterString FilterType = iota
+	FilterInt
+	FilterFloat
+	FilterBool
+	FilterTime // RFC 3339 or YYYY-MM-DD
+)
+
+// FilterSpec allows filtering on one query key
+type FilterSpec struct {
+	Column string     // column to filter; defaults to the query key
+	Type   FilterType // value parsing (default FilterString)
+	// Ops lists the allowed operators; empty allows all of them (like/ilike on strings only)
+	Ops []FilterOp
+}
+
+func (s FilterSpec) allows(op FilterOp) bool {
+	if len(s.Ops) > 0 {
+		return slices.Contains(s.Ops, op)
+	}
+	return (op != FilterLike && op != FilterILike) || s.Type == FilterString
+}
+
+// FilterFromQuery converts URL query filters into a Condition, e.g.
+//
+//	?email=like:*@x.com&age=gte:18&status=in:active,trial
+//
+// Values are "op:value" or a bare value (eq). Only keys present in allowed are considered; other keys
+// (page, limit, ...) are ignored. Repeated keys are ANDed. A disallowed operator or an unparsable value is an
+// ErrCodeValidation error. Columns come from allowed, never from the request, and values are always bound.
+func FilterFromQuery(values url.Values, allowed map[string]FilterSpec) (Condition, error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		if _, ok := allowed[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	var conds []Condition
+	for _, k := range keys {
+		spec := allowed[k]
+		col := spec.Column
+		if col == "" {
+			col = k
+		}
+		col = quoteQualified(col)
+		for _, raw := range values[k] {
+			c, err := parseFilter(col, raw, spec)
+			if err != nil {
+				return Condition{}, &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("filter %s: %s", k, err.Error()), Internal: err}
+			}
+			conds = append(conds, c)
+		}
+	}
+	if len(conds) == 1 {
+		return conds[0], nil
+	}
+	return And(conds...), nil
+}
+
+func parseFilter(col, raw string, spec FilterSpec) (Condition, error) {
+	op, val := FilterEq, raw
+	if name, rest, ok := strings.Cut(raw, ":"); ok && slices.Contains(filterOps, FilterOp(strings.ToLower(name))) {
+		op, val = FilterOp(strings.ToLower(name)), rest
+	}
+	if !spec.allows(op) {
+		return Condition{}, fmt.Errorf("operator %s not allowed", op)
+	}
+	switch op {
+	case FilterNull:
+		isNull, err := strconv.ParseBool(val)
+		if err != nil {
+			return Condition{}, fmt.Errorf("null expects true or false, got %q", val)
+		}
+		if isNull {
+			return Condition{Expr: col + " IS NULL"}, nil
+		}
+		return Condition{Expr: col + " IS NOT NULL"}, nil
+	case FilterLike, FilterILike:
+		// backslash is the default LIKE escape character
+		kw := " LIKE ?"
+		if op == FilterILike {
+			kw = " ILIKE ?"
+		}
+		return Condition{Expr: col + kw, Args: []any{likePattern(val)}}, nil
+	case FilterIn:
+		parts := strings.Split(val, ",")
+		vals := make([]any, 0, len(parts))
+		for _, p := range parts {
+			v, err := parseFilterValue(strings.TrimSpace(p), spec.Type)
+			if err != nil {
+				return Condition{}, err
+			}
+			vals = append(vals, v)
+		}
+		return In(col, vals), nil
+	}
+	v, err := parseFilterValue(val, spec.Type)
+	if err != nil {
+		return Condition{}, err
+	}
+	switch op {
+	case FilterNe:
+		return Ne(col, v), nil
+	case FilterGt:
+		return Gt(col, v), nil
+	case FilterGte:
+		return Ge(col, v), nil
+	case FilterLt:
+		return Lt(col, v), nil
+	case FilterLte:
+		return Le(col, v), nil
+	}
+	return Eq(col, v), nil
+}
+
+func parseFilterValue(s string, typ FilterType) (any, error) {
+	switch typ {
+	case FilterInt:
+		n, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", s)
+		}
+		return n, nil
+	case FilterFloat:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", s)
+		}
+		return f, nil
+	case FilterBool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean %q", s)
+		}
+		return b, nil
+	case FilterTime:
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t, nil
+		}
+		t, err := time.Parse(time.DateOnly, s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time %q", s)
+		}
+		return t, nil
+	}
+	return s, nil
+}
+
+// likePattern escapes LIKE metacharacters in s and turns '*' into '%'
+func likePattern(s string) string {
+	var sb strings.Builder
+	sb.Grow(len(s) + 4)
+	for _, r := range s {
+		switch r {
+		case '%', '_', '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case '*':
+			sb.WriteByte('%')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,45 @@
+package norm
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFilterFromQuery(t *testing.T) {
+	q, _ := url.ParseQuery("email=like:*@x.com&age=gte:18&age=lt:65&status=in:active,trial&page=2&deleted_at=null:true")
+	c, err := FilterFromQuery(q, map[string]FilterSpec{
+		"email":      {},
+		"age":        {Type: FilterInt},
+		"status":     {Ops: []FilterOp{FilterEq, FilterIn}},
+		"deleted_at": {Ops: []FilterOp{FilterNull}},
+	})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `("age" >= ?) AND ("age" < ?) AND ("deleted_at" IS NULL) AND ("email" LIKE ?) AND ("status" IN (?, ?))`
+	if c.Expr != want || !reflect.DeepEqual(c.Args, []any{int64(18), int64(65), "%@x.com", "active", "trial"}) {
+		t.Fatalf("expr=%s args=%v", c.Expr, c.Args)
+	}
+
+	single, err := FilterFromQuery(url.Values{"name": {"a_b%"}}, map[string]FilterSpec{"name": {Column: "u.name", Ops: []FilterOp{FilterEq, FilterILike}}})
+	if err != nil || single.Expr != `"u"."name" = ?` || single.Args[0] != "a_b%" {
+		t.Fatalf("expr=%s args=%v err=%v", single.Expr, single.Args, err)
+	}
+	if got := likePattern("50%_off*"); got != `50\%\_off%` {
+		t.Fatalf("pattern=%s", got)
+	}
+}
+
+func TestFilterFromQuery_Rejects(t *testing.T) {
+	allowed := map[string]FilterSpec{"age": {Type: FilterInt}, "status": {Ops: []FilterOp{FilterEq}}}
+	for _, raw := range []string{"age=gte:abc", "age=like:1*", "status=ne:x"} {
+		q, _ := url.ParseQuery(raw)
+		_, err := FilterFromQuery(q, allowed)
+		var oe *ORMError
+		if !errors.As(err, &oe) || oe.Code != ErrCodeValidation {
+			t.Fatalf("%s: err=%v", raw, err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package norm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestJSONConditions(t *testing.T) {
+	if c := JSONContains("meta", map[string]any{"a": 1}); c.Expr != "meta @> ?::jsonb" || len(c.Args) != 1 {
+		t.Fatalf("contains=%+v", c)
+	}
+	if c := JSONPathExists("meta", "$.tags[*]"); c.Expr != "jsonb_path_exists(meta, ?::jsonpath)" {
+		t.Fatalf("path=%+v", c)
+	}
+	if c := JSONHasKey("meta", "a"); c.Expr != "jsonb_exists(meta, ?)" {
+		t.Fatalf("haskey=%+v", c)
+	}
+	c := JSONEq("meta", "address.city", 7)
+	if p, ok := c.Args[0].([]string); !ok || len(p) != 2 || c.Args[1] != "7" {
+		t.Fatalf("eq=%+v", c)
+	}
+}
+
+type jsonDoc struct {
+	ID   int64             `db:"id"`
+	Meta map[string]string `db:"meta" norm:"jsonb"`
+	Tags []string          `db:"tags" norm:"jsonb"`
+}
+
+func TestFind_DecodesJSONBFields(t *testing.T) {
+	f := &fakeExecRU{rows: [][]any{{int64(1), map[string]any{"k": "v"}, []byte(`["a","b"]`)}}, fields: []string{"id", "meta", "tags"}}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: f}).Table("json_docs").WhereJSON("meta", "k", "v")
+	var out []jsonDoc
+	if err := qb.Find(context.Background(), &out); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if len(out) != 1 || out[0].Meta["k"] != "v" || len(out[0].Tags) != 2 {
+		t.Fatalf("out=%+v", out)
+	}
+	if f.lastSQL != "SELECT * FROM json_docs WHERE meta #>> $1 = $2" {
+		t.Fatalf("sql=%s", f.lastSQL)
+	}
+}
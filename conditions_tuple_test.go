@@ -0,0 +1,26 @@
+package norm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTupleConditions(t *testing.T) {
+	c := TupleEq([]string{"a", "b"}, []any{1, 2})
+	if c.Expr != "(a, b) = (?, ?)" || !reflect.DeepEqual(c.Args, []any{1, 2}) {
+		t.Fatalf("eq=%+v", c)
+	}
+	if c := TupleGt([]string{"created_at", "id"}, []any{"t", 5}); c.Expr != "(created_at, id) > (?, ?)" {
+		t.Fatalf("gt=%+v", c)
+	}
+	if c := TupleEq([]string{"a"}, []any{1, 2}); c.Expr != "1=0" {
+		t.Fatalf("mismatch=%+v", c)
+	}
+	in := TupleIn([]string{"a", "b"}, [][]any{{1, 2}, {3, 4}, {5}})
+	if in.Expr != "(a, b) IN ((?, ?), (?, ?))" || !reflect.DeepEqual(in.Args, []any{1, 2, 3, 4}) {
+		t.Fatalf("in=%+v", in)
+	}
+	if c := TupleIn([]string{"a"}, nil); c.Expr != "1=0" {
+		t.Fatalf("empty=%+v", c)
+	}
+}
@@ -24,6 +24,16 @@ type Config struct {
 	RetryAttempts          int           // transient error retries (default 0 = no retry)
 	RetryBackoff           time.Duration // backoff between retries
 	StatementCacheCapacity int           // pgx per-conn statement cache capacity (0 = default)
+	// SessionSettings are run-time parameters (GUCs) set on every new connection of the primary and read pools,
+	// e.g. {"statement_timeout": "5s", "idle_in_transaction_session_timeout": "30s", "timezone": "UTC"}
+	SessionSettings map[string]string
+	// DefaultQueryTimeout bounds every statement with a context deadline (0 = none); override per builder with
+	// QueryBuilder.WithTimeout or per repository with WithQueryTimeout
+	DefaultQueryTimeout time.Duration
+	// AcquireTimeout bounds the wait for a pooled connection (0 = until the context is done); MaxWaiters caps
+	// the callers waiting on a saturated pool (0 = no cap). Either fails the request with ErrPoolExhausted.
+	AcquireTimeout time.Duration
+	MaxWaiters     int
 	// Circuit breaker
 	CircuitBreakerEnabled   bool
 	CircuitFailureThreshold int           // consecutive failures to open the circuit (default 5 if 0)
@@ -4,13 +4,17 @@ import (
 	"context"
 	"errors"
 	"math/rand/v2"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func newPool(ctx context.Context, cfg *Config) (*pgxpool.Pool, error) {
+// connHook runs on every new pgx connection (e.g. custom type registration); nil means none
+type connHook func(ctx context.Context, conn *pgx.Conn) error
+
+func newPool(ctx context.Context, cfg *Config, afterConnect connHook) (*pgxpool.Pool, error) {
 	if cfg == nil {
 		return nil, errors.New("nil config")
 	}
@@ -37,6 +41,10 @@ func newPool(ctx context.Context, cfg *Config) (*pgxpool.Pool, error) {
 		conf.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
 		conf.ConnConfig.StatementCacheCapacity = cfg.StatementCacheCapacity
 	}
+	applySessionSettings(conf, cfg.SessionSettings)
+	if afterConnect != nil {
+		conf.AfterConnect = afterConnect
+	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, conf)
 	if err != nil {
@@ -45,11 +53,15 @@ func newPool(ctx context.Context, cfg *Config) (*pgxpool.Pool, error) {
 	return pool, nil
 }
 
-func newPoolFromConnString(ctx context.Context, connString string) (*pgxpool.Pool, error) {
+func newPoolFromConnString(ctx context.Context, connString string, settings map[string]string, afterConnect connHook) (*pgxpool.Pool, error) {
 	conf, err := pgxpool.ParseConfig(connString)
 	if err != nil {
 		return nil, err
 	}
+	applySessionSettings(conf, settings)
+	if afterConnect != nil {
+		conf.AfterConnect = afterConnect
+	}
 	pool, err := pgxpool.NewWithConfig(ctx, conf)
 	if err != nil {
 		return nil, err
@@ -57,6 +69,20 @@ func newPoolFromConnString(ctx context.Context, connString string) (*pgxpool.Poo
 	return pool, nil
 }
 
+// applySessionSettings sends the settings as startup run-time parameters, so they apply to every
+// connection before its first statement without an extra round trip
+func applySessionSettings(conf *pgxpool.Config, settings map[string]string) {
+	if len(settings) == 0 {
+		return
+	}
+	if conf.ConnConfig.RuntimeParams == nil {
+		conf.ConnConfig.RuntimeParams = make(map[string]string, len(settings))
+	}
+	for k, v := range settings {
+		conf.ConnConfig.RuntimeParams[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+}
+
 func healthCheck(ctx context.Context, pool *pgxpool.Pool) error {
 	if pool == nil {
 		return errors.New("nil pool")
@@ -98,6 +124,9 @@ func (kn *KintsNorm) withRetry(ctx context.Context, fn func() error) error {
 		if err == nil {
 			return nil
 		}
+		if rm, ok := kn.metrics.(RetryMetrics); ok && i < attempts-1 {
+			rm.Retry(i + 1)
+		}
 		if i < attempts-1 && baseBackoff > 0 {
 			// exponential backoff with jitter
 			sleep := baseBackoff << i
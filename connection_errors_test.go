@@ -6,7 +6,7 @@ import (
 )
 
 func TestNewPool_NilConfig(t *testing.T) {
-	if _, err := newPool(context.Background(), nil); err == nil {
+	if _, err := newPool(context.Background(), nil, nil); err == nil {
 		t.Fatalf("expected error for nil config")
 	}
 }
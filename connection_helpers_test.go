@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func TestWithRetry_RespectsBackoffAndAttempts(t *testing.T) {
@@ -15,3 +17,16 @@ func TestWithRetry_RespectsBackoffAndAttempts(t *testing.T) {
 		t.Fatalf("expected 2 attempts, got %d err=%v", calls, err)
 	}
 }
+
+func TestApplySessionSettings_SetsRuntimeParams(t *testing.T) {
+	c := &Config{Database: "d", Username: "u", ApplicationName: "app"}
+	conf, err := pgxpool.ParseConfig(c.ConnString())
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	applySessionSettings(conf, map[string]string{"statement_timeout": "5s", " TimeZone ": "UTC"})
+	p := conf.ConnConfig.RuntimeParams
+	if p["statement_timeout"] != "5s" || p["timezone"] != "UTC" || p["application_name"] != "app" {
+		t.Fatalf("params=%v", p)
+	}
+}
@@ -2,10 +2,12 @@ package norm
 
 import (
 	"context"
+	"strings"
 	"sync"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/kintsdev/norm/internal/sqlutil"
 )
 
 // dbExecuter abstracts pgxpool.Pool and pgx.Tx
@@ -21,8 +23,10 @@ type breakerExecuter struct {
 	exec dbExecuter
 }
 
+func (b breakerExecuter) unwrap() dbExecuter { return b.exec }
+
 func (b breakerExecuter) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
-	if br := b.kn.breaker; br != nil {
+	if br := b.kn.breakerFor(ctx); br != nil {
 		if err := br.before(); err != nil {
 			return pgconn.CommandTag{}, err
 		}
@@ -34,7 +38,7 @@ func (b breakerExecuter) Exec(ctx context.Context, sql string, arguments ...any)
 }
 
 func (b breakerExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
-	if br := b.kn.breaker; br != nil {
+	if br := b.kn.breakerFor(ctx); br != nil {
 		if err := br.before(); err != nil {
 			return nil, err
 		}
@@ -46,7 +50,7 @@ func (b breakerExecuter) Query(ctx context.Context, sql string, args ...any) (pg
 }
 
 func (b breakerExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
-	if br := b.kn.breaker; br != nil {
+	if br := b.kn.breakerFor(ctx); br != nil {
 		if err := br.before(); err != nil {
 			// emulate a Row with immediate error; pgx.Row is interface with Scan method
 			return errorRow{err: err}
@@ -79,12 +83,41 @@ func (r *rowWithAfter) Scan(dest ...any) error {
 	return err
 }
 
-// routingExecuter routes read operations (Query/QueryRow) to readPool when available, writes (Exec) to primary pool
+// routingExecuter routes read operations (Query/QueryRow) to readPool when available, writes (Exec) to primary pool.
+// Data-modifying statements issued through Query/QueryRow (e.g. INSERT ... RETURNING) are routed to primary.
 type routingExecuter struct{ kn *KintsNorm }
 
+// isWriteSQL reports whether a statement modifies data and therefore must run on the primary: INSERT,
+// UPDATE, DELETE or MERGE, also after leading comments or parentheses and inside WITH queries
+func isWriteSQL(sql string) bool {
+	switch statementVerb(sql) {
+	case "INSERT", "UPDATE", "DELETE", "MERGE":
+		return true
+	case "WITH", "":
+		return writesData(sqlutil.Blank(sql))
+	}
+	return false
+}
+
+// writesData is isWriteSQL for a blanked statement, walking its WITH queries
+func writesData(s string) bool {
+	s = strings.TrimLeft(s, " \t\r\n(")
+	ctes, main := splitWith(s)
+	for _, c := range ctes {
+		if writesData(c) {
+			return true
+		}
+	}
+	switch statementVerb(strings.TrimLeft(main, " \t\r\n(")) {
+	case "INSERT", "UPDATE", "DELETE", "MERGE":
+		return true
+	}
+	return false
+}
+
 func (r routingExecuter) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
-	exec := dbExecuter(r.kn.pool)
-	if br := r.kn.breaker; br != nil {
+	exec := dbExecuter(r.kn.poolExec(r.kn.pool))
+	if br := r.kn.breakerFor(ctx); br != nil {
 		if err := br.before(); err != nil {
 			return pgconn.CommandTag{}, err
 		}
@@ -96,8 +129,11 @@ func (r routingExecuter) Exec(ctx context.Context, sql string, arguments ...any)
 }
 
 func (r routingExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
-	exec := dbExecuter(r.kn.ReadPool())
-	if br := r.kn.breaker; br != nil {
+	exec := dbExecuter(r.kn.poolExec(r.kn.ReadPool()))
+	if isWriteSQL(sql) {
+		exec = r.kn.poolExec(r.kn.pool)
+	}
+	if br := r.kn.breakerFor(ctx); br != nil {
 		if err := br.before(); err != nil {
 			return nil, err
 		}
@@ -109,8 +145,11 @@ func (r routingExecuter) Query(ctx context.Context, sql string, args ...any) (pg
 }
 
 func (r routingExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
-	exec := dbExecuter(r.kn.ReadPool())
-	if br := r.kn.breaker; br != nil {
+	exec := dbExecuter(r.kn.poolExec(r.kn.ReadPool()))
+	if isWriteSQL(sql) {
+		exec = r.kn.poolExec(r.kn.pool)
+	}
+	if br := r.kn.breakerFor(ctx); br != nil {
 		if err := br.before(); err != nil {
 			return errorRow{err: err}
 		}
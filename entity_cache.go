@@ -0,0 +1,172 @@
+package norm
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// Cached returns a copy of the repository keeping the rows loaded by GetByID, FindByPK and FindOne in the
+// Cache configured with WithCache for ttl, under norm:entity:<table>:<key>. GetByID and FindByPK are served
+// from the cache; FindOne reads from the database and stores its row for them. Update, UpdatePartial,
+// UpdatePartialVersion, Delete, SoftDelete, SoftDeleteCascade and Restore of a cached repository invalidate
+// the row's entry. Set-based writes (UpdateWhere, DeleteWhere, ...), upserts and writes through other
+// repositories do not, so ttl bounds how stale such a row can get.
+//
+// Reads inside a transaction, on a tenant or RLS executor, with scopes or with WithTrashed/OnlyTrashed
+// bypass the cache. Rows are stored gob-encoded; a model gob cannot encode is simply not cached.
+func (r *repo[T]) Cached(ttl time.Duration) Repository[T] {
+	nr := *r
+	nr.cacheTTL = ttl
+	return &nr
+}
+
+// entityCache returns the cache of a Cached repository, or nil
+func (r *repo[T]) entityCache() Cache {
+	if r.cacheTTL <= 0 || r.kn == nil || r.kn.cache == nil {
+		return nil
+	}
+	return r.kn.cache
+}
+
+// cacheReads reports whether a read on ctx may use the entity cache: only unscoped reads of live rows
+// through the shared pool see rows every other caller sees
+func (r *repo[T]) cacheReads(ctx context.Context) bool {
+	if r.entityCache() == nil || r.mode != softModeDefault || len(r.scopes) > 0 || r.dry != nil {
+		return false
+	}
+	switch baseExec(ctx, r.exec).(type) {
+	case *pgxpool.Pool, gatedPool, routingExecuter:
+		return true
+	}
+	return false
+}
+
+// entityCacheKey names the entry of the row with the given key values
+func (r *repo[T]) entityCacheKey(key []any) string {
+	parts := make([]string, len(key))
+	for i, v := range key {
+		parts[i] = fmt.Sprint(v)
+	}
+	return "norm:entity:" + r.tableName() + ":" + strings.Join(parts, ",")
+}
+
+// errUncacheable is returned by a GetOrLoad loader whose row cannot be gob-encoded
+var errUncacheable = errors.New("row cannot be cached")
+
+// cachedGet returns the cached row with the given key values, loading (and storing) it with load on a
+// miss. With a LoadingCache concurrent misses of the key share one load.
+func (r *repo[T]) cachedGet(ctx context.Context, key []any, load func(ctx context.Context) (*T, error)) (*T, error) {
+	lc, ok := r.kn.cache.(LoadingCache)
+	if !ok {
+		if e, ok := r.cachedEntity(ctx, key); ok {
+			return e, nil
+		}
+		e, err := load(ctx)
+		if err == nil {
+			r.storeEntity(ctx, e)
+		}
+		return e, err
+	}
+	var loaded *T
+	data, err := lc.GetOrLoad(ctx, r.entityCacheKey(key), r.cacheTTL, func(ctx context.Context) ([]byte, error) {
+		e, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		loaded = e
+		b, err := encodeEntity(e)
+		if err != nil {
+			return nil, errUncacheable
+		}
+		return b, nil
+	})
+	// loaded is only read once GetOrLoad has returned the loader's result, never after a cancelled wait
+	cm, _ := r.kn.metrics.(CacheMetrics)
+	switch {
+	case err != nil && !errors.Is(err, errUncacheable):
+		return nil, err
+	case loaded != nil:
+		if cm != nil {
+			cm.CacheMiss()
+		}
+		return loaded, nil
+	case err != nil:
+		return load(ctx)
+	}
+	out := new(T)
+	if gob.NewDecoder(bytes.NewReader(data)).Decode(out) != nil {
+		return load(ctx)
+	}
+	if cm != nil {
+		cm.CacheHit()
+	}
+	return out, nil
+}
+
+// cachedEntity returns the cached row with the given key values
+func (r *repo[T]) cachedEntity(ctx context.Context, key []any) (*T, bool) {
+	cm, _ := r.kn.metrics.(CacheMetrics)
+	if data, ok, err := r.kn.cache.Get(ctx, r.entityCacheKey(key)); err == nil && ok {
+		out := new(T)
+		if gob.NewDecoder(bytes.NewReader(data)).Decode(out) == nil {
+			if cm != nil {
+				cm.CacheHit()
+			}
+			return out, true
+		}
+	}
+	if cm != nil {
+		cm.CacheMiss()
+	}
+	return nil, false
+}
+
+// storeEntity caches e under its primary key; it must be called before relations are preloaded
+func (r *repo[T]) storeEntity(ctx context.Context, e *T) {
+	val := reflect.ValueOf(e).Elem()
+	mapper := core.StructMapper(val.Type())
+	cols := keyColumns(mapper)
+	key := make([]any, len(cols))
+	for i, c := range cols {
+		fi, ok := mapper.FieldsByColumn[strings.ToLower(c)]
+		if !ok {
+			return
+		}
+		key[i] = val.FieldByIndex(fi.Index).Interface()
+	}
+	b, err := encodeEntity(e)
+	if err != nil {
+		return
+	}
+	_ = r.kn.cache.Set(ctx, r.entityCacheKey(key), b, r.cacheTTL)
+}
+
+func encodeEntity(e any) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// uncache invalidates the entry of the row with the given id after a write
+func (r *repo[T]) uncache(ctx context.Context, id any) {
+	c := r.entityCache()
+	if c == nil {
+		return
+	}
+	key, err := keyValues(keyColumns(core.StructMapper(reflect.TypeFor[T]())), id)
+	if err != nil {
+		return
+	}
+	_ = c.Invalidate(ctx, r.entityCacheKey(key))
+}
@@ -0,0 +1,71 @@
+package norm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestRepository_Cached(t *testing.T) {
+	ctx := context.Background()
+	// the pool connects lazily: a cache hit must not reach it
+	pool, err := pgxpool.New(ctx, "postgres://u@127.0.0.1:1/db?connect_timeout=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	cache := &mapCache{data: map[string][]byte{}}
+	kn := &KintsNorm{pool: pool, cache: cache}
+	users := NewRepository[repUser](kn).Cached(time.Minute).(*repo[repUser])
+
+	users.storeEntity(ctx, &repUser{ID: 7, Name: "ann", Version: 3})
+	if _, ok := cache.data["norm:entity:rep_users:7"]; !ok {
+		t.Fatalf("entries %v", cache.data)
+	}
+	u, err := users.GetByID(ctx, 7)
+	if err != nil || u.Name != "ann" || u.Version != 3 {
+		t.Fatalf("GetByID=%+v err=%v", u, err)
+	}
+	if u, err := users.FindByPK(ctx, int64(7)); err != nil || u.Name != "ann" {
+		t.Fatalf("FindByPK=%+v err=%v", u, err)
+	}
+
+	// reads that may see other rows than the shared pool bypass the cache
+	bypass := map[string]Repository[repUser]{
+		"scoped":   users.Scoped(func(qb *QueryBuilder) *QueryBuilder { return qb }),
+		"trashed":  users.WithTrashed(),
+		"executor": users.WithExecutor(&recExecRepo{}),
+		"uncached": NewRepository[repUser](kn),
+		"no ttl":   users.Cached(0),
+	}
+	for name, r := range bypass {
+		if r.(*repo[repUser]).cacheReads(ctx) {
+			t.Errorf("%s reads from the cache", name)
+		}
+	}
+
+	// writes of the cached repository invalidate the row, in a transaction too
+	writer := users.WithExecutor(&recExecRepo{})
+	if err := writer.Delete(ctx, 7); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.data["norm:entity:rep_users:7"]; ok {
+		t.Fatal("Delete kept the entry")
+	}
+	users.storeEntity(ctx, &repUser{ID: 8, Name: "bob"})
+	if err := writer.UpdatePartial(ctx, 8, map[string]any{"name": "rob"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.data["norm:entity:rep_users:8"]; ok {
+		t.Fatal("UpdatePartial kept the entry")
+	}
+	users.storeEntity(ctx, &repUser{ID: 9, Name: "cy"})
+	if err := NewRepositoryWithExecutor[repUser](kn, &recExecRepo{}).Delete(ctx, 9); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.data["norm:entity:rep_users:9"]; !ok {
+		t.Fatal("uncached repository invalidated the entry")
+	}
+}
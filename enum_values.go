@@ -0,0 +1,101 @@
+package norm
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// RegisterEnumValues declares the allowed values of column on model T. Migrations emit a
+// CHECK (column IN (...)) constraint named chk_<table>_<column>, and repository writes reject other values
+// with ErrCodeValidation before reaching the database. Typed string constants work directly:
+//
+//	norm.RegisterEnumValues[Order]("status", []OrderStatus{StatusPending, StatusPaid})
+//
+// Register before AutoMigrate; registering again replaces the values (the planner then suggests replacing
+// the constraint as an unsafe statement).
+func RegisterEnumValues[T any, V ~string](column string, values []V) {
+	vals := make([]string, len(values))
+	for i, v := range values {
+		vals[i] = string(v)
+	}
+	core.RegisterEnum(reflect.TypeFor[T](), column, vals)
+}
+
+// checkEnum validates one value written to column of typ; nil (SQL NULL) passes like it does a CHECK
+func checkEnum(typ reflect.Type, column string, value any) error {
+	allowed, ok := core.EnumColumns(typ)[strings.ToLower(column)]
+	if !ok {
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	for v.IsValid() && v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil
+	}
+	s := fmt.Sprint(v.Interface())
+	if v.Kind() == reflect.String {
+		s = v.String()
+	}
+	if !slices.Contains(allowed, s) {
+		return &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("invalid value %q for %s (allowed: %s)", s, column, strings.Join(allowed, ", "))}
+	}
+	return nil
+}
+
+// checkEnumFields validates the registered enum columns of entity. With skipDefaults, zero values of
+// default: columns are left to the column default, as Create does.
+func checkEnumFields(entity reflect.Value, skipDefaults bool) error {
+	entity = reflect.Indirect(entity)
+	typ := entity.Type()
+	enums := core.EnumColumns(typ)
+	if len(enums) == 0 {
+		return nil
+	}
+	mapper := core.StructMapper(typ)
+	for _, col := range slices.Sorted(maps.Keys(enums)) {
+		fi, ok := mapper.FieldsByColumn[col]
+		if !ok {
+			continue
+		}
+		fv := entity.FieldByIndex(fi.Index)
+		if skipDefaults && fv.IsZero() && hasDefaultTag(typ, fi.Index) {
+			continue
+		}
+		if err := checkEnum(typ, col, fv.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasDefaultTag(typ reflect.Type, index []int) bool {
+	f := typ.FieldByIndex(index)
+	orm := f.Tag.Get("norm")
+	if orm == "" {
+		orm = f.Tag.Get("orm")
+	}
+	return strings.Contains(orm, "default:")
+}
+
+// checkEnumMap validates the registered enum columns among fields (UpdatePartial/UpdateWhere)
+func checkEnumMap(typ reflect.Type, fields map[string]any) error {
+	if len(core.EnumColumns(typ)) == 0 {
+		return nil
+	}
+	for _, col := range slices.Sorted(maps.Keys(fields)) {
+		if err := checkEnum(typ, col, fields[col]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type enumStatus string
+
+type enumOrder struct {
+	ID     int64       `db:"id" norm:"primary_key,auto_increment"`
+	Status enumStatus  `db:"status" norm:"default:'pending'"`
+	Kind   *enumStatus `db:"kind"`
+}
+
+func init() {
+	RegisterEnumValues[enumOrder]("status", []enumStatus{"pending", "paid"})
+	RegisterEnumValues[enumOrder]("kind", []string{"web", "pos"})
+}
+
+func TestEnumValues_RejectsInvalidWrites(t *testing.T) {
+	ex := &recExecRepo{}
+	r := &repo[enumOrder]{kn: &KintsNorm{}, exec: ex}
+	ctx := context.Background()
+	var oe *ORMError
+
+	err := r.Create(ctx, &enumOrder{Status: "shipped"})
+	if !errors.As(err, &oe) || oe.Code != ErrCodeValidation || ex.lastSQL != "" {
+		t.Fatalf("create: err=%v sql=%q", err, ex.lastSQL)
+	}
+	err = r.UpdatePartial(ctx, 1, map[string]any{"kind": "phone"})
+	if !errors.As(err, &oe) || oe.Code != ErrCodeValidation || ex.lastSQL != "" {
+		t.Fatalf("update partial: err=%v sql=%q", err, ex.lastSQL)
+	}
+}
+
+func TestEnumValues_AllowsValidNullAndDefault(t *testing.T) {
+	ex := &recExecRepo{}
+	r := &repo[enumOrder]{kn: &KintsNorm{}, exec: ex}
+	ctx := context.Background()
+	// zero status falls back to the column default, nil kind is NULL
+	if err := checkEnumFields(reflect.ValueOf(&enumOrder{}), true); err != nil {
+		t.Fatalf("create defaults: %v", err)
+	}
+	if err := checkEnumFields(reflect.ValueOf(&enumOrder{}), false); err == nil {
+		t.Fatalf("update with empty status should fail")
+	}
+	pos := enumStatus("pos")
+	if err := checkEnumFields(reflect.ValueOf(&enumOrder{Status: "paid", Kind: &pos}), false); err != nil {
+		t.Fatalf("valid: %v", err)
+	}
+	if err := r.UpdatePartial(ctx, 1, map[string]any{"status": enumStatus("paid"), "kind": nil}); err != nil {
+		t.Fatalf("update partial: %v", err)
+	}
+	if ex.lastSQL == "" {
+		t.Fatalf("expected statement")
+	}
+}
@@ -103,13 +103,8 @@ func usersHandler(kn *norm.KintsNorm, w http.ResponseWriter, r *http.Request) {
 			writeErr(w, http.StatusBadRequest, err)
 			return
 		}
-		// fetch the created row to return with ID
-		created, err := repo.FindOne(ctx, norm.Eq("email", in.Email))
-		if err != nil {
-			writeErr(w, http.StatusInternalServerError, err)
-			return
-		}
-		writeJSON(w, http.StatusCreated, created)
+		// Create populates the generated ID and default columns via RETURNING
+		writeJSON(w, http.StatusCreated, u)
 	default:
 		writeErr(w, http.StatusMethodNotAllowed, nil)
 	}
@@ -212,13 +207,7 @@ func postsHandler(kn *norm.KintsNorm, w http.ResponseWriter, r *http.Request) {
 			writeErr(w, http.StatusBadRequest, err)
 			return
 		}
-		// return last created by querying latest by created_at for this user/title
-		created, err := repo.FindOne(ctx, norm.Eq("user_id", in.UserID), norm.Eq("title", in.Title))
-		if err != nil {
-			writeErr(w, http.StatusInternalServerError, err)
-			return
-		}
-		writeJSON(w, http.StatusCreated, created)
+		writeJSON(w, http.StatusCreated, p)
 	default:
 		writeErr(w, http.StatusMethodNotAllowed, nil)
 	}
@@ -324,12 +313,7 @@ func commentsHandler(kn *norm.KintsNorm, w http.ResponseWriter, r *http.Request)
 			writeErr(w, http.StatusBadRequest, err)
 			return
 		}
-		created, err := repo.FindOne(ctx, norm.Eq("post_id", in.PostID), norm.Eq("user_id", in.UserID), norm.Eq("body", in.Body))
-		if err != nil {
-			writeErr(w, http.StatusInternalServerError, err)
-			return
-		}
-		writeJSON(w, http.StatusCreated, created)
+		writeJSON(w, http.StatusCreated, c)
 	default:
 		writeErr(w, http.StatusMethodNotAllowed, nil)
 	}
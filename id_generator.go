@@ -0,0 +1,109 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/kintsdev/norm/internal/core"
+)
+
+// IDGenerator produces primary key values for new rows, e.g. ULIDs, snowflake ids or ids from an external
+// service. table is the (schema-qualified) table the row is inserted into.
+type IDGenerator interface {
+	NewID(ctx context.Context, table string) (any, error)
+}
+
+// IDGeneratorFunc adapts a function to IDGenerator
+type IDGeneratorFunc func(ctx context.Context, table string) (any, error)
+
+func (f IDGeneratorFunc) NewID(ctx context.Context, table string) (any, error) { return f(ctx, table) }
+
+// WithIDGenerator sets the generator filling zero primary keys on Create, CreateBatch and CreateCopyFrom
+// for every model without a generator of its own (see RegisterIDGenerator). Keys that are auto_increment,
+// have a default: or uuid: tag, or span several columns are left alone.
+func WithIDGenerator(g IDGenerator) Option {
+	return func(o *options) { o.idGenerator = g }
+}
+
+var idGenerators sync.Map // map[reflect.Type]IDGenerator
+
+// RegisterIDGenerator sets the generator of model T's primary key, overriding WithIDGenerator:
+//
+//	norm.RegisterIDGenerator[Order](norm.IDGeneratorFunc(func(context.Context, string) (any, error) {
+//		return ulid.Make().String(), nil
+//	}))
+func RegisterIDGenerator[T any](g IDGenerator) {
+	idGenerators.Store(reflect.TypeFor[T](), g)
+}
+
+// idGenerator returns the generator for T: its registered one, else the instance-wide one
+func (r *repo[T]) idGenerator() IDGenerator {
+	if g, ok := idGenerators.Load(reflect.TypeFor[T]()); ok {
+		return g.(IDGenerator)
+	}
+	if r.kn != nil {
+		return r.kn.idGenerator
+	}
+	return nil
+}
+
+// generateID fills the zero primary key of entity from the model's IDGenerator, if any
+func (r *repo[T]) generateID(ctx context.Context, entity *T) error {
+	g := r.idGenerator()
+	if g == nil || entity == nil {
+		return nil
+	}
+	val := reflect.ValueOf(entity).Elem()
+	mapping := core.StructMapper(val.Type())
+	if mapping.PrimaryColumn == "" || mapping.AutoIncrement {
+		return nil
+	}
+	fi, ok := mapping.FieldsByColumn[strings.ToLower(mapping.PrimaryColumn)]
+	if !ok || fi.Default || fi.UUID != "" {
+		return nil
+	}
+	fv := val.FieldByIndex(fi.Index)
+	if !fv.IsZero() {
+		return nil
+	}
+	id, err := g.NewID(ctx, r.tableName())
+	if err != nil {
+		return err
+	}
+	if err := setGeneratedID(fv, id); err != nil {
+		return &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("primary key %s: %v", mapping.PrimaryColumn, err)}
+	}
+	return nil
+}
+
+// setGeneratedID assigns id to the key field: as is when assignable or convertible, through String() for
+// string keys (ULID and similar types)
+func setGeneratedID(fv reflect.Value, id any) error {
+	v := reflect.ValueOf(id)
+	if !v.IsValid() {
+		return fmt.Errorf("generator returned nil")
+	}
+	ft := fv.Type()
+	if ft.Kind() == reflect.Pointer {
+		ptr := reflect.New(ft.Elem())
+		if err := setGeneratedID(ptr.Elem(), id); err != nil {
+			return err
+		}
+		fv.Set(ptr)
+		return nil
+	}
+	switch {
+	case v.Type().AssignableTo(ft):
+		fv.Set(v)
+	case ft.Kind() == reflect.String && v.Type().Implements(reflect.TypeFor[fmt.Stringer]()):
+		fv.SetString(id.(fmt.Stringer).String())
+	case v.Type().ConvertibleTo(ft) && (ft.Kind() != reflect.String || v.Kind() == reflect.String):
+		fv.Set(v.Convert(ft))
+	default:
+		return fmt.Errorf("generator returned %T, not usable as %s", id, ft)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type ulidLike [2]byte
+
+func (u ulidLike) String() string { return "01J" + string(u[:]) }
+
+type genDoc struct {
+	ID    string `db:"id" norm:"primary_key"`
+	Title string `db:"title"`
+}
+
+type genOrder struct {
+	ID   int64 `db:"id" norm:"primary_key"`
+	Note string
+}
+
+func TestIDGenerator_GlobalAndPerModel(t *testing.T) {
+	var tables []string
+	kn := &KintsNorm{idGenerator: IDGeneratorFunc(func(_ context.Context, table string) (any, error) {
+		tables = append(tables, table)
+		return ulidLike{'A', 'B'}, nil
+	})}
+	ex := &recExecRepo{}
+	docs := NewRepositoryWithExecutor[genDoc](kn, ex)
+	d := &genDoc{Title: "t"}
+	if err := docs.Create(context.Background(), d); err != nil {
+		t.Fatal(err)
+	}
+	if d.ID != "01JAB" || ex.lastArgs[0] != "01JAB" || len(tables) != 1 || tables[0] != "gen_docs" {
+		t.Fatalf("id=%q args=%v tables=%v", d.ID, ex.lastArgs, tables)
+	}
+	// set keys and auto_increment models are left alone
+	if err := docs.Create(context.Background(), &genDoc{ID: "mine"}); err != nil || ex.lastArgs[0] != "mine" {
+		t.Fatalf("err=%v args=%v", err, ex.lastArgs)
+	}
+	if err := NewRepositoryWithExecutor[repUser](kn, ex).CreateBatch(context.Background(), []*repUser{{Name: "a"}}); err != nil || len(tables) != 1 {
+		t.Fatalf("auto_increment: err=%v tables=%v", err, tables)
+	}
+
+	RegisterIDGenerator[genOrder](IDGeneratorFunc(func(context.Context, string) (any, error) { return 42, nil }))
+	defer idGenerators.Delete(reflect.TypeFor[genOrder]())
+	orders := NewRepositoryWithExecutor[genOrder](kn, ex)
+	batch := []*genOrder{{}, {ID: 7}}
+	if err := orders.CreateBatch(context.Background(), batch); err != nil {
+		t.Fatal(err)
+	}
+	if batch[0].ID != 42 || batch[1].ID != 7 || len(tables) != 1 {
+		t.Fatalf("ids=%d,%d tables=%v", batch[0].ID, batch[1].ID, tables)
+	}
+}
+
+func TestIDGenerator_Errors(t *testing.T) {
+	boom := errors.New("id service down")
+	kn := &KintsNorm{idGenerator: IDGeneratorFunc(func(context.Context, string) (any, error) { return nil, boom })}
+	ex := &recExecRepo{}
+	if err := NewRepositoryWithExecutor[genDoc](kn, ex).Create(context.Background(), &genDoc{}); !errors.Is(err, boom) {
+		t.Fatalf("generator error: %v", err)
+	}
+	kn.idGenerator = IDGeneratorFunc(func(context.Context, string) (any, error) { return 1.5, nil })
+	var oe *ORMError
+	if err := NewRepositoryWithExecutor[genDoc](kn, ex).Create(context.Background(), &genDoc{}); !errors.As(err, &oe) || oe.Code != ErrCodeValidation {
+		t.Fatalf("unusable id: %v", err)
+	}
+	if ex.lastSQL != "" {
+		t.Fatalf("insert sent: %s", ex.lastSQL)
+	}
+}
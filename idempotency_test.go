@@ -0,0 +1,113 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// idemDB emulates the idempotency table across transactions
+type idemDB struct {
+	keys    map[string]bool
+	writes  []string
+	commits int
+	begins  int
+}
+
+func (d *idemDB) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, errors.New("writes must run in a transaction")
+}
+func (d *idemDB) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, errors.New("unexpected query")
+}
+func (d *idemDB) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return errorRow{err: errors.New("unexpected query")}
+}
+func (d *idemDB) Begin(ctx context.Context) (pgx.Tx, error) {
+	d.begins++
+	return &idemTx{db: d}, nil
+}
+
+type idemTx struct {
+	pgx.Tx
+	db      *idemDB
+	pending []string
+	writes  []string
+}
+
+func (t *idemTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	switch {
+	case strings.HasPrefix(sql, "CREATE TABLE"):
+		return pgconn.NewCommandTag("CREATE TABLE"), nil
+	case strings.Contains(sql, IdempotencyTable):
+		k := args[0].(string)
+		if t.db.keys[k] {
+			return pgconn.NewCommandTag("INSERT 0 0"), nil
+		}
+		t.pending = append(t.pending, k)
+		return pgconn.NewCommandTag("INSERT 0 1"), nil
+	}
+	t.writes = append(t.writes, sql)
+	return pgconn.NewCommandTag("INSERT 0 1"), nil
+}
+func (t *idemTx) Commit(ctx context.Context) error {
+	for _, k := range t.pending {
+		t.db.keys[k] = true
+	}
+	t.db.writes = append(t.db.writes, t.writes...)
+	t.db.commits++
+	return nil
+}
+func (t *idemTx) Rollback(ctx context.Context) error { return nil }
+
+func TestWithIdempotencyKey_ShortCircuitsDuplicates(t *testing.T) {
+	db := &idemDB{keys: map[string]bool{}}
+	kn := &KintsNorm{}
+	insert := func() (int64, error) {
+		qb := &QueryBuilder{kn: kn, exec: db}
+		return qb.Table("orders").Insert("ref").Values("r1").WithIdempotencyKey("webhook-42").ExecInsert(context.Background(), nil)
+	}
+	if n, err := insert(); err != nil || n != 1 {
+		t.Fatalf("first insert n=%d err=%v", n, err)
+	}
+	_, err := insert()
+	if !errors.Is(err, ErrIdempotencyKeyUsed) {
+		t.Fatalf("expected ErrIdempotencyKeyUsed, got %v", err)
+	}
+	var oe *ORMError
+	if !errors.As(err, &oe) || oe.Code != ErrCodeDuplicate {
+		t.Fatalf("expected duplicate ORMError, got %v", err)
+	}
+	if len(db.writes) != 1 || db.commits != 1 || db.begins != 2 {
+		t.Fatalf("writes=%v commits=%d begins=%d", db.writes, db.commits, db.begins)
+	}
+	if !kn.idempotencyReady.Load() {
+		t.Fatalf("table should be marked ready after commit")
+	}
+}
+
+func TestWithIdempotencyKey_RequiresTransactionalExecutor(t *testing.T) {
+	qb := &QueryBuilder{kn: &KintsNorm{}, exec: &fakeExecRU{}}
+	_, err := qb.Table("t").Where("id = ?", 1).HardDelete().WithIdempotencyKey("k").Delete(context.Background())
+	var oe *ORMError
+	if !errors.As(err, &oe) || oe.Code != ErrCodeTransaction {
+		t.Fatalf("expected transaction error, got %v", err)
+	}
+}
+
+func TestBaseExec_UnwrapsAnyWrapperOrder(t *testing.T) {
+	base := &recExecRepo{}
+	exec := dbExecuter(guardExecuter{exec: timeoutExecuter{exec: breakerExemptExecuter{exec: resultExecuter{
+		exec: lsnExecuter{exec: interceptExecuter{exec: deadlineExecuter{exec: breakerExecuter{exec: timeoutExecuter{exec: base}}}}},
+	}}}})
+	if got := baseExec(context.Background(), exec); got != dbExecuter(base) {
+		t.Fatalf("baseExec=%T", got)
+	}
+	if got := baseExec(context.Background(), ctxTxExecuter{base: guardExecuter{exec: base}}); got != dbExecuter(base) {
+		t.Fatalf("baseExec through ctxTxExecuter=%T", got)
+	}
+}
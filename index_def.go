@@ -0,0 +1,7 @@
+package norm
+
+import "github.com/kintsdev/norm/migration"
+
+// IndexDef declares an index beyond the column tags of a model; models list them from an
+// Indexes() []norm.IndexDef method (see migration.Indexer)
+type IndexDef = migration.IndexDef
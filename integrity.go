@@ -0,0 +1,65 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kintsdev/norm/migration"
+)
+
+// integritySampleSize caps the orphaned keys reported per foreign key
+const integritySampleSize = 10
+
+// Violation reports rows of Table whose Column references a missing RefTable.RefColumn row
+type Violation struct {
+	Table      string
+	Column     string
+	RefTable   string
+	RefColumn  string
+	Count      int64
+	SampleKeys []string
+}
+
+// CheckIntegrity scans for orphaned rows violating the fk:table(column) tags of models, e.g. before enabling
+// constraints on legacy data. It returns one Violation per foreign key with orphans (NULL keys are not orphans).
+func (kn *KintsNorm) CheckIntegrity(ctx context.Context, models ...any) ([]Violation, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return checkIntegrity(ctx, kn.Query().exec, migration.ModelForeignKeys(models...))
+}
+
+func checkIntegrity(ctx context.Context, exec dbExecuter, fks []migration.ForeignKeyRef) ([]Violation, error) {
+	var out []Violation
+	for _, fk := range fks {
+		col := QuoteIdentifier(fk.Column)
+		// count(*) OVER () is evaluated before LIMIT, so the first row carries the total
+		q := fmt.Sprintf("SELECT c.%s::text, count(*) OVER () FROM %s c WHERE c.%s IS NOT NULL AND NOT EXISTS (SELECT 1 FROM %s p WHERE p.%s = c.%s) LIMIT %d",
+			col, quoteQualified(fk.Table), col, quoteQualified(fk.RefTable), QuoteIdentifier(fk.RefColumn), col, integritySampleSize)
+		rows, err := exec.Query(ctx, q)
+		if err != nil {
+			return out, wrapPgError(err, q, nil)
+		}
+		v := Violation{Table: fk.Table, Column: fk.Column, RefTable: fk.RefTable, RefColumn: fk.RefColumn}
+		for rows.Next() {
+			vals, err := rows.Values()
+			if err != nil {
+				rows.Close()
+				return out, wrapPgError(err, q, nil)
+			}
+			key, _ := vals[0].(string)
+			v.SampleKeys = append(v.SampleKeys, key)
+			if n, ok := vals[1].(int64); ok {
+				v.Count = n
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return out, wrapPgError(err, q, nil)
+		}
+		if v.Count > 0 {
+			out = append(out, v)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,41 @@
+package norm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kintsdev/norm/migration"
+)
+
+type integrityOrder struct {
+	ID     int64  `db:"id" norm:"primary_key"`
+	UserID *int64 `db:"user_id" norm:"fk:users(id),on_delete:cascade"`
+	Note   string `db:"note"`
+}
+
+func TestCheckIntegrity_ReportsOrphans(t *testing.T) {
+	fks := migration.ModelForeignKeys(&integrityOrder{})
+	if len(fks) != 1 || fks[0] != (migration.ForeignKeyRef{Table: "integrity_orders", Column: "user_id", RefTable: "users", RefColumn: "id"}) {
+		t.Fatalf("fks=%+v", fks)
+	}
+	ex := &seqExec{fields: []string{"user_id", "count"}, results: [][][]any{{{"7", int64(42)}, {"9", int64(42)}}}}
+	vs, err := checkIntegrity(context.Background(), ex, fks)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if len(vs) != 1 || vs[0].Count != 42 || len(vs[0].SampleKeys) != 2 || vs[0].SampleKeys[1] != "9" {
+		t.Fatalf("violations=%+v", vs)
+	}
+	want := `SELECT c."user_id"::text, count(*) OVER () FROM "integrity_orders" c WHERE c."user_id" IS NOT NULL AND NOT EXISTS (SELECT 1 FROM "users" p WHERE p."id" = c."user_id") LIMIT 10`
+	if ex.sqls[0] != want {
+		t.Fatalf("sql=%s", ex.sqls[0])
+	}
+}
+
+func TestCheckIntegrity_CleanTable(t *testing.T) {
+	ex := &seqExec{fields: []string{"user_id", "count"}}
+	vs, err := checkIntegrity(context.Background(), ex, migration.ModelForeignKeys(&integrityOrder{}))
+	if err != nil || len(vs) != 0 {
+		t.Fatalf("vs=%v err=%v", vs, err)
+	}
+}
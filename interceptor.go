@@ -0,0 +1,100 @@
+package norm
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// StatementKind tells which executor method issued a Statement
+type StatementKind string
+
+const (
+	StatementExec     StatementKind = "exec"
+	StatementQuery    StatementKind = "query"
+	StatementQueryRow StatementKind = "query_row"
+)
+
+// Statement is a SQL statement on its way to the database
+type Statement struct {
+	Kind StatementKind
+	SQL  string
+	Args []any
+}
+
+// Invoker runs stmt: the next interceptor, or the database call at the end of the chain
+type Invoker func(ctx context.Context, stmt Statement) error
+
+// Interceptor wraps every statement sent through the pool, the read pool and transactions. It may inspect or
+// rewrite stmt before calling next, call next several times (retries) or not at all (returning an error).
+// For StatementQuery the error covers sending the query, not iterating its rows; for StatementQueryRow it
+// includes Scan.
+type Interceptor func(ctx context.Context, stmt Statement, next Invoker) error
+
+// interceptExecuter runs statements through the interceptor chain; the first interceptor is the outermost
+type interceptExecuter struct {
+	chain []Interceptor
+	exec  dbExecuter
+}
+
+func (i interceptExecuter) unwrap() dbExecuter { return i.exec }
+
+// withInterceptors wraps exec with the interceptors of kn, if any
+func withInterceptors(kn *KintsNorm, exec dbExecuter) dbExecuter {
+	if kn == nil || len(kn.interceptors) == 0 {
+		return exec
+	}
+	return interceptExecuter{chain: kn.interceptors, exec: exec}
+}
+
+func runInterceptors(ctx context.Context, chain []Interceptor, stmt Statement, final Invoker) error {
+	if len(chain) == 0 {
+		return final(ctx, stmt)
+	}
+	return chain[0](ctx, stmt, func(ctx context.Context, stmt Statement) error {
+		return runInterceptors(ctx, chain[1:], stmt, final)
+	})
+}
+
+func (e interceptExecuter) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	var tag pgconn.CommandTag
+	err := runInterceptors(ctx, e.chain, Statement{Kind: StatementExec, SQL: sql, Args: arguments}, func(ctx context.Context, s Statement) (err error) {
+		tag, err = e.exec.Exec(ctx, s.SQL, s.Args...)
+		return err
+	})
+	return tag, err
+}
+
+func (e interceptExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	var rows pgx.Rows
+	err := runInterceptors(ctx, e.chain, Statement{Kind: StatementQuery, SQL: sql, Args: args}, func(ctx context.Context, s Statement) (err error) {
+		if rows != nil {
+			// a retrying interceptor calls next again; drop the previous attempt's rows
+			rows.Close()
+		}
+		rows, err = e.exec.Query(ctx, s.SQL, s.Args...)
+		return err
+	})
+	if err != nil {
+		if rows != nil {
+			rows.Close()
+		}
+		return nil, err
+	}
+	return rows, nil
+}
+
+func (e interceptExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	stmt := Statement{Kind: StatementQueryRow, SQL: sql, Args: args}
+	return interceptedRow(func(dest ...any) error {
+		return runInterceptors(ctx, e.chain, stmt, func(ctx context.Context, s Statement) error {
+			return e.exec.QueryRow(ctx, s.SQL, s.Args...).Scan(dest...)
+		})
+	})
+}
+
+// interceptedRow defers the whole chain to Scan, where a row's errors surface
+type interceptedRow func(dest ...any) error
+
+func (r interceptedRow) Scan(dest ...any) error { return r(dest...) }
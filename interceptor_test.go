@@ -0,0 +1,68 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInterceptors_OrderRewriteAndRetry(t *testing.T) {
+	var trace []string
+	tracer := func(ctx context.Context, stmt Statement, next Invoker) error {
+		trace = append(trace, "begin "+string(stmt.Kind))
+		err := next(ctx, stmt)
+		trace = append(trace, "end "+string(stmt.Kind))
+		return err
+	}
+	rewrite := func(ctx context.Context, stmt Statement, next Invoker) error {
+		stmt.SQL = "/* app */ " + stmt.SQL
+		return next(ctx, stmt)
+	}
+	kn := &KintsNorm{interceptors: []Interceptor{tracer, rewrite}}
+	ex := &recExec2{}
+	exec := wrapExec(kn, ex)
+	if _, err := exec.Exec(context.Background(), "DELETE FROM users WHERE id = $1", 1); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if ex.lastSQL != "/* app */ DELETE FROM users WHERE id = $1" || !reflect.DeepEqual(ex.lastArgs, []any{1}) {
+		t.Fatalf("sql=%s args=%v", ex.lastSQL, ex.lastArgs)
+	}
+	if err := exec.QueryRow(context.Background(), "SELECT 1").Scan(); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if !reflect.DeepEqual(trace, []string{"begin exec", "end exec", "begin query_row", "end query_row"}) {
+		t.Fatalf("trace=%v", trace)
+	}
+	if _, ok := wrapExec(kn, exec).(interceptExecuter); !ok {
+		t.Fatalf("expected already-wrapped executor to be kept")
+	}
+
+	attempts := 0
+	retry := func(ctx context.Context, stmt Statement, next Invoker) error {
+		var err error
+		for range 3 {
+			attempts++
+			if err = next(ctx, stmt); err == nil {
+				return nil
+			}
+		}
+		return err
+	}
+	flaky := errors.New("flaky")
+	failing := func(ctx context.Context, stmt Statement, next Invoker) error {
+		if attempts < 2 {
+			return flaky
+		}
+		return next(ctx, stmt)
+	}
+	kn = &KintsNorm{interceptors: []Interceptor{retry, failing}}
+	ex = &recExec2{}
+	if err := NewRepositoryWithExecutor[rUser](kn, ex).Delete(context.Background(), 7); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if attempts != 2 || !strings.HasPrefix(ex.lastSQL, "DELETE FROM r_users") {
+		t.Fatalf("attempts=%d sql=%s", attempts, ex.lastSQL)
+	}
+}
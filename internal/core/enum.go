@@ -0,0 +1,40 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var enumRegistry struct {
+	mu     sync.RWMutex
+	values map[reflect.Type]map[string][]string
+}
+
+// RegisterEnum records the allowed values of column on struct type t, replacing earlier values
+func RegisterEnum(t reflect.Type, column string, values []string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	enumRegistry.mu.Lock()
+	defer enumRegistry.mu.Unlock()
+	if enumRegistry.values == nil {
+		enumRegistry.values = map[reflect.Type]map[string][]string{}
+	}
+	cols := enumRegistry.values[t]
+	if cols == nil {
+		cols = map[string][]string{}
+		enumRegistry.values[t] = cols
+	}
+	cols[strings.ToLower(column)] = append([]string(nil), values...)
+}
+
+// EnumColumns returns the registered enum columns of t (lower-case column -> allowed values), or nil
+func EnumColumns(t reflect.Type) map[string][]string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	enumRegistry.mu.RLock()
+	defer enumRegistry.mu.RUnlock()
+	return enumRegistry.values[t]
+}
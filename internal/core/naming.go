@@ -0,0 +1,43 @@
+package core
+
+import "sync/atomic"
+
+// Namer derives the default table and column names of models; db tags, table tags and TableName()
+// methods still take precedence
+type Namer interface {
+	TableName(typeName string) string
+	ColumnName(fieldName string) string
+}
+
+type namerHolder struct{ Namer }
+
+var namer atomic.Pointer[namerHolder]
+
+// SetNamer installs n as the naming of all models, or restores the legacy snake_case(name) + "s" naming
+// when n is nil. The cached mappings are dropped, so it should be called before models are used.
+func SetNamer(n Namer) {
+	if n == nil {
+		namer.Store(nil)
+	} else {
+		namer.Store(&namerHolder{n})
+	}
+	tableNameCache.Clear()
+	structMappingCache.Clear()
+	fieldsCache.Clear()
+}
+
+// defaultTableName is the table of a model type without a TableName() method or table tag
+func defaultTableName(typeName string) string {
+	if h := namer.Load(); h != nil {
+		return h.TableName(typeName)
+	}
+	return ToSnakeCase(typeName) + "s"
+}
+
+// defaultColumnName is the column of a struct field without a db tag
+func defaultColumnName(fieldName string) string {
+	if h := namer.Load(); h != nil {
+		return h.ColumnName(fieldName)
+	}
+	return ToSnakeCase(fieldName)
+}
@@ -1,6 +1,9 @@
 package core
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
 	"reflect"
 	"strings"
 	"sync"
@@ -10,20 +13,244 @@ import (
 type StructFieldInfo struct {
 	Index []int
 	Name  string
+	JSON  bool // norm:"jsonb"/"json": value is (un)marshaled as JSON
+	// Immutable (norm:"immutable") columns are write-once: set on insert, never updated
+	Immutable bool
+	// Generated (norm:"generated:(expr)") columns are computed by the database and never written
+	Generated bool
+	// OmitEmpty (db:"col,omitempty") leaves zero values out of INSERT and UPDATE column lists
+	OmitEmpty bool
+	// FKTable and FKColumn hold the norm:"fk:table(column)" reference, if any
+	FKTable  string
+	FKColumn string
+	// UUID holds the version of norm:"uuid:v4" / "uuid:v7": a zero value is generated client-side on insert
+	UUID string
+	// Default (norm:"default:...") columns get their value from the database when left zero
+	Default bool
 }
 
 type StructMapping struct {
 	FieldsByColumn map[string]StructFieldInfo
 	PrimaryColumn  string
+	// PrimaryColumns lists the key columns in field order: several for a composite key declared with
+	// norm:"primary_key:group" (or primary_key) on more than one field, when PrimaryColumn is empty
+	PrimaryColumns []string
 	AutoIncrement  bool
 	VersionColumn  string
 	HasSoftDelete  bool
+	// Relations holds relation fields keyed by Go field name; they are not columns
+	Relations map[string]RelationInfo
+	// Prefixes holds struct fields with a db tag, keyed by lower-cased tag; result columns aliased
+	// "prefix.column" are scanned into them, see Column
+	Prefixes map[string]PrefixInfo
+	// Aliases maps the lower-cased name of a db:"name,column:Physical" field to its physical column
+	Aliases map[string]string
+}
+
+// Resolve returns the physical column of name: the column of an aliased field
+// (db:"email,column:EmailAddress" resolves "email" to "EmailAddress"), or name unchanged
+func (m StructMapping) Resolve(name string) string {
+	if col, ok := m.Aliases[strings.ToLower(name)]; ok {
+		return col
+	}
+	return name
+}
+
+// PrefixInfo is a nested struct field (Profile Profile `db:"p"`) filled from "p.*" result columns
+type PrefixInfo struct {
+	Index []int
+	Type  reflect.Type // the struct type, dereferenced when the field is a pointer
+}
+
+// Column returns the field receiving the lower-cased result column col. A column missing from
+// FieldsByColumn and named "prefix.name" resolves through Prefixes to the nested struct's field,
+// with Index holding the full path.
+func (m StructMapping) Column(col string) (StructFieldInfo, bool) {
+	if fi, ok := m.FieldsByColumn[col]; ok {
+		return fi, true
+	}
+	prefix, rest, ok := strings.Cut(col, ".")
+	if !ok {
+		return StructFieldInfo{}, false
+	}
+	p, ok := m.Prefixes[prefix]
+	if !ok {
+		return StructFieldInfo{}, false
+	}
+	fi, ok := StructMapper(p.Type).Column(rest)
+	if !ok {
+		return StructFieldInfo{}, false
+	}
+	fi.Index = append(append([]int(nil), p.Index...), fi.Index...)
+	return fi, true
+}
+
+// prefixType returns the struct type of a db-tagged field usable as a column prefix: a struct or struct
+// pointer other than time.Time that does not scan itself
+func prefixType(f reflect.StructField) (reflect.Type, bool) {
+	t := f.Type
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeFor[time.Time]() || reflect.PointerTo(t).Implements(reflect.TypeFor[sql.Scanner]()) {
+		return nil, false
+	}
+	return t, true
+}
+
+// Relation kinds declared via norm:"hasmany:table,fk:col" / "hasone:..." / "belongsto:..."
+const (
+	RelationHasMany   = "hasmany"
+	RelationHasOne    = "hasone"
+	RelationBelongsTo = "belongsto"
+)
+
+// RelationInfo describes a relation field.
+// For has-many/has-one, ForeignKey is the child's column referencing the parent primary key;
+// for belongs-to it is this struct's column referencing the target primary key.
+type RelationInfo struct {
+	Kind       string
+	Table      string // related table; empty means derived from the field type
+	ForeignKey string
+	Index      []int
+	Type       reflect.Type // field type ([]*R, []R, *R or R)
+}
+
+// IsRelationTag reports whether a norm tag declares a relation field
+func IsRelationTag(tag string) bool {
+	_, ok := ParseRelationTag(tag)
+	return ok
+}
+
+// IsRelationField reports whether a struct field is a relation (norm or legacy orm tag)
+func IsRelationField(f reflect.StructField) bool {
+	tag := f.Tag.Get("norm")
+	if tag == "" {
+		tag = f.Tag.Get("orm")
+	}
+	return IsRelationTag(tag)
+}
+
+// ParseRelationTag extracts kind, table and foreign key from a relation tag
+func ParseRelationTag(tag string) (RelationInfo, bool) {
+	var ri RelationInfo
+	for p := range strings.SplitSeq(tag, ",") {
+		p = strings.TrimSpace(p)
+		k, v, _ := strings.Cut(p, ":")
+		switch strings.ToLower(k) {
+		case RelationHasMany, RelationHasOne, RelationBelongsTo:
+			ri.Kind = strings.ToLower(k)
+			ri.Table = strings.TrimSpace(v)
+		case "fk", "foreign_key":
+			ri.ForeignKey = strings.TrimSpace(v)
+		}
+	}
+	return ri, ri.Kind != ""
 }
 
 func ParseDBTag(tag string) string { return tag }
 
+// ParseFKRef splits an fk reference "table(column)"; both are empty when it is malformed
+func ParseFKRef(ref string) (string, string) {
+	ref = strings.TrimSpace(ref)
+	if i := strings.Index(ref, "("); i > 0 && strings.HasSuffix(ref, ")") {
+		return strings.TrimSpace(ref[:i]), strings.TrimSpace(strings.TrimSuffix(ref[i+1:], ")"))
+	}
+	return "", ""
+}
+
 var structMappingCache sync.Map // map[reflect.Type]StructMapping
 
+var fieldsCache sync.Map // map[reflect.Type][]reflect.StructField
+
+// Fields returns the fields of struct type t with embedded structs flattened, so a shared
+// BaseModel{ID, CreatedAt, ...} contributes its columns to every model embedding it. Index holds the full path
+// from t (use FieldByIndex). An embedded struct is flattened unless it has a db tag or is time.Time; embedded
+// pointers are skipped since they may be nil. A field declared closer to t wins over a deeper one with the same
+// column, like Go's own field promotion.
+func Fields(t reflect.Type) []reflect.StructField {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if v, ok := fieldsCache.Load(t); ok {
+		return v.([]reflect.StructField)
+	}
+	var all []reflect.StructField
+	collectFields(t, nil, &all)
+	// resolve column clashes of exported fields in favour of the shallowest ones, keeping declaration order
+	depth := make(map[string]int, len(all))
+	for _, f := range all {
+		if f.PkgPath != "" {
+			continue
+		}
+		col := strings.ToLower(ColumnName(f))
+		if d, ok := depth[col]; !ok || len(f.Index) < d {
+			depth[col] = len(f.Index)
+		}
+	}
+	out := make([]reflect.StructField, 0, len(all))
+	for _, f := range all {
+		if f.PkgPath != "" || depth[strings.ToLower(ColumnName(f))] == len(f.Index) {
+			out = append(out, f)
+		}
+	}
+	fieldsCache.Store(t, out)
+	return out
+}
+
+func collectFields(t reflect.Type, prefix []int, out *[]reflect.StructField) {
+	for f := range t.Fields() {
+		f.Index = append(append([]int(nil), prefix...), f.Index...)
+		if f.Anonymous && f.Tag.Get("db") == "" {
+			if f.Type.Kind() == reflect.Pointer {
+				continue
+			}
+			if f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeFor[time.Time]() {
+				collectFields(f.Type, f.Index, out)
+				continue
+			}
+		}
+		*out = append(*out, f)
+	}
+}
+
+// ColumnName returns the db column of a struct field: its column: option (db:"email,column:EmailAddress"
+// targets a legacy column under another name), the db tag name (other options after a comma are ignored)
+// or the field name under the naming of SetNamer (snake_case by default)
+func ColumnName(f reflect.StructField) string {
+	name, opts, _ := strings.Cut(f.Tag.Get("db"), ",")
+	for o := range strings.SplitSeq(opts, ",") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(o), ":"); ok && k == "column" && strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	if name != "" {
+		return name
+	}
+	return defaultColumnName(f.Name)
+}
+
+// ColumnAlias returns the name a field is addressed by when its column: option renames the column
+// (db:"email,column:EmailAddress" gives "email"), or ""
+func ColumnAlias(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("db"), ",")
+	if col := ColumnName(f); name != "" && col != name {
+		return name
+	}
+	return ""
+}
+
+// OmitEmpty reports whether the db tag carries the omitempty option (db:"nickname,omitempty")
+func OmitEmpty(f reflect.StructField) bool {
+	_, opts, _ := strings.Cut(f.Tag.Get("db"), ",")
+	for o := range strings.SplitSeq(opts, ",") {
+		if strings.TrimSpace(o) == "omitempty" {
+			return true
+		}
+	}
+	return false
+}
+
 func StructMapper(t reflect.Type) StructMapping {
 	// deref pointer
 	for t.Kind() == reflect.Pointer {
@@ -33,21 +260,25 @@ func StructMapper(t reflect.Type) StructMapping {
 		return v.(StructMapping)
 	}
 	m := StructMapping{FieldsByColumn: make(map[string]StructFieldInfo)}
-	for f := range t.Fields() {
-		f := f
+	for _, f := range Fields(t) {
 		if f.PkgPath != "" { // unexported
 			continue
 		}
-		col := f.Tag.Get("db")
-		if col == "" {
-			col = ToSnakeCase(f.Name)
-		}
+		col := ColumnName(f)
 
 		// Prefer `norm` tag; fallback to legacy `orm`
 		orm := f.Tag.Get("norm")
 		if orm == "" {
 			orm = f.Tag.Get("orm")
 		}
+		if ri, ok := ParseRelationTag(orm); ok {
+			ri.Index, ri.Type = f.Index, f.Type
+			if m.Relations == nil {
+				m.Relations = make(map[string]RelationInfo)
+			}
+			m.Relations[f.Name] = ri
+			continue
+		}
 		// if ignored, skip mapping; else map
 		ignored := false
 		if orm != "" {
@@ -56,15 +287,16 @@ func StructMapper(t reflect.Type) StructMapping {
 				ignored = true
 			}
 		}
-		if !ignored {
-			m.FieldsByColumn[strings.ToLower(col)] = StructFieldInfo{Index: f.Index, Name: f.Name}
-		}
+		info := StructFieldInfo{Index: f.Index, Name: f.Name, OmitEmpty: OmitEmpty(f)}
 		if orm != "" {
 			parts := strings.SplitSeq(orm, ",")
 			for p := range parts {
 				p = strings.TrimSpace(p)
-				if p == "primary_key" {
-					m.PrimaryColumn = col
+				if strings.EqualFold(p, "jsonb") || strings.EqualFold(p, "json") {
+					info.JSON = true
+				}
+				if p == "primary_key" || strings.HasPrefix(strings.ToLower(p), "primary_key:") {
+					m.PrimaryColumns = append(m.PrimaryColumns, col)
 				}
 				if p == "auto_increment" {
 					m.AutoIncrement = true
@@ -72,6 +304,36 @@ func StructMapper(t reflect.Type) StructMapping {
 				if p == "version" {
 					m.VersionColumn = col
 				}
+				if strings.EqualFold(p, "immutable") {
+					info.Immutable = true
+				}
+				if strings.HasPrefix(strings.ToLower(p), "generated:") {
+					info.Generated = true
+				}
+				if strings.HasPrefix(p, "default:") {
+					info.Default = true
+				}
+				if strings.HasPrefix(strings.ToLower(p), "uuid:") {
+					info.UUID = strings.ToLower(strings.TrimSpace(p[len("uuid:"):]))
+				}
+				if low := strings.ToLower(p); strings.HasPrefix(low, "fk:") || strings.HasPrefix(low, "references:") {
+					info.FKTable, info.FKColumn = ParseFKRef(p[strings.Index(p, ":")+1:])
+				}
+			}
+		}
+		if !ignored {
+			m.FieldsByColumn[strings.ToLower(col)] = info
+			if alias := ColumnAlias(f); alias != "" {
+				if m.Aliases == nil {
+					m.Aliases = make(map[string]string)
+				}
+				m.Aliases[strings.ToLower(alias)] = col
+			}
+			if pt, ok := prefixType(f); ok && !info.JSON && f.Tag.Get("db") != "" {
+				if m.Prefixes == nil {
+					m.Prefixes = make(map[string]PrefixInfo)
+				}
+				m.Prefixes[strings.ToLower(col)] = PrefixInfo{Index: f.Index, Type: pt}
 			}
 		}
 		if strings.EqualFold(col, "id") && m.PrimaryColumn == "" {
@@ -82,10 +344,63 @@ func StructMapper(t reflect.Type) StructMapping {
 			m.HasSoftDelete = true
 		}
 	}
+	switch {
+	case len(m.PrimaryColumns) == 1:
+		m.PrimaryColumn = m.PrimaryColumns[0]
+	case len(m.PrimaryColumns) > 1:
+		m.PrimaryColumn = ""
+	case m.PrimaryColumn != "":
+		m.PrimaryColumns = []string{m.PrimaryColumn}
+	}
 	structMappingCache.Store(t, m)
 	return m
 }
 
+// SetField assigns a scanned column value to the mapped field, decoding JSON columns into the field type
+func SetField(v reflect.Value, fi StructFieldInfo, value any) {
+	if fi.JSON {
+		setJSONField(v, fi.Index, value)
+		return
+	}
+	SetFieldByIndex(v, fi.Index, value)
+}
+
+// setJSONField unmarshals a json/jsonb column value (raw bytes, string or pgx-decoded value) into the field
+func setJSONField(v reflect.Value, index []int, value any) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	fv, ok := fieldByIndexAlloc(v, index, value == nil)
+	if !ok || !fv.IsValid() || !fv.CanSet() {
+		return
+	}
+	if value == nil {
+		fv.Set(reflect.Zero(fv.Type()))
+		return
+	}
+	var raw []byte
+	switch t := value.(type) {
+	case []byte:
+		raw = t
+	case string:
+		raw = []byte(t)
+	default:
+		b, err := json.Marshal(t)
+		if err != nil {
+			return
+		}
+		raw = b
+	}
+	target := reflect.New(fv.Type())
+	if err := json.Unmarshal(raw, target.Interface()); err != nil {
+		return
+	}
+	fv.Set(target.Elem())
+}
+
 func SetFieldByIndex(v reflect.Value, index []int, value any) {
 	// ensure addressable
 	for v.Kind() == reflect.Pointer {
@@ -94,15 +409,41 @@ func SetFieldByIndex(v reflect.Value, index []int, value any) {
 		}
 		v = v.Elem()
 	}
-	fv := v.FieldByIndex(index)
-	if !fv.IsValid() || !fv.CanSet() {
+	fv, ok := fieldByIndexAlloc(v, index, value == nil)
+	if !ok || !fv.IsValid() || !fv.CanSet() {
 		return
 	}
+	setValue(fv, value)
+}
+
+// fieldByIndexAlloc is FieldByIndex allocating the nil struct pointers on the path (nested prefix
+// fields). With skipNil it stops at a nil pointer instead, so the NULL columns of an outer-joined row
+// leave its struct nil.
+func fieldByIndexAlloc(v reflect.Value, index []int, skipNil bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if skipNil || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// setValue assigns a driver-decoded value to a settable field value, applying the same conversions for scalars and slice elements
+func setValue(fv reflect.Value, value any) {
 	val := reflect.ValueOf(value)
 	if value == nil {
 		// set zero if pointer or nullable
 		if fv.Kind() == reflect.Pointer {
 			fv.Set(reflect.Zero(fv.Type()))
+		} else if s, ok := addrScanner(fv); ok {
+			_ = s.Scan(nil) // sql.Null*, pgtype.* mark themselves invalid
 		}
 		return
 	}
@@ -119,10 +460,22 @@ func SetFieldByIndex(v reflect.Value, index []int, value any) {
 		fv.Set(val)
 		return
 	}
+	if scanValue(fv, value) {
+		return
+	}
 	if val.Type().ConvertibleTo(fv.Type()) {
 		fv.Set(val.Convert(fv.Type()))
 		return
 	}
+	// Postgres arrays decode as []any; convert element-wise into typed slices ([]string, []int64, []uuid.UUID, ...)
+	if fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8 && val.Kind() == reflect.Slice {
+		out := reflect.MakeSlice(fv.Type(), val.Len(), val.Len())
+		for i := range val.Len() {
+			setValue(out.Index(i), val.Index(i).Interface())
+		}
+		fv.Set(out)
+		return
+	}
 	// Special-case: convert UUID-like values to string target
 	// - Postgres/pgx may return [16]byte or []byte for UUID
 	if fv.Kind() == reflect.String {
@@ -200,6 +553,43 @@ func SetFieldByIndex(v reflect.Value, index []int, value any) {
 	}
 }
 
+// addrScanner returns fv's address as a sql.Scanner when its type implements it
+func addrScanner(fv reflect.Value) (sql.Scanner, bool) {
+	if !fv.CanAddr() {
+		return nil, false
+	}
+	s, ok := fv.Addr().Interface().(sql.Scanner)
+	return s, ok
+}
+
+// scanValue decodes value through sql.Scanner when the field type (or the element of a pointer field)
+// implements it, e.g. decimal.Decimal or custom enums. pgx-decoded values implementing driver.Valuer
+// (pgtype.Numeric, ...) are first reduced to their driver representation, which is what Scan expects.
+func scanValue(fv reflect.Value, value any) bool {
+	target := fv
+	if fv.Kind() == reflect.Pointer {
+		target = reflect.New(fv.Type().Elem()).Elem()
+	}
+	s, ok := addrScanner(target)
+	if !ok {
+		return false
+	}
+	if v, ok := value.(driver.Valuer); ok {
+		dv, err := v.Value()
+		if err != nil {
+			return false
+		}
+		value = dv
+	}
+	if err := s.Scan(value); err != nil {
+		return false
+	}
+	if fv.Kind() == reflect.Pointer {
+		fv.Set(target.Addr())
+	}
+	return true
+}
+
 func ToSnakeCase(s string) string {
 	var out []rune
 	for i, r := range s {
@@ -0,0 +1,41 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type baseModel struct {
+	ID        int64      `db:"id" norm:"primary_key,auto_increment"`
+	CreatedAt time.Time  `db:"created_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+type embedUser struct {
+	baseModel
+	Email     string    `db:"email"`
+	CreatedAt time.Time `db:"created_at" norm:"default:now()"` // shadows baseModel.CreatedAt
+}
+
+func TestFields_FlattensEmbeddedStructs(t *testing.T) {
+	var cols []string
+	for _, f := range Fields(reflect.TypeFor[embedUser]()) {
+		cols = append(cols, ColumnName(f))
+	}
+	if !reflect.DeepEqual(cols, []string{"id", "deleted_at", "email", "created_at"}) {
+		t.Fatalf("cols=%v", cols)
+	}
+	m := StructMapper(reflect.TypeFor[embedUser]())
+	if m.PrimaryColumn != "id" || !m.AutoIncrement || !m.HasSoftDelete {
+		t.Fatalf("mapping=%+v", m)
+	}
+	u := &embedUser{}
+	SetField(reflect.ValueOf(u), m.FieldsByColumn["id"], int64(9))
+	if u.ID != 9 {
+		t.Fatalf("promoted id=%d", u.ID)
+	}
+	if got := m.FieldsByColumn["created_at"].Index; len(got) != 1 {
+		t.Fatalf("created_at must map to the outer field, index=%v", got)
+	}
+}
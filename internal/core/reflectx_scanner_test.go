@@ -0,0 +1,55 @@
+package core
+
+import (
+	"database/sql"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// cents is a decimal-like Scanner that only understands driver values
+type cents struct{ v int64 }
+
+func (c *cents) Scan(src any) error {
+	s, ok := src.(string)
+	if !ok {
+		return fmt.Errorf("unsupported %T", src)
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	c.v = int64(f * 100)
+	return err
+}
+
+type priced struct {
+	Price   cents          `db:"price"`
+	Refund  *cents         `db:"refund"`
+	Note    sql.NullString `db:"note"`
+	Numeric pgtype.Numeric `db:"numeric"`
+}
+
+func TestSetField_HonorsScanner(t *testing.T) {
+	m := StructMapper(reflect.TypeFor[priced]())
+	num := pgtype.Numeric{Int: big.NewInt(1250), Exp: -2, Valid: true}
+	p := &priced{Note: sql.NullString{String: "x", Valid: true}}
+	v := reflect.ValueOf(p)
+	SetField(v, m.FieldsByColumn["price"], num)
+	SetField(v, m.FieldsByColumn["refund"], "3.5")
+	SetField(v, m.FieldsByColumn["note"], nil)
+	SetField(v, m.FieldsByColumn["numeric"], num)
+	if p.Price.v != 1250 {
+		t.Fatalf("price=%d", p.Price.v)
+	}
+	if p.Refund == nil || p.Refund.v != 350 {
+		t.Fatalf("refund=%+v", p.Refund)
+	}
+	if p.Note.Valid {
+		t.Fatalf("NULL must invalidate sql.NullString")
+	}
+	if !p.Numeric.Valid || p.Numeric.Int.Int64() != 1250 {
+		t.Fatalf("numeric=%+v", p.Numeric)
+	}
+}
@@ -74,3 +74,37 @@ func TestSetFieldByIndexAndModelHasSoftDelete(t *testing.T) {
 		t.Fatalf("soft delete detect")
 	}
 }
+
+func TestSetFieldByIndex_Arrays(t *testing.T) {
+	type id [16]byte
+	var v struct {
+		Tags []string
+		IDs  []int64
+		Refs []id
+	}
+	rv := reflect.ValueOf(&v)
+	SetFieldByIndex(rv, []int{0}, []any{"a", "b"})
+	SetFieldByIndex(rv, []int{1}, []any{int64(1), int64(2)})
+	SetFieldByIndex(rv, []int{2}, []any{[16]byte{1}})
+	if !reflect.DeepEqual(v.Tags, []string{"a", "b"}) || !reflect.DeepEqual(v.IDs, []int64{1, 2}) {
+		t.Fatalf("got %+v", v)
+	}
+	if len(v.Refs) != 1 || v.Refs[0][0] != 1 {
+		t.Fatalf("uuid array %+v", v.Refs)
+	}
+}
+
+type aliasedModel struct {
+	Email string `db:"email,column:EmailAddress"`
+	Nick  string `db:"nick,omitempty"`
+}
+
+func TestStructMapper_ColumnAlias(t *testing.T) {
+	m := StructMapper(reflect.TypeFor[aliasedModel]())
+	if _, ok := m.FieldsByColumn["emailaddress"]; !ok {
+		t.Fatalf("columns %v", m.FieldsByColumn)
+	}
+	if m.Resolve("Email") != "EmailAddress" || m.Resolve("nick") != "nick" || m.Resolve("other") != "other" {
+		t.Fatalf("aliases %v", m.Aliases)
+	}
+}
@@ -0,0 +1,128 @@
+package core
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+type tableNamer interface{ TableName() string }
+
+var (
+	tableNameCache  sync.Map // map[reflect.Type]string
+	schemaNameCache sync.Map // map[reflect.Type]string
+)
+
+// TableName returns the table of model type t: its TableName() method, a `norm:"table:name"` (or
+// `norm_table:"table:name"`) tag on a blank `_` field, or the
+// naming of SetNamer (snake_case(type name) + "s" by default)
+func TableName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if v, ok := tableNameCache.Load(t); ok {
+		return v.(string)
+	}
+	name := defaultTableName(t.Name())
+	if tn, ok := reflect.New(t).Interface().(tableNamer); ok {
+		name = tn.TableName()
+	} else if tag, ok := tableTag(t, "table"); ok {
+		name = tag
+	}
+	tableNameCache.Store(t, name)
+	return name
+}
+
+// TableNameOf is TableName for a model value, letting a TableName() method see the value's fields
+func TableNameOf(model any) string {
+	if tn, ok := model.(tableNamer); ok {
+		return tn.TableName()
+	}
+	return TableName(reflect.TypeOf(model))
+}
+
+// SchemaName returns the schema declared with a `norm:"schema:name"` tag on a blank `_` field, or ""
+func SchemaName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if v, ok := schemaNameCache.Load(t); ok {
+		return v.(string)
+	}
+	name, _ := tableTag(t, "schema")
+	schemaNameCache.Store(t, name)
+	return name
+}
+
+// PartitionBy returns the declarative partitioning of a model, from a `norm:"partition_by:range(created_at)"`
+// tag on a blank `_` field, or ""
+func PartitionBy(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	v, _ := tableTag(t, "partition_by")
+	return v
+}
+
+// SplitTagTokens splits a tag on commas that are not inside parentheses, so options like
+// partition_by:hash(a,b) stay whole
+func SplitTagTokens(s string) []string {
+	var tokens []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth = max(depth-1, 0)
+		case ',':
+			if depth == 0 {
+				if tok := strings.TrimSpace(s[start:i]); tok != "" {
+					tokens = append(tokens, tok)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if tok := strings.TrimSpace(s[start:]); tok != "" {
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+// QualifiedTableName is TableName prefixed with the model's schema tag (schema.table), if any
+func QualifiedTableName(t reflect.Type) string {
+	return Qualify(SchemaName(t), TableName(t))
+}
+
+// Qualify places table in schema, replacing any schema the table name already carries; an empty
+// schema leaves table unchanged
+func Qualify(schema, table string) string {
+	if schema == "" {
+		return table
+	}
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		table = table[i+1:]
+	}
+	return schema + "." + table
+}
+
+// tableTag reads a table-level key:value option from the tags of blank `_` fields
+func tableTag(t reflect.Type, key string) (string, bool) {
+	if t.Kind() != reflect.Struct {
+		return "", false
+	}
+	for f := range t.Fields() {
+		if f.Name != "_" {
+			continue
+		}
+		// norm_table carries the other table-level options (retention, ...)
+		tag := f.Tag.Get("norm") + "," + f.Tag.Get("orm") + "," + f.Tag.Get("norm_table")
+		for _, p := range SplitTagTokens(tag) {
+			if k, v, ok := strings.Cut(p, ":"); ok && strings.EqualFold(k, key) && strings.TrimSpace(v) != "" {
+				return strings.TrimSpace(v), true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,68 @@
+package core
+
+import (
+	"reflect"
+	"testing"
+)
+
+type Person struct {
+	_  struct{} `norm:"table:people"`
+	ID int64    `db:"id"`
+}
+
+type Child struct{ ID int64 }
+
+func (*Child) TableName() string { return "children" }
+
+type OrderLine struct{ ID int64 }
+
+type Event struct {
+	_  struct{} `norm_table:"table:audit_events,retention:30d"`
+	ID int64
+}
+
+func TestTableName(t *testing.T) {
+	cases := map[reflect.Type]string{
+		reflect.TypeFor[Person]():    "people",
+		reflect.TypeFor[*Child]():    "children",
+		reflect.TypeFor[Child]():     "children",
+		reflect.TypeFor[OrderLine](): "order_lines",
+		reflect.TypeFor[Event]():     "audit_events",
+	}
+	for typ, want := range cases {
+		if got := TableName(typ); got != want {
+			t.Fatalf("%s: got %s want %s", typ, got, want)
+		}
+	}
+	if got := TableNameOf(&Child{}); got != "children" {
+		t.Fatalf("TableNameOf=%s", got)
+	}
+	if _, ok := StructMapper(reflect.TypeFor[Person]()).FieldsByColumn["_"]; ok {
+		t.Fatalf("blank tag field must not be mapped")
+	}
+}
+
+type Invoice struct {
+	_  struct{} `norm:"schema:billing"`
+	ID int64
+}
+
+type Ledger struct {
+	_  struct{} `norm:"schema:billing,table:ledger"`
+	ID int64
+}
+
+func TestQualifiedTableName(t *testing.T) {
+	if got := QualifiedTableName(reflect.TypeFor[Invoice]()); got != "billing.invoices" {
+		t.Fatalf("got %s", got)
+	}
+	if got := QualifiedTableName(reflect.TypeFor[*Ledger]()); got != "billing.ledger" {
+		t.Fatalf("got %s", got)
+	}
+	if got := QualifiedTableName(reflect.TypeFor[Person]()); got != "people" {
+		t.Fatalf("got %s", got)
+	}
+	if got := Qualify("tenant_1", "billing.invoices"); got != "tenant_1.invoices" {
+		t.Fatalf("got %s", got)
+	}
+}
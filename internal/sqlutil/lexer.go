@@ -0,0 +1,108 @@
+package sqlutil
+
+import "strings"
+
+// Blank replaces comments, string literals (including E'...' strings), quoted identifiers and
+// dollar-quoted bodies ($$ ... $$, $fn$ ... $fn$) with spaces, keeping the length and offsets of sql, so
+// keyword and parenthesis scans only see the statement's own tokens
+func Blank(sql string) string {
+	b := []byte(sql)
+	blank := func(from, to int) {
+		for k := from; k <= to && k < len(b); k++ {
+			if b[k] != '\n' {
+				b[k] = ' '
+			}
+		}
+	}
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			j := i
+			for j < len(sql) && sql[j] != '\n' {
+				j++
+			}
+			blank(i, j-1)
+			i = j
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			j := SkipBlockComment(sql, i)
+			blank(i, j)
+			i = j
+		case c == '\'':
+			escapes := i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i < 2 || !IsIdentByte(sql[i-2]))
+			j := SkipQuoted(sql, i, '\'', escapes)
+			blank(i, j)
+			i = j
+		case c == '"':
+			j := SkipQuoted(sql, i, '"', false)
+			blank(i, j)
+			i = j
+		case c == '$' && (i == 0 || !IsIdentByte(sql[i-1])):
+			if tag, ok := DollarTag(sql[i:]); ok {
+				j := len(sql) - 1
+				if end := strings.Index(sql[i+len(tag):], tag); end >= 0 {
+					j = i + len(tag) + end + len(tag) - 1
+				}
+				blank(i, j)
+				i = j
+			}
+		}
+	}
+	return string(b)
+}
+
+// SkipBlockComment returns the index of the '/' closing the block comment opened at sql[i]; block
+// comments nest in PostgreSQL
+func SkipBlockComment(sql string, i int) int {
+	depth := 0
+	for ; i < len(sql); i++ {
+		if sql[i] == '/' && i+1 < len(sql) && sql[i+1] == '*' {
+			depth++
+			i++
+		} else if sql[i] == '*' && i+1 < len(sql) && sql[i+1] == '/' {
+			depth--
+			i++
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(sql) - 1
+}
+
+// SkipQuoted returns the index of the quote closing the literal opened at sql[i]; doubled quotes and,
+// with escapes, backslashed characters stay inside
+func SkipQuoted(sql string, i int, quote byte, escapes bool) int {
+	for i++; i < len(sql); i++ {
+		switch {
+		case escapes && sql[i] == '\\':
+			i++
+		case sql[i] == quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(sql) - 1
+}
+
+// DollarTag returns the opening $tag$ at the start of s, if any ($1 placeholders are not tags)
+func DollarTag(s string) (string, bool) {
+	for j := 1; j < len(s); j++ {
+		c := s[j]
+		if c == '$' {
+			return s[:j+1], true
+		}
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || j > 1 && c >= '0' && c <= '9' || c >= 0x80) {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// IsIdentByte reports whether c can be part of an unquoted identifier or keyword
+func IsIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
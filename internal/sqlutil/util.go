@@ -1,6 +1,7 @@
 package sqlutil
 
 import (
+	"database/sql/driver"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -156,6 +157,10 @@ func isIdentPart(b byte) bool {
 }
 
 func isSliceButNotBytes(v any) bool {
+	// driver.Valuer types (uuid.UUID, pgtype arrays, ...) bind as a single value
+	if _, ok := v.(driver.Valuer); ok {
+		return false
+	}
 	rv := reflect.ValueOf(v)
 	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
 		// exclude []byte
@@ -166,3 +171,34 @@ func isSliceButNotBytes(v any) bool {
 	}
 	return false
 }
+
+// PgPlaceholdersToQMarks rewrites $N placeholders (outside single-quoted literals) to '?' and returns args
+// reordered to match the '?' occurrence order, so a compiled statement can be embedded into a '?'-style
+// fragment. Repeated or out-of-order placeholders are supported; out-of-range ones are left untouched.
+func PgPlaceholdersToQMarks(sql string, args []any) (string, []any) {
+	var sb strings.Builder
+	sb.Grow(len(sql))
+	out := make([]any, 0, len(args))
+	inSingle := false
+	for i := 0; i < len(sql); i++ {
+		ch := sql[i]
+		if ch == '\'' {
+			inSingle = !inSingle
+		}
+		if !inSingle && ch == '$' && i+1 < len(sql) && sql[i+1] >= '0' && sql[i+1] <= '9' {
+			j := i + 1
+			for j < len(sql) && sql[j] >= '0' && sql[j] <= '9' {
+				j++
+			}
+			n, _ := strconv.Atoi(sql[i+1 : j])
+			if n >= 1 && n <= len(args) {
+				sb.WriteByte('?')
+				out = append(out, args[n-1])
+				i = j - 1
+				continue
+			}
+		}
+		sb.WriteByte(ch)
+	}
+	return sb.String(), out
+}
@@ -1,7 +1,9 @@
 package sqlutil
 
 import (
+	"database/sql/driver"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -86,4 +88,31 @@ func TestIsSliceButNotBytes(t *testing.T) {
 	if isSliceButNotBytes(5) {
 		t.Fatalf("want false for scalar")
 	}
+	if isSliceButNotBytes(valuerSlice{"a", "b"}) {
+		t.Fatalf("want false for driver.Valuer slice")
+	}
+}
+
+func TestPgPlaceholdersToQMarks(t *testing.T) {
+	s, args := PgPlaceholdersToQMarks("a = $2 AND b = $1 AND c = $2 AND d = '$1' AND e = $9", []any{"x", "y"})
+	if s != "a = ? AND b = ? AND c = ? AND d = '$1' AND e = $9" {
+		t.Fatalf("sql=%s", s)
+	}
+	if !reflect.DeepEqual(args, []any{"y", "x", "y"}) {
+		t.Fatalf("args=%v", args)
+	}
+}
+
+// valuerSlice binds as one array value, like pq.StringArray
+type valuerSlice []string
+
+func (v valuerSlice) Value() (driver.Value, error) { return "{" + strings.Join(v, ",") + "}", nil }
+
+func TestBlank(t *testing.T) {
+	in := `SELECT 'a;b', E'c\'d', "Where" /* x /* y */ z */ -- c
+$$ body $$, $t$ q $t$, $1 FROM t`
+	want := "SELECT      , E      ,                               \n          ,          , $1 FROM t"
+	if got := Blank(in); got != want {
+		t.Fatalf("got  %q\nwant %q", got, want)
+	}
 }
@@ -0,0 +1,72 @@
+package norm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// loadingMapCache is a mapCache implementing LoadingCache, counting loads
+type loadingMapCache struct {
+	mapCache
+	loads int
+}
+
+func (c *loadingMapCache) GetOrLoad(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	if b, ok, _ := c.Get(ctx, key); ok {
+		return b, nil
+	}
+	c.loads++
+	b, err := load(ctx)
+	if err == nil {
+		_ = c.Set(ctx, key, b, ttl)
+	}
+	return b, err
+}
+
+func TestQueryBuilder_FindThroughLoadingCache(t *testing.T) {
+	cache := &loadingMapCache{mapCache: mapCache{data: map[string][]byte{}}}
+	kn := &KintsNorm{cache: cache}
+	f := &fakeExec{rows: [][]any{{int64(2)}}, fields: []string{"id"}}
+	find := func() []map[string]any {
+		t.Helper()
+		var out []map[string]any
+		if err := (&QueryBuilder{kn: kn, exec: f}).Table("users").WithCacheKey("users", time.Minute).Find(context.Background(), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	// the loading caller keeps the scanned values, later callers decode the stored entry
+	if out := find(); len(out) != 1 || out[0]["id"] != int64(2) {
+		t.Fatalf("miss %v", out)
+	}
+	f.rows = nil
+	if out := find(); len(out) != 1 || out[0]["id"] != float64(2) {
+		t.Fatalf("hit %v", out)
+	}
+	if cache.loads != 1 {
+		t.Fatalf("loads=%d", cache.loads)
+	}
+}
+
+func TestRepository_CachedGetThroughLoadingCache(t *testing.T) {
+	ctx := context.Background()
+	cache := &loadingMapCache{mapCache: mapCache{data: map[string][]byte{}}}
+	users := &repo[repUser]{kn: &KintsNorm{cache: cache}, cacheTTL: time.Minute}
+	calls := 0
+	load := func(context.Context) (*repUser, error) { calls++; return &repUser{ID: 3, Name: "ann"}, nil }
+
+	for range 2 {
+		u, err := users.cachedGet(ctx, []any{3}, load)
+		if err != nil || u.Name != "ann" {
+			t.Fatalf("u=%+v err=%v", u, err)
+		}
+	}
+	if calls != 1 || cache.loads != 1 {
+		t.Fatalf("calls=%d loads=%d", calls, cache.loads)
+	}
+	if _, ok := cache.data["norm:entity:rep_users:3"]; !ok {
+		t.Fatalf("entries %v", cache.data)
+	}
+}
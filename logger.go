@@ -1,6 +1,7 @@
 package norm
 
 import (
+	"database/sql/driver"
 	"encoding/hex"
 	"fmt"
 	"log"
@@ -109,6 +110,14 @@ func sqlLiteral(v any) string {
 	if v == nil {
 		return "NULL"
 	}
+	// render custom types (decimal.Decimal, pgtype.Numeric, enums) by their driver value
+	if dv, ok := v.(driver.Valuer); ok {
+		if val, err := dv.Value(); err == nil {
+			if _, again := val.(driver.Valuer); !again {
+				return sqlLiteral(val)
+			}
+		}
+	}
 	switch t := v.(type) {
 	case string:
 		return "'" + escapeSQLString(t) + "'"
@@ -3,9 +3,12 @@ package norm
 import (
 	"bytes"
 	"log"
+	"math/big"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func TestStdLoggerAndFormatHelpers(t *testing.T) {
@@ -67,6 +70,9 @@ func TestInlineSQLAndSQLLiteral(t *testing.T) {
 	if got := sqlLiteral(struct{ Name string }{Name: "bob"}); !strings.Contains(got, "bob") {
 		t.Fatalf("sqlLiteral struct mismatch: %q", got)
 	}
+	if got := sqlLiteral(pgtype.Numeric{Int: big.NewInt(1250), Exp: -2, Valid: true}); got != "'12.50'" {
+		t.Fatalf("sqlLiteral valuer mismatch: %q", got)
+	}
 	if got := escapeSQLString("a'b"); got != "a''b" {
 		t.Fatalf("escapeSQLString mismatch: %q", got)
 	}
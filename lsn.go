@@ -0,0 +1,110 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	// defaultLSNWaitTimeout bounds how long a replica read waits to catch up before going to the primary
+	defaultLSNWaitTimeout = time.Second
+	lsnPollInterval       = 10 * time.Millisecond
+	// a primary (not in recovery) returns NULL and always counts as caught up
+	lsnReplayQuery = "SELECT COALESCE(pg_last_wal_replay_lsn() >= $1::pg_lsn, true)"
+)
+
+var lsnPattern = regexp.MustCompile(`^[0-9A-Fa-f]{1,8}/[0-9A-Fa-f]{1,8}$`)
+
+// CurrentLSN returns the primary's current WAL position (e.g. "0/16B3748"). Take it after a write has
+// committed and hand it to clients as a consistency token; reads passing it to WaitForLSN observe the write.
+func (kn *KintsNorm) CurrentLSN(ctx context.Context) (string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	const q = "SELECT pg_current_wal_lsn()::text"
+	var lsn string
+	if err := wrapExec(kn, kn.pool).QueryRow(ctx, q).Scan(&lsn); err != nil {
+		return "", wrapPgError(err, q, nil)
+	}
+	return lsn, nil
+}
+
+// WaitForLSN makes replica reads of this builder wait until the replica has replayed lsn (see CurrentLSN),
+// polling pg_last_wal_replay_lsn. If the replica does not catch up within a second the read runs on the
+// primary instead. An empty lsn is a no-op, as is a configuration without a read pool. Call it after
+// UsePrimary/UseReadPool, which replace the executor.
+func (qb *QueryBuilder) WaitForLSN(lsn string) *QueryBuilder {
+	if lsn == "" {
+		return qb
+	}
+	if !lsnPattern.MatchString(lsn) {
+		qb.setError(fmt.Errorf("invalid LSN %q", lsn))
+		return qb
+	}
+	if qb.kn == nil || qb.kn.readPool == nil {
+		return qb
+	}
+	qb.exec = lsnExecuter{exec: qb.exec, replica: qb.kn.readPool, primary: wrapExec(qb.kn, qb.kn.pool), lsn: lsn, timeout: defaultLSNWaitTimeout}
+	return qb
+}
+
+// lsnExecuter holds reads back until the replica has replayed lsn, falling back to primary after timeout.
+// Writes and statements inside a context transaction pass straight through.
+type lsnExecuter struct {
+	exec    dbExecuter
+	replica dbExecuter
+	primary dbExecuter
+	lsn     string
+	timeout time.Duration
+}
+
+func (e lsnExecuter) unwrap() dbExecuter { return e.exec }
+
+func (e lsnExecuter) pick(ctx context.Context, sql string) dbExecuter {
+	if _, ok := TxFromContext(ctx); ok || isWriteSQL(sql) {
+		return e.exec
+	}
+	if waitReplayLSN(ctx, e.replica, e.lsn, e.timeout) {
+		return e.exec
+	}
+	return e.primary
+}
+
+func (e lsnExecuter) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return e.exec.Exec(ctx, sql, arguments...)
+}
+
+func (e lsnExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return e.pick(ctx, sql).Query(ctx, sql, args...)
+}
+
+func (e lsnExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return e.pick(ctx, sql).QueryRow(ctx, sql, args...)
+}
+
+// waitReplayLSN polls the replica until it has replayed lsn; false on timeout, error or cancellation
+func waitReplayLSN(ctx context.Context, replica dbExecuter, lsn string, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for {
+		var ok bool
+		if err := replica.QueryRow(ctx, lsnReplayQuery, lsn).Scan(&ok); err != nil {
+			return false
+		}
+		if ok {
+			return true
+		}
+		if time.Now().Add(lsnPollInterval).After(deadline) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(lsnPollInterval):
+		}
+	}
+}
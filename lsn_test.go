@@ -0,0 +1,72 @@
+package norm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// replayExec reports the replica as caught up after a number of polls
+type replayExec struct {
+	recExecRepo
+	polls, lagging int
+}
+
+func (e *replayExec) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	e.lastSQL, e.lastArgs = sql, args
+	e.polls++
+	return boolRow(e.polls > e.lagging)
+}
+
+func TestLSNExecuter_WaitsThenReadsReplica(t *testing.T) {
+	replica := &replayExec{lagging: 2}
+	read, primary := &recExecRepo{}, &recExecRepo{}
+	e := lsnExecuter{exec: read, replica: replica, primary: primary, lsn: "0/16B3748", timeout: time.Second}
+	rows, err := e.Query(context.Background(), "SELECT * FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if replica.polls != 3 || replica.lastSQL != lsnReplayQuery || replica.lastArgs[0] != "0/16B3748" {
+		t.Fatalf("polls=%d sql=%s args=%v", replica.polls, replica.lastSQL, replica.lastArgs)
+	}
+	if read.lastSQL != "SELECT * FROM users" || primary.lastSQL != "" {
+		t.Fatalf("read=%q primary=%q", read.lastSQL, primary.lastSQL)
+	}
+}
+
+func TestLSNExecuter_FallsBackToPrimary(t *testing.T) {
+	replica := &replayExec{lagging: 1 << 30}
+	read, primary := &recExecRepo{}, &recExecRepo{}
+	e := lsnExecuter{exec: read, replica: replica, primary: primary, lsn: "0/1", timeout: 30 * time.Millisecond}
+	rows, err := e.Query(context.Background(), "SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if primary.lastSQL != "SELECT 1" || read.lastSQL != "" {
+		t.Fatalf("read=%q primary=%q", read.lastSQL, primary.lastSQL)
+	}
+
+	// writes never wait
+	replica.polls = 0
+	if _, err := e.Query(context.Background(), "INSERT INTO t VALUES (1) RETURNING id"); err != nil || replica.polls != 0 {
+		t.Fatalf("polls=%d err=%v", replica.polls, err)
+	}
+}
+
+func TestWaitForLSN_Validation(t *testing.T) {
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: &recExecRepo{}}).Table("t").WaitForLSN("not-an-lsn")
+	if qb.queryError() == nil {
+		t.Fatalf("expected validation error")
+	}
+	qb = (&QueryBuilder{kn: &KintsNorm{}, exec: &recExecRepo{}}).Table("t").WaitForLSN("1A/FF00")
+	if qb.queryError() != nil {
+		t.Fatalf("unexpected error: %v", qb.queryError())
+	}
+	if _, ok := qb.exec.(lsnExecuter); ok {
+		t.Fatalf("no read pool: executor should be unchanged")
+	}
+}
@@ -17,3 +17,15 @@ func (NoopMetrics) QueryDuration(duration time.Duration, query string) {}
 func (NoopMetrics) ConnectionCount(active, idle int32)                 {}
 func (NoopMetrics) ErrorCount(errorType string)                        {}
 func (NoopMetrics) CircuitStateChanged(state string)                   {}
+
+// CacheMetrics can optionally be implemented by a Metrics collector to observe read-through cache lookups
+type CacheMetrics interface {
+	CacheHit()
+	CacheMiss()
+}
+
+// RetryMetrics can optionally be implemented by a Metrics collector to count retries of failed operations
+// (attempt is 1 for the first retry)
+type RetryMetrics interface {
+	Retry(attempt int)
+}
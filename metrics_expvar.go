@@ -18,6 +18,13 @@ var (
 	expvarCircuitState      = expvar.NewString("norm_circuit_state")
 	expvarConnectionsActive = expvar.NewInt("norm_connections_active")
 	expvarConnectionsIdle   = expvar.NewInt("norm_connections_idle")
+	expvarTemplateHits      = expvar.NewInt("norm_query_template_cache_hits")
+	expvarTemplateMisses    = expvar.NewInt("norm_query_template_cache_misses")
+	expvarTxCount           = expvar.NewMap("norm_tx_count")
+	expvarLastTxMs          = expvar.NewInt("norm_last_tx_ms")
+	expvarLastTxStatements  = expvar.NewInt("norm_last_tx_statements")
+	expvarRowsReturned      = expvar.NewInt("norm_rows_returned")
+	expvarBytesReturned     = expvar.NewInt("norm_bytes_returned")
 )
 
 func (ExpvarMetrics) QueryDuration(duration time.Duration, _ string) {
@@ -34,3 +41,14 @@ func (ExpvarMetrics) ErrorCount(errorType string) {
 func (ExpvarMetrics) CircuitStateChanged(state string) {
 	expvarCircuitState.Set(state)
 }
+func (ExpvarMetrics) QueryTemplateCacheHit()  { expvarTemplateHits.Add(1) }
+func (ExpvarMetrics) QueryTemplateCacheMiss() { expvarTemplateMisses.Add(1) }
+func (ExpvarMetrics) TransactionDone(s TxStats) {
+	expvarTxCount.Add(string(s.Result), 1)
+	expvarLastTxMs.Set(s.Duration.Milliseconds())
+	expvarLastTxStatements.Set(int64(s.Statements))
+}
+func (ExpvarMetrics) QueryResult(rows, bytes int64, _ string) {
+	expvarRowsReturned.Add(rows)
+	expvarBytesReturned.Add(bytes)
+}
@@ -0,0 +1,89 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// defaultBackfillBatch is the number of rows a backfill updates per statement when BatchSize is unset
+const defaultBackfillBatch = 1000
+
+// Execer runs a statement; *pgxpool.Pool, *pgx.Conn and pgx.Tx all satisfy it
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// BackfillSpec describes a batched UPDATE
+type BackfillSpec struct {
+	Table   string // table name, optionally schema-qualified (billing.invoices)
+	SetExpr string // SET clause, e.g. `"full_name" = "name"`
+	// Where selects the rows still to fill, e.g. `"full_name" IS NULL`. SetExpr must make it false for
+	// the rows it updates, or the backfill never ends.
+	Where     string
+	BatchSize int           // rows per UPDATE (default 1000)
+	Sleep     time.Duration // pause between batches, to let replicas and autovacuum keep up
+	// Progress, when set, is called after every batch
+	Progress func(BackfillProgress)
+}
+
+// BackfillProgress reports a running backfill
+type BackfillProgress struct {
+	Table   string
+	Batches int
+	Rows    int64 // rows updated so far
+	Elapsed time.Duration
+}
+
+// Statement returns the UPDATE that fills one batch of spec
+func (s BackfillSpec) Statement() string {
+	table := quoteQualifiedIdent(s.Table)
+	return fmt.Sprintf("UPDATE %s SET %s WHERE ctid IN (SELECT ctid FROM %s WHERE %s LIMIT %d)", table, s.SetExpr, table, s.Where, s.batchSize())
+}
+
+func (s BackfillSpec) batchSize() int {
+	if s.BatchSize > 0 {
+		return s.BatchSize
+	}
+	return defaultBackfillBatch
+}
+
+// Backfill runs the UPDATE of spec batch by batch until no row matches Where, and returns the number of
+// rows updated. Each batch is its own statement, so on a pool no lock outlives a batch; inside a pgx.Tx
+// (a Go migration) the locks are held until that transaction ends. It stops early when ctx is done.
+func Backfill(ctx context.Context, db Execer, spec BackfillSpec) (int64, error) {
+	if spec.Table == "" || spec.SetExpr == "" {
+		return 0, errors.New("backfill: Table and SetExpr are required")
+	}
+	if spec.Where == "" {
+		return 0, errors.New("backfill: Where is required to know when to stop")
+	}
+	stmt := spec.Statement()
+	start := time.Now()
+	var total int64
+	for batch := 1; ; batch++ {
+		tag, err := db.Exec(ctx, stmt)
+		if err != nil {
+			return total, fmt.Errorf("backfill %s batch %d: %w", spec.Table, batch, err)
+		}
+		total += tag.RowsAffected()
+		if spec.Progress != nil {
+			spec.Progress(BackfillProgress{Table: spec.Table, Batches: batch, Rows: total, Elapsed: time.Since(start)})
+		}
+		// rows moved by concurrent updates are missed by a batch but still match Where, so only an
+		// empty batch ends the loop
+		if tag.RowsAffected() == 0 {
+			return total, nil
+		}
+		if spec.Sleep > 0 {
+			select {
+			case <-ctx.Done():
+				return total, ctx.Err()
+			case <-time.After(spec.Sleep):
+			}
+		}
+	}
+}
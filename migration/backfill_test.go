@@ -0,0 +1,56 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// batchExec updates up to the LIMIT of each statement from a fixed number of remaining rows
+type batchExec struct {
+	remaining int
+	stmts     []string
+}
+
+func (e *batchExec) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	e.stmts = append(e.stmts, sql)
+	var limit int
+	_, _ = fmt.Sscanf(sql[strings.LastIndex(sql, "LIMIT "):], "LIMIT %d", &limit)
+	n := min(limit, e.remaining)
+	e.remaining -= n
+	return pgconn.NewCommandTag(fmt.Sprintf("UPDATE %d", n)), nil
+}
+
+func TestBackfill(t *testing.T) {
+	exec := &batchExec{remaining: 250}
+	var progress []BackfillProgress
+	n, err := Backfill(context.Background(), exec, BackfillSpec{
+		Table:     "billing.invoices",
+		SetExpr:   `"total_cents" = "total" * 100`,
+		Where:     `"total_cents" IS NULL`,
+		BatchSize: 100,
+		Progress:  func(p BackfillProgress) { progress = append(progress, p) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 250 || len(exec.stmts) != 4 {
+		t.Fatalf("rows %d batches %d", n, len(exec.stmts))
+	}
+	want := `UPDATE "billing"."invoices" SET "total_cents" = "total" * 100 WHERE ctid IN (SELECT ctid FROM "billing"."invoices" WHERE "total_cents" IS NULL LIMIT 100)`
+	if exec.stmts[0] != want {
+		t.Fatalf("stmt %s", exec.stmts[0])
+	}
+	if len(progress) != 4 || progress[2].Rows != 250 || progress[2].Batches != 3 {
+		t.Fatalf("progress %+v", progress)
+	}
+}
+
+func TestBackfillRequiresWhere(t *testing.T) {
+	if _, err := Backfill(context.Background(), &batchExec{}, BackfillSpec{Table: "t", SetExpr: "a = b"}); err == nil {
+		t.Fatal("expected error without Where")
+	}
+}
@@ -10,3 +10,12 @@ func TestCanonicalPgType_Extra(t *testing.T) {
 		t.Fatalf("case preserve")
 	}
 }
+
+func TestCollationDiffers(t *testing.T) {
+	if collationDiffers(`"C"`, "C") || collationDiffers("und-x-icu", "UND-X-ICU") {
+		t.Fatalf("equal collations reported as drift")
+	}
+	if !collationDiffers(`"C"`, "") || !collationDiffers("C", "en_US") {
+		t.Fatalf("drift not detected")
+	}
+}
@@ -0,0 +1,136 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// concurrentIndexAttempts bounds the tries of one CREATE INDEX CONCURRENTLY (deadlocks and lock timeouts
+// against long-running transactions are the usual transient failures)
+const concurrentIndexAttempts = 3
+
+// concurrentIndexLock serializes concurrent index builds of migrators; it is a session lock because
+// CONCURRENTLY cannot run inside a transaction
+const concurrentIndexLock = `hashtext('github.com/kintsdev/norm-migrate-index')`
+
+// concurrentIndex is a CREATE INDEX rewritten to CREATE INDEX CONCURRENTLY
+type concurrentIndex struct {
+	stmt  string
+	index string // regclass-style quoted name, schema-qualified like its table
+}
+
+// toConcurrentIndex rewrites a planned CREATE [UNIQUE] INDEX IF NOT EXISTS "name" ON table ... statement
+func toConcurrentIndex(stmt string) (concurrentIndex, bool) {
+	var head string
+	for _, h := range []string{"CREATE INDEX IF NOT EXISTS ", "CREATE UNIQUE INDEX IF NOT EXISTS "} {
+		if strings.HasPrefix(stmt, h) {
+			head = h
+		}
+	}
+	on := strings.Index(stmt, " ON ")
+	if head == "" || on < 0 {
+		return concurrentIndex{}, false
+	}
+	name := strings.TrimSpace(stmt[len(head):on])
+	kw := strings.TrimSuffix(head, "IF NOT EXISTS ")
+	ci := concurrentIndex{stmt: kw + "CONCURRENTLY IF NOT EXISTS " + stmt[len(head):], index: name}
+	// the index lives in its table's schema
+	if tbl := statementTable(stmt); strings.Contains(tbl, `"."`) {
+		ci.index = `"` + tbl[:strings.Index(tbl, `"."`)] + `".` + name
+	}
+	return ci, true
+}
+
+// splitConcurrentIndexes takes the index statements on tables that already exist out of stmts. Indexes of
+// tables created by the same plan stay in the transaction: they are empty, and foreign keys may need them.
+func splitConcurrentIndexes(stmts []string) (rest []string, indexes []concurrentIndex) {
+	created := map[string]bool{}
+	for _, s := range stmts {
+		if strings.HasPrefix(s, "CREATE TABLE ") {
+			created[statementTable(s)] = true
+		}
+	}
+	for _, s := range stmts {
+		if ci, ok := toConcurrentIndex(s); ok && !created[statementTable(s)] {
+			indexes = append(indexes, ci)
+			continue
+		}
+		rest = append(rest, s)
+	}
+	return rest, indexes
+}
+
+// createIndexesConcurrently builds indexes one by one outside any transaction. An interrupted build leaves
+// an INVALID index behind, which IF NOT EXISTS would then accept, so invalid leftovers are dropped before
+// each attempt and after the last failed one.
+func (m *Migrator) createIndexesConcurrently(ctx context.Context, indexes []concurrentIndex) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock("+concurrentIndexLock+")"); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock("+concurrentIndexLock+")")
+	}()
+	for _, ci := range indexes {
+		if err := createIndexConcurrently(ctx, conn, ci); err != nil {
+			return fmt.Errorf("%s: %w", ci.stmt, err)
+		}
+	}
+	return nil
+}
+
+func createIndexConcurrently(ctx context.Context, conn *pgxpool.Conn, ci concurrentIndex) error {
+	var err error
+	for attempt := range concurrentIndexAttempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+		if err = dropInvalidIndex(ctx, conn, ci.index); err != nil {
+			return err
+		}
+		if _, err = conn.Exec(ctx, ci.stmt); err == nil {
+			return nil
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			switch pgErr.Code {
+			case "0A000": // feature_not_supported: partitioned tables cannot be indexed concurrently
+				_, err = conn.Exec(ctx, strings.Replace(ci.stmt, "CONCURRENTLY ", "", 1))
+				return err
+			case "23505": // unique_violation: retrying cannot help
+				_ = dropInvalidIndex(context.WithoutCancel(ctx), conn, ci.index)
+				return err
+			}
+		}
+	}
+	_ = dropInvalidIndex(context.WithoutCancel(ctx), conn, ci.index)
+	return err
+}
+
+// dropInvalidIndex removes index when a previous concurrent build left it INVALID
+func dropInvalidIndex(ctx context.Context, conn *pgxpool.Conn, index string) error {
+	var invalid bool
+	err := conn.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM pg_index WHERE indexrelid = to_regclass($1) AND NOT indisvalid)`, index).Scan(&invalid)
+	if err != nil || !invalid {
+		return err
+	}
+	_, err = conn.Exec(ctx, "DROP INDEX CONCURRENTLY IF EXISTS "+index)
+	return err
+}
@@ -0,0 +1,31 @@
+package migration
+
+import (
+	"testing"
+)
+
+func TestToConcurrentIndex(t *testing.T) {
+	ci, ok := toConcurrentIndex(`CREATE UNIQUE INDEX IF NOT EXISTS "idx_invoices_no" ON "billing"."invoices"("no")`)
+	if !ok || ci.stmt != `CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS "idx_invoices_no" ON "billing"."invoices"("no")` || ci.index != `"billing"."idx_invoices_no"` {
+		t.Fatalf("got %+v", ci)
+	}
+	ci, ok = toConcurrentIndex(`CREATE INDEX IF NOT EXISTS "idx_users_email" ON "users" USING gin(("email"))`)
+	if !ok || ci.stmt != `CREATE INDEX CONCURRENTLY IF NOT EXISTS "idx_users_email" ON "users" USING gin(("email"))` || ci.index != `"idx_users_email"` {
+		t.Fatalf("got %+v", ci)
+	}
+	if _, ok := toConcurrentIndex(`ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "x" TEXT`); ok {
+		t.Fatal("rewrote a non-index statement")
+	}
+}
+
+func TestSplitConcurrentIndexes(t *testing.T) {
+	rest, idx := splitConcurrentIndexes([]string{
+		`CREATE TABLE IF NOT EXISTS "posts" ("id" BIGINT)`,
+		`CREATE INDEX IF NOT EXISTS "idx_posts_id" ON "posts"("id")`,
+		`ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "nick" TEXT`,
+		`CREATE INDEX IF NOT EXISTS "idx_users_nick" ON "users"("nick")`,
+	})
+	if len(rest) != 3 || len(idx) != 1 || idx[0].index != `"idx_users_nick"` {
+		t.Fatalf("rest %v idx %+v", rest, idx)
+	}
+}
@@ -0,0 +1,200 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SchemaChange is one difference between a baseline snapshot and the live database
+type SchemaChange struct {
+	Kind     string // table, column, index or constraint
+	Name     string // table key, then .column / .index / .constraint for the parts of a table
+	Change   string // added, removed or changed (relative to the baseline)
+	Baseline string // definition in the baseline ("" when added)
+	Live     string // definition in the live database ("" when removed)
+}
+
+func (c SchemaChange) String() string {
+	switch c.Change {
+	case "added":
+		return fmt.Sprintf("%s %s added: %s", c.Kind, c.Name, c.Live)
+	case "removed":
+		return fmt.Sprintf("%s %s removed (was %s)", c.Kind, c.Name, c.Baseline)
+	}
+	return fmt.Sprintf("%s %s changed: %s -> %s", c.Kind, c.Name, c.Baseline, c.Live)
+}
+
+// DriftReport is the result of DetectDrift
+type DriftReport struct {
+	// Changes made to the live database since the baseline snapshot, e.g. out-of-band DDL
+	Changes []SchemaChange
+	// Models is the plan that would bring the live database to the models, without the statements that
+	// re-create what already exists (CREATE INDEX IF NOT EXISTS, column comments)
+	Models PlanResult
+}
+
+// HasDrift reports whether the live database differs from the baseline or from the models
+func (r DriftReport) HasDrift() bool {
+	p := r.Models
+	return len(r.Changes) > 0 || len(p.Statements) > 0 || len(p.UnsafeStatements) > 0 || len(p.DestructiveStatements) > 0 ||
+		len(p.IndexDrops) > 0 || len(p.ConstraintDrops) > 0 || len(p.TableDrops) > 0 || len(p.TableRenames) > 0 ||
+		len(p.ExtensionCreates) > 0 || len(p.SchemaCreates) > 0 || len(p.TypeStatements) > 0 || len(p.Backfills) > 0 || len(p.ContractStatements) > 0
+}
+
+// DetectDrift compares the live database with baseline (a snapshot taken after the last deploy, see
+// Snapshot) and with models. A nil baseline only compares with models. Run it from a health check or a
+// scheduled job to catch schema changes made outside migrations.
+func (m *Migrator) DetectDrift(ctx context.Context, baseline *SchemaSnapshot, models ...any) (DriftReport, error) {
+	schemas, _ := m.planSchemas(models)
+	if baseline != nil {
+		for _, s := range baseline.Schemas {
+			if !slices.Contains(schemas, s) {
+				schemas = append(schemas, s)
+			}
+		}
+	}
+	live, err := loadSnapshot(ctx, m.pool, schemas)
+	if err != nil {
+		return DriftReport{}, err
+	}
+	var report DriftReport
+	if len(models) > 0 {
+		plan, err := m.planFrom(live, models)
+		if err != nil {
+			return report, err
+		}
+		plan.Statements = pendingStatements(plan.Statements, live)
+		report.Models = plan
+	}
+	if baseline != nil {
+		report.Changes = diffSnapshots(baseline.Tables, live.only(baseline.Schemas))
+	}
+	return report, nil
+}
+
+// pendingStatements drops the statements of a plan that change nothing on live: the schema_migrations
+// table, indexes that exist and column comments, which Plan repeats for every existing table
+func pendingStatements(stmts []string, live *SchemaSnapshot) []string {
+	have := map[string]bool{}
+	for _, t := range live.Tables {
+		for _, idx := range t.Indexes {
+			have[quoteQualifiedIdent(tableKey(t.Schema, idx.Name))] = true
+		}
+	}
+	var out []string
+	for _, s := range stmts {
+		if strings.HasPrefix(s, "CREATE TABLE IF NOT EXISTS schema_migrations ") || strings.HasPrefix(s, "COMMENT ON ") {
+			continue
+		}
+		if ci, ok := toConcurrentIndex(s); ok && have[ci.index] {
+			continue
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+// diffSnapshots lists the changes from the tables of baseline to those of live
+func diffSnapshots(baseline, live []SnapshotTable) []SchemaChange {
+	var out []SchemaChange
+	diff := func(kind string, was, now map[string]string) {
+		for _, name := range sortedKeys(was, now) {
+			b, inBase := was[name]
+			l, inLive := now[name]
+			switch {
+			case !inLive:
+				out = append(out, SchemaChange{Kind: kind, Name: name, Change: "removed", Baseline: b})
+			case !inBase:
+				out = append(out, SchemaChange{Kind: kind, Name: name, Change: "added", Live: l})
+			case b != l:
+				out = append(out, SchemaChange{Kind: kind, Name: name, Change: "changed", Baseline: b, Live: l})
+			}
+		}
+	}
+	was, now := tablesByKey(baseline), tablesByKey(live)
+	for _, k := range sortedKeys(was, now) {
+		b, inBase := was[k]
+		l, inLive := now[k]
+		switch {
+		case !inLive:
+			out = append(out, SchemaChange{Kind: "table", Name: k, Change: "removed", Baseline: columnList(b)})
+		case !inBase:
+			out = append(out, SchemaChange{Kind: "table", Name: k, Change: "added", Live: columnList(l)})
+		default:
+			diff("column", columnDefs(k, b), columnDefs(k, l))
+			diff("index", indexDefs(k, b), indexDefs(k, l))
+			diff("constraint", constraintDefs(k, b), constraintDefs(k, l))
+		}
+	}
+	return out
+}
+
+func tablesByKey(tables []SnapshotTable) map[string]SnapshotTable {
+	out := make(map[string]SnapshotTable, len(tables))
+	for _, t := range tables {
+		// relations without columns only carry partitioning or grants
+		if len(t.Columns) > 0 {
+			out[tableKey(t.Schema, t.Name)] = t
+		}
+	}
+	return out
+}
+
+func columnList(t SnapshotTable) string {
+	names := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		names[i] = c.Name
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+func columnDefs(table string, t SnapshotTable) map[string]string {
+	out := make(map[string]string, len(t.Columns))
+	for _, c := range t.Columns {
+		def := c.DataType
+		if c.Collation != "" {
+			def += " COLLATE " + c.Collation
+		}
+		if !c.Nullable {
+			def += " NOT NULL"
+		}
+		if c.Generated != "" {
+			def += " GENERATED ALWAYS AS (" + c.Generated + ")"
+		}
+		out[table+"."+c.Name] = def
+	}
+	return out
+}
+
+func indexDefs(table string, t SnapshotTable) map[string]string {
+	out := make(map[string]string, len(t.Indexes))
+	for _, idx := range t.Indexes {
+		out[table+"."+idx.Name] = idx.Def
+	}
+	return out
+}
+
+func constraintDefs(table string, t SnapshotTable) map[string]string {
+	out := make(map[string]string, len(t.Constraints))
+	for _, c := range t.Constraints {
+		out[table+"."+c.Name] = c.Def
+	}
+	return out
+}
+
+// sortedKeys returns the union of the keys of a and b in order
+func sortedKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,59 @@
+package migration
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	baseline := snapshotOf(snapUser{}).Tables
+	baseline[0].Indexes = []SnapshotIndex{{Name: "idx_snap_users_email", Def: "CREATE INDEX idx_snap_users_email ON public.snap_users USING btree (email)"}}
+	baseline = append(baseline, SnapshotTable{Schema: "public", Name: "gone", Columns: []SnapshotColumn{{Name: "id", DataType: "bigint"}}})
+
+	live := snapshotOf(snapUser{}).Tables
+	live[0].Columns[1].Nullable = true
+	live[0].Columns = append(live[0].Columns, SnapshotColumn{Name: "hotfix", DataType: "text", Nullable: true})
+	live[0].Constraints = []SnapshotConstraint{{Name: "chk_email", Type: "c", Def: "CHECK ((email <> ''::text))"}}
+	live = append(live, SnapshotTable{Schema: "ops", Name: "scratch", Columns: []SnapshotColumn{{Name: "a", DataType: "int4", Nullable: true}}})
+
+	var got []string
+	for _, c := range diffSnapshots(baseline, live) {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"table gone removed (was (id))",
+		"table ops.scratch added: (a)",
+		"column snap_users.email changed: text NOT NULL -> text",
+		"column snap_users.hotfix added: text",
+		"index snap_users.idx_snap_users_email removed (was CREATE INDEX idx_snap_users_email ON public.snap_users USING btree (email))",
+		"constraint snap_users.chk_email added: CHECK ((email <> ''::text))",
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got\n%q\nwant\n%q", got, want)
+	}
+	if len(diffSnapshots(live, live)) != 0 {
+		t.Fatal("identical snapshots differ")
+	}
+}
+
+func TestPendingStatements(t *testing.T) {
+	live := snapshotOf(snapUser{})
+	live.Tables[0].Indexes = []SnapshotIndex{{Name: "idx_snap_users_email", Def: "CREATE INDEX ..."}}
+	plan, err := NewMigrator(nil).planFrom(live, []any{&snapUser{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.Statements) < 2 {
+		t.Fatalf("plan repeats schema_migrations and existing indexes: %v", plan.Statements)
+	}
+	if got := pendingStatements(plan.Statements, live); len(got) != 0 {
+		t.Fatalf("pending: %v", got)
+	}
+	if (DriftReport{Models: PlanResult{Statements: nil}}).HasDrift() {
+		t.Fatal("empty report has drift")
+	}
+	live.Tables[0].Indexes = nil
+	if got := pendingStatements(plan.Statements, live); len(got) != 1 {
+		t.Fatalf("missing index not pending: %v", got)
+	}
+}
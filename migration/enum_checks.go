@@ -0,0 +1,45 @@
+package migration
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var checkLiteral = regexp.MustCompile(`'((?:[^']|'')*)'`)
+
+// checkValues extracts the string literals of a constraint definition, e.g.
+// CHECK (((status)::text = ANY ((ARRAY['a'::character varying, 'b'::character varying])::text[]))) -> [a b]
+func checkValues(def string) []string {
+	var out []string
+	for _, m := range checkLiteral.FindAllStringSubmatch(def, -1) {
+		out = append(out, strings.ReplaceAll(m[1], "''", "'"))
+	}
+	return out
+}
+
+// diffEnumChecks replaces CHECK constraints of existing tables whose allowed values no longer match the
+// registered enum values. Replacing validates existing rows, so it is an unsafe statement.
+func (plan *PlanResult) diffEnumChecks(mi modelInfo, defs map[string]string) {
+	for _, f := range mi.Fields {
+		vals := mi.enumValues(f)
+		if len(vals) == 0 {
+			continue
+		}
+		name := enumCheckName(mi, f)
+		def, ok := defs[tableKey(mi.Schema, name)]
+		if !ok {
+			continue
+		}
+		have, want := checkValues(def), slices.Clone(vals)
+		slices.Sort(have)
+		slices.Sort(want)
+		if slices.Equal(slices.Compact(have), slices.Compact(want)) {
+			continue
+		}
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("enum values change for %s.%s: %s -> %s", mi.key(), f.DBName, strings.Join(have, ","), strings.Join(want, ",")))
+		plan.UnsafeStatements = append(plan.UnsafeStatements, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s, ADD CONSTRAINT %s %s",
+			mi.quotedTable(), quoteIdent(name), quoteIdent(name), enumCheckExpr(f.DBName, vals)))
+	}
+}
@@ -0,0 +1,31 @@
+package migration
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+type enumTicket struct {
+	ID     int64  `db:"id" norm:"primary_key"`
+	Status string `db:"status"`
+}
+
+func TestGenerateCreateTableSQL_EnumCheck(t *testing.T) {
+	core.RegisterEnum(reflect.TypeFor[enumTicket](), "status", []string{"open", "o'k"})
+	sqls := generateCreateTableSQL(parseModel(enumTicket{}))
+	want := `ALTER TABLE "enum_tickets" ADD CONSTRAINT "chk_enum_tickets_status" CHECK ("status" IN ('open', 'o''k'))`
+	if !slices.Contains(sqls.Statements, want) {
+		t.Fatalf("missing check in:\n%s", strings.Join(sqls.Statements, "\n"))
+	}
+}
+
+func TestCheckValues_PostgresDefinition(t *testing.T) {
+	def := `CHECK (((status)::text = ANY ((ARRAY['open'::character varying, 'o''k'::character varying])::text[])))`
+	if got := checkValues(def); !slices.Equal(got, []string{"open", "o'k"}) {
+		t.Fatalf("got %v", got)
+	}
+}
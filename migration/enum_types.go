@@ -0,0 +1,97 @@
+package migration
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// parseEnumTag splits an enum: tag value like "order_status(pending,paid)" into type name and labels
+func parseEnumTag(v string) (string, string) {
+	v = strings.TrimSpace(v)
+	name, rest, ok := strings.Cut(v, "(")
+	if !ok || !strings.HasSuffix(rest, ")") {
+		return strings.TrimSpace(name), ""
+	}
+	labels := make([]string, 0, 4)
+	for l := range strings.SplitSeq(strings.TrimSuffix(rest, ")"), ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			labels = append(labels, l)
+		}
+	}
+	return strings.TrimSpace(name), strings.Join(labels, ",")
+}
+
+// labels returns the declared labels of an enum field
+func (f fieldTag) labels() []string {
+	if f.EnumLabels == "" {
+		return nil
+	}
+	return strings.Split(f.EnumLabels, ",")
+}
+
+// enumTypeKey is the tableKey-style identity of an enum type: it lives in the model's schema
+func (mi modelInfo) enumTypeKey(f fieldTag) string { return tableKey(mi.Schema, f.EnumType) }
+
+// qualifyEnumTypes points enum columns at their type in the model's schema
+func (mi *modelInfo) qualifyEnumTypes() {
+	for i, f := range mi.Fields {
+		if f.EnumType != "" {
+			mi.Fields[i].DBType = quoteQualifiedIdent(mi.enumTypeKey(f))
+		}
+	}
+}
+
+func quoteLiteral(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+
+// planEnumTypes creates missing enum types and adds new labels in declaration order. Postgres cannot drop
+// enum labels, so labels missing from the model only produce a warning.
+func (plan *PlanResult) planEnumTypes(models []modelInfo, have map[string][]string) {
+	done := map[string]bool{}
+	for _, mi := range models {
+		for _, f := range mi.Fields {
+			if f.EnumType == "" {
+				continue
+			}
+			key := mi.enumTypeKey(f)
+			if done[key] {
+				continue
+			}
+			done[key] = true
+			want := f.labels()
+			typ := quoteQualifiedIdent(key)
+			cur, exists := have[key]
+			if !exists {
+				lits := make([]string, len(want))
+				for i, l := range want {
+					lits[i] = quoteLiteral(l)
+				}
+				plan.TypeStatements = append(plan.TypeStatements, fmt.Sprintf("CREATE TYPE %s AS ENUM (%s)", typ, strings.Join(lits, ", ")))
+				continue
+			}
+			prev := ""
+			for _, l := range want {
+				if !slices.Contains(cur, l) {
+					stmt := fmt.Sprintf("ALTER TYPE %s ADD VALUE IF NOT EXISTS %s", typ, quoteLiteral(l))
+					switch {
+					case prev != "":
+						stmt += " AFTER " + quoteLiteral(prev)
+					case len(cur) > 0:
+						stmt += " BEFORE " + quoteLiteral(cur[0])
+					}
+					plan.TypeStatements = append(plan.TypeStatements, stmt)
+				}
+				prev = l
+			}
+			var removed []string
+			for _, l := range cur {
+				if !slices.Contains(want, l) {
+					removed = append(removed, l)
+				}
+			}
+			if len(removed) > 0 {
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("enum type %s has labels not in the model: %s (Postgres cannot drop enum labels; recreate the type manually)", key, strings.Join(removed, ", ")))
+			}
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package migration
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+type enumOrder struct {
+	ID     int64  `db:"id" norm:"primary_key"`
+	Status string `db:"status" norm:"enum:order_status(pending, paid,cancelled)"`
+}
+
+func TestParseEnumTag(t *testing.T) {
+	name, labels := parseEnumTag("order_status( pending ,paid,, cancelled )")
+	if name != "order_status" || labels != "pending,paid,cancelled" {
+		t.Fatalf("got %q %q", name, labels)
+	}
+}
+
+func TestGenerateCreateTableSQL_EnumType(t *testing.T) {
+	mi := parseModel(enumOrder{})
+	create := generateCreateTableSQL(mi).Statements[0]
+	if !strings.Contains(create, `"status" `+mi.Fields[1].DBType) || !strings.Contains(mi.Fields[1].DBType, `"order_status"`) {
+		t.Fatalf("enum column type missing:\n%s", create)
+	}
+}
+
+func TestPlanEnumTypes(t *testing.T) {
+	mi := parseModel(enumOrder{})
+	var plan PlanResult
+	plan.planEnumTypes([]modelInfo{mi, mi}, nil)
+	if len(plan.TypeStatements) != 1 || !strings.HasPrefix(plan.TypeStatements[0], "CREATE TYPE ") ||
+		!strings.HasSuffix(plan.TypeStatements[0], `"order_status" AS ENUM ('pending', 'paid', 'cancelled')`) {
+		t.Fatalf("create: %v", plan.TypeStatements)
+	}
+
+	plan = PlanResult{}
+	plan.planEnumTypes([]modelInfo{mi}, map[string][]string{mi.enumTypeKey(mi.Fields[1]): {"paid", "refunded"}})
+	want := []string{
+		`ALTER TYPE ` + mi.Fields[1].DBType + ` ADD VALUE IF NOT EXISTS 'pending' BEFORE 'paid'`,
+		`ALTER TYPE ` + mi.Fields[1].DBType + ` ADD VALUE IF NOT EXISTS 'cancelled' AFTER 'paid'`,
+	}
+	if !slices.Equal(plan.TypeStatements, want) {
+		t.Fatalf("alter:\n%s", strings.Join(plan.TypeStatements, "\n"))
+	}
+	if len(plan.Warnings) != 1 || !strings.Contains(plan.Warnings[0], "refunded") {
+		t.Fatalf("warnings: %v", plan.Warnings)
+	}
+}
@@ -0,0 +1,41 @@
+package migration
+
+import (
+	"slices"
+	"strings"
+)
+
+// defaultExtension returns the extension providing the function called by a column default, or "" when it
+// is built in: uuid_generate_v*() comes from uuid-ossp, and gen_random_uuid() from pgcrypto before
+// PostgreSQL 13 (serverVersion is server_version_num; 0 means unknown and is treated as current)
+func defaultExtension(def string, serverVersion int) string {
+	d := strings.ToLower(def)
+	switch {
+	case strings.Contains(d, "uuid_generate_v"):
+		return "uuid-ossp"
+	case strings.Contains(d, "gen_random_uuid(") && serverVersion > 0 && serverVersion < 130000:
+		return "pgcrypto"
+	}
+	return ""
+}
+
+// planExtensions creates the extensions the models' column defaults need and snap does not have
+func (plan *PlanResult) planExtensions(models []modelInfo, snap *SchemaSnapshot) {
+	var version int
+	var have []string
+	if snap != nil {
+		version, have = snap.ServerVersion, snap.Extensions
+	}
+	var need []string
+	for _, mi := range models {
+		for _, f := range mi.Fields {
+			if ext := defaultExtension(f.Default, version); ext != "" && !slices.Contains(have, ext) && !slices.Contains(need, ext) {
+				need = append(need, ext)
+			}
+		}
+	}
+	slices.Sort(need)
+	for _, ext := range need {
+		plan.ExtensionCreates = append(plan.ExtensionCreates, "CREATE EXTENSION IF NOT EXISTS "+quoteIdent(ext))
+	}
+}
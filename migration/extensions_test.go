@@ -0,0 +1,53 @@
+package migration
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+type uuidGenItem struct {
+	ID    string `db:"id" norm:"primary_key,uuid:v7"`
+	Ref   string `db:"ref" norm:"default:uuid_generate_v4()"`
+	Other string `db:"other" norm:"type:text,uuid:v4,default:md5(random()::text)"`
+}
+
+func TestParseModel_UUIDGeneration(t *testing.T) {
+	mi := parseModel(uuidGenItem{})
+	if f := mi.Fields[0]; f.DBType != "UUID" || f.Default != "gen_random_uuid()" {
+		t.Fatalf("id: %+v", f)
+	}
+	// explicit type and default win
+	if f := mi.Fields[2]; f.DBType != "text" || f.Default != "md5(random()::text)" {
+		t.Fatalf("other: %+v", f)
+	}
+	create := generateCreateTableSQL(mi).Statements[0]
+	if !strings.Contains(create, `"id" UUID DEFAULT gen_random_uuid()`) {
+		t.Fatalf("create:\n%s", create)
+	}
+}
+
+func TestPlanExtensions(t *testing.T) {
+	mi := parseModel(uuidGenItem{})
+	var plan PlanResult
+	plan.planExtensions([]modelInfo{mi}, &SchemaSnapshot{ServerVersion: 120010})
+	want := []string{`CREATE EXTENSION IF NOT EXISTS "pgcrypto"`, `CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`}
+	if !slices.Equal(plan.ExtensionCreates, want) {
+		t.Fatalf("pg12: %v", plan.ExtensionCreates)
+	}
+
+	// gen_random_uuid is built in from PostgreSQL 13; installed extensions are not created again
+	plan = PlanResult{}
+	plan.planExtensions([]modelInfo{mi}, &SchemaSnapshot{ServerVersion: 160002, Extensions: []string{"uuid-ossp"}})
+	if len(plan.ExtensionCreates) != 0 {
+		t.Fatalf("pg16: %v", plan.ExtensionCreates)
+	}
+	plan = PlanResult{}
+	plan.planExtensions([]modelInfo{mi}, nil)
+	if !slices.Equal(plan.ExtensionCreates, want[1:]) {
+		t.Fatalf("no snapshot: %v", plan.ExtensionCreates)
+	}
+	if !strings.Contains(FormatPlanSQL(plan), "-- Extensions\nCREATE EXTENSION IF NOT EXISTS \"uuid-ossp\";") {
+		t.Fatalf("sql:\n%s", FormatPlanSQL(plan))
+	}
+}
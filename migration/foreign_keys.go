@@ -0,0 +1,24 @@
+package migration
+
+// ForeignKeyRef is a foreign key declared on a model field via fk:table(column)
+type ForeignKeyRef struct {
+	Table     string // referencing (child) table, schema-qualified outside public
+	Column    string // referencing column
+	RefTable  string // referenced (parent) table
+	RefColumn string // referenced column
+}
+
+// ModelForeignKeys returns the foreign keys declared by the models' fk: tags, in field order
+func ModelForeignKeys(models ...any) []ForeignKeyRef {
+	var out []ForeignKeyRef
+	for _, m := range models {
+		mi := parseModel(m)
+		for _, f := range mi.Fields {
+			if f.FKTable == "" || f.FKColumn == "" {
+				continue
+			}
+			out = append(out, ForeignKeyRef{Table: mi.key(), Column: f.DBName, RefTable: f.FKTable, RefColumn: f.FKColumn})
+		}
+	}
+	return out
+}
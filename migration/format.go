@@ -128,3 +128,49 @@ func extractTableName(sql string) string {
 	}
 	return strings.TrimSpace(rest[:end])
 }
+
+// FormatPlanSQL renders plan as an executable .sql script in apply order: schemas, enum types, table renames
+// and statements inside one transaction holding the migration lock, then rename backfills. Unsafe and destructive statements, which
+// AutoMigrate never runs or runs only with ApplyOptions opt-ins, follow commented out for review.
+func FormatPlanSQL(plan PlanResult) string {
+	var sb strings.Builder
+	sb.WriteString("-- Migration plan generated by norm; review before running.\n")
+	if len(plan.Warnings) > 0 {
+		sb.WriteString("--\n-- Warnings:\n")
+		for _, w := range plan.Warnings {
+			sb.WriteString("--   " + w + "\n")
+		}
+	}
+	section := func(title string, stmts []string, commented bool) {
+		if len(stmts) == 0 {
+			return
+		}
+		sb.WriteString("\n-- " + title + "\n")
+		for _, s := range stmts {
+			s = strings.TrimSuffix(strings.TrimSpace(s), ";") + ";"
+			if commented {
+				s = "-- " + strings.ReplaceAll(s, "\n", "\n-- ")
+			}
+			sb.WriteString(s + "\n")
+		}
+	}
+	sb.WriteString("\nBEGIN;\nSELECT pg_advisory_xact_lock(hashtext('github.com/kintsdev/norm-migrate'));\n")
+	section("Extensions", plan.ExtensionCreates, false)
+	section("Schemas", plan.SchemaCreates, false)
+	section("Enum types", plan.TypeStatements, false)
+	section("Table renames", plan.TableRenames, false)
+	section("Statements", plan.Statements, false)
+	sb.WriteString("\nCOMMIT;\n")
+	backfills := make([]string, len(plan.Backfills))
+	for i, b := range plan.Backfills {
+		backfills[i] = b.Statement()
+	}
+	section("Backfills: copy renamed columns outside the transaction; repeat each until it updates no rows", backfills, false)
+	section("Unsafe: type, collation and nullability changes (may rewrite the table or fail on existing rows)", plan.UnsafeStatements, true)
+	section("Destructive: column drops (ApplyOptions.AllowDropColumns)", plan.DestructiveStatements, true)
+	section("Destructive: index drops (ApplyOptions.AllowDropIndexes)", plan.IndexDrops, true)
+	section("Destructive: constraint drops (ApplyOptions.AllowDropConstraints)", plan.ConstraintDrops, true)
+	section("Destructive: table drops (ApplyOptions.AllowDropTables)", plan.TableDrops, true)
+	section("Contract: finish expand/contract renames once no instance uses the old columns (ApplyOptions.AllowContract)", plan.ContractStatements, true)
+	return sb.String()
+}
@@ -0,0 +1,84 @@
+package migration
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// parseGenerated splits a generated: tag value like "(lower(email)) stored" into expression and kind
+func parseGenerated(v string) (string, string) {
+	v = strings.TrimSpace(v)
+	kind := "STORED"
+	if i := strings.LastIndex(v, " "); i > 0 {
+		switch k := strings.ToUpper(strings.TrimSpace(v[i+1:])); k {
+		case "STORED", "VIRTUAL":
+			kind, v = k, strings.TrimSpace(v[:i])
+		}
+	}
+	return trimOuterParens(v), kind
+}
+
+// trimOuterParens removes one pair of parentheses wrapping the whole expression
+func trimOuterParens(s string) string {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return s
+	}
+	depth := 0
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i != len(s)-1 {
+				return s // "(a) + (b)": the first paren closes early
+			}
+		}
+	}
+	return strings.TrimSpace(s[1 : len(s)-1])
+}
+
+// generatedClause renders the GENERATED ALWAYS AS (...) column clause
+func generatedClause(f fieldTag) string {
+	return fmt.Sprintf(" GENERATED ALWAYS AS (%s) %s", f.Generated, f.GeneratedKind)
+}
+
+// indexTarget is the parenthesized key of a single-column index: the column or its index_expr
+func indexTarget(f fieldTag) string {
+	if f.IndexExpr != "" {
+		return "(" + f.IndexExpr + ")"
+	}
+	return quoteIdent(f.DBName)
+}
+
+var (
+	genCast  = regexp.MustCompile(`::(?:character varying|double precision|timestamp with(?:out)? time zone|"?[a-z_][a-z0-9_]*"?)(?:\[\])?`)
+	genNoise = strings.NewReplacer(" ", "", "\t", "", "\n", "", "(", "", ")", "", `"`, "")
+)
+
+// generationExprDiffers compares a model expression with information_schema.columns.generation_expression,
+// ignoring the casts, parentheses, quoting and spacing PostgreSQL adds when it deparses the expression
+func generationExprDiffers(want, have string) bool {
+	norm := func(s string) string {
+		return genNoise.Replace(genCast.ReplaceAllString(strings.ToLower(s), ""))
+	}
+	return norm(want) != norm(have)
+}
+
+// diffGenerated plans the changes turning an existing column into the model's generated (or regular) column
+func (plan *PlanResult) diffGenerated(mi modelInfo, f fieldTag, have string) {
+	tk, col := mi.key(), quoteIdent(f.DBName)
+	switch {
+	case f.Generated != "" && have == "":
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("column %s.%s is not generated; it must be re-created as GENERATED ALWAYS AS (%s)", tk, f.DBName, f.Generated))
+		plan.UnsafeStatements = append(plan.UnsafeStatements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s, ADD COLUMN %s %s%s",
+			mi.quotedTable(), col, col, normalizeType(f), generatedClause(f)))
+	case f.Generated != "" && generationExprDiffers(f.Generated, have):
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("generation expression change for %s.%s: %s -> %s (SET EXPRESSION needs PostgreSQL 17+)", tk, f.DBName, have, f.Generated))
+		plan.UnsafeStatements = append(plan.UnsafeStatements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET EXPRESSION AS (%s)", mi.quotedTable(), col, f.Generated))
+	case f.Generated == "" && have != "":
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("column %s.%s is generated in the database but not in the model", tk, f.DBName))
+		plan.UnsafeStatements = append(plan.UnsafeStatements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s DROP EXPRESSION", mi.quotedTable(), col))
+	}
+}
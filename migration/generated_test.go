@@ -0,0 +1,64 @@
+package migration
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+type genUser struct {
+	ID         int64  `db:"id" norm:"primary_key"`
+	Email      string `db:"email"`
+	EmailLower string `db:"email_lower" norm:"generated:(lower(email)) stored,index_expr:lower(email_lower)"`
+	Total      int64  `db:"total" norm:"generated:price + tax"`
+}
+
+func TestGenerateCreateTableSQL_GeneratedAndExpressionIndex(t *testing.T) {
+	mi := parseModel(genUser{})
+	if f := mi.Fields[2]; f.Generated != "lower(email)" || f.GeneratedKind != "STORED" || !f.Index {
+		t.Fatalf("parsed %+v", f)
+	}
+	sqls := generateCreateTableSQL(mi)
+	create := sqls.Statements[0]
+	if !strings.Contains(create, `"email_lower" TEXT GENERATED ALWAYS AS (lower(email)) STORED`) || !strings.Contains(create, `"total" BIGINT GENERATED ALWAYS AS (price + tax) STORED`) {
+		t.Fatalf("create: %s", create)
+	}
+	want := `CREATE INDEX IF NOT EXISTS "idx_gen_users_email_lower" ON "gen_users"((lower(email_lower)))`
+	if !slices.Contains(sqls.Statements, want) {
+		t.Fatalf("missing expression index in:\n%s", strings.Join(sqls.Statements, "\n"))
+	}
+}
+
+func TestParseGenerated(t *testing.T) {
+	cases := map[string][2]string{
+		"(lower(email)) stored": {"lower(email)", "STORED"},
+		"(a) + (b) virtual":     {"(a) + (b)", "VIRTUAL"},
+		"upper(name)":           {"upper(name)", "STORED"},
+	}
+	for in, want := range cases {
+		if expr, kind := parseGenerated(in); expr != want[0] || kind != want[1] {
+			t.Fatalf("%q -> %q %q", in, expr, kind)
+		}
+	}
+}
+
+func TestDiffGenerated(t *testing.T) {
+	mi := parseModel(genUser{})
+	f := mi.Fields[2]
+	var plan PlanResult
+	plan.diffGenerated(mi, f, "lower((email)::text)")
+	if len(plan.UnsafeStatements) != 0 {
+		t.Fatalf("deparsed expression should match: %v", plan.UnsafeStatements)
+	}
+	plan.diffGenerated(mi, f, "upper((email)::text)")
+	plan.diffGenerated(mi, f, "")
+	plan.diffGenerated(mi, mi.Fields[1], "lower(x)")
+	want := []string{
+		`ALTER TABLE "gen_users" ALTER COLUMN "email_lower" SET EXPRESSION AS (lower(email))`,
+		`ALTER TABLE "gen_users" DROP COLUMN "email_lower", ADD COLUMN "email_lower" TEXT GENERATED ALWAYS AS (lower(email)) STORED`,
+		`ALTER TABLE "gen_users" ALTER COLUMN "email" DROP EXPRESSION`,
+	}
+	if !slices.Equal(plan.UnsafeStatements, want) || len(plan.Warnings) != 3 {
+		t.Fatalf("unsafe:\n%s\nwarnings: %v", strings.Join(plan.UnsafeStatements, "\n"), plan.Warnings)
+	}
+}
@@ -26,7 +26,9 @@ func generateCreateTableSQL(mi modelInfo) createTableSQL {
 		if f.NotNull {
 			col += " NOT NULL"
 		}
-		if f.Default != "" {
+		if f.Generated != "" {
+			col += generatedClause(f)
+		} else if f.Default != "" {
 			col += " DEFAULT " + f.Default
 		}
 		if f.AutoInc {
@@ -36,7 +38,7 @@ func generateCreateTableSQL(mi modelInfo) createTableSQL {
 		if f.Comment != "" {
 			// escape single quotes
 			c := strings.ReplaceAll(f.Comment, "'", "''")
-			comments = append(comments, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS '%s'", quoteIdent(mi.TableName), quoteIdent(f.DBName), c))
+			comments = append(comments, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS '%s'", mi.quotedTable(), quoteIdent(f.DBName), c))
 		}
 		if f.PrimaryKey {
 			if f.PKGroup != "" {
@@ -56,11 +58,11 @@ func generateCreateTableSQL(mi modelInfo) createTableSQL {
 				if f.IndexName != "" {
 					name = f.IndexName
 				}
-				stmt := fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s", quoteIdent(name), quoteIdent(mi.TableName))
+				stmt := fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s", quoteIdent(name), mi.quotedTable())
 				if f.IndexMethod != "" {
 					stmt += fmt.Sprintf(" USING %s", f.IndexMethod)
 				}
-				stmt += fmt.Sprintf("(%s)", quoteIdent(f.DBName))
+				stmt += fmt.Sprintf("(%s)", indexTarget(f))
 				if f.IndexWhere != "" {
 					stmt += fmt.Sprintf(" WHERE %s", f.IndexWhere)
 				}
@@ -72,16 +74,19 @@ func generateCreateTableSQL(mi modelInfo) createTableSQL {
 			if f.IndexName != "" {
 				name = f.IndexName
 			}
-			stmt := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s", quoteIdent(name), quoteIdent(mi.TableName))
+			stmt := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s", quoteIdent(name), mi.quotedTable())
 			if f.IndexMethod != "" {
 				stmt += fmt.Sprintf(" USING %s", f.IndexMethod)
 			}
-			stmt += fmt.Sprintf("(%s)", quoteIdent(f.DBName))
+			stmt += fmt.Sprintf("(%s)", indexTarget(f))
 			if f.IndexWhere != "" {
 				stmt += fmt.Sprintf(" WHERE %s", f.IndexWhere)
 			}
 			idxs = append(idxs, stmt)
 		}
+		if vals := mi.enumValues(f); len(vals) > 0 {
+			idxs = append(idxs, fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s", mi.quotedTable(), quoteIdent(enumCheckName(mi, f)), enumCheckExpr(f.DBName, vals)))
+		}
 		// foreign key constraints
 		if f.FKTable != "" && f.FKColumn != "" {
 			cname := fmt.Sprintf("fk_%s_%s", mi.TableName, f.DBName)
@@ -90,7 +95,7 @@ func generateCreateTableSQL(mi modelInfo) createTableSQL {
 			}
 			// Note: PostgreSQL does not support IF NOT EXISTS for ADD CONSTRAINT; we'll de-dup in planner
 			stmt := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s(%s)",
-				quoteIdent(mi.TableName), quoteIdent(cname), quoteIdent(f.DBName), quoteIdent(f.FKTable), quoteIdent(f.FKColumn))
+				mi.quotedTable(), quoteIdent(cname), quoteIdent(f.DBName), quoteQualifiedIdent(f.FKTable), quoteIdent(f.FKColumn))
 			if f.FKOnDelete != "" {
 				stmt += fmt.Sprintf(" ON DELETE %s", strings.ToUpper(f.FKOnDelete))
 			}
@@ -122,20 +127,41 @@ func generateCreateTableSQL(mi modelInfo) createTableSQL {
 		if n, ok := uniqueNames[grp]; ok && n != "" {
 			name = n
 		}
-		idxs = append(idxs, fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s(%s)", quoteIdent(name), quoteIdent(mi.TableName), strings.Join(colsIn, ", ")))
+		idxs = append(idxs, fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s(%s)", quoteIdent(name), mi.quotedTable(), strings.Join(colsIn, ", ")))
+	}
+	for _, d := range mi.Indexes {
+		idxs = append(idxs, mi.indexStatement(d))
 	}
 	sb := strings.Builder{}
 	sb.WriteString("CREATE TABLE IF NOT EXISTS ")
-	sb.WriteString(quoteIdent(mi.TableName))
+	sb.WriteString(mi.quotedTable())
 	sb.WriteString(" (")
 	sb.WriteString(strings.Join(cols, ", "))
 	sb.WriteString(")")
+	if mi.PartitionBy != "" {
+		sb.WriteString(" PARTITION BY ")
+		sb.WriteString(mi.PartitionBy)
+	}
 	stmts := []string{sb.String()}
 	stmts = append(stmts, idxs...)
 	stmts = append(stmts, comments...)
 	return createTableSQL{Statements: stmts}
 }
 
+// enumCheckName names the CHECK constraint of a registered enum column
+func enumCheckName(mi modelInfo, f fieldTag) string {
+	return fmt.Sprintf("chk_%s_%s", mi.TableName, f.DBName)
+}
+
+// enumCheckExpr renders CHECK (col IN ('a', 'b')) for a registered enum column
+func enumCheckExpr(col string, values []string) string {
+	lits := make([]string, len(values))
+	for i, v := range values {
+		lits[i] = quoteLiteral(v)
+	}
+	return fmt.Sprintf("CHECK (%s IN (%s))", quoteIdent(col), strings.Join(lits, ", "))
+}
+
 func normalizeType(f fieldTag) string {
 	// allow explicit override like varchar(50)
 	t := strings.ToLower(f.DBType)
@@ -158,6 +184,10 @@ func normalizeType(f fieldTag) string {
 		return "DOUBLE PRECISION"
 	case t == "real":
 		return "REAL"
+	case t == "jsonb":
+		return "JSONB"
+	case t == "json":
+		return "JSON"
 	default:
 		// fallback
 		return f.DBType
@@ -15,6 +15,44 @@ type GoMigration struct {
 	Description string
 	Up          func(ctx context.Context, tx pgx.Tx) error
 	Down        func(ctx context.Context, tx pgx.Tx) error
+	// NoTransaction runs UpConn/DownConn on a connection outside any transaction instead of Up/Down, for
+	// statements such as CREATE INDEX CONCURRENTLY. Migrations before it commit first.
+	NoTransaction bool
+	UpConn        func(ctx context.Context, conn *pgx.Conn) error
+	DownConn      func(ctx context.Context, conn *pgx.Conn) error
+}
+
+// hasDown reports whether m can be rolled back
+func (m GoMigration) hasDown() bool {
+	if m.NoTransaction {
+		return m.DownConn != nil
+	}
+	return m.Down != nil
+}
+
+// step wraps the up or down function of m with the statement recording it
+func (m GoMigration) step(up bool, record string, args ...any) migrationStep {
+	desc := m.Description
+	if desc == "" {
+		desc = "unnamed"
+	}
+	dir, inTx, onConn := "up", m.Up, m.UpConn
+	if !up {
+		dir, inTx, onConn = "down", m.Down, m.DownConn
+	}
+	wrap := func(err error) error {
+		if err != nil {
+			return fmt.Errorf("go migration %d (%s) %s failed: %w", m.Version, desc, dir, err)
+		}
+		return nil
+	}
+	return migrationStep{
+		noTx:   m.NoTransaction,
+		inTx:   func(ctx context.Context, tx pgx.Tx) error { return wrap(inTx(ctx, tx)) },
+		onConn: func(ctx context.Context, conn *pgx.Conn) error { return wrap(onConn(ctx, conn)) },
+		record: record,
+		args:   args,
+	}
 }
 
 // GoMigrationRegistry holds registered Go-based migrations.
@@ -32,7 +70,10 @@ func (r *GoMigrationRegistry) Register(m GoMigration) error {
 	if m.Version <= 0 {
 		return errors.New("migration version must be > 0")
 	}
-	if m.Up == nil {
+	if m.NoTransaction && m.UpConn == nil {
+		return fmt.Errorf("migration %d: UpConn function is required with NoTransaction", m.Version)
+	}
+	if !m.NoTransaction && m.Up == nil {
 		return fmt.Errorf("migration %d: Up function is required", m.Version)
 	}
 	if _, exists := r.migrations[m.Version]; exists {
@@ -59,58 +100,31 @@ func (r *GoMigrationRegistry) sorted() []GoMigration {
 	return out
 }
 
-// MigrateUpGo applies pending Go-based migrations in ascending version order.
+// MigrateUpGo applies pending Go-based migrations in ascending version order, in one transaction except
+// for NoTransaction migrations.
 func (m *Migrator) MigrateUpGo(ctx context.Context, registry *GoMigrationRegistry) error {
 	if registry == nil || len(registry.migrations) == 0 {
 		return nil
 	}
-
-	tx, err := m.pool.Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = tx.Rollback(ctx) }()
-
-	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('github.com/kintsdev/norm-migrate'))`); err != nil {
-		return err
-	}
-	if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), checksum TEXT)`); err != nil {
-		return err
-	}
-
-	// fetch already-applied versions
-	applied := map[int64]bool{}
-	rows, err := tx.Query(ctx, `SELECT version FROM schema_migrations`)
-	if err != nil {
-		return err
-	}
-	for rows.Next() {
-		var v int64
-		if err := rows.Scan(&v); err != nil {
+	return m.withMigrationLock(ctx, func(conn *pgx.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
 			return err
 		}
-		applied[v] = true
-	}
-	rows.Close()
-
-	for _, mig := range registry.sorted() {
-		if applied[mig.Version] {
-			continue
+		applied := map[int64]bool{}
+		for _, v := range versions {
+			applied[v] = true
 		}
-		if err := mig.Up(ctx, tx); err != nil {
-			desc := mig.Description
-			if desc == "" {
-				desc = "unnamed"
+		var steps []migrationStep
+		for _, mig := range registry.sorted() {
+			if applied[mig.Version] {
+				continue
 			}
-			return fmt.Errorf("go migration %d (%s) up failed: %w", mig.Version, desc, err)
+			checksum := computeChecksum(fmt.Sprintf("go:%d:%s", mig.Version, mig.Description))
+			steps = append(steps, mig.step(true, `INSERT INTO schema_migrations(version, checksum) VALUES($1, $2)`, mig.Version, checksum))
 		}
-		checksum := computeChecksum(fmt.Sprintf("go:%d:%s", mig.Version, mig.Description))
-		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations(version, checksum) VALUES($1, $2)`, mig.Version, checksum); err != nil {
-			return err
-		}
-	}
-
-	return tx.Commit(ctx)
+		return runSteps(ctx, conn, steps)
+	})
 }
 
 // MigrateDownGo rolls back the last N applied Go-based migrations in descending version order.
@@ -121,59 +135,26 @@ func (m *Migrator) MigrateDownGo(ctx context.Context, registry *GoMigrationRegis
 	if steps <= 0 {
 		steps = 1
 	}
-
-	tx, err := m.pool.Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = tx.Rollback(ctx) }()
-
-	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('github.com/kintsdev/norm-migrate'))`); err != nil {
-		return err
-	}
-	if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), checksum TEXT)`); err != nil {
-		return err
-	}
-
-	// get applied versions in descending order
-	applied := []int64{}
-	rows, err := tx.Query(ctx, `SELECT version FROM schema_migrations ORDER BY version DESC`)
-	if err != nil {
-		return err
-	}
-	for rows.Next() {
-		var v int64
-		if err := rows.Scan(&v); err != nil {
+	return m.withMigrationLock(ctx, func(conn *pgx.Conn) error {
+		// applied versions in descending order
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
 			return err
 		}
-		applied = append(applied, v)
-	}
-	rows.Close()
-
-	rolled := 0
-	for _, v := range applied {
-		if rolled >= steps {
-			break
-		}
-		mig, ok := registry.migrations[v]
-		if !ok {
-			continue // not a Go migration version, skip
-		}
-		if mig.Down == nil {
-			return fmt.Errorf("go migration %d: Down function not provided, cannot rollback", v)
-		}
-		if err := mig.Down(ctx, tx); err != nil {
-			desc := mig.Description
-			if desc == "" {
-				desc = "unnamed"
+		var rollback []migrationStep
+		for _, v := range applied {
+			if len(rollback) >= steps {
+				break
 			}
-			return fmt.Errorf("go migration %d (%s) down failed: %w", v, desc, err)
-		}
-		if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, v); err != nil {
-			return err
+			mig, ok := registry.migrations[v]
+			if !ok {
+				continue // not a Go migration version, skip
+			}
+			if !mig.hasDown() {
+				return fmt.Errorf("go migration %d: Down function not provided, cannot rollback", v)
+			}
+			rollback = append(rollback, mig.step(false, `DELETE FROM schema_migrations WHERE version = $1`, v))
 		}
-		rolled++
-	}
-
-	return tx.Commit(ctx)
+		return runSteps(ctx, conn, rollback)
+	})
 }
@@ -0,0 +1,116 @@
+package migration
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// IndexDef declares an index that column tags cannot express, such as a composite non-unique index
+type IndexDef struct {
+	// Name defaults to idx_<table>_<columns>
+	Name string
+	// Columns are the index keys in order: a column name, optionally followed by DESC, ASC, NULLS FIRST or
+	// NULLS LAST ("created_at DESC"), or a parenthesized expression ("(lower(email))")
+	Columns []string
+	Unique  bool
+	Method  string   // btree (default), gin, gist, brin, hash
+	Where   string   // predicate of a partial index
+	Include []string // non-key columns of a covering index (INCLUDE)
+}
+
+// Indexer can be implemented by a model to declare indexes beyond its column tags. AutoMigrate creates
+// the missing ones and, like tag indexes, drops an idx_ index whose uniqueness changed so it is rebuilt.
+//
+//	func (Order) Indexes() []migration.IndexDef {
+//		return []migration.IndexDef{
+//			{Columns: []string{"customer_id", "created_at DESC"}, Include: []string{"total"}},
+//			{Columns: []string{"status"}, Where: "deleted_at IS NULL"},
+//		}
+//	}
+type Indexer interface {
+	Indexes() []IndexDef
+}
+
+var (
+	// indexKeyRe splits a key into a column name and its ordering options
+	indexKeyRe = regexp.MustCompile(`(?i)^([a-z_][a-z0-9_$]*)((?:\s+(?:ASC|DESC|NULLS\s+FIRST|NULLS\s+LAST))*)\s*$`)
+	// indexNameNoise is what a generated index name leaves out of its keys
+	indexNameNoise = regexp.MustCompile(`(?i)\s+(?:asc|desc|nulls\s+first|nulls\s+last)\b|[^a-z0-9_]+`)
+)
+
+// resolveIndexes fills the default names of defs and checks them
+func resolveIndexes(table string, defs []IndexDef) ([]IndexDef, error) {
+	out := make([]IndexDef, 0, len(defs))
+	seen := map[string]bool{}
+	for _, d := range defs {
+		if len(d.Columns) == 0 {
+			return nil, errors.New("index declares no columns")
+		}
+		if d.Name == "" {
+			parts := make([]string, len(d.Columns))
+			for i, c := range d.Columns {
+				parts[i] = strings.Trim(indexNameNoise.ReplaceAllString(strings.ToLower(c), "_"), "_")
+			}
+			// PostgreSQL truncates identifiers; keep the name the catalog will report
+			d.Name = truncateIdent("idx_" + table + "_" + strings.Join(parts, "_"))
+		}
+		if seen[d.Name] {
+			return nil, fmt.Errorf("index %s declared twice", d.Name)
+		}
+		seen[d.Name] = true
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+// indexKey quotes the column of a key, leaving expressions as written
+func indexKey(key string) string {
+	key = strings.TrimSpace(key)
+	m := indexKeyRe.FindStringSubmatch(key)
+	if m == nil {
+		return key
+	}
+	if opts := strings.Fields(m[2]); len(opts) > 0 {
+		return quoteIdent(m[1]) + " " + strings.ToUpper(strings.Join(opts, " "))
+	}
+	return quoteIdent(m[1])
+}
+
+// indexStatement renders d as CREATE [UNIQUE] INDEX IF NOT EXISTS on the table of mi
+func (mi modelInfo) indexStatement(d IndexDef) string {
+	var sb strings.Builder
+	sb.WriteString("CREATE ")
+	if d.Unique {
+		sb.WriteString("UNIQUE ")
+	}
+	sb.WriteString("INDEX IF NOT EXISTS " + quoteIdent(d.Name) + " ON " + mi.quotedTable())
+	if d.Method != "" {
+		sb.WriteString(" USING " + d.Method)
+	}
+	keys := make([]string, len(d.Columns))
+	for i, c := range d.Columns {
+		keys[i] = indexKey(c)
+	}
+	sb.WriteString("(" + strings.Join(keys, ", ") + ")")
+	if len(d.Include) > 0 {
+		cols := make([]string, len(d.Include))
+		for i, c := range d.Include {
+			cols[i] = quoteIdent(strings.TrimSpace(c))
+		}
+		sb.WriteString(" INCLUDE (" + strings.Join(cols, ", ") + ")")
+	}
+	if d.Where != "" {
+		sb.WriteString(" WHERE " + d.Where)
+	}
+	return sb.String()
+}
+
+// truncateIdent cuts name to PostgreSQL's 63 byte identifier limit
+func truncateIdent(name string) string {
+	if len(name) > 63 {
+		return name[:63]
+	}
+	return name
+}
@@ -0,0 +1,71 @@
+package migration
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type indexedOrder struct {
+	ID         int64     `db:"id" norm:"primary_key"`
+	CustomerID int64     `db:"customer_id"`
+	Status     string    `db:"status"`
+	Email      string    `db:"email"`
+	Total      float64   `db:"total"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func (indexedOrder) TableName() string { return "orders" }
+
+func (indexedOrder) Indexes() []IndexDef {
+	return []IndexDef{
+		{Columns: []string{"customer_id", "created_at desc nulls last"}, Include: []string{"total"}},
+		{Name: "orders_open_status", Columns: []string{"status"}, Where: "status <> 'closed'"},
+		{Columns: []string{"(lower(email))"}, Unique: true, Method: "btree"},
+	}
+}
+
+func TestModelIndexes(t *testing.T) {
+	stmts := generateCreateTableSQL(parseModel(&indexedOrder{})).Statements
+	for _, want := range []string{
+		`CREATE INDEX IF NOT EXISTS "idx_orders_customer_id_created_at" ON "orders"("customer_id", "created_at" DESC NULLS LAST) INCLUDE ("total")`,
+		`CREATE INDEX IF NOT EXISTS "orders_open_status" ON "orders"("status") WHERE status <> 'closed'`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS "idx_orders_lower_email" ON "orders" USING btree((lower(email)))`,
+	} {
+		if !slices.Contains(stmts, want) {
+			t.Fatalf("missing %s in %v", want, stmts)
+		}
+	}
+}
+
+func TestModelIndexesPlan(t *testing.T) {
+	snap := snapshotOf(indexedOrder{})
+	snap.Tables[0].Indexes = []SnapshotIndex{
+		{Name: "idx_orders_customer_id_created_at", Def: "CREATE INDEX idx_orders_customer_id_created_at ON public.orders USING btree (customer_id, created_at DESC NULLS LAST) INCLUDE (total)"},
+		{Name: "idx_orders_lower_email", Def: "CREATE INDEX idx_orders_lower_email ON public.orders USING btree (lower(email))"},
+	}
+	plan, err := NewMigrator(nil).PlanFromSnapshot(snap, &indexedOrder{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the email index lost its uniqueness and is rebuilt; the other declared index is kept
+	if !slices.Equal(plan.IndexDrops, []string{`DROP INDEX IF EXISTS "idx_orders_lower_email"`}) {
+		t.Fatalf("index drops: %v", plan.IndexDrops)
+	}
+	if !slices.ContainsFunc(plan.Statements, func(s string) bool { return strings.Contains(s, `"orders_open_status"`) }) {
+		t.Fatalf("statements: %v", plan.Statements)
+	}
+}
+
+type badIndexes struct {
+	ID int64 `db:"id" norm:"primary_key"`
+}
+
+func (badIndexes) Indexes() []IndexDef { return []IndexDef{{Name: "x"}} }
+
+func TestModelIndexesInvalid(t *testing.T) {
+	if _, err := NewMigrator(nil).PlanFromSnapshot(snapshotOf(), &badIndexes{}); err == nil || !strings.Contains(err.Error(), "no columns") {
+		t.Fatalf("err = %v", err)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+
+	"github.com/jackc/pgx/v5"
 )
 
 var migFileRe = regexp.MustCompile(`^(\d+)_.*\.(up|down)\.sql$`)
@@ -25,7 +27,8 @@ type filePair struct {
 	downSQL  string
 }
 
-// MigrateUpDir applies pending .up.sql migrations from dir in ascending version order
+// MigrateUpDir applies pending .up.sql migrations from dir in ascending version order, in one transaction
+// except for files with a "-- norm:no_transaction" line, which run statement by statement outside it
 func (m *Migrator) MigrateUpDir(ctx context.Context, dir string) error {
 	if dir == "" {
 		return errors.New("empty dir")
@@ -34,70 +37,77 @@ func (m *Migrator) MigrateUpDir(ctx context.Context, dir string) error {
 	if err != nil {
 		return err
 	}
-	if len(pairs) == 0 {
-		return nil
-	}
-	// ensure table
-	tx, err := m.pool.Begin(ctx)
+	return m.migrateUp(ctx, pairs)
+}
+
+// MigrateUpFS applies pending .up.sql migrations found under root of fsys, e.g. an embed.FS shipped in
+// the binary. File names and ordering follow MigrateUpDir.
+func (m *Migrator) MigrateUpFS(ctx context.Context, fsys fs.FS, root string) error {
+	pairs, err := loadMigrationPairsFS(fsys, root)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = tx.Rollback(ctx) }()
-	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('github.com/kintsdev/norm-migrate'))`); err != nil {
-		return err
-	}
-	if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), checksum TEXT)`); err != nil {
-		return err
-	}
-	// fetch applied
-	applied := map[int64]bool{}
-	rows, err := tx.Query(ctx, `SELECT version FROM schema_migrations`)
-	if err != nil {
-		return err
+	return m.migrateUp(ctx, pairs)
+}
+
+func (m *Migrator) migrateUp(ctx context.Context, pairs []filePair) error {
+	if len(pairs) == 0 {
+		return nil
 	}
-	for rows.Next() {
-		var v int64
-		if err := rows.Scan(&v); err != nil {
+	return m.withMigrationLock(ctx, func(conn *pgx.Conn) error {
+		versions, err := appliedVersions(ctx, conn)
+		if err != nil {
 			return err
 		}
-		applied[v] = true
-	}
-	rows.Close()
-	// apply in order
-	sort.Slice(pairs, func(i, j int) bool { return pairs[i].version < pairs[j].version })
-	for _, p := range pairs {
-		if applied[p.version] {
-			continue
-		}
-		if strings.TrimSpace(p.upSQL) == "" {
-			return fmt.Errorf("missing up sql for version %d", p.version)
+		applied := map[int64]bool{}
+		for _, v := range versions {
+			applied[v] = true
 		}
-		for _, stmt := range splitSQLStatements(p.upSQL) {
-			if _, err := tx.Exec(ctx, stmt); err != nil {
-				// include file information for easier debugging
-				file := p.upPath
-				if file == "" {
-					file = p.upName
-				}
-				return fmt.Errorf("apply up %d failed in %s: %w", p.version, file, err)
+		// apply in order
+		sort.Slice(pairs, func(i, j int) bool { return pairs[i].version < pairs[j].version })
+		var steps []migrationStep
+		for _, p := range pairs {
+			if applied[p.version] {
+				continue
 			}
+			if strings.TrimSpace(p.upSQL) == "" {
+				return fmt.Errorf("missing up sql for version %d", p.version)
+			}
+			// include file information for easier debugging
+			file := p.upPath
+			if file == "" {
+				file = p.upName
+			}
+			wrap := func(err error) error { return fmt.Errorf("apply up %d failed in %s: %w", p.version, file, err) }
+			steps = append(steps, sqlStep(p.upSQL, hasNoTransactionDirective(p.upSQL), wrap,
+				`INSERT INTO schema_migrations(version, checksum) VALUES($1, $2)`, p.version, computeChecksum(p.upSQL)))
 		}
-		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations(version, checksum) VALUES($1, $2)`, p.version, computeChecksum(p.upSQL)); err != nil {
-			return err
-		}
-	}
-	return tx.Commit(ctx)
+		return runSteps(ctx, conn, steps)
+	})
 }
 
 // MigrateDownDir rolls back the last N applied migrations using .down.sql files
 func (m *Migrator) MigrateDownDir(ctx context.Context, dir string, steps int) error {
-	if steps <= 0 {
-		steps = 1
-	}
 	pairs, err := loadMigrationPairs(dir)
 	if err != nil {
 		return err
 	}
+	return m.migrateDown(ctx, pairs, steps)
+}
+
+// MigrateDownFS rolls back the last N applied migrations using the .down.sql files under root of fsys
+func (m *Migrator) MigrateDownFS(ctx context.Context, fsys fs.FS, root string, steps int) error {
+	pairs, err := loadMigrationPairsFS(fsys, root)
+	if err != nil {
+		return err
+	}
+	return m.migrateDown(ctx, pairs, steps)
+}
+
+func (m *Migrator) migrateDown(ctx context.Context, pairs []filePair, steps int) error {
+	if steps <= 0 {
+		steps = 1
+	}
 	if len(pairs) == 0 {
 		return nil
 	}
@@ -105,80 +115,79 @@ func (m *Migrator) MigrateDownDir(ctx context.Context, dir string, steps int) er
 	for _, p := range pairs {
 		byVersion[p.version] = p
 	}
-	tx, err := m.pool.Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = tx.Rollback(ctx) }()
-	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('github.com/kintsdev/norm-migrate'))`); err != nil {
-		return err
-	}
-	if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), checksum TEXT)`); err != nil {
-		return err
-	}
-	// get applied versions desc
-	applied := []int64{}
-	rows, err := tx.Query(ctx, `SELECT version FROM schema_migrations ORDER BY version DESC`)
-	if err != nil {
-		return err
-	}
-	for rows.Next() {
-		var v int64
-		if err := rows.Scan(&v); err != nil {
+	return m.withMigrationLock(ctx, func(conn *pgx.Conn) error {
+		// applied versions desc
+		applied, err := appliedVersions(ctx, conn)
+		if err != nil {
 			return err
 		}
-		applied = append(applied, v)
-	}
-	rows.Close()
-	// rollback
-	for i := 0; i < steps && i < len(applied); i++ {
-		v := applied[i]
-		p, ok := byVersion[v]
-		if !ok || strings.TrimSpace(p.downSQL) == "" {
-			return fmt.Errorf("missing down sql for version %d", v)
-		}
-		for _, stmt := range splitSQLStatements(p.downSQL) {
-			// safety gates: block table/column drops unless allowed
-			low := strings.ToLower(strings.TrimSpace(stmt))
-			if strings.HasPrefix(low, "drop table ") && !m.manualOpts.AllowTableDrop {
-				return fmt.Errorf("DROP TABLE blocked by safety gate: %s", stmt)
-			}
-			if strings.Contains(low, " drop column ") && !m.manualOpts.AllowColumnDrop {
-				return fmt.Errorf("DROP COLUMN blocked by safety gate: %s", stmt)
+		var rollback []migrationStep
+		for i := 0; i < steps && i < len(applied); i++ {
+			v := applied[i]
+			p, ok := byVersion[v]
+			if !ok || strings.TrimSpace(p.downSQL) == "" {
+				return fmt.Errorf("missing down sql for version %d", v)
 			}
-			if _, err := tx.Exec(ctx, stmt); err != nil {
-				// include file information for easier debugging
-				file := p.downPath
-				if file == "" {
-					file = p.downName
+			for _, stmt := range splitSQLStatements(p.downSQL) {
+				// safety gates: block table/column drops unless allowed
+				low := strings.ToLower(strings.TrimSpace(stmt))
+				if strings.HasPrefix(low, "drop table ") && !m.manualOpts.AllowTableDrop {
+					return fmt.Errorf("DROP TABLE blocked by safety gate: %s", stmt)
 				}
-				return fmt.Errorf("apply down %d failed in %s: %w", v, file, err)
+				if strings.Contains(low, " drop column ") && !m.manualOpts.AllowColumnDrop {
+					return fmt.Errorf("DROP COLUMN blocked by safety gate: %s", stmt)
+				}
+			}
+			// include file information for easier debugging
+			file := p.downPath
+			if file == "" {
+				file = p.downName
 			}
+			wrap := func(err error) error { return fmt.Errorf("apply down %d failed in %s: %w", v, file, err) }
+			rollback = append(rollback, sqlStep(p.downSQL, hasNoTransactionDirective(p.downSQL), wrap, `DELETE FROM schema_migrations WHERE version = $1`, v))
 		}
-		if _, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, v); err != nil {
-			return err
+		return runSteps(ctx, conn, rollback)
+	})
+}
+
+func loadMigrationPairs(dir string) ([]filePair, error) {
+	pairs, err := loadMigrationPairsFS(os.DirFS(dir), ".")
+	if err != nil {
+		return nil, err
+	}
+	// report file paths as the caller knows them
+	for i := range pairs {
+		if pairs[i].upPath != "" {
+			pairs[i].upPath = filepath.Join(dir, pairs[i].upPath)
+		}
+		if pairs[i].downPath != "" {
+			pairs[i].downPath = filepath.Join(dir, pairs[i].downPath)
 		}
 	}
-	return tx.Commit(ctx)
+	return pairs, nil
 }
 
-func loadMigrationPairs(dir string) ([]filePair, error) {
+// loadMigrationPairsFS collects the migration files under root of fsys ("" = the whole of fsys)
+func loadMigrationPairsFS(fsys fs.FS, root string) ([]filePair, error) {
+	if root == "" {
+		root = "."
+	}
 	entries := map[int64]*filePair{}
-	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+	err := fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		if d.IsDir() {
 			return nil
 		}
-		name := filepath.Base(path)
+		name := d.Name()
 		m := migFileRe.FindStringSubmatch(name)
 		if len(m) != 3 {
 			return nil
 		}
 		version, _ := parseInt64(m[1])
 		kind := m[2]
-		b, rerr := os.ReadFile(path)
+		b, rerr := fs.ReadFile(fsys, path)
 		if rerr != nil {
 			return rerr
 		}
@@ -208,19 +217,6 @@ func loadMigrationPairs(dir string) ([]filePair, error) {
 	return out, nil
 }
 
-func splitSQLStatements(sql string) []string {
-	parts := strings.Split(sql, ";")
-	out := make([]string, 0, len(parts))
-	for _, p := range parts {
-		s := strings.TrimSpace(p)
-		if s == "" {
-			continue
-		}
-		out = append(out, s)
-	}
-	return out
-}
-
 func parseInt64(s string) (int64, error) {
 	var n int64
 	for _, r := range s {
@@ -0,0 +1,45 @@
+package migration
+
+import (
+	"cmp"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrationPairsFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"db/migrations/0001_init.up.sql":     {Data: []byte("CREATE TABLE a (id int)")},
+		"db/migrations/0001_init.down.sql":   {Data: []byte("DROP TABLE a")},
+		"db/migrations/nested/0002_b.up.sql": {Data: []byte("CREATE TABLE b (id int)")},
+		"db/migrations/README.md":            {Data: []byte("ignored")},
+		"other/0003_c.up.sql":                {Data: []byte("outside root")},
+	}
+	pairs, err := loadMigrationPairsFS(fsys, "db/migrations")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.SortFunc(pairs, func(a, b filePair) int { return cmp.Compare(a.version, b.version) })
+	if len(pairs) != 2 || pairs[0].downSQL != "DROP TABLE a" || pairs[1].upPath != "db/migrations/nested/0002_b.up.sql" {
+		t.Fatalf("pairs = %+v", pairs)
+	}
+	if _, err := loadMigrationPairsFS(fsys, "missing"); err == nil {
+		t.Fatal("expected error for missing root")
+	}
+}
+
+func TestLoadMigrationPairsKeepsDirPaths(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "1_a.up.sql"), []byte("SELECT 1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	pairs, err := loadMigrationPairs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pairs) != 1 || pairs[0].upPath != filepath.Join(dir, "1_a.up.sql") {
+		t.Fatalf("pairs = %+v", pairs)
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -15,10 +19,38 @@ type Migrator struct {
 	pool *pgxpool.Pool
 	// manual migration safety options
 	manualOpts ManualOptions
+	// schema, when set, places every model in it (see InSchema)
+	schema string
+	// renames selects how rename: tags are planned (see SetRenameStrategy)
+	renames RenameStrategy
 }
 
 func NewMigrator(pool *pgxpool.Pool) *Migrator { return &Migrator{pool: pool} }
 
+// InSchema returns a migrator that plans and applies every model inside schema, ignoring model schema
+// tags; unqualified fk: references resolve to the same schema. Used to migrate one schema per tenant.
+func (m *Migrator) InSchema(schema string) *Migrator {
+	c := *m
+	c.schema = schema
+	return &c
+}
+
+// parseModel is parseModel with the migrator's schema override applied
+func (m *Migrator) parseModel(model any) modelInfo {
+	mi := parseModel(model)
+	if m.schema == "" {
+		return mi
+	}
+	mi.Schema = m.schema
+	mi.qualifyEnumTypes()
+	for i, f := range mi.Fields {
+		if f.FKTable != "" && !strings.Contains(f.FKTable, ".") {
+			mi.Fields[i].FKTable = m.schema + "." + f.FKTable
+		}
+	}
+	return mi
+}
+
 // ManualOptions controls safety gates for manual file-based migrations
 type ManualOptions struct {
 	AllowTableDrop  bool // allow DROP TABLE in down migrations
@@ -36,84 +68,133 @@ type PlanResult struct {
 	DestructiveStatements []string
 	IndexDrops            []string
 	ConstraintDrops       []string
-	TableDrops            []string // tables in DB but not in models (explicit opt-in to apply)
-	TableRenames          []string // table rename statements detected via model tag
+	TableDrops            []string       // tables in DB but not in models (explicit opt-in to apply)
+	TableRenames          []string       // table rename statements detected via model tag
+	ExtensionCreates      []string       // CREATE EXTENSION statements for functions used by column defaults (applied first)
+	SchemaCreates         []string       // CREATE SCHEMA statements for non-public model schemas (applied after extensions)
+	TypeStatements        []string       // CREATE TYPE / ALTER TYPE ... ADD VALUE for enum: columns (applied after schemas)
+	Backfills             []BackfillSpec // expand/contract renames: copy old column values (applied after the migration transaction)
+	ContractStatements    []string       // expand/contract renames: drop sync triggers and old columns (opt-in, after every instance is upgraded)
 }
 
-// Plan computes a safe migration plan for given models (public schema)
+// planSchemas returns the schemas to diff and CREATE SCHEMA statements for those outside public:
+// public plus every model schema, or only the override schema of InSchema
+func (m *Migrator) planSchemas(models []any) ([]string, []string) {
+	if m.schema != "" {
+		if m.schema == "public" {
+			return []string{"public"}, nil
+		}
+		return []string{m.schema}, []string{"CREATE SCHEMA IF NOT EXISTS " + quoteIdent(m.schema)}
+	}
+	schemas := []string{"public"}
+	var creates []string
+	seen := map[string]struct{}{"public": {}}
+	for _, model := range models {
+		s := parseModel(model).Schema
+		if s == "" {
+			continue
+		}
+		if _, ok := seen[s]; ok {
+			continue
+		}
+		seen[s] = struct{}{}
+		schemas = append(schemas, s)
+		creates = append(creates, "CREATE SCHEMA IF NOT EXISTS "+quoteIdent(s))
+	}
+	return schemas, creates
+}
+
+// Plan computes a safe migration plan for given models. Models default to the public schema;
+// a norm:"schema:..." tag (or a schema-qualified TableName()) places them elsewhere.
 func (m *Migrator) Plan(ctx context.Context, models ...any) (PlanResult, error) {
-	plan := PlanResult{}
-	// ensure migrations table exists in plan as safe
-	plan.Statements = append(plan.Statements, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), checksum TEXT)`)
+	schemas, _ := m.planSchemas(models)
+	snap, err := loadSnapshot(ctx, m.pool, schemas)
+	if err != nil {
+		return PlanResult{}, err
+	}
+	return m.planFrom(snap, models)
+}
 
-	// fetch existing tables and columns with types and nullability
-	rows, err := m.pool.Query(ctx, `
-        SELECT table_name, column_name, data_type, is_nullable, COALESCE(character_maximum_length, -1)
-        FROM information_schema.columns
-        WHERE table_schema = 'public'
-    `)
+// PlanFromSnapshot computes the plan of Plan against snap instead of the live database, e.g. in CI against
+// a snapshot file taken from production (see Snapshot, ReadSnapshotFile). It needs no connection; a
+// migrator from NewMigrator(nil) works. Schemas the snapshot does not cover are planned as empty.
+func (m *Migrator) PlanFromSnapshot(snap *SchemaSnapshot, models ...any) (PlanResult, error) {
+	plan, err := m.planFrom(snap, models)
 	if err != nil {
 		return plan, err
 	}
-	defer rows.Close()
-	type colInfo struct {
-		dataType   string
-		isNullable string
-	}
-	existing := map[string]map[string]colInfo{}
-	for rows.Next() {
-		var tn, cn, dt, nn string
-		var charLen int32
-		if err := rows.Scan(&tn, &cn, &dt, &nn, &charLen); err != nil {
-			return plan, err
+	schemas, _ := m.planSchemas(models)
+	for _, s := range schemas {
+		if snap == nil || !slices.Contains(snap.Schemas, s) {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("snapshot does not cover schema %s; its tables are planned as new", s))
 		}
-		if _, ok := existing[tn]; !ok {
-			existing[tn] = map[string]colInfo{}
+	}
+	return plan, nil
+}
+
+// PlanSQL returns the plan of models as a reviewable .sql script (see FormatPlanSQL)
+func (m *Migrator) PlanSQL(ctx context.Context, models ...any) (string, error) {
+	plan, err := m.Plan(ctx, models...)
+	if err != nil {
+		return "", err
+	}
+	return FormatPlanSQL(plan), nil
+}
+
+// planFrom diffs models against the tables of snap in the plan's schemas
+func (m *Migrator) planFrom(snap *SchemaSnapshot, models []any) (PlanResult, error) {
+	plan := PlanResult{}
+	// ensure migrations table exists in plan as safe
+	plan.Statements = append(plan.Statements, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), checksum TEXT)`)
+	schemas, creates := m.planSchemas(models)
+	plan.SchemaCreates = creates
+
+	tables := snap.only(schemas)
+	existing := snapshotColumns(tables)
+	existingConstraints := snapshotConstraints(tables)
+	checkDefs := snapshotCheckDefs(tables)
+	partKeys, partitions := snapshotPartitioning(tables)
+
+	parsed := make([]modelInfo, len(models))
+	hasEnumTypes := false
+	for i, model := range models {
+		parsed[i] = m.parseModel(model)
+		if err := parsed[i].partitionErr; err != nil {
+			return plan, fmt.Errorf("%s: %w", parsed[i].key(), err)
 		}
-		existing[tn][cn] = colInfo{dataType: canonicalPgType(dt, charLen), isNullable: nn}
-	}
-	if rows.Err() != nil {
-		return plan, rows.Err()
-	}
-
-	// fetch existing constraints upfront to avoid re-adding
-	existingConstraints := map[string]struct{}{}
-	cinit, errc := m.pool.Query(ctx, `
-        SELECT c.conname
-        FROM pg_constraint c
-        JOIN pg_class r ON r.oid = c.conrelid
-        JOIN pg_namespace n ON n.oid = r.relnamespace
-        WHERE n.nspname = 'public' AND c.contype IN ('f','p','u')`)
-	if errc == nil {
-		defer cinit.Close()
-		for cinit.Next() {
-			var name string
-			if err := cinit.Scan(&name); err == nil {
-				existingConstraints[name] = struct{}{}
-			}
+		if err := parsed[i].indexErr; err != nil {
+			return plan, fmt.Errorf("%s: %w", parsed[i].key(), err)
 		}
+		hasEnumTypes = hasEnumTypes || slices.ContainsFunc(parsed[i].Fields, func(f fieldTag) bool { return f.EnumType != "" })
+	}
+	if hasEnumTypes {
+		plan.planEnumTypes(parsed, snap.enumLabels(schemas))
 	}
+	plan.planExtensions(parsed, snap)
 
 	modelTables := map[string]struct{}{}
-	for _, model := range models {
-		mi := parseModel(model)
-		modelTables[mi.TableName] = struct{}{}
+	for _, mi := range parsed {
+		tk := mi.key()
+		modelTables[tk] = struct{}{}
 
 		// Handle table rename if old name exists and new doesn't
 		if mi.RenameTableFrom != "" {
-			_, oldExists := existing[mi.RenameTableFrom]
-			_, newExists := existing[mi.TableName]
+			oldKey := tableKey(mi.Schema, mi.RenameTableFrom)
+			_, oldExists := existing[oldKey]
+			_, newExists := existing[tk]
 			if oldExists && !newExists {
-				plan.TableRenames = append(plan.TableRenames, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quoteIdent(mi.RenameTableFrom), quoteIdent(mi.TableName)))
+				plan.TableRenames = append(plan.TableRenames, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", quoteQualifiedIdent(oldKey), quoteIdent(mi.TableName)))
 				// update tracking so subsequent column checks work against new name
-				existing[mi.TableName] = existing[mi.RenameTableFrom]
-				delete(existing, mi.RenameTableFrom)
+				existing[tk] = existing[oldKey]
+				delete(existing, oldKey)
 			} else if oldExists && newExists {
-				plan.Warnings = append(plan.Warnings, fmt.Sprintf("both tables %s and %s exist; manual migration likely required", mi.RenameTableFrom, mi.TableName))
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("both tables %s and %s exist; manual migration likely required", oldKey, tk))
 			}
 		}
 
-		if _, ok := existing[mi.TableName]; !ok {
+		_, exists := existing[tk]
+		plan.planPartitioning(mi, exists, partKeys[tk])
+		if !exists {
 			sqls := generateCreateTableSQL(mi)
 			// filter out ADD CONSTRAINT if exists already
 			filtered := make([]string, 0, len(sqls.Statements))
@@ -127,7 +208,7 @@ func (m *Migrator) Plan(ctx context.Context, models ...any) (PlanResult, error)
 						cname = seg[:i]
 					}
 					cname = strings.Trim(cname, `"`)
-					if _, exists := existingConstraints[cname]; exists {
+					if _, exists := existingConstraints[tableKey(mi.Schema, cname)]; exists {
 						continue
 					}
 				}
@@ -139,41 +220,67 @@ func (m *Migrator) Plan(ctx context.Context, models ...any) (PlanResult, error)
 		for _, f := range mi.Fields {
 			// handle rename
 			if f.RenameFrom != "" {
-				_, oldExists := existing[mi.TableName][f.RenameFrom]
-				_, newExists := existing[mi.TableName][f.DBName]
+				_, oldExists := existing[tk][f.RenameFrom]
+				_, newExists := existing[tk][f.DBName]
+				if oldExists && m.renames == RenameExpandContract && f.Generated == "" {
+					plan.planExpandRename(mi, f, newExists)
+					continue
+				}
 				if oldExists && !newExists {
-					plan.Statements = append(plan.Statements, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", quoteIdent(mi.TableName), quoteIdent(f.RenameFrom), quoteIdent(f.DBName)))
+					plan.Statements = append(plan.Statements, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", mi.quotedTable(), quoteIdent(f.RenameFrom), quoteIdent(f.DBName)))
 					// treat as existing after rename for subsequent checks
-					existing[mi.TableName][f.DBName] = existing[mi.TableName][f.RenameFrom]
-					delete(existing[mi.TableName], f.RenameFrom)
+					existing[tk][f.DBName] = existing[tk][f.RenameFrom]
+					delete(existing[tk], f.RenameFrom)
 				} else if oldExists && newExists {
-					plan.Warnings = append(plan.Warnings, fmt.Sprintf("both %s and %s exist on %s; manual data migration likely required", f.RenameFrom, f.DBName, mi.TableName))
+					plan.Warnings = append(plan.Warnings, fmt.Sprintf("both %s and %s exist on %s; manual data migration likely required", f.RenameFrom, f.DBName, tk))
 				}
 			}
 
-			if _, ok := existing[mi.TableName][f.DBName]; !ok {
-				stmt := "ALTER TABLE " + quoteIdent(mi.TableName) + " ADD COLUMN IF NOT EXISTS " + quoteIdent(f.DBName) + " " + normalizeType(f)
-				if f.Default != "" {
+			if _, ok := existing[tk][f.DBName]; !ok {
+				stmt := "ALTER TABLE " + mi.quotedTable() + " ADD COLUMN IF NOT EXISTS " + quoteIdent(f.DBName) + " " + normalizeType(f)
+				if f.Collate != "" {
+					stmt += " COLLATE " + f.Collate
+				}
+				if f.Generated != "" {
+					stmt += generatedClause(f)
+				} else if f.Default != "" {
 					stmt += " DEFAULT " + f.Default
 				}
 				plan.Statements = append(plan.Statements, stmt)
 			} else {
 				// type and nullability checks
 				expected := strings.ToLower(normalizeType(f))
-				ci := existing[mi.TableName][f.DBName]
+				ci := existing[tk][f.DBName]
 				have := strings.ToLower(ci.dataType)
+				target := expected
+				if f.EnumType != "" {
+					// enum columns report their type name (udt_name); cast via the qualified type
+					expected, target = strings.ToLower(f.EnumType), f.DBType
+				}
 				if expected != "" && have != "" && expected != have {
-					plan.Warnings = append(plan.Warnings, fmt.Sprintf("type change for %s.%s: %s -> %s", mi.TableName, f.DBName, have, expected))
+					plan.Warnings = append(plan.Warnings, fmt.Sprintf("type change for %s.%s: %s -> %s", tk, f.DBName, have, expected))
 					plan.UnsafeStatements = append(plan.UnsafeStatements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s USING %s::%s",
-						quoteIdent(mi.TableName), quoteIdent(f.DBName), expected, quoteIdent(f.DBName), expected))
+						mi.quotedTable(), quoteIdent(f.DBName), target, quoteIdent(f.DBName), target))
 				}
+				// collation drift: only when the model declares one (an absent tag means "database default")
+				if f.Collate != "" && collationDiffers(f.Collate, ci.collation) {
+					have := ci.collation
+					if have == "" {
+						have = "default"
+					}
+					plan.Warnings = append(plan.Warnings, fmt.Sprintf("collation change for %s.%s: %s -> %s", tk, f.DBName, have, unquoteCollation(f.Collate)))
+					plan.UnsafeStatements = append(plan.UnsafeStatements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DATA TYPE %s COLLATE %s",
+						mi.quotedTable(), quoteIdent(f.DBName), normalizeType(f), f.Collate))
+				}
+				plan.diffGenerated(mi, f, ci.generated)
 				// nullability: set NOT NULL if model requires not null and column is nullable
 				if f.NotNull && strings.EqualFold(ci.isNullable, "YES") {
-					plan.Warnings = append(plan.Warnings, fmt.Sprintf("nullability change for %s.%s: NULLABLE -> NOT NULL", mi.TableName, f.DBName))
-					plan.UnsafeStatements = append(plan.UnsafeStatements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", quoteIdent(mi.TableName), quoteIdent(f.DBName)))
+					plan.Warnings = append(plan.Warnings, fmt.Sprintf("nullability change for %s.%s: NULLABLE -> NOT NULL", tk, f.DBName))
+					plan.UnsafeStatements = append(plan.UnsafeStatements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", mi.quotedTable(), quoteIdent(f.DBName)))
 				}
 			}
 		}
+		plan.diffEnumChecks(mi, checkDefs)
 		sqls := generateCreateTableSQL(mi)
 		if len(sqls.Statements) > 1 {
 			// filter out existing constraints
@@ -187,7 +294,7 @@ func (m *Migrator) Plan(ctx context.Context, models ...any) (PlanResult, error)
 						cname = seg[:i]
 					}
 					cname = strings.Trim(cname, `"`)
-					if _, exists := existingConstraints[cname]; exists {
+					if _, exists := existingConstraints[tableKey(mi.Schema, cname)]; exists {
 						continue
 					}
 				}
@@ -196,109 +303,109 @@ func (m *Migrator) Plan(ctx context.Context, models ...any) (PlanResult, error)
 			plan.Statements = append(plan.Statements, filtered...)
 		}
 	}
+	// privileges come last so grants on new tables follow their CREATE TABLE
+	grants := snapshotGrants(tables)
+	for _, model := range models {
+		mi := m.parseModel(model)
+		if len(mi.Privileges) == 0 {
+			continue
+		}
+		plan.Statements = append(plan.Statements, privilegeStatements(mi, grants[mi.key()])...)
+	}
 	// destructive: detect tables in DB but not in any model (opt-in apply)
 	// system tables like schema_migrations are excluded
 	systemTables := map[string]struct{}{"schema_migrations": {}}
-	for tbl := range existing {
+	for _, tbl := range slices.Sorted(maps.Keys(existing)) {
 		if _, ok := modelTables[tbl]; ok {
 			continue
 		}
 		if _, ok := systemTables[tbl]; ok {
 			continue
 		}
-		plan.TableDrops = append(plan.TableDrops, fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", quoteIdent(tbl)))
+		// partitions belong to their (model) parent; see CreatePartition
+		if _, ok := partitions[tbl]; ok {
+			continue
+		}
+		plan.TableDrops = append(plan.TableDrops, fmt.Sprintf("DROP TABLE IF EXISTS %s CASCADE", quoteQualifiedIdent(tbl)))
 		plan.Warnings = append(plan.Warnings, fmt.Sprintf("table %s exists in database but not in models; would be dropped with opt-in", tbl))
 	}
 
 	// destructive: drop columns that exist in DB but not in model (opt-in apply)
-	for tbl, cols := range existing {
+	for _, tbl := range slices.Sorted(maps.Keys(existing)) {
 		if _, ok := modelTables[tbl]; !ok {
 			continue
 		}
 		// build set of expected columns from model
 		expected := map[string]struct{}{}
 		for _, model := range models {
-			mi := parseModel(model)
-			if mi.TableName != tbl {
+			mi := m.parseModel(model)
+			if mi.key() != tbl {
 				continue
 			}
 			for _, f := range mi.Fields {
 				expected[strings.ToLower(f.DBName)] = struct{}{}
+				if f.RenameFrom != "" && m.renames == RenameExpandContract {
+					// dropped by the contract phase
+					expected[strings.ToLower(f.RenameFrom)] = struct{}{}
+				}
 			}
 		}
-		for cn := range cols {
+		for _, cn := range slices.Sorted(maps.Keys(existing[tbl])) {
 			lcn := strings.ToLower(cn)
 			if _, ok := expected[lcn]; !ok {
-				plan.DestructiveStatements = append(plan.DestructiveStatements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quoteIdent(tbl), quoteIdent(cn)))
+				plan.DestructiveStatements = append(plan.DestructiveStatements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", quoteQualifiedIdent(tbl), quoteIdent(cn)))
 			}
 		}
 	}
 	// Index diffing: drop indexes that are not expected by model, or with wrong uniqueness
-	idxRows, err := m.pool.Query(ctx, `SELECT tablename, indexname, indexdef FROM pg_indexes WHERE schemaname='public'`)
-	if err == nil {
-		defer idxRows.Close()
-		// build expected index set by name and uniqueness
-		type idxSpec struct{ unique bool }
-		expectedIdx := map[string]idxSpec{}
-		for _, model := range models {
-			mi := parseModel(model)
-			for _, f := range mi.Fields {
-				if f.Unique {
-					expectedIdx[fmt.Sprintf("idx_%s_%s", mi.TableName, f.DBName)] = idxSpec{unique: true}
-				} else if f.Index {
-					expectedIdx[fmt.Sprintf("idx_%s_%s", mi.TableName, f.DBName)] = idxSpec{unique: false}
-				}
+	// build expected index set by name and uniqueness
+	type idxSpec struct{ unique bool }
+	expectedIdx := map[string]idxSpec{}
+	expectedFK := map[string]struct{}{}
+	for _, model := range models {
+		mi := m.parseModel(model)
+		for _, f := range mi.Fields {
+			name := tableKey(mi.Schema, fmt.Sprintf("idx_%s_%s", mi.TableName, f.DBName))
+			if f.Unique {
+				expectedIdx[name] = idxSpec{unique: true}
+			} else if f.Index {
+				expectedIdx[name] = idxSpec{unique: false}
 			}
-		}
-		for idxRows.Next() {
-			var tbl, name, def string
-			if err := idxRows.Scan(&tbl, &name, &def); err != nil {
-				continue
+			if f.FKTable != "" && f.FKColumn != "" {
+				expectedFK[tableKey(mi.Schema, fmt.Sprintf("fk_%s_%s", mi.TableName, f.DBName))] = struct{}{}
 			}
-			if !strings.HasPrefix(name, "idx_") {
+		}
+		for _, d := range mi.Indexes {
+			expectedIdx[tableKey(mi.Schema, d.Name)] = idxSpec{unique: d.Unique}
+		}
+	}
+	for _, t := range tables {
+		for _, idx := range t.Indexes {
+			if !strings.HasPrefix(idx.Name, "idx_") {
 				continue
 			}
-			if spec, ok := expectedIdx[name]; ok {
+			key := tableKey(t.Schema, idx.Name)
+			if spec, ok := expectedIdx[key]; ok {
 				// if uniqueness mismatch, drop so it can be recreated
-				hasUnique := strings.Contains(strings.ToUpper(def), "UNIQUE INDEX")
+				hasUnique := strings.Contains(strings.ToUpper(idx.Def), "UNIQUE INDEX")
 				if hasUnique != spec.unique {
-					plan.IndexDrops = append(plan.IndexDrops, fmt.Sprintf("DROP INDEX IF EXISTS %s", quoteIdent(name)))
+					plan.IndexDrops = append(plan.IndexDrops, fmt.Sprintf("DROP INDEX IF EXISTS %s", quoteQualifiedIdent(key)))
 				}
 				continue
 			}
 			// unexpected index for this table -> drop
-			plan.IndexDrops = append(plan.IndexDrops, fmt.Sprintf("DROP INDEX IF EXISTS %s", quoteIdent(name)))
+			plan.IndexDrops = append(plan.IndexDrops, fmt.Sprintf("DROP INDEX IF EXISTS %s", quoteQualifiedIdent(key)))
 		}
 	}
 
 	// Constraint diffing: drop fk_* constraints not present in model
-	crows, err2 := m.pool.Query(ctx, `
-        SELECT c.conname
-        FROM pg_constraint c
-        JOIN pg_class r ON r.oid = c.conrelid
-        JOIN pg_namespace n ON n.oid = r.relnamespace
-        WHERE n.nspname = 'public' AND c.contype IN ('f')`)
-	if err2 == nil {
-		defer crows.Close()
-		expectedFK := map[string]struct{}{}
-		for _, model := range models {
-			mi := parseModel(model)
-			for _, f := range mi.Fields {
-				if f.FKTable != "" && f.FKColumn != "" {
-					expectedFK[fmt.Sprintf("fk_%s_%s", mi.TableName, f.DBName)] = struct{}{}
-				}
-			}
-		}
-		for crows.Next() {
-			var conname string
-			if err := crows.Scan(&conname); err != nil {
-				continue
-			}
-			if !strings.HasPrefix(conname, "fk_") {
+	for _, t := range tables {
+		for _, c := range t.Constraints {
+			if c.Type != "f" || !strings.HasPrefix(c.Name, "fk_") {
 				continue
 			}
-			if _, ok := expectedFK[conname]; !ok {
-				plan.ConstraintDrops = append(plan.ConstraintDrops, fmt.Sprintf("ALTER TABLE %%s DROP CONSTRAINT %s", quoteIdent(conname)))
+			if _, ok := expectedFK[tableKey(t.Schema, c.Name)]; !ok {
+				plan.ConstraintDrops = append(plan.ConstraintDrops, fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", quoteQualifiedIdent(tableKey(t.Schema, t.Name)), quoteIdent(c.Name)))
 			}
 		}
 	}
@@ -323,9 +430,9 @@ func (m *Migrator) AutoMigrate(ctx context.Context, models ...any) error {
 	if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), checksum TEXT)`); err != nil {
 		return err
 	}
-	allStmts := make([]string, 0, len(plan.Statements)+len(plan.TableRenames))
-	// apply table renames first (safe, explicit via model interface)
-	for _, s := range plan.TableRenames {
+	allStmts := make([]string, 0, len(plan.SchemaCreates)+len(plan.TypeStatements)+len(plan.Statements)+len(plan.TableRenames))
+	// extensions, schemas and enum types first, then table renames (safe, explicit via model interface)
+	for _, s := range slices.Concat(plan.ExtensionCreates, plan.SchemaCreates, plan.TypeStatements, plan.TableRenames) {
 		if _, err := tx.Exec(ctx, s); err != nil {
 			return err
 		}
@@ -351,7 +458,10 @@ func (m *Migrator) AutoMigrate(ctx context.Context, models ...any) error {
 			return err
 		}
 	}
-	return tx.Commit(ctx)
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+	return m.applyRenamePhases(ctx, ApplyOptions{}, plan)
 }
 
 // ApplyOptions controls execution of destructive statements
@@ -360,6 +470,21 @@ type ApplyOptions struct {
 	AllowDropIndexes     bool
 	AllowDropConstraints bool
 	AllowDropTables      bool
+	// Parallelism > 1 applies independent tables concurrently, one transaction per table
+	// guarded by a per-table advisory lock (foreign keys and drops are applied afterwards). It is capped
+	// at the pool's MaxConns-1, and a pool too small for two table transactions applies serially.
+	Parallelism int
+	// CreateIndexesConcurrently builds new indexes of existing tables with CREATE INDEX CONCURRENTLY after
+	// the migration transaction commits, so writes are not blocked while they build. Failed builds are
+	// retried and their INVALID leftovers dropped.
+	CreateIndexesConcurrently bool
+	// AllowContract runs PlanResult.ContractStatements after the backfills, finishing expand/contract
+	// renames. Enable it in the deploy after the one that expanded, once no instance reads the old columns.
+	AllowContract bool
+	// BackfillBatchSize, BackfillSleep and BackfillProgress tune the rename backfills (see BackfillSpec)
+	BackfillBatchSize int
+	BackfillSleep     time.Duration
+	BackfillProgress  func(BackfillProgress)
 }
 
 // AutoMigrateWithOptions applies plan with additional options (e.g., allow drops)
@@ -368,6 +493,25 @@ func (m *Migrator) AutoMigrateWithOptions(ctx context.Context, opts ApplyOptions
 	if err != nil {
 		return err
 	}
+	var indexes []concurrentIndex
+	if opts.CreateIndexesConcurrently {
+		plan.Statements, indexes = splitConcurrentIndexes(plan.Statements)
+	}
+	if opts.Parallelism = capParallelism(opts.Parallelism, m.pool.Config().MaxConns); opts.Parallelism > 1 {
+		if err := m.applyParallel(ctx, opts, plan); err != nil {
+			return err
+		}
+	} else if err := m.applyPlan(ctx, opts, plan); err != nil {
+		return err
+	}
+	if err := m.applyRenamePhases(ctx, opts, plan); err != nil {
+		return err
+	}
+	return m.createIndexesConcurrently(ctx, indexes)
+}
+
+// applyPlan applies plan in one transaction holding the migration lock
+func (m *Migrator) applyPlan(ctx context.Context, opts ApplyOptions, plan PlanResult) error {
 	tx, err := m.pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -379,35 +523,34 @@ func (m *Migrator) AutoMigrateWithOptions(ctx context.Context, opts ApplyOptions
 	if _, err := tx.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), checksum TEXT)`); err != nil {
 		return err
 	}
-	allStmts := make([]string, 0, len(plan.Statements)+len(plan.DestructiveStatements)+len(plan.IndexDrops)+len(plan.ConstraintDrops)+len(plan.TableRenames)+len(plan.TableDrops))
-	// apply table renames first (safe, explicit via model interface)
-	for _, s := range plan.TableRenames {
+	allStmts := planStatements(opts, plan)
+	for _, s := range allStmts {
 		if _, err := tx.Exec(ctx, s); err != nil {
 			return err
 		}
-		allStmts = append(allStmts, s)
 	}
-	for _, s := range plan.Statements {
-		if _, err := tx.Exec(ctx, s); err != nil {
-			return err
-		}
-		allStmts = append(allStmts, s)
+	if err := recordMigration(ctx, tx, allStmts); err != nil {
+		return err
 	}
+	return tx.Commit(ctx)
+}
+
+// planStatements lists the statements of plan that opts allows, in the order applyPlan runs them. The
+// recorded checksum is computed over this order, so a plan records the same checksum however it is applied.
+func planStatements(opts ApplyOptions, plan PlanResult) []string {
+	// extensions, schemas and enum types first, then table renames (safe, explicit via model interface)
+	out := slices.Concat(plan.ExtensionCreates, plan.SchemaCreates, plan.TypeStatements, plan.TableRenames, plan.Statements)
 	if opts.AllowDropColumns {
-		for _, s := range plan.DestructiveStatements {
-			if _, err := tx.Exec(ctx, s); err != nil {
-				return err
-			}
-			allStmts = append(allStmts, s)
-		}
+		out = append(out, plan.DestructiveStatements...)
 	}
+	return append(out, planDrops(opts, plan)...)
+}
+
+// planDrops lists the index, constraint and table drops of plan that opts allows
+func planDrops(opts ApplyOptions, plan PlanResult) []string {
+	var out []string
 	if opts.AllowDropIndexes {
-		for _, s := range plan.IndexDrops {
-			if _, err := tx.Exec(ctx, s); err != nil {
-				return err
-			}
-			allStmts = append(allStmts, s)
-		}
+		out = append(out, plan.IndexDrops...)
 	}
 	if opts.AllowDropConstraints {
 		for _, s := range plan.ConstraintDrops {
@@ -415,35 +558,31 @@ func (m *Migrator) AutoMigrateWithOptions(ctx context.Context, opts ApplyOptions
 			if strings.Contains(s, "%s") {
 				continue
 			}
-			if _, err := tx.Exec(ctx, s); err != nil {
-				return err
-			}
-			allStmts = append(allStmts, s)
+			out = append(out, s)
 		}
 	}
 	if opts.AllowDropTables {
-		for _, s := range plan.TableDrops {
-			if _, err := tx.Exec(ctx, s); err != nil {
-				return err
-			}
-			allStmts = append(allStmts, s)
-		}
+		out = append(out, plan.TableDrops...)
 	}
-	checksum := computeChecksum(strings.Join(allStmts, ";"))
+	return out
+}
+
+// recordMigration adds a schema_migrations row for the checksum of stmts unless one exists
+func recordMigration(ctx context.Context, tx pgx.Tx, stmts []string) error {
+	checksum := computeChecksum(strings.Join(stmts, ";"))
 	var exists bool
 	if err := tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE checksum = $1)`, checksum).Scan(&exists); err != nil {
 		return err
 	}
-	if !exists {
-		var maxVersion int64
-		if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&maxVersion); err != nil {
-			return err
-		}
-		if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations(version, checksum) VALUES($1, $2)`, maxVersion+1, checksum); err != nil {
-			return err
-		}
+	if exists {
+		return nil
 	}
-	return tx.Commit(ctx)
+	var maxVersion int64
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&maxVersion); err != nil {
+		return err
+	}
+	_, err := tx.Exec(ctx, `INSERT INTO schema_migrations(version, checksum) VALUES($1, $2)`, maxVersion+1, checksum)
+	return err
 }
 
 func computeChecksum(s string) string {
@@ -451,6 +590,16 @@ func computeChecksum(s string) string {
 	return hex.EncodeToString(sum[:])
 }
 
+// unquoteCollation strips identifier quotes from a collation tag value ("C" -> C)
+func unquoteCollation(c string) string {
+	return strings.Trim(strings.TrimSpace(c), `"`)
+}
+
+// collationDiffers compares a model collation with information_schema.columns.collation_name
+func collationDiffers(want, have string) bool {
+	return !strings.EqualFold(unquoteCollation(want), unquoteCollation(have))
+}
+
 // canonicalPgType converts information_schema types into our normalized tokens
 func canonicalPgType(dataType string, charLen int32) string {
 	dt := strings.ToLower(strings.TrimSpace(dataType))
@@ -469,12 +618,36 @@ func canonicalPgType(dataType string, charLen int32) string {
 		return "TEXT"
 	case "timestamp with time zone":
 		return "TIMESTAMPTZ"
+	case "jsonb":
+		return "JSONB"
+	case "json":
+		return "JSON"
 	case "character varying":
 		if charLen > 0 {
 			return fmt.Sprintf("varchar(%d)", charLen)
 		}
 		return "varchar"
 	default:
+		// array columns are reported by their udt_name (e.g. _text, _int8)
+		if elem, ok := strings.CutPrefix(dt, "_"); ok {
+			if base, ok := pgArrayElemTypes[elem]; ok {
+				return base + "[]"
+			}
+			return strings.ToUpper(elem) + "[]"
+		}
 		return strings.ToUpper(dataType)
 	}
 }
+
+// pgArrayElemTypes maps array element udt names to the tokens produced by normalizeType
+var pgArrayElemTypes = map[string]string{
+	"text":        "TEXT",
+	"int4":        "INTEGER",
+	"int8":        "BIGINT",
+	"bool":        "BOOLEAN",
+	"float4":      "REAL",
+	"float8":      "DOUBLE PRECISION",
+	"uuid":        "UUID",
+	"timestamptz": "TIMESTAMPTZ",
+	"jsonb":       "JSONB",
+}
@@ -0,0 +1,25 @@
+package migration
+
+// ModelTable is the table and columns a model maps to
+type ModelTable struct {
+	Schema  string // "public" unless set with a schema tag
+	Table   string
+	Columns []string
+}
+
+// ModelTables returns the table and column names declared by the models, in model and field order
+func ModelTables(models ...any) []ModelTable {
+	out := make([]ModelTable, 0, len(models))
+	for _, m := range models {
+		mi := parseModel(m)
+		mt := ModelTable{Schema: mi.Schema, Table: mi.TableName, Columns: make([]string, 0, len(mi.Fields))}
+		if mt.Schema == "" {
+			mt.Schema = "public"
+		}
+		for _, f := range mi.Fields {
+			mt.Columns = append(mt.Columns, f.DBName)
+		}
+		out = append(out, mt)
+	}
+	return out
+}
@@ -0,0 +1,36 @@
+package migration
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kintsdev/norm/internal/core"
+)
+
+type upperNamer struct{}
+
+func (upperNamer) TableName(typeName string) string   { return "tbl_" + strings.ToLower(typeName) }
+func (upperNamer) ColumnName(fieldName string) string { return "col_" + strings.ToLower(fieldName) }
+
+type namedWidget struct {
+	ID    int64 `norm:"primary_key"`
+	Title string
+	Code  string `db:"code"`
+}
+
+func TestParseModel_NamingStrategy(t *testing.T) {
+	core.SetNamer(upperNamer{})
+	t.Cleanup(func() { core.SetNamer(nil) })
+
+	mi := parseModel(namedWidget{})
+	if mi.TableName != "tbl_namedwidget" {
+		t.Fatalf("table %q", mi.TableName)
+	}
+	var cols []string
+	for _, f := range mi.Fields {
+		cols = append(cols, f.DBName)
+	}
+	if got := strings.Join(cols, ","); got != "col_id,col_title,code" {
+		t.Fatalf("columns %s", got)
+	}
+}
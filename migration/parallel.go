@@ -0,0 +1,177 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// tableGroup holds the statements of a single table that can be applied independently
+type tableGroup struct {
+	table      string
+	statements []string
+}
+
+// statementTable returns the (unquoted) table a DDL statement targets, or "" when unknown.
+// Handles CREATE/ALTER TABLE, CREATE [UNIQUE] INDEX ... ON and COMMENT ON COLUMN.
+func statementTable(stmt string) string {
+	up := strings.ToUpper(stmt)
+	var rest string
+	switch {
+	case strings.HasPrefix(up, "CREATE TABLE ") || strings.HasPrefix(up, "ALTER TABLE "):
+		rest = stmt[strings.Index(up, "TABLE ")+len("TABLE "):]
+		if strings.HasPrefix(strings.ToUpper(rest), "IF NOT EXISTS ") {
+			rest = rest[len("IF NOT EXISTS "):]
+		}
+	case strings.HasPrefix(up, "CREATE INDEX ") || strings.HasPrefix(up, "CREATE UNIQUE INDEX "):
+		i := strings.Index(up, " ON ")
+		if i < 0 {
+			return ""
+		}
+		rest = stmt[i+len(" ON "):]
+	case strings.HasPrefix(up, "COMMENT ON COLUMN "):
+		rest = stmt[len("COMMENT ON COLUMN "):]
+		// "table"."column" -> keep the table part only
+		if i := strings.LastIndex(rest[:strings.IndexAny(rest+" ", " ")], "."); i > 0 {
+			rest = rest[:i]
+		}
+	default:
+		return ""
+	}
+	rest = strings.TrimSpace(rest)
+	end := len(rest)
+	if i := strings.IndexAny(rest, " (\n\t"); i >= 0 {
+		end = i
+	}
+	return strings.Trim(rest[:end], `"`)
+}
+
+// groupStatementsByTable splits statements into per-table groups that can run concurrently.
+// Foreign key constraints reference other tables and statements that cannot be attributed
+// to a single table are returned as deferred, to be applied serially afterwards.
+// Groups are sorted by table name and keep the original statement order within a table.
+func groupStatementsByTable(stmts []string) (groups []tableGroup, deferred []string) {
+	byTable := map[string]*tableGroup{}
+	for _, s := range stmts {
+		if strings.Contains(strings.ToUpper(s), "FOREIGN KEY") {
+			deferred = append(deferred, s)
+			continue
+		}
+		tbl := statementTable(s)
+		if tbl == "" || tbl == "schema_migrations" {
+			deferred = append(deferred, s)
+			continue
+		}
+		g := byTable[tbl]
+		if g == nil {
+			g = &tableGroup{table: tbl}
+			byTable[tbl] = g
+		}
+		g.statements = append(g.statements, s)
+	}
+	groups = make([]tableGroup, 0, len(byTable))
+	for _, g := range byTable {
+		groups = append(groups, *g)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].table < groups[j].table })
+	return groups, deferred
+}
+
+// applyParallel applies a plan using one transaction per table, running up to opts.Parallelism
+// transactions concurrently, each holding an exclusive per-table advisory lock. The global migration lock
+// is held at session level for the whole run (see withMigrationLock), so no other migrator sees the schema
+// between phases. Extensions, schemas, types and renames run first; foreign keys, drops and the
+// schema_migrations bookkeeping run afterwards in a single serial transaction.
+func (m *Migrator) applyParallel(ctx context.Context, opts ApplyOptions, plan PlanResult) error {
+	return m.withMigrationLock(ctx, func(conn *pgx.Conn) error {
+		// phase 1: schemas, enum types and renames must happen before anything touches the new names
+		tx, err := conn.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback(ctx) }()
+		for _, s := range slices.Concat(plan.ExtensionCreates, plan.SchemaCreates, plan.TypeStatements, plan.TableRenames) {
+			if _, err := tx.Exec(ctx, s); err != nil {
+				return err
+			}
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return err
+		}
+
+		// phase 2: independent per-table groups in parallel
+		stmts := append([]string(nil), plan.Statements...)
+		if opts.AllowDropColumns {
+			stmts = append(stmts, plan.DestructiveStatements...)
+		}
+		groups, deferred := groupStatementsByTable(stmts)
+		sem := make(chan struct{}, opts.Parallelism)
+		errs := make([]error, len(groups))
+		var wg sync.WaitGroup
+		for i, g := range groups {
+			wg.Go(func() {
+				select {
+				case sem <- struct{}{}:
+				case <-ctx.Done():
+					errs[i] = ctx.Err()
+					return
+				}
+				defer func() { <-sem }()
+				errs[i] = m.applyTableGroup(ctx, g)
+			})
+		}
+		wg.Wait()
+		for i, err := range errs {
+			if err != nil {
+				return fmt.Errorf("table %s: %w", groups[i].table, err)
+			}
+		}
+
+		// phase 3: cross-table and destructive statements serially, then record the checksum of the
+		// statements in plan order, as a serial apply records it
+		final, err := conn.Begin(ctx)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = final.Rollback(ctx) }()
+		for _, s := range slices.Concat(deferred, planDrops(opts, plan)) {
+			if _, err := final.Exec(ctx, s); err != nil {
+				return err
+			}
+		}
+		if err := recordMigration(ctx, final, planStatements(opts, plan)); err != nil {
+			return err
+		}
+		return final.Commit(ctx)
+	})
+}
+
+// capParallelism limits the table transactions to the pool connections left beside the one pinned by
+// the session migration lock, so none of them waits for a connection the run itself holds
+func capParallelism(parallelism int, maxConns int32) int {
+	return max(min(parallelism, int(maxConns)-1), 0)
+}
+
+// applyTableGroup applies a single table's statements in its own transaction; the caller holds the global
+// migration lock
+func (m *Migrator) applyTableGroup(ctx context.Context, g tableGroup) error {
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('github.com/kintsdev/norm-migrate:' || $1::text))`, g.table); err != nil {
+		return err
+	}
+	for _, s := range g.statements {
+		if _, err := tx.Exec(ctx, s); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,81 @@
+package migration
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStatementTable(t *testing.T) {
+	cases := map[string]string{
+		`CREATE TABLE IF NOT EXISTS "users" ("id" BIGINT)`:                        "users",
+		`ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "age" INTEGER`:              "users",
+		`CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_email" ON "users"("email")`: "users",
+		`COMMENT ON COLUMN "users"."name" IS 'x'`:                                 "users",
+		`DROP INDEX IF EXISTS "idx_x"`:                                            "",
+	}
+	for in, want := range cases {
+		if got := statementTable(in); got != want {
+			t.Fatalf("%s => %q want %q", in, got, want)
+		}
+	}
+}
+
+func TestGroupStatementsByTable(t *testing.T) {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS "users" ("id" BIGINT)`,
+		`CREATE TABLE IF NOT EXISTS "posts" ("id" BIGINT, "user_id" BIGINT)`,
+		`ALTER TABLE "posts" ADD CONSTRAINT "fk_posts_user_id" FOREIGN KEY ("user_id") REFERENCES "users"("id")`,
+		`CREATE INDEX IF NOT EXISTS "idx_posts_user_id" ON "posts"("user_id")`,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY)`,
+	}
+	groups, deferred := groupStatementsByTable(stmts)
+	if len(groups) != 2 || groups[0].table != "posts" || groups[1].table != "users" {
+		t.Fatalf("groups=%v", groups)
+	}
+	if len(groups[0].statements) != 2 {
+		t.Fatalf("posts stmts=%v", groups[0].statements)
+	}
+	if len(deferred) != 2 {
+		t.Fatalf("deferred=%v", deferred)
+	}
+}
+
+func TestPlanStatementsKeepPlanOrder(t *testing.T) {
+	plan := PlanResult{
+		SchemaCreates:   []string{"s"},
+		TypeStatements:  []string{"t"},
+		TableRenames:    []string{"r"},
+		Statements:      []string{"users", "posts fk", "posts"},
+		IndexDrops:      []string{"di"},
+		ConstraintDrops: []string{"dc", "dc %s"},
+		TableDrops:      []string{"dt"},
+	}
+	opts := ApplyOptions{AllowDropIndexes: true, AllowDropConstraints: true, Parallelism: 4}
+	want := []string{"s", "t", "r", "users", "posts fk", "posts", "di", "dc"}
+	if got := planStatements(opts, plan); !slices.Equal(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if got := planDrops(opts, plan); !slices.Equal(got, []string{"di", "dc"}) {
+		t.Fatalf("drops=%v", got)
+	}
+}
+
+func TestCapParallelism(t *testing.T) {
+	cases := []struct {
+		parallelism int
+		maxConns    int32
+		want        int
+	}{
+		{4, 10, 4},
+		{4, 4, 3},
+		{4, 3, 2},
+		{4, 2, 1}, // one connection beside the lock: serial
+		{4, 1, 0},
+		{0, 10, 0},
+	}
+	for _, c := range cases {
+		if got := capParallelism(c.parallelism, c.maxConns); got != c.want {
+			t.Errorf("capParallelism(%d, %d) = %d, want %d", c.parallelism, c.maxConns, got, c.want)
+		}
+	}
+}
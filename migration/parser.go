@@ -4,6 +4,8 @@ import (
 	"reflect"
 	"strings"
 	"time"
+
+	core "github.com/kintsdev/norm/internal/core"
 )
 
 // fieldTag represents parsed metadata for a struct field
@@ -24,6 +26,7 @@ type fieldTag struct {
 	IndexName           string
 	IndexMethod         string // btree, gin, hash
 	IndexWhere          string
+	IndexExpr           string // index_expr: indexes this expression instead of the column
 	OnUpdate            string
 	IsPointer           bool
 	FKTable             string
@@ -36,15 +39,31 @@ type fieldTag struct {
 	RenameFrom          string
 	Collate             string
 	Comment             string
+	Generated           string // generated: column expression, without the outer parentheses
+	GeneratedKind       string // STORED or VIRTUAL
+	EnumType            string // enum:name(labels) Postgres ENUM type name
+	EnumLabels          string // comma-separated labels in declaration order
 }
 
 type modelInfo struct {
 	TableName       string
+	Schema          string // from a norm:"schema:..." tag or a qualified TableName(); "" means public
 	RenameTableFrom string // non-empty if table was renamed from old name
 	Fields          []fieldTag
+	// Enums maps lower-case columns to values registered via norm.RegisterEnumValues (CHECK constraints)
+	Enums map[string][]string
+	// Privileges holds the per-role grants declared by a Privileger model
+	Privileges map[string]Privileges
+	// PartitionBy is the PARTITION BY argument from a partition_by tag, e.g. RANGE ("created_at")
+	PartitionBy  string
+	partitionErr error
+	// Indexes holds the indexes declared by an Indexer model
+	Indexes  []IndexDef
+	indexErr error
 }
 
-// TableNamer can be implemented by a model to override the default table name.
+// TableNamer can be implemented by a model to override the default table name
+// (a `norm:"table:name"` tag on a blank `_` field works too).
 type TableNamer interface {
 	TableName() string
 }
@@ -56,39 +75,7 @@ type TableRenamer interface {
 }
 
 // splitTagTokens splits a tag string by commas while preserving commas inside parentheses
-func splitTagTokens(s string) []string {
-	tokens := []string{}
-	var b strings.Builder
-	depth := 0
-	for _, r := range s {
-		switch r {
-		case '(':
-			depth++
-			b.WriteRune(r)
-		case ')':
-			if depth > 0 {
-				depth--
-			}
-			b.WriteRune(r)
-		case ',':
-			if depth == 0 {
-				tok := strings.TrimSpace(b.String())
-				if tok != "" {
-					tokens = append(tokens, tok)
-				}
-				b.Reset()
-			} else {
-				b.WriteRune(r)
-			}
-		default:
-			b.WriteRune(r)
-		}
-	}
-	if t := strings.TrimSpace(b.String()); t != "" {
-		tokens = append(tokens, t)
-	}
-	return tokens
-}
+func splitTagTokens(s string) []string { return core.SplitTagTokens(s) }
 
 // quoteIdent wraps an identifier with double quotes to avoid reserved word collisions
 func quoteIdent(id string) string {
@@ -100,52 +87,64 @@ func quoteIdent(id string) string {
 	return "\"" + id + "\""
 }
 
-func toSnakeCase(s string) string {
-	var out []rune
-	for i, r := range s {
-		if i > 0 && r >= 'A' && r <= 'Z' {
-			out = append(out, '_', r+('a'-'A'))
-		} else {
-			if r >= 'A' && r <= 'Z' {
-				out = append(out, r+('a'-'A'))
-			} else {
-				out = append(out, r)
-			}
-		}
+// quoteQualifiedIdent quotes each part of a dotted name (schema.table -> "schema"."table")
+func quoteQualifiedIdent(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = quoteIdent(p)
 	}
-	return string(out)
+	return strings.Join(parts, ".")
 }
 
-func defaultTableName(t reflect.Type) string {
-	for t.Kind() == reflect.Pointer {
-		t = t.Elem()
+// tableKey identifies a table across schemas; public tables keep their bare name
+func tableKey(schema, table string) string {
+	if schema == "" || schema == "public" {
+		return table
 	}
-	return toSnakeCase(t.Name()) + "s"
+	return schema + "." + table
 }
 
+// enumValues returns the registered enum values of field f, if any
+func (mi modelInfo) enumValues(f fieldTag) []string { return mi.Enums[strings.ToLower(f.DBName)] }
+
+// key is the model's tableKey
+func (mi modelInfo) key() string { return tableKey(mi.Schema, mi.TableName) }
+
+// quotedTable is the model's table as a (schema-qualified when not public) quoted identifier
+func (mi modelInfo) quotedTable() string { return quoteQualifiedIdent(mi.key()) }
+
 func parseModel(model any) modelInfo {
 	t := reflect.TypeOf(model)
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
-	mi := modelInfo{TableName: defaultTableName(t)}
-	// Allow model to override table name
-	if tn, ok := model.(TableNamer); ok {
-		mi.TableName = tn.TableName()
+	// TableName() method, norm:"table:..." tag or snake_case plural
+	mi := modelInfo{TableName: core.TableNameOf(model), Schema: core.SchemaName(t)}
+	if i := strings.LastIndex(mi.TableName, "."); i >= 0 {
+		if mi.Schema == "" {
+			mi.Schema = mi.TableName[:i]
+		}
+		mi.TableName = mi.TableName[i+1:]
 	}
 	// Allow model to signal a table rename
 	if tr, ok := model.(TableRenamer); ok {
 		mi.RenameTableFrom = tr.RenameTableFrom()
 	}
-	for f := range t.Fields() {
-		f := f
+	mi.Enums = core.EnumColumns(t)
+	if spec := core.PartitionBy(t); spec != "" {
+		mi.PartitionBy, mi.partitionErr = parsePartitionBy(spec)
+	}
+	if p, ok := model.(Privileger); ok {
+		mi.Privileges = p.Privileges()
+	}
+	if ix, ok := model.(Indexer); ok {
+		mi.Indexes, mi.indexErr = resolveIndexes(mi.TableName, ix.Indexes())
+	}
+	for _, f := range core.Fields(t) {
 		if f.PkgPath != "" {
 			continue
 		}
-		db := f.Tag.Get("db")
-		if db == "" {
-			db = toSnakeCase(f.Name)
-		}
+		db := core.ColumnName(f)
 		// Prefer `norm` tag; fallback to legacy `orm`
 		orm := f.Tag.Get("norm")
 		if orm == "" {
@@ -162,10 +161,16 @@ func parseModel(model any) modelInfo {
 					ignored = true
 					break
 				}
+				// relation fields (hasmany:/hasone:/belongsto:) are not columns
+				if k, _, ok := strings.Cut(strings.ToLower(t), ":"); ok && (k == "hasmany" || k == "hasone" || k == "belongsto") {
+					ignored = true
+					break
+				}
 			}
 			if ignored {
 				continue
 			}
+			goType, uuidGen := ft.DBType, false
 			for _, p := range tokens {
 				p = strings.TrimSpace(p)
 				switch {
@@ -195,6 +200,13 @@ func parseModel(model any) modelInfo {
 				case strings.HasPrefix(strings.ToLower(p), "index:"):
 					ft.Index = true
 					ft.IndexName = strings.TrimSpace(p[strings.Index(p, ":")+1:])
+				case strings.HasPrefix(strings.ToLower(p), "index_expr:"):
+					ft.Index = true
+					ft.IndexExpr = strings.TrimSpace(p[strings.Index(p, ":")+1:])
+				case strings.HasPrefix(strings.ToLower(p), "enum:"):
+					ft.EnumType, ft.EnumLabels = parseEnumTag(p[strings.Index(p, ":")+1:])
+				case strings.HasPrefix(strings.ToLower(p), "generated:"):
+					ft.Generated, ft.GeneratedKind = parseGenerated(p[strings.Index(p, ":")+1:])
 				case strings.HasPrefix(strings.ToLower(p), "index_where:"):
 					ft.IndexWhere = strings.TrimSpace(p[strings.Index(p, ":")+1:])
 				case strings.HasPrefix(strings.ToLower(p), "using:") || strings.HasPrefix(strings.ToLower(p), "index_type:"):
@@ -204,10 +216,8 @@ func parseModel(model any) modelInfo {
 				case p == "version":
 					ft.DBType = "BIGINT"
 				case strings.HasPrefix(strings.ToLower(p), "fk:") || strings.HasPrefix(strings.ToLower(p), "references:"):
-					ref := p[strings.Index(p, ":")+1:]
-					if i := strings.Index(ref, "("); i > 0 && strings.HasSuffix(ref, ")") {
-						ft.FKTable = ref[:i]
-						ft.FKColumn = strings.TrimSuffix(ref[i+1:], ")")
+					if table, col := core.ParseFKRef(p[strings.Index(p, ":")+1:]); table != "" {
+						ft.FKTable, ft.FKColumn = table, col
 					}
 				case strings.HasPrefix(strings.ToLower(p), "fk_name:"):
 					ft.FKName = strings.TrimSpace(p[strings.Index(p, ":")+1:])
@@ -225,8 +235,14 @@ func parseModel(model any) modelInfo {
 					ft.Collate = strings.TrimSpace(p[strings.Index(p, ":")+1:])
 				case strings.HasPrefix(strings.ToLower(p), "comment:"):
 					ft.Comment = strings.TrimSpace(p[strings.Index(p, ":")+1:])
+				case strings.EqualFold(p, "jsonb"):
+					ft.DBType = "JSONB"
+				case strings.EqualFold(p, "json"):
+					ft.DBType = "JSON"
 				case strings.HasPrefix(strings.ToLower(p), "type:"):
 					ft.DBType = strings.TrimSpace(p[strings.Index(p, ":")+1:])
+				case strings.HasPrefix(strings.ToLower(p), "uuid:"):
+					uuidGen = true
 				default:
 					// If token looks like a type override e.g. varchar(50), numeric/decimal, citext
 					lp := strings.ToLower(p)
@@ -235,18 +251,57 @@ func parseModel(model any) modelInfo {
 					}
 				}
 			}
+			// client-generated uuids (uuid:v4/v7) get a uuid column with a database default for other writers
+			if uuidGen {
+				if ft.DBType == goType {
+					ft.DBType = "UUID"
+				}
+				if ft.Default == "" {
+					ft.Default = "gen_random_uuid()"
+				}
+			}
 		}
 		mi.Fields = append(mi.Fields, ft)
 	}
+	mi.qualifyEnumTypes()
 	return mi
 }
 
+// pgtypeColumns maps pgx's nullable pgtype wrappers to their column types
+var pgtypeColumns = map[string]string{
+	"Bool":        "BOOLEAN",
+	"Int2":        "SMALLINT",
+	"Int4":        "INTEGER",
+	"Int8":        "BIGINT",
+	"Float4":      "REAL",
+	"Float8":      "DOUBLE PRECISION",
+	"Numeric":     "NUMERIC",
+	"Text":        "TEXT",
+	"UUID":        "UUID",
+	"Date":        "DATE",
+	"Time":        "TIME",
+	"Timestamp":   "TIMESTAMP",
+	"Timestamptz": "TIMESTAMPTZ",
+	"Interval":    "INTERVAL",
+}
+
 func mapGoTypeToPgType(t reflect.Type, ormTag string) string {
 	// strip pointer
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
 	switch t.Kind() {
+	case reflect.Slice:
+		// []T maps to a Postgres array of T's type ([]string -> TEXT[], []int64 -> BIGINT[], []uuid.UUID -> UUID[]);
+		// []byte stays on the scalar fallback
+		if t.Elem().Kind() != reflect.Uint8 {
+			elem := mapGoTypeToPgType(t.Elem(), "")
+			if strings.HasSuffix(elem, "[]") {
+				// Postgres does not distinguish array dimensions in the column type
+				return elem
+			}
+			return elem + "[]"
+		}
 	case reflect.Array:
 		// Map fixed-size 16-byte arrays to UUID
 		if t.Len() == 16 && t.Elem().Kind() == reflect.Uint8 {
@@ -281,6 +336,15 @@ func mapGoTypeToPgType(t reflect.Type, ormTag string) string {
 		if strings.EqualFold(t.Name(), "UUID") && strings.Contains(strings.ToLower(t.PkgPath()), "uuid") {
 			return "UUID"
 		}
+		if t.PkgPath() == "github.com/jackc/pgx/v5/pgtype" {
+			if pg, ok := pgtypeColumns[t.Name()]; ok {
+				return pg
+			}
+		}
+		// decimal types (shopspring/decimal, ericlagergren/decimal, ...) scan from NUMERIC text
+		if t.Name() == "Decimal" && strings.Contains(strings.ToLower(t.PkgPath()), "decimal") {
+			return "NUMERIC"
+		}
 	}
 	return "TEXT"
 }
@@ -2,7 +2,11 @@ package migration
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 func TestMapGoTypeToPgType_MoreBranches(t *testing.T) {
@@ -20,3 +24,130 @@ func TestMapGoTypeToPgType_MoreBranches(t *testing.T) {
 		t.Fatalf("f64 %s", got)
 	}
 }
+
+func TestParseModel_JSONB(t *testing.T) {
+	type doc struct {
+		Meta map[string]any `db:"meta" norm:"jsonb,not_null"`
+	}
+	mi := parseModel(doc{})
+	if mi.Fields[0].DBType != "JSONB" || normalizeType(mi.Fields[0]) != "JSONB" {
+		t.Fatalf("got %s", mi.Fields[0].DBType)
+	}
+}
+
+func TestMapGoTypeToPgType_Arrays(t *testing.T) {
+	if got := mapGoTypeToPgType(reflect.TypeFor[[]string](), ""); got != "TEXT[]" {
+		t.Fatalf("[]string %s", got)
+	}
+	if got := mapGoTypeToPgType(reflect.TypeFor[[]int64](), ""); got != "BIGINT[]" {
+		t.Fatalf("[]int64 %s", got)
+	}
+	if got := mapGoTypeToPgType(reflect.TypeFor[[][16]byte](), ""); got != "UUID[]" {
+		t.Fatalf("[]uuid %s", got)
+	}
+	if got := mapGoTypeToPgType(reflect.TypeFor[pgtype.Numeric](), ""); got != "NUMERIC" {
+		t.Fatalf("pgtype.Numeric %s", got)
+	}
+	if got := mapGoTypeToPgType(reflect.TypeFor[*pgtype.Timestamptz](), ""); got != "TIMESTAMPTZ" {
+		t.Fatalf("pgtype.Timestamptz %s", got)
+	}
+	if got := mapGoTypeToPgType(reflect.TypeFor[[]byte](), ""); got != "TEXT" {
+		t.Fatalf("[]byte %s", got)
+	}
+	if canonicalPgType("_int8", -1) != "BIGINT[]" || canonicalPgType("_uuid", -1) != "UUID[]" {
+		t.Fatalf("canonical arrays")
+	}
+}
+
+func TestParseModel_SkipsRelationFields(t *testing.T) {
+	type post struct {
+		ID int64 `db:"id"`
+	}
+	type author struct {
+		ID    int64   `db:"id" norm:"primary_key"`
+		Posts []*post `norm:"hasmany:posts,fk:author_id"`
+	}
+	if mi := parseModel(author{}); len(mi.Fields) != 1 {
+		t.Fatalf("fields=%+v", mi.Fields)
+	}
+}
+
+func TestParseModel_FlattensEmbeddedStructs(t *testing.T) {
+	type Base struct {
+		ID        int64     `db:"id" norm:"primary_key"`
+		CreatedAt time.Time `db:"created_at"`
+	}
+	type invoice struct {
+		Base
+		Total int64 `db:"total"`
+	}
+	mi := parseModel(invoice{})
+	var cols []string
+	for _, f := range mi.Fields {
+		cols = append(cols, f.DBName)
+	}
+	if !reflect.DeepEqual(cols, []string{"id", "created_at", "total"}) || !mi.Fields[0].PrimaryKey {
+		t.Fatalf("fields=%+v", mi.Fields)
+	}
+}
+
+func TestParseModel_TableTag(t *testing.T) {
+	type Person struct {
+		_  struct{} `norm:"table:people"`
+		ID int64    `db:"id" norm:"primary_key"`
+	}
+	mi := parseModel(Person{})
+	if mi.TableName != "people" || len(mi.Fields) != 1 {
+		t.Fatalf("mi=%+v", mi)
+	}
+}
+
+type qualifiedInvoice struct {
+	ID        int64 `db:"id" norm:"primary_key"`
+	AccountID int64 `db:"account_id" norm:"index,fk:billing.accounts(id)"`
+}
+
+func (qualifiedInvoice) TableName() string { return "billing.invoices" }
+
+func TestParseModel_Schema(t *testing.T) {
+	type Account struct {
+		_  struct{} `norm:"schema:billing"`
+		ID int64    `db:"id" norm:"primary_key"`
+	}
+	mi := parseModel(Account{})
+	if mi.Schema != "billing" || mi.TableName != "accounts" || mi.quotedTable() != `"billing"."accounts"` {
+		t.Fatalf("mi=%+v", mi)
+	}
+	inv := parseModel(qualifiedInvoice{})
+	if inv.Schema != "billing" || inv.TableName != "invoices" {
+		t.Fatalf("mi=%+v", inv)
+	}
+	sqls := generateCreateTableSQL(inv).Statements
+	if sqls[0] != `CREATE TABLE IF NOT EXISTS "billing"."invoices" ("id" BIGINT, "account_id" BIGINT, PRIMARY KEY ("id"))` {
+		t.Fatalf("create=%s", sqls[0])
+	}
+	joined := strings.Join(sqls, ";")
+	if !strings.Contains(joined, `CREATE INDEX IF NOT EXISTS "idx_invoices_account_id" ON "billing"."invoices"("account_id")`) ||
+		!strings.Contains(joined, `REFERENCES "billing"."accounts"("id")`) {
+		t.Fatalf("stmts=%v", sqls)
+	}
+	if _, creates := (&Migrator{}).planSchemas([]any{Account{}, qualifiedInvoice{}}); !reflect.DeepEqual(creates, []string{`CREATE SCHEMA IF NOT EXISTS "billing"`}) {
+		t.Fatalf("creates=%v", creates)
+	}
+}
+
+func TestMigrator_InSchema(t *testing.T) {
+	type Tag struct {
+		ID     int64 `db:"id" norm:"primary_key"`
+		PostID int64 `db:"post_id" norm:"fk:posts(id)"`
+	}
+	m := (&Migrator{}).InSchema("tenant_acme")
+	mi := m.parseModel(Tag{})
+	if mi.quotedTable() != `"tenant_acme"."tags"` || mi.Fields[1].FKTable != "tenant_acme.posts" {
+		t.Fatalf("mi=%+v", mi)
+	}
+	schemas, creates := m.planSchemas([]any{Tag{}})
+	if !reflect.DeepEqual(schemas, []string{"tenant_acme"}) || !reflect.DeepEqual(creates, []string{`CREATE SCHEMA IF NOT EXISTS "tenant_acme"`}) {
+		t.Fatalf("schemas=%v creates=%v", schemas, creates)
+	}
+}
@@ -0,0 +1,197 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var partitionSpecRe = regexp.MustCompile(`^(?i)(range|list|hash)\s*\((.+)\)$`)
+
+// parsePartitionBy renders a partition_by tag value like "range(created_at)" as PARTITION BY's argument,
+// RANGE ("created_at"). Plain column names are quoted; expressions are kept as written.
+func parsePartitionBy(spec string) (string, error) {
+	m := partitionSpecRe.FindStringSubmatch(strings.TrimSpace(spec))
+	if m == nil {
+		return "", fmt.Errorf("invalid partition_by %q: want range(cols), list(cols) or hash(cols)", spec)
+	}
+	keys := splitTagTokens(m[2])
+	for i, k := range keys {
+		if identRe.MatchString(k) {
+			keys[i] = quoteIdent(k)
+		}
+	}
+	return strings.ToUpper(m[1]) + " (" + strings.Join(keys, ", ") + ")", nil
+}
+
+var identRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// partitionColumns lists the plain columns of a rendered partition key
+func partitionColumns(partitionBy string) []string {
+	_, keys, _ := strings.Cut(strings.TrimSuffix(partitionBy, ")"), "(")
+	var cols []string
+	for _, k := range splitTagTokens(keys) {
+		if strings.HasPrefix(k, `"`) && strings.HasSuffix(k, `"`) {
+			cols = append(cols, strings.Trim(k, `"`))
+		}
+	}
+	return cols
+}
+
+// normalizePartitionKey compares partition keys as Postgres prints them (pg_get_partkeydef)
+func normalizePartitionKey(def string) string {
+	return strings.NewReplacer(" ", "", `"`, "").Replace(strings.ToLower(def))
+}
+
+// planPartitioning warns about partitioning the migrator cannot change in place: a model declaring
+// partition_by for an existing table partitioned differently (or not at all), and primary keys that miss
+// a partition column, which Postgres rejects on partitioned tables.
+func (plan *PlanResult) planPartitioning(mi modelInfo, exists bool, haveKey string) {
+	if mi.PartitionBy == "" {
+		if exists && haveKey != "" {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("table %s is partitioned by %s but the model declares no partition_by", mi.key(), haveKey))
+		}
+		return
+	}
+	if exists {
+		if normalizePartitionKey(haveKey) != normalizePartitionKey(mi.PartitionBy) {
+			have := haveKey
+			if have == "" {
+				have = "nothing"
+			}
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("table %s is partitioned by %s, model declares %s; recreate the table and copy the data to change it", mi.key(), have, mi.PartitionBy))
+		}
+		return
+	}
+	var pk []string
+	for _, f := range mi.Fields {
+		if f.PrimaryKey {
+			pk = append(pk, f.DBName)
+		}
+	}
+	for _, c := range partitionColumns(mi.PartitionBy) {
+		if len(pk) > 0 && !slices.Contains(pk, c) {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("primary key of partitioned table %s must include partition column %s", mi.key(), c))
+		}
+	}
+}
+
+// PartitionBound is the bound clause of a partition (FOR VALUES ... or DEFAULT)
+type PartitionBound string
+
+// RangeBound covers [from, to) of a range-partitioned table; values are SQL literals' text, e.g. "2026-01-01"
+func RangeBound(from, to string) PartitionBound {
+	return PartitionBound(fmt.Sprintf("FOR VALUES FROM (%s) TO (%s)", quoteLiteral(from), quoteLiteral(to)))
+}
+
+// ListBound holds the given values of a list-partitioned table
+func ListBound(values ...string) PartitionBound {
+	lits := make([]string, len(values))
+	for i, v := range values {
+		lits[i] = quoteLiteral(v)
+	}
+	return PartitionBound("FOR VALUES IN (" + strings.Join(lits, ", ") + ")")
+}
+
+// HashBound holds the rows whose key hash modulo modulus is remainder
+func HashBound(modulus, remainder int) PartitionBound {
+	return PartitionBound(fmt.Sprintf("FOR VALUES WITH (MODULUS %d, REMAINDER %d)", modulus, remainder))
+}
+
+// DefaultBound receives the rows no other partition accepts
+const DefaultBound PartitionBound = "DEFAULT"
+
+// partitionTable places a partition name next to the model's table (same schema unless qualified)
+func (mi modelInfo) partitionTable(name string) string {
+	if strings.Contains(name, ".") {
+		return quoteQualifiedIdent(name)
+	}
+	return quoteQualifiedIdent(tableKey(mi.Schema, name))
+}
+
+func (m *Migrator) partitionParent(model any) (modelInfo, error) {
+	mi := m.parseModel(model)
+	if mi.partitionErr != nil {
+		return mi, mi.partitionErr
+	}
+	if mi.PartitionBy == "" {
+		return mi, fmt.Errorf("table %s declares no partition_by", mi.key())
+	}
+	return mi, nil
+}
+
+// CreatePartition creates partition of model's partitioned table with bound, if it does not exist yet
+//
+//	mig.CreatePartition(ctx, &Event{}, "events_2026_01", migration.RangeBound("2026-01-01", "2026-02-01"))
+func (m *Migrator) CreatePartition(ctx context.Context, model any, partition string, bound PartitionBound) error {
+	mi, err := m.partitionParent(model)
+	if err != nil {
+		return err
+	}
+	_, err = m.pool.Exec(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s PARTITION OF %s %s", mi.partitionTable(partition), mi.quotedTable(), bound))
+	return err
+}
+
+// AttachPartition attaches an existing table as a partition of model's table. Postgres scans the table to
+// validate bound unless a matching CHECK constraint already proves it.
+func (m *Migrator) AttachPartition(ctx context.Context, model any, table string, bound PartitionBound) error {
+	mi, err := m.partitionParent(model)
+	if err != nil {
+		return err
+	}
+	_, err = m.pool.Exec(ctx, fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s %s", mi.quotedTable(), mi.partitionTable(table), bound))
+	return err
+}
+
+// DetachPartition detaches a partition from model's table, keeping it as a standalone table. concurrently
+// uses DETACH ... CONCURRENTLY (PostgreSQL 14+), which does not block queries on the parent but cannot run
+// inside a transaction.
+func (m *Migrator) DetachPartition(ctx context.Context, model any, table string, concurrently bool) error {
+	mi, err := m.partitionParent(model)
+	if err != nil {
+		return err
+	}
+	stmt := fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s", mi.quotedTable(), mi.partitionTable(table))
+	if concurrently {
+		stmt += " CONCURRENTLY"
+	}
+	_, err = m.pool.Exec(ctx, stmt)
+	return err
+}
+
+// monthlyPartitions returns the names and bounds of months monthly partitions starting at from's month (UTC),
+// named <table>_YYYY_MM
+func monthlyPartitions(table string, from time.Time, months int) ([]string, []PartitionBound) {
+	start := time.Date(from.UTC().Year(), from.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	names := make([]string, 0, months)
+	bounds := make([]PartitionBound, 0, months)
+	for i := range months {
+		lo, hi := start.AddDate(0, i, 0), start.AddDate(0, i+1, 0)
+		names = append(names, fmt.Sprintf("%s_%s", table, lo.Format("2006_01")))
+		bounds = append(bounds, RangeBound(lo.Format(time.RFC3339), hi.Format(time.RFC3339)))
+	}
+	return names, bounds
+}
+
+// EnsureMonthlyPartitions creates the monthly partitions of a range-partitioned model for months months
+// starting at from's month, skipping existing ones, and returns their names. Run it ahead of time (e.g. from
+// a daily job) so inserts never lack a partition.
+func (m *Migrator) EnsureMonthlyPartitions(ctx context.Context, model any, from time.Time, months int) ([]string, error) {
+	mi, err := m.partitionParent(model)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(mi.PartitionBy, "RANGE") {
+		return nil, fmt.Errorf("table %s is not range partitioned", mi.key())
+	}
+	names, bounds := monthlyPartitions(mi.TableName, from, months)
+	for i, name := range names {
+		if err := m.CreatePartition(ctx, model, name, bounds[i]); err != nil {
+			return names[:i], err
+		}
+	}
+	return names, nil
+}
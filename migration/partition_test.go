@@ -0,0 +1,71 @@
+package migration
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+type partEvent struct {
+	_         struct{}  `norm:"partition_by:range(created_at)"`
+	ID        int64     `db:"id" norm:"primary_key:pk"`
+	CreatedAt time.Time `db:"created_at" norm:"primary_key:pk"`
+}
+
+type partShard struct {
+	_        struct{} `norm:"partition_by:hash(tenant_id, lower(region))"`
+	ID       int64    `db:"id" norm:"primary_key"`
+	TenantID int64    `db:"tenant_id"`
+	Region   string   `db:"region"`
+}
+
+func TestParsePartitionBy(t *testing.T) {
+	if got := parseModel(partShard{}).PartitionBy; got != `HASH ("tenant_id", lower(region))` {
+		t.Fatalf("got %q", got)
+	}
+	if _, err := parsePartitionBy("interval(created_at)"); err == nil {
+		t.Fatal("expected error")
+	}
+	if partitionColumns(`HASH ("tenant_id", lower(region))`)[0] != "tenant_id" {
+		t.Fatal("partition columns")
+	}
+}
+
+func TestGenerateCreateTableSQL_PartitionBy(t *testing.T) {
+	create := generateCreateTableSQL(parseModel(partEvent{})).Statements[0]
+	if !strings.HasSuffix(create, `) PARTITION BY RANGE ("created_at")`) {
+		t.Fatalf("got %s", create)
+	}
+}
+
+func TestPlanPartitioning(t *testing.T) {
+	var plan PlanResult
+	plan.planPartitioning(parseModel(partShard{}), false, "")
+	if len(plan.Warnings) != 1 || !strings.Contains(plan.Warnings[0], "partition column tenant_id") {
+		t.Fatalf("pk warning: %v", plan.Warnings)
+	}
+	plan = PlanResult{}
+	mi := parseModel(partEvent{})
+	plan.planPartitioning(mi, true, "RANGE (created_at)")
+	if len(plan.Warnings) != 0 {
+		t.Fatalf("same key warned: %v", plan.Warnings)
+	}
+	plan.planPartitioning(mi, true, "")
+	if len(plan.Warnings) != 1 || !strings.Contains(plan.Warnings[0], "partitioned by nothing") {
+		t.Fatalf("unpartitioned: %v", plan.Warnings)
+	}
+}
+
+func TestMonthlyPartitions(t *testing.T) {
+	names, bounds := monthlyPartitions("events", time.Date(2026, 12, 15, 0, 0, 0, 0, time.UTC), 2)
+	if !slices.Equal(names, []string{"events_2026_12", "events_2027_01"}) {
+		t.Fatalf("names %v", names)
+	}
+	if bounds[1] != "FOR VALUES FROM ('2027-01-01T00:00:00Z') TO ('2027-02-01T00:00:00Z')" {
+		t.Fatalf("bound %s", bounds[1])
+	}
+	if ListBound("eu", "o'k") != "FOR VALUES IN ('eu', 'o''k')" || HashBound(4, 1) != "FOR VALUES WITH (MODULUS 4, REMAINDER 1)" {
+		t.Fatal("bounds")
+	}
+}
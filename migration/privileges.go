@@ -0,0 +1,139 @@
+package migration
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Privileges lists the columns a role may use per privilege; "*" grants the privilege on the whole table
+type Privileges struct {
+	Select []string
+	Insert []string
+	Update []string
+	Delete bool
+}
+
+// Privileger can be implemented by a model to declare what database roles may do with its table.
+// AutoMigrate reconciles the returned roles with GRANT / REVOKE; roles not returned are left untouched.
+//
+//	func (Account) Privileges() map[string]migration.Privileges {
+//		return map[string]migration.Privileges{
+//			"app_reader": {Select: []string{"id", "name"}},
+//			"app_writer": {Select: []string{"*"}, Update: []string{"name"}},
+//		}
+//	}
+type Privileger interface {
+	Privileges() map[string]Privileges
+}
+
+// grantSet maps role -> privilege -> granted columns ("*" for a table-level grant)
+type grantSet map[string]map[string]map[string]struct{}
+
+func (g grantSet) add(role, priv, col string) {
+	if g[role] == nil {
+		g[role] = map[string]map[string]struct{}{}
+	}
+	if g[role][priv] == nil {
+		g[role][priv] = map[string]struct{}{}
+	}
+	g[role][priv][col] = struct{}{}
+}
+
+var privilegeOrder = []string{"SELECT", "INSERT", "UPDATE", "DELETE"}
+
+// wantColumns returns the desired columns of one privilege, collapsing to {"*"} for a table-level grant
+func (p Privileges) wantColumns(priv string) []string {
+	var cols []string
+	switch priv {
+	case "SELECT":
+		cols = p.Select
+	case "INSERT":
+		cols = p.Insert
+	case "UPDATE":
+		cols = p.Update
+	case "DELETE":
+		if p.Delete {
+			return []string{"*"}
+		}
+		return nil
+	}
+	out := make([]string, 0, len(cols))
+	for _, c := range cols {
+		c = strings.ToLower(strings.TrimSpace(c))
+		if c == "*" {
+			return []string{"*"}
+		}
+		if c != "" && !slices.Contains(out, c) {
+			out = append(out, c)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+// privilegeStatements returns the GRANT / REVOKE statements turning have into the model's declared privileges
+func privilegeStatements(mi modelInfo, have grantSet) []string {
+	table := mi.quotedTable()
+	var stmts []string
+	colList := func(cols []string) string {
+		q := make([]string, len(cols))
+		for i, c := range cols {
+			q[i] = quoteIdent(c)
+		}
+		return " (" + strings.Join(q, ", ") + ")"
+	}
+	roles := make([]string, 0, len(mi.Privileges))
+	for r := range mi.Privileges {
+		roles = append(roles, r)
+	}
+	slices.Sort(roles)
+	for _, role := range roles {
+		spec := mi.Privileges[role]
+		qrole := quoteIdent(role)
+		for _, priv := range privilegeOrder {
+			want := spec.wantColumns(priv)
+			cur := have[role][priv]
+			_, curTable := cur["*"]
+			var curCols []string
+			for c := range cur {
+				if c != "*" {
+					curCols = append(curCols, c)
+				}
+			}
+			slices.Sort(curCols)
+			if slices.Equal(want, []string{"*"}) {
+				if !curTable {
+					stmts = append(stmts, fmt.Sprintf("GRANT %s ON %s TO %s", priv, table, qrole))
+				}
+				if len(curCols) > 0 {
+					stmts = append(stmts, fmt.Sprintf("REVOKE %s%s ON %s FROM %s", priv, colList(curCols), table, qrole))
+				}
+				continue
+			}
+			if curTable {
+				// revoking the table-level privilege also revokes its column-level grants
+				stmts = append(stmts, fmt.Sprintf("REVOKE %s ON %s FROM %s", priv, table, qrole))
+				curCols = nil
+			}
+			var grant, revoke []string
+			for _, c := range want {
+				if !slices.Contains(curCols, c) {
+					grant = append(grant, c)
+				}
+			}
+			for _, c := range curCols {
+				if !slices.Contains(want, c) {
+					revoke = append(revoke, c)
+				}
+			}
+			if len(revoke) > 0 {
+				stmts = append(stmts, fmt.Sprintf("REVOKE %s%s ON %s FROM %s", priv, colList(revoke), table, qrole))
+			}
+			if len(grant) > 0 {
+				stmts = append(stmts, fmt.Sprintf("GRANT %s%s ON %s TO %s", priv, colList(grant), table, qrole))
+			}
+		}
+	}
+	return stmts
+}
@@ -0,0 +1,61 @@
+package migration
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+type grantAccount struct {
+	ID   int64  `db:"id" norm:"primary_key"`
+	Name string `db:"name"`
+	Note string `db:"note"`
+}
+
+func (grantAccount) Privileges() map[string]Privileges {
+	return map[string]Privileges{
+		"reader": {Select: []string{"id", "name"}},
+		"writer": {Select: []string{"*"}, Update: []string{"Name"}, Delete: true},
+	}
+}
+
+func TestPrivilegeStatements_NewTable(t *testing.T) {
+	got := privilegeStatements(parseModel(&grantAccount{}), nil)
+	want := []string{
+		`GRANT SELECT ("id", "name") ON "grant_accounts" TO "reader"`,
+		`GRANT SELECT ON "grant_accounts" TO "writer"`,
+		`GRANT UPDATE ("name") ON "grant_accounts" TO "writer"`,
+		`GRANT DELETE ON "grant_accounts" TO "writer"`,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestPrivilegeStatements_Reconcile(t *testing.T) {
+	have := grantSet{}
+	have.add("reader", "SELECT", "*")
+	have.add("writer", "SELECT", "note")
+	have.add("writer", "SELECT", "*")
+	have.add("writer", "UPDATE", "note")
+	have.add("writer", "UPDATE", "name")
+	have.add("writer", "DELETE", "*")
+	have.add("other", "SELECT", "*")
+	got := privilegeStatements(parseModel(grantAccount{}), have)
+	want := []string{
+		`REVOKE SELECT ON "grant_accounts" FROM "reader"`,
+		`GRANT SELECT ("id", "name") ON "grant_accounts" TO "reader"`,
+		`REVOKE SELECT ("note") ON "grant_accounts" FROM "writer"`,
+		`REVOKE UPDATE ("note") ON "grant_accounts" FROM "writer"`,
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("got:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestStatementTable_GrantsAreDeferred(t *testing.T) {
+	_, deferred := groupStatementsByTable([]string{`GRANT SELECT ON "grant_accounts" TO "reader"`})
+	if len(deferred) != 1 {
+		t.Fatalf("grant should be applied after table groups")
+	}
+}
@@ -0,0 +1,99 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+)
+
+// RenameStrategy selects how Plan migrates rename: column tags
+type RenameStrategy int
+
+const (
+	// RenameInPlace renames the column with ALTER TABLE ... RENAME COLUMN (the default). Instances still
+	// running the previous version fail on the old name until they are replaced.
+	RenameInPlace RenameStrategy = iota
+	// RenameExpandContract keeps both columns during a rolling deploy: the expand phase adds the new
+	// column, a trigger keeps the two in sync and a backfill copies existing rows; the contract phase
+	// (ApplyOptions.AllowContract) drops the trigger and the old column once no instance uses it.
+	RenameExpandContract
+)
+
+// SetRenameStrategy sets how rename: tags are planned
+func (m *Migrator) SetRenameStrategy(s RenameStrategy) { m.renames = s }
+
+// planExpandRename plans the expand/contract phases renaming f.RenameFrom to f.DBName on mi, given
+// whether the new column already exists (an earlier expand)
+func (plan *PlanResult) planExpandRename(mi modelInfo, f fieldTag, newExists bool) {
+	table := mi.quotedTable()
+	oldCol, newCol := quoteIdent(f.RenameFrom), quoteIdent(f.DBName)
+	fn := quoteQualifiedIdent(tableKey(mi.Schema, "norm_sync_"+mi.TableName+"_"+f.DBName))
+	trigger := quoteIdent("norm_sync_" + f.DBName)
+	tk := mi.key()
+	if !newExists {
+		// no DEFAULT yet: existing rows must stay NULL for the backfill, and inserts by old instances
+		// must not get the default over the value they wrote to the old column
+		add := "ALTER TABLE " + table + " ADD COLUMN IF NOT EXISTS " + newCol + " " + normalizeType(f)
+		if f.Collate != "" {
+			add += " COLLATE " + f.Collate
+		}
+		plan.Statements = append(plan.Statements,
+			add,
+			fmt.Sprintf(`CREATE OR REPLACE FUNCTION %s() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+	IF TG_OP = 'INSERT' THEN
+		IF NEW.%[2]s IS NULL THEN NEW.%[2]s := NEW.%[3]s; ELSE NEW.%[3]s := NEW.%[2]s; END IF;
+	ELSIF NEW.%[2]s IS DISTINCT FROM OLD.%[2]s THEN
+		NEW.%[3]s := NEW.%[2]s;
+	ELSE
+		NEW.%[2]s := NEW.%[3]s;
+	END IF;
+	RETURN NEW;
+END $$`, fn, newCol, oldCol),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", trigger, table),
+			fmt.Sprintf("CREATE TRIGGER %s BEFORE INSERT OR UPDATE ON %s FOR EACH ROW EXECUTE FUNCTION %s()", trigger, table, fn),
+		)
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("expanding rename of %s.%s to %s; contract once no instance uses %s", tk, f.RenameFrom, f.DBName, f.RenameFrom))
+	} else {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("rename of %s.%s to %s awaits contract (ApplyOptions.AllowContract)", tk, f.RenameFrom, f.DBName))
+	}
+	plan.Backfills = append(plan.Backfills, BackfillSpec{Table: tk, SetExpr: newCol + " = " + oldCol, Where: newCol + " IS NULL AND " + oldCol + " IS NOT NULL"})
+	plan.ContractStatements = append(plan.ContractStatements,
+		fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", trigger, table),
+		fmt.Sprintf("DROP FUNCTION IF EXISTS %s()", fn),
+	)
+	if f.Default != "" {
+		plan.ContractStatements = append(plan.ContractStatements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET DEFAULT %s", table, newCol, f.Default))
+	}
+	if f.NotNull || f.PrimaryKey {
+		plan.ContractStatements = append(plan.ContractStatements, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s SET NOT NULL", table, newCol))
+	}
+	plan.ContractStatements = append(plan.ContractStatements, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, oldCol))
+}
+
+// applyRenamePhases runs the backfills of plan in batches after its migration transaction committed, so
+// no long transaction holds the table, then the contract phase when opts allow it
+func (m *Migrator) applyRenamePhases(ctx context.Context, opts ApplyOptions, plan PlanResult) error {
+	for _, spec := range plan.Backfills {
+		spec.BatchSize, spec.Sleep, spec.Progress = opts.BackfillBatchSize, opts.BackfillSleep, opts.BackfillProgress
+		if _, err := Backfill(ctx, m.pool, spec); err != nil {
+			return err
+		}
+	}
+	if !opts.AllowContract || len(plan.ContractStatements) == 0 {
+		return nil
+	}
+	tx, err := m.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('github.com/kintsdev/norm-migrate'))`); err != nil {
+		return err
+	}
+	for _, s := range plan.ContractStatements {
+		if _, err := tx.Exec(ctx, s); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,75 @@
+package migration
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+type renamedUser struct {
+	ID       int64  `db:"id" norm:"primary_key"`
+	FullName string `db:"full_name" norm:"not_null,rename:name"`
+}
+
+func (renamedUser) TableName() string { return "snap_users" }
+
+func TestPlanExpandContractRename(t *testing.T) {
+	snap := snapshotOf(snapUser{})
+	snap.Tables[0].Columns = []SnapshotColumn{{Name: "id", DataType: "bigint"}, {Name: "name", DataType: "text"}}
+	m := NewMigrator(nil)
+	m.SetRenameStrategy(RenameExpandContract)
+
+	plan, err := m.PlanFromSnapshot(snap, &renamedUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.ContainsFunc(plan.Statements, func(s string) bool { return strings.Contains(s, "RENAME COLUMN") }) {
+		t.Fatalf("in-place rename planned: %v", plan.Statements)
+	}
+	expand := plan.Statements[1:] // after schema_migrations
+	if len(expand) < 4 || expand[0] != `ALTER TABLE "snap_users" ADD COLUMN IF NOT EXISTS "full_name" TEXT` ||
+		!strings.HasPrefix(expand[1], `CREATE OR REPLACE FUNCTION "norm_sync_snap_users_full_name"()`) ||
+		expand[3] != `CREATE TRIGGER "norm_sync_full_name" BEFORE INSERT OR UPDATE ON "snap_users" FOR EACH ROW EXECUTE FUNCTION "norm_sync_snap_users_full_name"()` {
+		t.Fatalf("expand: %v", expand)
+	}
+	if len(plan.Backfills) != 1 || plan.Backfills[0].Statement() != `UPDATE "snap_users" SET "full_name" = "name" WHERE ctid IN (SELECT ctid FROM "snap_users" WHERE "full_name" IS NULL AND "name" IS NOT NULL LIMIT 1000)` {
+		t.Fatalf("backfills: %v", plan.Backfills)
+	}
+	if len(plan.UnsafeStatements) != 0 || len(plan.DestructiveStatements) != 0 {
+		t.Fatalf("unsafe %v destructive %v", plan.UnsafeStatements, plan.DestructiveStatements)
+	}
+	wantContract := []string{
+		`DROP TRIGGER IF EXISTS "norm_sync_full_name" ON "snap_users"`,
+		`DROP FUNCTION IF EXISTS "norm_sync_snap_users_full_name"()`,
+		`ALTER TABLE "snap_users" ALTER COLUMN "full_name" SET NOT NULL`,
+		`ALTER TABLE "snap_users" DROP COLUMN "name"`,
+	}
+	if !slices.Equal(plan.ContractStatements, wantContract) {
+		t.Fatalf("contract: %v", plan.ContractStatements)
+	}
+
+	// next deploy: both columns exist, only the backfill and contract remain
+	snap.Tables[0].Columns = append(snap.Tables[0].Columns, SnapshotColumn{Name: "full_name", DataType: "text", Nullable: true})
+	plan, err = m.PlanFromSnapshot(snap, &renamedUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.ContainsFunc(plan.Statements, func(s string) bool { return strings.Contains(s, "full_name") && !strings.HasPrefix(s, "COMMENT ON") }) {
+		t.Fatalf("statements: %v", plan.Statements)
+	}
+	if !slices.Equal(plan.ContractStatements, wantContract) || len(plan.UnsafeStatements) != 0 {
+		t.Fatalf("contract %v unsafe %v", plan.ContractStatements, plan.UnsafeStatements)
+	}
+}
+
+func TestPlanInPlaceRenameByDefault(t *testing.T) {
+	snap := snapshotOf(snapUser{})
+	snap.Tables[0].Columns = []SnapshotColumn{{Name: "id", DataType: "bigint"}, {Name: "name", DataType: "text"}}
+	plan, err := NewMigrator(nil).PlanFromSnapshot(snap, &renamedUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(plan.Statements, `ALTER TABLE "snap_users" RENAME COLUMN "name" TO "full_name"`) || len(plan.ContractStatements) != 0 {
+		t.Fatalf("statements %v contract %v", plan.Statements, plan.ContractStatements)
+	}
+}
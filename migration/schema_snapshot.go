@@ -0,0 +1,406 @@
+package migration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// snapshotVersion is the format version written to snapshot files
+const snapshotVersion = 1
+
+// SchemaSnapshot is the part of a database schema the migrator diffs models against. It serializes to JSON,
+// so a snapshot taken from production can be committed and planned against offline (see PlanFromSnapshot).
+type SchemaSnapshot struct {
+	Version   int                `json:"version"`
+	TakenAt   time.Time          `json:"taken_at"`
+	Schemas   []string           `json:"schemas"`
+	Tables    []SnapshotTable    `json:"tables"`
+	EnumTypes []SnapshotEnumType `json:"enum_types,omitempty"`
+	// ServerVersion is server_version_num (e.g. 160002); Extensions are the installed extensions, sorted
+	ServerVersion int      `json:"server_version,omitempty"`
+	Extensions    []string `json:"extensions,omitempty"`
+}
+
+// SnapshotTable is a table (or view) with its columns, indexes, constraints and grants
+type SnapshotTable struct {
+	Schema       string               `json:"schema"`
+	Name         string               `json:"name"`
+	Columns      []SnapshotColumn     `json:"columns"`
+	Indexes      []SnapshotIndex      `json:"indexes,omitempty"`
+	Constraints  []SnapshotConstraint `json:"constraints,omitempty"`
+	Grants       []SnapshotGrant      `json:"grants,omitempty"`
+	PartitionKey string               `json:"partition_key,omitempty"` // pg_get_partkeydef of a partitioned table
+	PartitionOf  bool                 `json:"partition_of,omitempty"`  // the table is a partition of another table
+}
+
+// SnapshotColumn is a column; DataType is canonical (e.g. varchar(255), int4[]), Generated the generation expression
+type SnapshotColumn struct {
+	Name      string `json:"name"`
+	DataType  string `json:"data_type"`
+	Nullable  bool   `json:"nullable"`
+	Collation string `json:"collation,omitempty"`
+	Generated string `json:"generated,omitempty"`
+}
+
+// SnapshotIndex is an index with its pg_indexes definition
+type SnapshotIndex struct {
+	Name string `json:"name"`
+	Def  string `json:"def"`
+}
+
+// SnapshotConstraint is a primary key (p), unique (u), check (c) or foreign key (f) constraint
+type SnapshotConstraint struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Def  string `json:"def"`
+}
+
+// SnapshotGrant is a privilege of role on the table, or on one column of it
+type SnapshotGrant struct {
+	Role      string `json:"role"`
+	Privilege string `json:"privilege"`
+	Column    string `json:"column,omitempty"`
+}
+
+// SnapshotEnumType is an enum type with its labels in sort order
+type SnapshotEnumType struct {
+	Schema string   `json:"schema"`
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+}
+
+// Snapshot captures every user schema (or only the InSchema schema) of the database
+func (m *Migrator) Snapshot(ctx context.Context) (*SchemaSnapshot, error) {
+	schemas := []string{m.schema}
+	if m.schema == "" {
+		rows, err := m.pool.Query(ctx, `SELECT nspname FROM pg_namespace WHERE nspname <> 'information_schema' AND nspname NOT LIKE 'pg\_%' ORDER BY nspname`)
+		if err != nil {
+			return nil, err
+		}
+		schemas = schemas[:0]
+		for rows.Next() {
+			var s string
+			if err := rows.Scan(&s); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			schemas = append(schemas, s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return loadSnapshot(ctx, m.pool, schemas)
+}
+
+// WriteFile stores the snapshot as indented JSON
+func (s *SchemaSnapshot) WriteFile(path string) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+// ReadSnapshotFile loads a snapshot written by WriteFile
+func ReadSnapshotFile(path string) (*SchemaSnapshot, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s SchemaSnapshot
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if s.Version > snapshotVersion {
+		return nil, fmt.Errorf("%s: snapshot version %d is newer than supported version %d", path, s.Version, snapshotVersion)
+	}
+	return &s, nil
+}
+
+// loadSnapshot reads the catalog of schemas; tables and their parts come out sorted, so snapshots of an
+// unchanged schema differ only in TakenAt
+func loadSnapshot(ctx context.Context, pool *pgxpool.Pool, schemas []string) (*SchemaSnapshot, error) {
+	snap := &SchemaSnapshot{Version: snapshotVersion, TakenAt: time.Now().UTC(), Schemas: schemas}
+	byKey := map[string]int{}
+	table := func(schema, name string) *SnapshotTable {
+		k := tableKey(schema, name)
+		i, ok := byKey[k]
+		if !ok {
+			i = len(snap.Tables)
+			byKey[k] = i
+			snap.Tables = append(snap.Tables, SnapshotTable{Schema: schema, Name: name})
+		}
+		return &snap.Tables[i]
+	}
+	scan := func(query string, fn func(rows interface{ Scan(...any) error }) error) error {
+		rows, err := pool.Query(ctx, query, schemas)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			if err := fn(rows); err != nil {
+				return err
+			}
+		}
+		return rows.Err()
+	}
+
+	// columns with canonical types and nullability
+	err := scan(`
+        SELECT table_schema, table_name, column_name, CASE WHEN data_type IN ('ARRAY', 'USER-DEFINED') THEN udt_name ELSE data_type END, is_nullable, COALESCE(character_maximum_length, -1), COALESCE(collation_name, ''), COALESCE(generation_expression, '')
+        FROM information_schema.columns
+        WHERE table_schema = ANY($1)
+        ORDER BY table_schema, table_name, ordinal_position`, func(rows interface{ Scan(...any) error }) error {
+		var sn, tn, cn, dt, nn, coll, gen string
+		var charLen int32
+		if err := rows.Scan(&sn, &tn, &cn, &dt, &nn, &charLen, &coll, &gen); err != nil {
+			return err
+		}
+		t := table(sn, tn)
+		t.Columns = append(t.Columns, SnapshotColumn{Name: cn, DataType: canonicalPgType(dt, charLen), Nullable: strings.EqualFold(nn, "YES"), Collation: coll, Generated: gen})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scan(`
+        SELECT n.nspname, r.relname, c.conname, c.contype::text, pg_get_constraintdef(c.oid)
+        FROM pg_constraint c
+        JOIN pg_class r ON r.oid = c.conrelid
+        JOIN pg_namespace n ON n.oid = r.relnamespace
+        WHERE n.nspname = ANY($1) AND c.contype IN ('f','p','u','c')
+        ORDER BY n.nspname, r.relname, c.conname`, func(rows interface{ Scan(...any) error }) error {
+		var schema, tbl string
+		var c SnapshotConstraint
+		if err := rows.Scan(&schema, &tbl, &c.Name, &c.Type, &c.Def); err != nil {
+			return err
+		}
+		t := table(schema, tbl)
+		t.Constraints = append(t.Constraints, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scan(`SELECT schemaname, tablename, indexname, indexdef FROM pg_indexes WHERE schemaname = ANY($1) ORDER BY schemaname, tablename, indexname`, func(rows interface{ Scan(...any) error }) error {
+		var schema, tbl string
+		var idx SnapshotIndex
+		if err := rows.Scan(&schema, &tbl, &idx.Name, &idx.Def); err != nil {
+			return err
+		}
+		t := table(schema, tbl)
+		t.Indexes = append(t.Indexes, idx)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scan(`
+        SELECT n.nspname, c.relname, c.relkind = 'p', c.relispartition, CASE WHEN c.relkind = 'p' THEN pg_get_partkeydef(c.oid) ELSE '' END
+        FROM pg_class c
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        WHERE n.nspname = ANY($1) AND (c.relkind = 'p' OR c.relispartition)`, func(rows interface{ Scan(...any) error }) error {
+		var schema, name, def string
+		var parent, partition bool
+		if err := rows.Scan(&schema, &name, &parent, &partition, &def); err != nil {
+			return err
+		}
+		t := table(schema, name)
+		// a sub-partitioned partition is both
+		if parent {
+			t.PartitionKey = def
+		}
+		t.PartitionOf = partition
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scan(`
+        SELECT n.nspname, c.relname, '', r.rolname, a.privilege_type
+        FROM pg_class c
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        CROSS JOIN LATERAL aclexplode(c.relacl) a
+        JOIN pg_roles r ON r.oid = a.grantee
+        WHERE n.nspname = ANY($1) AND c.relkind IN ('r', 'p')
+        UNION ALL
+        SELECT n.nspname, c.relname, att.attname, r.rolname, a.privilege_type
+        FROM pg_attribute att
+        JOIN pg_class c ON c.oid = att.attrelid
+        JOIN pg_namespace n ON n.oid = c.relnamespace
+        CROSS JOIN LATERAL aclexplode(att.attacl) a
+        JOIN pg_roles r ON r.oid = a.grantee
+        WHERE n.nspname = ANY($1) AND c.relkind IN ('r', 'p') AND att.attnum > 0 AND NOT att.attisdropped
+        ORDER BY 1, 2, 4, 5, 3`, func(rows interface{ Scan(...any) error }) error {
+		var schema, tbl string
+		var g SnapshotGrant
+		if err := rows.Scan(&schema, &tbl, &g.Column, &g.Role, &g.Privilege); err != nil {
+			return err
+		}
+		t := table(schema, tbl)
+		t.Grants = append(t.Grants, g)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = scan(`
+        SELECT n.nspname, t.typname, e.enumlabel
+        FROM pg_type t
+        JOIN pg_enum e ON e.enumtypid = t.oid
+        JOIN pg_namespace n ON n.oid = t.typnamespace
+        WHERE n.nspname = ANY($1)
+        ORDER BY n.nspname, t.typname, e.enumsortorder`, func(rows interface{ Scan(...any) error }) error {
+		var schema, name, label string
+		if err := rows.Scan(&schema, &name, &label); err != nil {
+			return err
+		}
+		if n := len(snap.EnumTypes); n == 0 || snap.EnumTypes[n-1].Schema != schema || snap.EnumTypes[n-1].Name != name {
+			snap.EnumTypes = append(snap.EnumTypes, SnapshotEnumType{Schema: schema, Name: name})
+		}
+		last := &snap.EnumTypes[len(snap.EnumTypes)-1]
+		last.Labels = append(last.Labels, label)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = pool.QueryRow(ctx, `SELECT current_setting('server_version_num')::int, COALESCE(array_agg(extname::text ORDER BY extname), '{}') FROM pg_extension`).
+		Scan(&snap.ServerVersion, &snap.Extensions)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(snap.Tables, func(a, b SnapshotTable) int {
+		return strings.Compare(tableKey(a.Schema, a.Name), tableKey(b.Schema, b.Name))
+	})
+	return snap, nil
+}
+
+// colInfo is the part of a column the planner compares
+type colInfo struct {
+	dataType   string
+	isNullable string
+	collation  string
+	generated  string // generation expression; "" for regular columns
+}
+
+// only returns the tables of snapshot in schemas (an empty snapshot for nil)
+func (s *SchemaSnapshot) only(schemas []string) []SnapshotTable {
+	if s == nil {
+		return nil
+	}
+	var out []SnapshotTable
+	for _, t := range s.Tables {
+		if slices.Contains(schemas, t.Schema) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// snapshotColumns indexes the columns of tables by tableKey and column name
+func snapshotColumns(tables []SnapshotTable) map[string]map[string]colInfo {
+	out := map[string]map[string]colInfo{}
+	for _, t := range tables {
+		if len(t.Columns) == 0 {
+			continue
+		}
+		cols := make(map[string]colInfo, len(t.Columns))
+		for _, c := range t.Columns {
+			nullable := "NO"
+			if c.Nullable {
+				nullable = "YES"
+			}
+			cols[c.Name] = colInfo{dataType: c.DataType, isNullable: nullable, collation: c.Collation, generated: c.Generated}
+		}
+		out[tableKey(t.Schema, t.Name)] = cols
+	}
+	return out
+}
+
+// snapshotConstraints returns the definition of every constraint keyed by tableKey(schema, name), the
+// namespace constraint names share with indexes
+func snapshotConstraints(tables []SnapshotTable) map[string]string {
+	out := map[string]string{}
+	for _, t := range tables {
+		for _, c := range t.Constraints {
+			out[tableKey(t.Schema, c.Name)] = c.Def
+		}
+	}
+	return out
+}
+
+// snapshotCheckDefs returns the definitions of chk_* CHECK constraints keyed by tableKey(schema, name)
+func snapshotCheckDefs(tables []SnapshotTable) map[string]string {
+	out := map[string]string{}
+	for _, t := range tables {
+		for _, c := range t.Constraints {
+			if c.Type == "c" && strings.HasPrefix(c.Name, "chk_") {
+				out[tableKey(t.Schema, c.Name)] = c.Def
+			}
+		}
+	}
+	return out
+}
+
+// snapshotPartitioning returns the partition key of each partitioned table keyed by tableKey, and the set of
+// tables that are partitions of another table
+func snapshotPartitioning(tables []SnapshotTable) (map[string]string, map[string]struct{}) {
+	keys := map[string]string{}
+	parts := map[string]struct{}{}
+	for _, t := range tables {
+		k := tableKey(t.Schema, t.Name)
+		if t.PartitionKey != "" {
+			keys[k] = t.PartitionKey
+		}
+		if t.PartitionOf {
+			parts[k] = struct{}{}
+		}
+	}
+	return keys, parts
+}
+
+// snapshotGrants returns table- and column-level grants keyed by tableKey(schema, table); table-level
+// grants use column "*"
+func snapshotGrants(tables []SnapshotTable) map[string]grantSet {
+	out := map[string]grantSet{}
+	for _, t := range tables {
+		if len(t.Grants) == 0 {
+			continue
+		}
+		g := grantSet{}
+		for _, gr := range t.Grants {
+			col := strings.ToLower(gr.Column)
+			if col == "" {
+				col = "*"
+			}
+			g.add(gr.Role, gr.Privilege, col)
+		}
+		out[tableKey(t.Schema, t.Name)] = g
+	}
+	return out
+}
+
+// enumLabels returns the labels of enum types in schemas keyed by tableKey(schema, type)
+func (s *SchemaSnapshot) enumLabels(schemas []string) map[string][]string {
+	out := map[string][]string{}
+	if s == nil {
+		return out
+	}
+	for _, e := range s.EnumTypes {
+		if slices.Contains(schemas, e.Schema) {
+			out[tableKey(e.Schema, e.Name)] = e.Labels
+		}
+	}
+	return out
+}
@@ -0,0 +1,115 @@
+package migration
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type snapUser struct {
+	ID    int64  `db:"id" norm:"primary_key"`
+	Email string `db:"email" norm:"not_null,index"`
+}
+
+// snapshotOf describes the tables of models as they would be after migrating them
+func snapshotOf(models ...any) *SchemaSnapshot {
+	snap := &SchemaSnapshot{Version: snapshotVersion, Schemas: []string{"public"}}
+	for _, model := range models {
+		mi := parseModel(model)
+		t := SnapshotTable{Schema: "public", Name: mi.TableName}
+		for _, f := range mi.Fields {
+			t.Columns = append(t.Columns, SnapshotColumn{Name: f.DBName, DataType: strings.ToLower(normalizeType(f)), Nullable: !f.NotNull && !f.PrimaryKey})
+		}
+		snap.Tables = append(snap.Tables, t)
+	}
+	return snap
+}
+
+func TestPlanFromSnapshot(t *testing.T) {
+	snap := snapshotOf(snapUser{})
+	users := &snap.Tables[0]
+	users.Columns = append(users.Columns, SnapshotColumn{Name: "legacy", DataType: "text", Nullable: true})
+	users.Indexes = []SnapshotIndex{{Name: "idx_snap_users_email", Def: "CREATE INDEX idx_snap_users_email ON public.snap_users USING btree (email)"}}
+	users.Constraints = []SnapshotConstraint{{Name: "fk_snap_users_team_id", Type: "f", Def: "FOREIGN KEY (team_id) REFERENCES teams(id)"}}
+	snap.Tables = append(snap.Tables, SnapshotTable{Schema: "public", Name: "orphans", Columns: []SnapshotColumn{{Name: "id", DataType: "bigint"}}})
+
+	plan, err := NewMigrator(nil).PlanFromSnapshot(snap, &snapUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slices.ContainsFunc(plan.Statements, func(s string) bool { return strings.HasPrefix(s, "CREATE TABLE IF NOT EXISTS \"snap_users\"") }) {
+		t.Fatalf("existing table recreated: %v", plan.Statements)
+	}
+	if !slices.Equal(plan.DestructiveStatements, []string{`ALTER TABLE "snap_users" DROP COLUMN "legacy"`}) {
+		t.Fatalf("destructive: %v", plan.DestructiveStatements)
+	}
+	if !slices.Equal(plan.ConstraintDrops, []string{`ALTER TABLE "snap_users" DROP CONSTRAINT "fk_snap_users_team_id"`}) {
+		t.Fatalf("constraint drops: %v", plan.ConstraintDrops)
+	}
+	if len(plan.IndexDrops) != 0 {
+		t.Fatalf("index drops: %v", plan.IndexDrops)
+	}
+	if !slices.Equal(plan.TableDrops, []string{`DROP TABLE IF EXISTS "orphans" CASCADE`}) {
+		t.Fatalf("table drops: %v", plan.TableDrops)
+	}
+}
+
+func TestPlanFromSnapshot_UncoveredSchema(t *testing.T) {
+	plan, err := NewMigrator(nil).InSchema("tenant_a").PlanFromSnapshot(snapshotOf(), &snapUser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.ContainsFunc(plan.Statements, func(s string) bool { return strings.HasPrefix(s, `CREATE TABLE IF NOT EXISTS "tenant_a"."snap_users"`) }) {
+		t.Fatalf("statements: %v", plan.Statements)
+	}
+	if !slices.Contains(plan.Warnings, "snapshot does not cover schema tenant_a; its tables are planned as new") {
+		t.Fatalf("warnings: %v", plan.Warnings)
+	}
+}
+
+func TestSnapshotFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schema.json")
+	snap := snapshotOf(snapUser{})
+	snap.EnumTypes = []SnapshotEnumType{{Schema: "public", Name: "mood", Labels: []string{"ok", "sad"}}}
+	if err := snap.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadSnapshotFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tables) != 1 || len(got.Tables[0].Columns) != 2 || !slices.Equal(got.EnumTypes[0].Labels, []string{"ok", "sad"}) {
+		t.Fatalf("got %+v", got)
+	}
+	snap.Version = snapshotVersion + 1
+	_ = snap.WriteFile(path)
+	if _, err := ReadSnapshotFile(path); err == nil {
+		t.Fatal("expected version error")
+	}
+}
+
+func TestFormatPlanSQL(t *testing.T) {
+	out := FormatPlanSQL(PlanResult{
+		Warnings:              []string{"type change for users.age: integer -> bigint"},
+		SchemaCreates:         []string{`CREATE SCHEMA IF NOT EXISTS "billing"`},
+		Statements:            []string{`ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "nick" text`},
+		UnsafeStatements:      []string{`ALTER TABLE "users" ALTER COLUMN "age" TYPE bigint USING "age"::bigint`},
+		DestructiveStatements: []string{`ALTER TABLE "users" DROP COLUMN "legacy"`},
+	})
+	for _, want := range []string{
+		"--   type change for users.age: integer -> bigint\n",
+		"\nBEGIN;\n",
+		"-- Schemas\nCREATE SCHEMA IF NOT EXISTS \"billing\";\n",
+		"ADD COLUMN IF NOT EXISTS \"nick\" text;\n\nCOMMIT;\n",
+		"\n-- ALTER TABLE \"users\" ALTER COLUMN \"age\" TYPE bigint USING \"age\"::bigint;\n",
+		"(ApplyOptions.AllowDropColumns)\n-- ALTER TABLE \"users\" DROP COLUMN \"legacy\";\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Index(out, "CREATE SCHEMA") > strings.Index(out, "ADD COLUMN") {
+		t.Fatal("schemas must come first")
+	}
+}
@@ -0,0 +1,79 @@
+package migration
+
+import (
+	"strings"
+
+	"github.com/kintsdev/norm/internal/sqlutil"
+)
+
+// splitSQLStatements splits a migration file on the semicolons that end statements. Semicolons inside
+// quoted literals and identifiers, E'...' strings, dollar-quoted bodies ($$ ... $$, $fn$ ... $fn$), comments
+// and BEGIN ATOMIC ... END function bodies are kept. Chunks holding only comments are dropped.
+func splitSQLStatements(sql string) []string {
+	var out []string
+	start := 0
+	content := false // the current chunk has more than whitespace and comments
+	atomic, cases := 0, 0
+	prevWord := ""
+	flush := func(end int) {
+		if s := strings.TrimSpace(sql[start:end]); s != "" && content {
+			out = append(out, s)
+		}
+		start, content, prevWord = end+1, false, ""
+	}
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			for i < len(sql) && sql[i] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			i = sqlutil.SkipBlockComment(sql, i)
+		case c == '\'':
+			// E'...' strings escape with backslashes
+			escapes := i > 0 && (sql[i-1] == 'E' || sql[i-1] == 'e') && (i < 2 || !sqlutil.IsIdentByte(sql[i-2]))
+			i = sqlutil.SkipQuoted(sql, i, '\'', escapes)
+			content = true
+		case c == '"':
+			i = sqlutil.SkipQuoted(sql, i, '"', false)
+			content = true
+		case c == '$' && (i == 0 || !sqlutil.IsIdentByte(sql[i-1])):
+			if tag, ok := sqlutil.DollarTag(sql[i:]); ok {
+				if end := strings.Index(sql[i+len(tag):], tag); end >= 0 {
+					i += len(tag) + end + len(tag) - 1
+				} else {
+					i = len(sql) - 1
+				}
+			}
+			content = true
+		case c == ';' && atomic == 0:
+			flush(i)
+		case sqlutil.IsIdentByte(c):
+			j := i
+			for j < len(sql) && sqlutil.IsIdentByte(sql[j]) {
+				j++
+			}
+			word := strings.ToUpper(sql[i:j])
+			switch {
+			case word == "ATOMIC" && prevWord == "BEGIN":
+				atomic++
+			case atomic > 0 && word == "CASE":
+				cases++
+			case atomic > 0 && word == "END":
+				if cases > 0 {
+					cases--
+				} else {
+					atomic--
+				}
+			}
+			prevWord = word
+			content = true
+			i = j - 1
+		case c > ' ':
+			content = true
+		}
+	}
+	flush(len(sql))
+	return out
+}
@@ -1,6 +1,9 @@
 package migration
 
-import "testing"
+import (
+	"strings"
+	"testing"
+)
 
 func TestSplitSQLStatements_Edges(t *testing.T) {
 	in := " ;  CREATE TABLE x(a int); ;  CREATE INDEX i ON x(a);  ;"
@@ -9,3 +12,45 @@ func TestSplitSQLStatements_Edges(t *testing.T) {
 		t.Fatalf("split: %v", out)
 	}
 }
+
+func TestSplitSQLStatements_Quoting(t *testing.T) {
+	in := `-- norm:no_transaction
+CREATE FUNCTION touch() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+	NEW.updated_at := now();
+	RETURN NEW;
+END $$;
+DO $body$ BEGIN RAISE NOTICE 'a;b'; END $body$;
+INSERT INTO notes (body, "odd;col") VALUES ('it''s; fine', E'esc\'; still'); /* trailing; /* nested; */ comment */
+CREATE FUNCTION add(a int, b int) RETURNS int LANGUAGE sql BEGIN ATOMIC
+	SELECT CASE WHEN a > 0 THEN a + b END;
+	SELECT a;
+END;
+SELECT $1::int; -- done;
+`
+	out := splitSQLStatements(in)
+	if len(out) != 5 {
+		t.Fatalf("got %d statements: %q", len(out), out)
+	}
+	if !strings.HasSuffix(out[0], "END $$") || !strings.HasPrefix(out[0], "-- norm:no_transaction\nCREATE FUNCTION touch()") {
+		t.Fatalf("function: %q", out[0])
+	}
+	if out[1] != `DO $body$ BEGIN RAISE NOTICE 'a;b'; END $body$` {
+		t.Fatalf("do block: %q", out[1])
+	}
+	if !strings.HasSuffix(out[2], `E'esc\'; still')`) {
+		t.Fatalf("literals: %q", out[2])
+	}
+	if !strings.HasPrefix(out[3], "/* trailing; /* nested; */ comment */\nCREATE FUNCTION add") || !strings.HasSuffix(out[3], "END") {
+		t.Fatalf("atomic body: %q", out[3])
+	}
+	if out[4] != "SELECT $1::int" {
+		t.Fatalf("placeholder: %q", out[4])
+	}
+}
+
+func TestSplitSQLStatements_CommentOnly(t *testing.T) {
+	if out := splitSQLStatements("CREATE TABLE a (id int);\n-- the end\n/* really */"); len(out) != 1 {
+		t.Fatalf("got %q", out)
+	}
+}
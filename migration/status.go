@@ -0,0 +1,139 @@
+package migration
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"slices"
+	"time"
+)
+
+// Migration sources of a version in schema_migrations
+const (
+	SourceFile = "file" // a .up.sql file of StatusOptions.Dir
+	SourceGo   = "go"   // a migration of StatusOptions.Go
+)
+
+// StatusOptions names the migration sources Status compares the applied versions with
+type StatusOptions struct {
+	Dir string               // directory of .up.sql/.down.sql files ("" = none)
+	FS  fs.FS                // when set, Dir is a directory of FS (e.g. an embed.FS; "" = its root)
+	Go  *GoMigrationRegistry // Go migrations (nil = none)
+}
+
+// AppliedMigration is a row of schema_migrations
+type AppliedMigration struct {
+	Version   int64
+	AppliedAt time.Time
+	Checksum  string
+	// Source is SourceFile or SourceGo when a current migration has this version; "" for AutoMigrate runs
+	// and for migrations whose source is gone
+	Source string
+	Name   string // file name or Go migration description
+	// ChecksumMismatch is set when the current source no longer matches what was applied (an edited file)
+	ChecksumMismatch bool
+}
+
+// PendingMigration is a file or Go migration not applied yet
+type PendingMigration struct {
+	Version int64
+	Source  string
+	Name    string
+}
+
+// MigrationStatus is the result of Status
+type MigrationStatus struct {
+	Applied []AppliedMigration // ascending by version
+	Pending []PendingMigration // ascending by version, in apply order
+}
+
+// UpToDate reports whether nothing is pending and every applied migration matches its source
+func (s MigrationStatus) UpToDate() bool {
+	return len(s.Pending) == 0 && len(s.Mismatched()) == 0
+}
+
+// Mismatched returns the applied migrations whose source changed since they were applied
+func (s MigrationStatus) Mismatched() []AppliedMigration {
+	var out []AppliedMigration
+	for _, a := range s.Applied {
+		if a.ChecksumMismatch {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// Status returns the applied versions from schema_migrations, the pending migrations of opts' sources, and
+// whether the applied file and Go migrations still match their checksums. It only reads, so it suits a
+// /migrations health endpoint.
+func (m *Migrator) Status(ctx context.Context, opts StatusOptions) (MigrationStatus, error) {
+	var files []filePair
+	var err error
+	switch {
+	case opts.FS != nil:
+		files, err = loadMigrationPairsFS(opts.FS, opts.Dir)
+	case opts.Dir != "":
+		files, err = loadMigrationPairs(opts.Dir)
+	}
+	if err != nil {
+		return MigrationStatus{}, err
+	}
+	var exists bool
+	if err := m.pool.QueryRow(ctx, `SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists); err != nil {
+		return MigrationStatus{}, err
+	}
+	var applied []AppliedMigration
+	if exists {
+		rows, err := m.pool.Query(ctx, `SELECT version, applied_at, COALESCE(checksum, '') FROM schema_migrations ORDER BY version`)
+		if err != nil {
+			return MigrationStatus{}, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var a AppliedMigration
+			if err := rows.Scan(&a.Version, &a.AppliedAt, &a.Checksum); err != nil {
+				return MigrationStatus{}, err
+			}
+			applied = append(applied, a)
+		}
+		if err := rows.Err(); err != nil {
+			return MigrationStatus{}, err
+		}
+	}
+	return buildStatus(applied, files, opts.Go), nil
+}
+
+// buildStatus matches applied versions with the current sources
+func buildStatus(applied []AppliedMigration, files []filePair, registry *GoMigrationRegistry) MigrationStatus {
+	type source struct {
+		kind, name, checksum string
+	}
+	sources := map[int64]source{}
+	for _, p := range files {
+		if p.upSQL != "" {
+			sources[p.version] = source{kind: SourceFile, name: p.upName, checksum: computeChecksum(p.upSQL)}
+		}
+	}
+	if registry != nil {
+		for _, mig := range registry.sorted() {
+			sources[mig.Version] = source{kind: SourceGo, name: mig.Description, checksum: computeChecksum(fmt.Sprintf("go:%d:%s", mig.Version, mig.Description))}
+		}
+	}
+	st := MigrationStatus{Applied: applied}
+	done := map[int64]bool{}
+	for i, a := range st.Applied {
+		done[a.Version] = true
+		if src, ok := sources[a.Version]; ok {
+			st.Applied[i].Source, st.Applied[i].Name = src.kind, src.name
+			st.Applied[i].ChecksumMismatch = a.Checksum != src.checksum
+		}
+	}
+	for v, src := range sources {
+		if !done[v] {
+			st.Pending = append(st.Pending, PendingMigration{Version: v, Source: src.kind, Name: src.name})
+		}
+	}
+	slices.SortFunc(st.Pending, func(a, b PendingMigration) int { return cmp.Compare(a.Version, b.Version) })
+	return st
+}
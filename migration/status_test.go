@@ -0,0 +1,55 @@
+package migration
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestBuildStatus(t *testing.T) {
+	dir := t.TempDir()
+	for name, sql := range map[string]string{
+		"1_users.up.sql":   "CREATE TABLE users (id bigint)",
+		"1_users.down.sql": "DROP TABLE users",
+		"2_orders.up.sql":  "CREATE TABLE orders (id bigint)",
+		"4_notes.up.sql":   "CREATE TABLE notes (id bigint)",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(sql), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, err := loadMigrationPairs(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := NewGoMigrationRegistry()
+	reg.MustRegister(GoMigration{Version: 3, Description: "backfill", Up: func(context.Context, pgx.Tx) error { return nil }})
+	applied := []AppliedMigration{
+		{Version: 1, Checksum: computeChecksum("CREATE TABLE users (id bigint)")},
+		{Version: 2, Checksum: computeChecksum("CREATE TABLE orders (id serial)")}, // edited after it was applied
+		{Version: 7, Checksum: "auto"},
+	}
+	st := buildStatus(applied, files, reg)
+
+	if a := st.Applied[0]; a.Source != SourceFile || a.Name != "1_users.up.sql" || a.ChecksumMismatch {
+		t.Fatalf("applied[0] = %+v", a)
+	}
+	if mm := st.Mismatched(); len(mm) != 1 || mm[0].Version != 2 {
+		t.Fatalf("mismatched = %+v", mm)
+	}
+	if a := st.Applied[2]; a.Source != "" || a.ChecksumMismatch {
+		t.Fatalf("AutoMigrate row = %+v", a)
+	}
+	if len(st.Pending) != 2 || st.Pending[0] != (PendingMigration{Version: 3, Source: SourceGo, Name: "backfill"}) || st.Pending[1].Version != 4 {
+		t.Fatalf("pending = %+v", st.Pending)
+	}
+	if st.UpToDate() {
+		t.Fatal("up to date with pending migrations")
+	}
+	if !buildStatus(applied[:1], files[:0], nil).UpToDate() {
+		t.Fatal("expected up to date")
+	}
+}
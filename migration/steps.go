@@ -0,0 +1,120 @@
+package migration
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// noTransactionDirective marks a migration file whose statements cannot run in a transaction, such as
+// CREATE INDEX CONCURRENTLY. It must be a line of its own, usually the first.
+var noTransactionDirective = regexp.MustCompile(`(?m)^[ \t]*--[ \t]*norm:no_transaction[ \t]*\r?$`)
+
+// hasNoTransactionDirective reports whether a migration file asks to run outside a transaction
+func hasNoTransactionDirective(sql string) bool { return noTransactionDirective.MatchString(sql) }
+
+// migrationStep is one pending file or Go migration plus the statement recording it in schema_migrations
+type migrationStep struct {
+	noTx   bool
+	inTx   func(ctx context.Context, tx pgx.Tx) error
+	onConn func(ctx context.Context, conn *pgx.Conn) error
+	record string
+	args   []any
+}
+
+// sqlStep runs the statements of a migration file; wrap annotates the error of the failing statement
+func sqlStep(sql string, noTx bool, wrap func(error) error, record string, args ...any) migrationStep {
+	run := func(ctx context.Context, db Execer) error {
+		for _, stmt := range splitSQLStatements(sql) {
+			if _, err := db.Exec(ctx, stmt); err != nil {
+				return wrap(err)
+			}
+		}
+		return nil
+	}
+	return migrationStep{
+		noTx:   noTx,
+		inTx:   func(ctx context.Context, tx pgx.Tx) error { return run(ctx, tx) },
+		onConn: func(ctx context.Context, conn *pgx.Conn) error { return run(ctx, conn) },
+		record: record,
+		args:   args,
+	}
+}
+
+// withMigrationLock runs fn on one connection holding the migration lock for the whole run, so steps
+// outside a transaction are serialized with other migrators too
+func (m *Migrator) withMigrationLock(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	pc, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer pc.Release()
+	conn := pc.Conn()
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock(hashtext('github.com/kintsdev/norm-migrate'))`); err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock(hashtext('github.com/kintsdev/norm-migrate'))`)
+	}()
+	if _, err := conn.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY, applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(), checksum TEXT)`); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// appliedVersions returns the versions in schema_migrations, newest first
+func appliedVersions(ctx context.Context, conn *pgx.Conn) ([]int64, error) {
+	rows, err := conn.Query(ctx, `SELECT version FROM schema_migrations ORDER BY version DESC`)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+// runSteps applies steps in order. Consecutive transactional steps share one transaction, as a run
+// without no-transaction steps always did; a no-transaction step commits the steps before it, then runs
+// its statements one by one, so a failure in it leaves the statements before the failing one applied.
+func runSteps(ctx context.Context, conn *pgx.Conn, steps []migrationStep) error {
+	var tx pgx.Tx
+	defer func() {
+		if tx != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+	commit := func() error {
+		if tx == nil {
+			return nil
+		}
+		err := tx.Commit(ctx)
+		tx = nil
+		return err
+	}
+	for _, s := range steps {
+		if s.noTx {
+			if err := commit(); err != nil {
+				return err
+			}
+			if err := s.onConn(ctx, conn); err != nil {
+				return err
+			}
+			if _, err := conn.Exec(ctx, s.record, s.args...); err != nil {
+				return err
+			}
+			continue
+		}
+		if tx == nil {
+			var err error
+			if tx, err = conn.Begin(ctx); err != nil {
+				return err
+			}
+		}
+		if err := s.inTx(ctx, tx); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, s.record, s.args...); err != nil {
+			return err
+		}
+	}
+	return commit()
+}
@@ -0,0 +1,47 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestNoTransactionDirective(t *testing.T) {
+	for sql, want := range map[string]bool{
+		"-- norm:no_transaction\nCREATE INDEX CONCURRENTLY idx ON t(a);":        true,
+		"  --norm:no_transaction  \r\nALTER TYPE mood ADD VALUE 'meh';":         true,
+		"CREATE TABLE t (id int);\n-- norm:no_transaction\n":                    true,
+		"CREATE TABLE t (id int); -- norm:no_transaction":                       false,
+		"-- norm:no_transactions\nCREATE TABLE t (id int);":                     false,
+		"INSERT INTO notes(body) VALUES ('-- norm:no_transaction is not here')": false,
+	} {
+		if got := hasNoTransactionDirective(sql); got != want {
+			t.Errorf("%q: got %v", sql, got)
+		}
+	}
+}
+
+func TestRegisterNoTransaction(t *testing.T) {
+	reg := NewGoMigrationRegistry()
+	if err := reg.Register(GoMigration{Version: 1, NoTransaction: true, Up: func(context.Context, pgx.Tx) error { return nil }}); err == nil {
+		t.Fatal("expected UpConn to be required")
+	}
+	mig := GoMigration{Version: 2, Description: "concurrent index", NoTransaction: true,
+		UpConn: func(context.Context, *pgx.Conn) error { return errors.New("boom") }}
+	if err := reg.Register(mig); err != nil {
+		t.Fatal(err)
+	}
+	if mig.hasDown() {
+		t.Fatal("no DownConn, no rollback")
+	}
+	s := mig.step(true, "INSERT")
+	if !s.noTx {
+		t.Fatal("step must run outside a transaction")
+	}
+	if err := s.onConn(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "go migration 2 (concurrent index) up failed: boom") {
+		t.Fatalf("err = %v", err)
+	}
+}
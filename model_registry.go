@@ -0,0 +1,150 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/kintsdev/norm/migration"
+)
+
+var modelRegistry struct {
+	mu     sync.Mutex
+	models []any
+	seen   map[reflect.Type]struct{}
+}
+
+// RegisterModels records models for VerifyRegisteredModels; registering the same type twice is a no-op.
+// Call it from init() or main before connecting.
+func RegisterModels(models ...any) {
+	modelRegistry.mu.Lock()
+	defer modelRegistry.mu.Unlock()
+	if modelRegistry.seen == nil {
+		modelRegistry.seen = map[reflect.Type]struct{}{}
+	}
+	for _, m := range models {
+		t := reflect.TypeOf(m)
+		if t == nil {
+			continue
+		}
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if _, ok := modelRegistry.seen[t]; ok {
+			continue
+		}
+		modelRegistry.seen[t] = struct{}{}
+		modelRegistry.models = append(modelRegistry.models, m)
+	}
+}
+
+// RegisteredModels returns the models recorded by RegisterModels, in registration order
+func RegisteredModels() []any {
+	modelRegistry.mu.Lock()
+	defer modelRegistry.mu.Unlock()
+	return slices.Clone(modelRegistry.models)
+}
+
+// SchemaReport lists what the registered models expect but the database lacks.
+// Tables outside public are schema-qualified (billing.invoices).
+type SchemaReport struct {
+	MissingTables  []string
+	MissingColumns map[string][]string // table -> columns absent from it
+}
+
+// OK reports whether every table and column was found
+func (r SchemaReport) OK() bool { return len(r.MissingTables) == 0 && len(r.MissingColumns) == 0 }
+
+func (r SchemaReport) String() string {
+	if r.OK() {
+		return "schema ok"
+	}
+	var parts []string
+	if len(r.MissingTables) > 0 {
+		parts = append(parts, "missing tables: "+strings.Join(r.MissingTables, ", "))
+	}
+	tables := make([]string, 0, len(r.MissingColumns))
+	for t := range r.MissingColumns {
+		tables = append(tables, t)
+	}
+	slices.Sort(tables)
+	for _, t := range tables {
+		parts = append(parts, fmt.Sprintf("missing columns in %s: %s", t, strings.Join(r.MissingColumns[t], ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// VerifyRegisteredModels checks the tables and columns of all registered models against the database
+// in one pass, so a deployment with missing migrations fails at boot rather than per query. When something
+// is missing the report is returned with an ErrCodeMigration error describing all of it.
+func (kn *KintsNorm) VerifyRegisteredModels(ctx context.Context) (SchemaReport, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return verifyModels(ctx, kn.Query().exec, migration.ModelTables(RegisteredModels()...))
+}
+
+func verifyModels(ctx context.Context, exec dbExecuter, tables []migration.ModelTable) (SchemaReport, error) {
+	var report SchemaReport
+	if len(tables) == 0 {
+		return report, nil
+	}
+	var schemas []string
+	for _, t := range tables {
+		if !slices.Contains(schemas, t.Schema) {
+			schemas = append(schemas, t.Schema)
+		}
+	}
+	const q = `SELECT table_schema, table_name, column_name FROM information_schema.columns WHERE table_schema = ANY($1)`
+	rows, err := exec.Query(ctx, q, schemas)
+	if err != nil {
+		return report, wrapPgError(err, q, []any{schemas})
+	}
+	existing := map[string]map[string]struct{}{}
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			rows.Close()
+			return report, wrapPgError(err, q, []any{schemas})
+		}
+		schema, _ := vals[0].(string)
+		table, _ := vals[1].(string)
+		col, _ := vals[2].(string)
+		key := schema + "." + table
+		if existing[key] == nil {
+			existing[key] = map[string]struct{}{}
+		}
+		existing[key][col] = struct{}{}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return report, wrapPgError(err, q, []any{schemas})
+	}
+	for _, t := range tables {
+		name := t.Table
+		if t.Schema != "public" {
+			name = t.Schema + "." + t.Table
+		}
+		cols, ok := existing[t.Schema+"."+t.Table]
+		if !ok {
+			report.MissingTables = append(report.MissingTables, name)
+			continue
+		}
+		for _, c := range t.Columns {
+			if _, ok := cols[c]; ok {
+				continue
+			}
+			if report.MissingColumns == nil {
+				report.MissingColumns = map[string][]string{}
+			}
+			report.MissingColumns[name] = append(report.MissingColumns[name], c)
+		}
+	}
+	if !report.OK() {
+		return report, &ORMError{Code: ErrCodeMigration, Message: "schema check failed: " + report.String()}
+	}
+	return report, nil
+}
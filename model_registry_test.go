@@ -0,0 +1,53 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/kintsdev/norm/migration"
+)
+
+type regAccount struct {
+	ID    int64  `db:"id" norm:"primary_key"`
+	Email string `db:"email"`
+	Plan  string `db:"plan"`
+}
+
+type regInvoice struct {
+	_  struct{} `norm:"schema:billing"`
+	ID int64    `db:"id" norm:"primary_key"`
+}
+
+func TestRegisterModels_Dedupes(t *testing.T) {
+	before := len(RegisteredModels())
+	RegisterModels(&regAccount{}, regAccount{}, &regInvoice{})
+	RegisterModels(&regInvoice{})
+	if n := len(RegisteredModels()) - before; n != 2 {
+		t.Fatalf("registered %d models", n)
+	}
+}
+
+func TestVerifyModels_ConsolidatedReport(t *testing.T) {
+	ex := &seqExec{fields: []string{"table_schema", "table_name", "column_name"}, results: [][][]any{{
+		{"public", "reg_accounts", "id"},
+		{"public", "reg_accounts", "email"},
+	}}}
+	report, err := verifyModels(context.Background(), ex, migration.ModelTables(&regAccount{}, &regInvoice{}))
+	var oe *ORMError
+	if !errors.As(err, &oe) || oe.Code != ErrCodeMigration {
+		t.Fatalf("err=%v", err)
+	}
+	if !reflect.DeepEqual(report.MissingTables, []string{"billing.reg_invoices"}) || !reflect.DeepEqual(report.MissingColumns, map[string][]string{"reg_accounts": {"plan"}}) {
+		t.Fatalf("report=%+v", report)
+	}
+	if report.String() != "missing tables: billing.reg_invoices; missing columns in reg_accounts: plan" {
+		t.Fatalf("string=%s", report)
+	}
+
+	ok := &seqExec{fields: []string{"table_schema", "table_name", "column_name"}, results: [][][]any{{{"billing", "reg_invoices", "id"}}}}
+	if report, err := verifyModels(context.Background(), ok, migration.ModelTables(&regInvoice{})); err != nil || !report.OK() {
+		t.Fatalf("report=%+v err=%v", report, err)
+	}
+}
@@ -0,0 +1,138 @@
+package norm
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/kintsdev/norm/internal/core"
+)
+
+// NamingStrategy derives the default table name of a model from its Go type name and the default column
+// of a field from its Go field name. It applies wherever norm names a model: repositories,
+// QueryBuilder.Model, relations and the migration parser. db tags, `norm:"table:..."` tags and TableName()
+// methods still take precedence.
+type NamingStrategy interface {
+	TableName(typeName string) string
+	ColumnName(fieldName string) string
+}
+
+// SetNamingStrategy installs ns as the naming of all models; nil restores the default snake_case(type
+// name) + "s" tables and snake_case(field name) columns. Call it once at startup, before any repository or
+// migration is used: names derived earlier are cached.
+//
+//	norm.SetNamingStrategy(norm.SnakeCaseNaming{}) // Person -> people, UserID -> user_id
+func SetNamingStrategy(ns NamingStrategy) { core.SetNamer(ns) }
+
+// SnakeCaseNaming is a NamingStrategy with acronym-aware snake_case (UserID -> user_id, HTTPServer ->
+// http_server) and English plural table names (Child -> children, Status -> statuses, Category ->
+// categories, Money -> money). Singular keeps table names singular (OrderLine -> order_line).
+type SnakeCaseNaming struct {
+	Singular bool
+}
+
+// TableName is the snake_case type name, pluralised unless Singular is set
+func (n SnakeCaseNaming) TableName(typeName string) string {
+	name := SnakeCase(typeName)
+	if n.Singular {
+		return name
+	}
+	// only the last word is pluralised: order_line -> order_lines
+	i := strings.LastIndexByte(name, '_') + 1
+	return name[:i] + Pluralize(name[i:])
+}
+
+// ColumnName is the snake_case field name
+func (SnakeCaseNaming) ColumnName(fieldName string) string { return SnakeCase(fieldName) }
+
+// SnakeCase converts a Go identifier to snake_case, keeping acronyms together: UserID -> user_id,
+// HTTPServer -> http_server, Address2 -> address2
+func SnakeCase(s string) string {
+	rs := []rune(s)
+	var b strings.Builder
+	b.Grow(len(s) + 4)
+	for i, r := range rs {
+		if unicode.IsUpper(r) {
+			// a word starts at an upper-case letter after a lower-case letter or digit, or at the last
+			// letter of an acronym followed by a lower-case one (HTTPServer: the S)
+			if i > 0 && rs[i-1] != '_' && (unicode.IsLower(rs[i-1]) || unicode.IsDigit(rs[i-1]) ||
+				(i+1 < len(rs) && unicode.IsLower(rs[i+1]) && unicode.IsUpper(rs[i-1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// irregularPlurals lists the English nouns whose plural does not follow the suffix rules
+var irregularPlurals = map[string]string{
+	"child":     "children",
+	"person":    "people",
+	"man":       "men",
+	"woman":     "women",
+	"mouse":     "mice",
+	"goose":     "geese",
+	"tooth":     "teeth",
+	"foot":      "feet",
+	"ox":        "oxen",
+	"criterion": "criteria",
+	"analysis":  "analyses",
+	"crisis":    "crises",
+	"thesis":    "theses",
+	"quiz":      "quizzes",
+	"leaf":      "leaves",
+	"half":      "halves",
+	"life":      "lives",
+	"wife":      "wives",
+	"knife":     "knives",
+	"shelf":     "shelves",
+	"wolf":      "wolves",
+	"hero":      "heroes",
+	"potato":    "potatoes",
+	"tomato":    "tomatoes",
+	"echo":      "echoes",
+}
+
+// uncountableNouns have no plural form
+var uncountableNouns = map[string]bool{
+	"money":       true,
+	"information": true,
+	"equipment":   true,
+	"feedback":    true,
+	"news":        true,
+	"data":        true,
+	"metadata":    true,
+	"media":       true,
+	"series":      true,
+	"species":     true,
+	"sheep":       true,
+	"fish":        true,
+	"deer":        true,
+	"staff":       true,
+	"software":    true,
+	"hardware":    true,
+	"audio":       true,
+	"rice":        true,
+	"police":      true,
+}
+
+// Pluralize returns the English plural of a lower-case noun: irregular forms (child -> children),
+// uncountable nouns unchanged (money), -s/-x/-z/-ch/-sh + es (status -> statuses), consonant + y -> ies
+// (category -> categories), + s otherwise
+func Pluralize(word string) string {
+	if word == "" || uncountableNouns[word] {
+		return word
+	}
+	if p, ok := irregularPlurals[word]; ok {
+		return p
+	}
+	switch {
+	case strings.HasSuffix(word, "s"), strings.HasSuffix(word, "x"), strings.HasSuffix(word, "z"),
+		strings.HasSuffix(word, "ch"), strings.HasSuffix(word, "sh"):
+		return word + "es"
+	case len(word) > 1 && word[len(word)-1] == 'y' && !strings.ContainsRune("aeiou", rune(word[len(word)-2])):
+		return word[:len(word)-1] + "ies"
+	}
+	return word + "s"
+}
@@ -0,0 +1,64 @@
+package norm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kintsdev/norm/internal/core"
+)
+
+func TestSnakeCaseNaming(t *testing.T) {
+	n := SnakeCaseNaming{}
+	tables := map[string]string{
+		"User":       "users",
+		"Child":      "children",
+		"Person":     "people",
+		"Money":      "money",
+		"Status":     "statuses",
+		"Category":   "categories",
+		"Day":        "days",
+		"Box":        "boxes",
+		"OrderLine":  "order_lines",
+		"HTTPServer": "http_servers",
+	}
+	for in, want := range tables {
+		if got := n.TableName(in); got != want {
+			t.Errorf("TableName(%q)=%q want %q", in, got, want)
+		}
+	}
+	if got := (SnakeCaseNaming{Singular: true}).TableName("OrderLine"); got != "order_line" {
+		t.Errorf("singular: %q", got)
+	}
+	cols := map[string]string{"UserID": "user_id", "ID": "id", "HTTPServer": "http_server", "Address2": "address2", "CreatedAt": "created_at"}
+	for in, want := range cols {
+		if got := n.ColumnName(in); got != want {
+			t.Errorf("ColumnName(%q)=%q want %q", in, got, want)
+		}
+	}
+}
+
+type namedChild struct {
+	ID       int64 `norm:"primary_key"`
+	ParentID int64
+	Name     string `db:"title"`
+}
+
+func TestSetNamingStrategy(t *testing.T) {
+	typ := reflect.TypeFor[namedChild]()
+	if got := core.TableName(typ); got != "named_childs" {
+		t.Fatalf("default table %q", got)
+	}
+	SetNamingStrategy(SnakeCaseNaming{})
+	t.Cleanup(func() { SetNamingStrategy(nil) })
+
+	if got := core.TableName(typ); got != "named_children" {
+		t.Fatalf("table %q", got)
+	}
+	m := core.StructMapper(typ)
+	if _, ok := m.FieldsByColumn["parent_id"]; !ok {
+		t.Fatalf("columns %v", m.FieldsByColumn)
+	}
+	if _, ok := m.FieldsByColumn["title"]; !ok {
+		t.Fatal("db tag overridden")
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"sync/atomic"
 	"time"
 
 	"github.com/kintsdev/norm/migration"
@@ -22,12 +24,41 @@ type KintsNorm struct {
 	cache    Cache
 	migrator *migration.Migrator
 	breaker  *circuitBreaker
+	// acquire limits per pool (see Config.AcquireTimeout/MaxWaiters; empty when unset)
+	gates map[*pgxpool.Pool]*poolGate
 	// logging enhancements
 	logContextFields   func(ctx context.Context) []Field
 	slowQueryThreshold time.Duration
 	maskParams         bool
+	deadlineWarning    time.Duration
 	// audit logging
 	auditHook AuditHook
+	// compiled SQL reused across builders with the same shape (nil when disabled)
+	templates *queryTemplateCache
+	// rows per multi-row INSERT in CreateBatch (0 = defaultBatchSize)
+	batchSize int
+	// adaptive chunk sizes (nil unless WithAdaptiveBatchSize)
+	batchTuner *batchTuner
+	// statement guard applied by every executor (see WithQueryGuard)
+	guardRules []QueryGuardRule
+	// Repository.Find scans into pooled buffers (see WithFindBufferPool)
+	findBufferPool bool
+	// schema prefix of ForTenant/TenantManager (see WithTenantSchemaPrefix)
+	tenantSchemaPrefix string
+	// statement interceptors (see WithInterceptor)
+	interceptors []Interceptor
+	// transaction spans (see WithTxTracer)
+	txTracer TxTracer
+	// primary keys of new rows (see WithIDGenerator)
+	idGenerator IDGenerator
+	// set once the idempotency key table is known to exist
+	idempotencyReady atomic.Bool
+	// retention policies registered via RegisterRetention/AutoMigrate
+	retention retentionRegistry
+	// stops the WithStatsCallback loop (nil when not running)
+	stopStats context.CancelFunc
+	// stops the WithCacheInvalidationChannel listener and waits for it (nil when not running)
+	stopCacheListener func()
 }
 
 // New creates a new KintsNorm instance, initializing the pgx pool
@@ -41,7 +72,7 @@ func New(config *Config, opts ...Option) (*KintsNorm, error) {
 		opt(&options)
 	}
 
-	pool, err := newPool(context.Background(), config)
+	pool, err := newPool(context.Background(), config, options.afterConnect())
 	if err != nil {
 		return nil, err
 	}
@@ -55,12 +86,22 @@ func New(config *Config, opts ...Option) (*KintsNorm, error) {
 		cache:              options.cache,
 		logContextFields:   options.logContextFields,
 		slowQueryThreshold: options.slowQueryThreshold,
+		deadlineWarning:    options.deadlineWarning,
 		maskParams:         options.maskParams,
 		auditHook:          options.auditHook,
+		templates:          newQueryTemplateCache(options.queryTemplateCacheSize),
+		batchSize:          options.batchSize,
+		batchTuner:         newBatchTuner(options.adaptiveBatch),
+		guardRules:         options.guardRules,
+		findBufferPool:     options.findBufferPool,
+		tenantSchemaPrefix: options.tenantSchemaPrefix,
+		interceptors:       options.interceptors,
+		txTracer:           options.txTracer,
+		idGenerator:        options.idGenerator,
 	}
 	// optional read-only pool
 	if config.ReadOnlyConnString != "" {
-		rp, rerr := newPoolFromConnString(context.Background(), config.ReadOnlyConnString)
+		rp, rerr := newPoolFromConnString(context.Background(), config.ReadOnlyConnString, config.SessionSettings, options.afterConnect())
 		if rerr != nil {
 			pool.Close()
 			return nil, fmt.Errorf("read pool: %w", rerr)
@@ -68,6 +109,14 @@ func New(config *Config, opts ...Option) (*KintsNorm, error) {
 		kn.readPool = rp
 	}
 	kn.migrator = migration.NewMigrator(kn.pool)
+	for _, p := range []*pgxpool.Pool{kn.pool, kn.readPool} {
+		if g := newPoolGate(config, p, kn.metrics); g != nil {
+			if kn.gates == nil {
+				kn.gates = map[*pgxpool.Pool]*poolGate{}
+			}
+			kn.gates[p] = g
+		}
+	}
 	// initialize circuit breaker if enabled
 	if config.CircuitBreakerEnabled {
 		kn.breaker = newCircuitBreaker(circuitBreakerConfig{
@@ -81,6 +130,8 @@ func New(config *Config, opts ...Option) (*KintsNorm, error) {
 			},
 		})
 	}
+	kn.startStatsLoop(options.statsInterval, options.statsCallback)
+	kn.startCacheListener(options.cacheChannel)
 	return kn, nil
 }
 
@@ -91,7 +142,7 @@ func NewWithConnString(connString string, opts ...Option) (*KintsNorm, error) {
 		opt(&options)
 	}
 
-	pool, err := newPoolFromConnString(context.Background(), connString)
+	pool, err := newPoolFromConnString(context.Background(), connString, nil, options.afterConnect())
 	if err != nil {
 		return nil, err
 	}
@@ -104,10 +155,22 @@ func NewWithConnString(connString string, opts ...Option) (*KintsNorm, error) {
 		cache:              options.cache,
 		logContextFields:   options.logContextFields,
 		slowQueryThreshold: options.slowQueryThreshold,
+		deadlineWarning:    options.deadlineWarning,
 		maskParams:         options.maskParams,
 		auditHook:          options.auditHook,
+		templates:          newQueryTemplateCache(options.queryTemplateCacheSize),
+		batchSize:          options.batchSize,
+		batchTuner:         newBatchTuner(options.adaptiveBatch),
+		guardRules:         options.guardRules,
+		findBufferPool:     options.findBufferPool,
+		tenantSchemaPrefix: options.tenantSchemaPrefix,
+		interceptors:       options.interceptors,
+		txTracer:           options.txTracer,
+		idGenerator:        options.idGenerator,
 	}
 	kn.migrator = migration.NewMigrator(kn.pool)
+	kn.startStatsLoop(options.statsInterval, options.statsCallback)
+	kn.startCacheListener(options.cacheChannel)
 	return kn, nil
 }
 
@@ -148,7 +211,7 @@ func (kn *KintsNorm) AutoMigrate(models ...any) error {
 	if err := kn.migrator.AutoMigrate(context.Background(), models...); err != nil {
 		return &ORMError{Code: ErrCodeMigration, Message: err.Error(), Internal: err}
 	}
-	return nil
+	return kn.RegisterRetention(models...)
 }
 
 // AutoMigrateWithOptions allows enabling destructive ops (e.g., drop columns)
@@ -159,7 +222,7 @@ func (kn *KintsNorm) AutoMigrateWithOptions(ctx context.Context, opts migration.
 	if err := kn.migrator.AutoMigrateWithOptions(ctx, opts, models...); err != nil {
 		return &ORMError{Code: ErrCodeMigration, Message: err.Error(), Internal: err}
 	}
-	return nil
+	return kn.RegisterRetention(models...)
 }
 
 // MigrateUpDir applies pending .up.sql migrations from a directory
@@ -184,11 +247,50 @@ func (kn *KintsNorm) MigrateDownDir(ctx context.Context, dir string, steps int)
 	return nil
 }
 
+// MigrateUpFS applies pending .up.sql migrations under root of fsys (e.g. an embed.FS)
+func (kn *KintsNorm) MigrateUpFS(ctx context.Context, fsys fs.FS, root string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := kn.migrator.MigrateUpFS(ctx, fsys, root); err != nil {
+		return &ORMError{Code: ErrCodeMigration, Message: err.Error(), Internal: err}
+	}
+	return nil
+}
+
+// MigrateDownFS rolls back the last N migrations using the .down.sql files under root of fsys
+func (kn *KintsNorm) MigrateDownFS(ctx context.Context, fsys fs.FS, root string, steps int) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := kn.migrator.MigrateDownFS(ctx, fsys, root, steps); err != nil {
+		return &ORMError{Code: ErrCodeMigration, Message: err.Error(), Internal: err}
+	}
+	return nil
+}
+
+// MigrationStatus reports applied and pending migrations and checksum mismatches (see migration.Migrator.Status)
+func (kn *KintsNorm) MigrationStatus(ctx context.Context, opts migration.StatusOptions) (migration.MigrationStatus, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	st, err := kn.migrator.Status(ctx, opts)
+	if err != nil {
+		return st, &ORMError{Code: ErrCodeMigration, Message: err.Error(), Internal: err}
+	}
+	return st, nil
+}
+
 // SetManualMigrationOptions configures safety gates for manual file-based migrations
 func (kn *KintsNorm) SetManualMigrationOptions(opts migration.ManualOptions) {
 	kn.migrator.SetManualOptions(opts)
 }
 
+// SetMigrationRenameStrategy selects how AutoMigrate applies rename: column tags
+func (kn *KintsNorm) SetMigrationRenameStrategy(s migration.RenameStrategy) {
+	kn.migrator.SetRenameStrategy(s)
+}
+
 // MigrateUpGo applies pending Go-based migrations from a registry
 func (kn *KintsNorm) MigrateUpGo(ctx context.Context, registry *migration.GoMigrationRegistry) error {
 	if ctx == nil {
@@ -213,6 +315,12 @@ func (kn *KintsNorm) MigrateDownGo(ctx context.Context, registry *migration.GoMi
 
 // Close gracefully closes the connection pool
 func (kn *KintsNorm) Close() error {
+	if kn.stopStats != nil {
+		kn.stopStats()
+	}
+	if kn.stopCacheListener != nil {
+		kn.stopCacheListener()
+	}
 	if kn.pool != nil {
 		kn.pool.Close()
 	}
@@ -247,10 +355,6 @@ func (kn *KintsNorm) ReadPool() *pgxpool.Pool {
 // QueryRead uses the read pool for building queries (falls back to primary)
 func (kn *KintsNorm) QueryRead() *QueryBuilder {
 	qb := kn.Query()
-	exec := dbExecuter(kn.ReadPool())
-	if kn.breaker != nil {
-		exec = breakerExecuter{kn: kn, exec: exec}
-	}
-	qb.exec = exec
+	qb.exec = wrapExec(kn, kn.ReadPool())
 	return qb
 }
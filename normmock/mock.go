@@ -0,0 +1,322 @@
+// Package normmock is a fake executor for unit testing code built on norm without a database. A Mock
+// matches every statement against the expectations registered on it, answers with canned rows or
+// results, and records the calls it received.
+//
+//	m := normmock.New()
+//	m.ExpectQuery(`FROM users WHERE id = \$1`).WithArgs(int64(7)).
+//		WillReturnRows(normmock.NewRows("id", "email").AddRow(int64(7), "ada@example.com"))
+//	users := norm.NewRepositoryWithExecutor[User](&norm.KintsNorm{}, m)
+//	u, err := users.GetByID(ctx, int64(7))
+//	...
+//	if err := m.ExpectationsWereMet(); err != nil {
+//		t.Fatal(err)
+//	}
+package normmock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Kind tells which executor method a call or expectation is about
+type Kind string
+
+const (
+	KindExec     Kind = "exec"
+	KindQuery    Kind = "query" // Query and QueryRow
+	KindBegin    Kind = "begin"
+	KindCommit   Kind = "commit"
+	KindRollback Kind = "rollback"
+)
+
+// Call is a statement received by a Mock
+type Call struct {
+	Kind Kind
+	SQL  string
+	Args []any
+}
+
+// Argument matches one statement argument in WithArgs; other values are compared with reflect.DeepEqual
+type Argument interface {
+	Match(v any) bool
+}
+
+type anyArg struct{}
+
+func (anyArg) Match(any) bool { return true }
+
+// AnyArg matches any argument value
+func AnyArg() Argument { return anyArg{} }
+
+// Expectation is a statement a Mock is expected to receive, and what it answers
+type Expectation struct {
+	kind     Kind
+	pattern  *regexp.Regexp
+	args     []any
+	argsSet  bool
+	rows     *Rows
+	affected int64
+	err      error
+	met      bool
+}
+
+// WithArgs requires the statement arguments to match args
+func (e *Expectation) WithArgs(args ...any) *Expectation {
+	e.args, e.argsSet = args, true
+	return e
+}
+
+// WillReturnRows answers a query with rows
+func (e *Expectation) WillReturnRows(rows *Rows) *Expectation {
+	e.rows = rows
+	return e
+}
+
+// WillReturnResult answers an Exec with a command tag reporting n rows affected
+func (e *Expectation) WillReturnResult(n int64) *Expectation {
+	e.affected = n
+	return e
+}
+
+// WillReturnError makes the matching call fail with err
+func (e *Expectation) WillReturnError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+func (e *Expectation) String() string {
+	if e.pattern == nil {
+		return string(e.kind)
+	}
+	return fmt.Sprintf("%s matching %q", e.kind, e.pattern.String())
+}
+
+func (e *Expectation) matches(kind Kind, sql string, args []any) bool {
+	if e.kind != kind || (e.pattern != nil && !e.pattern.MatchString(sql)) {
+		return false
+	}
+	if !e.argsSet {
+		return true
+	}
+	if len(e.args) != len(args) {
+		return false
+	}
+	for i, want := range e.args {
+		if m, ok := want.(Argument); ok {
+			if !m.Match(args[i]) {
+				return false
+			}
+		} else if !reflect.DeepEqual(want, args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ErrUnexpected is wrapped by the error returned for a call no expectation matches
+var ErrUnexpected = errors.New("normmock: unexpected call")
+
+// Mock is a fake executor: pass it to norm.NewRepositoryWithExecutor or a builder's executor. It also
+// implements Begin, so code opening transactions or savepoints on it works; statements inside a
+// transaction are matched against the same expectations. It is safe for concurrent use.
+type Mock struct {
+	mu           sync.Mutex
+	expectations []*Expectation
+	calls        []Call
+	unordered    bool
+	failures     []string
+}
+
+// New returns a Mock expecting nothing. Expectations are matched in the order they were registered
+// unless MatchExpectationsInOrder(false) is set.
+func New() *Mock { return &Mock{} }
+
+// MatchExpectationsInOrder sets whether each call must match the next pending expectation (the default)
+// or any pending one
+func (m *Mock) MatchExpectationsInOrder(ordered bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.unordered = !ordered
+}
+
+// ExpectExec expects an Exec whose SQL matches the regular expression pattern
+func (m *Mock) ExpectExec(pattern string) *Expectation {
+	return m.expect(KindExec, regexp.MustCompile(pattern))
+}
+
+// ExpectQuery expects a Query or QueryRow whose SQL matches the regular expression pattern. Without
+// WillReturnRows it returns no rows.
+func (m *Mock) ExpectQuery(pattern string) *Expectation {
+	return m.expect(KindQuery, regexp.MustCompile(pattern))
+}
+
+// ExpectBegin expects a transaction (or savepoint) to be opened
+func (m *Mock) ExpectBegin() *Expectation { return m.expect(KindBegin, nil) }
+
+// ExpectCommit expects a transaction to be committed
+func (m *Mock) ExpectCommit() *Expectation { return m.expect(KindCommit, nil) }
+
+// ExpectRollback expects a transaction to be rolled back
+func (m *Mock) ExpectRollback() *Expectation { return m.expect(KindRollback, nil) }
+
+func (m *Mock) expect(kind Kind, pattern *regexp.Regexp) *Expectation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e := &Expectation{kind: kind, pattern: pattern}
+	m.expectations = append(m.expectations, e)
+	return e
+}
+
+// Calls returns the calls received so far, matched or not
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Call(nil), m.calls...)
+}
+
+// ExpectationsWereMet reports calls that matched no expectation and expectations that were not met
+func (m *Mock) ExpectationsWereMet() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	problems := append([]string(nil), m.failures...)
+	for _, e := range m.expectations {
+		if !e.met {
+			problems = append(problems, "expected "+e.String()+" was not called")
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.New("normmock: " + strings.Join(problems, "; "))
+}
+
+// match records a call and returns the expectation answering it
+func (m *Mock) match(kind Kind, sql string, args []any) (*Expectation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, Call{Kind: kind, SQL: sql, Args: args})
+	for _, e := range m.expectations {
+		if e.met {
+			continue
+		}
+		if e.matches(kind, sql, args) {
+			e.met = true
+			return e, nil
+		}
+		if !m.unordered {
+			break
+		}
+	}
+	msg := fmt.Sprintf("%s %q with args %v", kind, sql, args)
+	m.failures = append(m.failures, "unexpected "+msg)
+	return nil, fmt.Errorf("%w: %s", ErrUnexpected, msg)
+}
+
+func (m *Mock) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	e, err := m.match(KindExec, sql, args)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	if e.err != nil {
+		return pgconn.CommandTag{}, e.err
+	}
+	return commandTag(sql, e.affected), nil
+}
+
+func (m *Mock) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	e, err := m.match(KindQuery, sql, args)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.rows.iter(), nil
+}
+
+func (m *Mock) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := m.Query(ctx, sql, args...)
+	if err != nil {
+		return errRow{err}
+	}
+	return firstRow{rows}
+}
+
+// Begin opens a fake transaction whose statements go through m
+func (m *Mock) Begin(ctx context.Context) (pgx.Tx, error) {
+	e, err := m.match(KindBegin, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &tx{Mock: m}, nil
+}
+
+// tx is a pgx.Tx over a Mock. Methods beyond statements, savepoints, Commit and Rollback (CopyFrom,
+// SendBatch, Prepare, ...) are not supported and panic.
+type tx struct {
+	pgx.Tx
+	*Mock
+	done bool
+}
+
+func (t *tx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return t.Mock.Exec(ctx, sql, args...)
+}
+
+func (t *tx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return t.Mock.Query(ctx, sql, args...)
+}
+
+func (t *tx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return t.Mock.QueryRow(ctx, sql, args...)
+}
+
+func (t *tx) Begin(ctx context.Context) (pgx.Tx, error) { return t.Mock.Begin(ctx) }
+
+func (t *tx) Commit(ctx context.Context) error {
+	if t.done {
+		return pgx.ErrTxClosed
+	}
+	t.done = true
+	return t.end(KindCommit)
+}
+
+// Rollback of an ended transaction returns pgx.ErrTxClosed without a call, like pgx, so a deferred
+// Rollback after Commit needs no expectation
+func (t *tx) Rollback(ctx context.Context) error {
+	if t.done {
+		return pgx.ErrTxClosed
+	}
+	t.done = true
+	return t.end(KindRollback)
+}
+
+func (t *tx) end(kind Kind) error {
+	e, err := t.match(kind, "", nil)
+	if err != nil {
+		return err
+	}
+	return e.err
+}
+
+func (t *tx) Conn() *pgx.Conn { return nil }
+
+// commandTag renders the tag PostgreSQL sends for sql affecting n rows
+func commandTag(sql string, n int64) pgconn.CommandTag {
+	verb := strings.ToUpper(strings.SplitN(strings.TrimSpace(sql), " ", 2)[0])
+	if verb == "INSERT" {
+		return pgconn.NewCommandTag(fmt.Sprintf("INSERT 0 %d", n))
+	}
+	return pgconn.NewCommandTag(fmt.Sprintf("%s %d", verb, n))
+}
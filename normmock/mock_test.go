@@ -0,0 +1,106 @@
+package normmock_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kintsdev/norm"
+	"github.com/kintsdev/norm/normmock"
+)
+
+type mockUser struct {
+	ID        int64      `db:"id" norm:"primary_key,auto_increment"`
+	Email     string     `db:"email"`
+	Nickname  *string    `db:"nickname"`
+	CreatedAt time.Time  `db:"created_at"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+func (mockUser) TableName() string { return "users" }
+
+func TestRepositoryAgainstMock(t *testing.T) {
+	ctx := context.Background()
+	m := normmock.New()
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	m.ExpectQuery(`^SELECT .* FROM users WHERE id = \$1`).WithArgs(int64(7)).
+		WillReturnRows(normmock.NewRows("id", "email", "nickname", "created_at", "deleted_at").AddRow(int64(7), "ada@example.com", "ada", created, nil))
+	m.ExpectExec(`^UPDATE users SET "email" = \$1`).WithArgs(normmock.AnyArg(), int64(7)).WillReturnResult(1)
+	m.ExpectQuery(`^SELECT COUNT\(\*\) FROM users`).WillReturnRows(normmock.NewRows("count").AddRow(int64(3)))
+
+	users := norm.NewRepositoryWithExecutor[mockUser](&norm.KintsNorm{}, m)
+	u, err := users.GetByID(ctx, int64(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Email != "ada@example.com" || u.Nickname == nil || *u.Nickname != "ada" || !u.CreatedAt.Equal(created) {
+		t.Fatalf("user: %+v", u)
+	}
+	if err := users.UpdatePartial(ctx, int64(7), map[string]any{"email": "lovelace@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := users.Count(ctx); err != nil || n != 3 {
+		t.Fatalf("count=%d err=%v", n, err)
+	}
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if calls := m.Calls(); len(calls) != 3 || calls[1].Kind != normmock.KindExec {
+		t.Fatalf("calls: %+v", calls)
+	}
+}
+
+func TestUnexpectedAndUnmet(t *testing.T) {
+	ctx := context.Background()
+	m := normmock.New()
+	m.ExpectExec(`^DELETE FROM users`)
+	users := norm.NewRepositoryWithExecutor[mockUser](&norm.KintsNorm{}, m)
+	if _, err := users.GetByID(ctx, int64(1)); !errors.Is(err, normmock.ErrUnexpected) {
+		t.Fatalf("err=%v", err)
+	}
+	if err := m.ExpectationsWereMet(); err == nil {
+		t.Fatal("expected unmet expectations")
+	}
+}
+
+func TestNotFoundAndErrors(t *testing.T) {
+	ctx := context.Background()
+	m := normmock.New()
+	m.ExpectQuery(`FROM users`).WillReturnRows(normmock.NewRows("id"))
+	boom := errors.New("boom")
+	m.ExpectExec(`^DELETE FROM users`).WillReturnError(boom)
+	users := norm.NewRepositoryWithExecutor[mockUser](&norm.KintsNorm{}, m)
+	var oe *norm.ORMError
+	if _, err := users.GetByID(ctx, int64(1)); !errors.As(err, &oe) || oe.Code != norm.ErrCodeNotFound {
+		t.Fatalf("get err=%v", err)
+	}
+	if err := users.Delete(ctx, int64(1)); !errors.Is(err, boom) {
+		t.Fatalf("delete err=%v", err)
+	}
+}
+
+func TestUnorderedAndTransactions(t *testing.T) {
+	ctx := context.Background()
+	m := normmock.New()
+	m.MatchExpectationsInOrder(false)
+	m.ExpectCommit()
+	m.ExpectExec(`INSERT INTO audit`).WillReturnResult(1)
+	m.ExpectBegin()
+
+	tx, err := m.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := tx.Exec(ctx, "INSERT INTO audit (msg) VALUES ($1)", "hi")
+	if err != nil || tag.RowsAffected() != 1 {
+		t.Fatalf("tag=%v err=%v", tag, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	_ = tx.Rollback(ctx) // ended: no call
+	if err := m.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,164 @@
+package normmock
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Rows is a canned query result
+type Rows struct {
+	columns []string
+	values  [][]any
+	rowErr  map[int]error
+}
+
+// NewRows starts a result with the given column names
+func NewRows(columns ...string) *Rows { return &Rows{columns: columns} }
+
+// AddRow appends a row; values are in column order and are returned as-is by Values
+func (r *Rows) AddRow(values ...any) *Rows {
+	if len(values) != len(r.columns) {
+		panic(fmt.Sprintf("normmock: row has %d values for %d columns", len(values), len(r.columns)))
+	}
+	r.values = append(r.values, values)
+	return r
+}
+
+// RowError makes iteration stop with err when it reaches row i (0-based)
+func (r *Rows) RowError(i int, err error) *Rows {
+	if r.rowErr == nil {
+		r.rowErr = map[int]error{}
+	}
+	r.rowErr[i] = err
+	return r
+}
+
+// iter returns a fresh cursor over r, so an expectation's rows can be read once per call
+func (r *Rows) iter() *rowsIter {
+	if r == nil {
+		return &rowsIter{rows: &Rows{}, i: -1}
+	}
+	return &rowsIter{rows: r, i: -1}
+}
+
+// rowsIter implements pgx.Rows over Rows
+type rowsIter struct {
+	rows   *Rows
+	i      int
+	err    error
+	closed bool
+}
+
+func (it *rowsIter) Next() bool {
+	if it.closed || it.err != nil {
+		return false
+	}
+	it.i++
+	if err := it.rows.rowErr[it.i]; err != nil {
+		it.err = err
+		return false
+	}
+	if it.i >= len(it.rows.values) {
+		it.closed = true
+		return false
+	}
+	return true
+}
+
+func (it *rowsIter) Values() ([]any, error) {
+	if it.i < 0 || it.i >= len(it.rows.values) {
+		return nil, fmt.Errorf("normmock: no current row")
+	}
+	return append([]any(nil), it.rows.values[it.i]...), nil
+}
+
+func (it *rowsIter) Scan(dest ...any) error {
+	vals, err := it.Values()
+	if err != nil {
+		return err
+	}
+	if len(dest) != len(vals) {
+		return fmt.Errorf("normmock: scan of %d columns into %d destinations", len(vals), len(dest))
+	}
+	for i, d := range dest {
+		if err := assign(d, vals[i]); err != nil {
+			return fmt.Errorf("normmock: column %s: %w", it.rows.columns[i], err)
+		}
+	}
+	return nil
+}
+
+func (it *rowsIter) FieldDescriptions() []pgconn.FieldDescription {
+	out := make([]pgconn.FieldDescription, len(it.rows.columns))
+	for i, c := range it.rows.columns {
+		out[i] = pgconn.FieldDescription{Name: c}
+	}
+	return out
+}
+
+func (it *rowsIter) Err() error          { return it.err }
+func (it *rowsIter) Close()              { it.closed = true }
+func (it *rowsIter) RawValues() [][]byte { return nil }
+func (it *rowsIter) Conn() *pgx.Conn     { return nil }
+func (it *rowsIter) CommandTag() pgconn.CommandTag {
+	return pgconn.NewCommandTag(fmt.Sprintf("SELECT %d", len(it.rows.values)))
+}
+
+// firstRow is QueryRow's pgx.Row: it scans the first row, or fails with pgx.ErrNoRows
+type firstRow struct{ rows pgx.Rows }
+
+func (r firstRow) Scan(dest ...any) error {
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	return r.rows.Scan(dest...)
+}
+
+type errRow struct{ err error }
+
+func (r errRow) Scan(...any) error { return r.err }
+
+// assign stores v in the pointer dest: sql.Scanner destinations scan it, pointer fields are allocated
+// (or set to nil for a nil v), and other values must be assignable or convertible to the destination
+func assign(dest, v any) error {
+	if s, ok := dest.(sql.Scanner); ok {
+		return s.Scan(v)
+	}
+	dv := reflect.ValueOf(dest)
+	if dv.Kind() != reflect.Pointer || dv.IsNil() {
+		return fmt.Errorf("destination %T is not a non-nil pointer", dest)
+	}
+	target := dv.Elem()
+	if v == nil {
+		target.SetZero()
+		return nil
+	}
+	if target.Kind() == reflect.Pointer {
+		p := reflect.New(target.Type().Elem())
+		if err := assign(p.Interface(), v); err != nil {
+			return err
+		}
+		target.Set(p)
+		return nil
+	}
+	vv := reflect.ValueOf(v)
+	switch {
+	case vv.Type().AssignableTo(target.Type()):
+		target.Set(vv)
+	case vv.Type().ConvertibleTo(target.Type()) && vv.Kind() != reflect.String && target.Kind() != reflect.String:
+		target.Set(vv.Convert(target.Type()))
+	case vv.Kind() == reflect.String && target.Kind() == reflect.String:
+		target.SetString(vv.String())
+	default:
+		return fmt.Errorf("cannot assign %T to %s", v, target.Type())
+	}
+	return nil
+}
@@ -0,0 +1,280 @@
+// Package normprom exports norm metrics to Prometheus.
+//
+//	m, _ := normprom.New(prometheus.DefaultRegisterer)
+//	db, _ := norm.New(cfg, norm.WithMetrics(m))
+//	m.ObservePool("primary", db.Pool())
+package normprom
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/kintsdev/norm"
+)
+
+var circuitStates = []string{"closed", "open", "half_open"}
+
+// PrometheusMetrics implements norm.Metrics and the optional template cache, cache, retry, transaction,
+// deadline, result size and pool exhaustion hooks
+type PrometheusMetrics struct {
+	queryDuration  *prometheus.HistogramVec
+	errors         *prometheus.CounterVec
+	circuitState   *prometheus.GaugeVec
+	connections    *prometheus.GaugeVec
+	cacheLookups   *prometheus.CounterVec
+	templateLookup *prometheus.CounterVec
+	retries        prometheus.Counter
+	txDuration     *prometheus.HistogramVec
+	txStatements   prometheus.Histogram
+	deadlineLeft   *prometheus.HistogramVec
+	noDeadline     *prometheus.CounterVec
+	poolExhausted  *prometheus.CounterVec
+	resultRows     *prometheus.HistogramVec
+	resultBytes    *prometheus.HistogramVec
+	pools          *poolCollector
+}
+
+var (
+	_ norm.Metrics                   = (*PrometheusMetrics)(nil)
+	_ norm.QueryTemplateCacheMetrics = (*PrometheusMetrics)(nil)
+	_ norm.CacheMetrics              = (*PrometheusMetrics)(nil)
+	_ norm.RetryMetrics              = (*PrometheusMetrics)(nil)
+	_ norm.TxMetrics                 = (*PrometheusMetrics)(nil)
+	_ norm.DeadlineMetrics           = (*PrometheusMetrics)(nil)
+	_ norm.PoolMetrics               = (*PrometheusMetrics)(nil)
+	_ norm.ResultMetrics             = (*PrometheusMetrics)(nil)
+)
+
+// New creates the collectors and registers them on reg (prometheus.DefaultRegisterer when nil)
+func New(reg prometheus.Registerer) (*PrometheusMetrics, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	m := &PrometheusMetrics{
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "norm_query_duration_seconds",
+			Help:    "Duration of queries by operation and table.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation", "table"}),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "norm_errors_total",
+			Help: "Errors by type.",
+		}, []string{"type"}),
+		circuitState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "norm_circuit_state",
+			Help: "Circuit breaker state; 1 for the current state.",
+		}, []string{"state"}),
+		connections: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "norm_connections",
+			Help: "Connections reported by norm, by state.",
+		}, []string{"state"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "norm_cache_lookups_total",
+			Help: "Read-through cache lookups by result (hit or miss).",
+		}, []string{"result"}),
+		templateLookup: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "norm_query_template_cache_lookups_total",
+			Help: "Query template cache lookups by result (hit or miss).",
+		}, []string{"result"}),
+		retries: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "norm_retries_total",
+			Help: "Retries of failed operations.",
+		}),
+		txDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "norm_transaction_duration_seconds",
+			Help:    "Duration of transactions by result (commit, rollback or commit_failed).",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"result"}),
+		txStatements: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Name:    "norm_transaction_statements",
+			Help:    "Statements run per transaction.",
+			Buckets: []float64{1, 2, 5, 10, 20, 50, 100, 500},
+		}),
+		deadlineLeft: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "norm_query_deadline_remaining_seconds",
+			Help:    "Context deadline left when a statement starts, by operation (0 when already expired).",
+			Buckets: []float64{0, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"operation"}),
+		noDeadline: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "norm_queries_without_deadline_total",
+			Help: "Statements started with a context that has no deadline, by operation.",
+		}, []string{"operation"}),
+		poolExhausted: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "norm_pool_exhausted_total",
+			Help: "Requests failed because no connection was available, by reason (timeout or max_waiters).",
+		}, []string{"reason"}),
+		resultRows: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "norm_query_result_rows",
+			Help:    "Rows returned per query, by operation and table.",
+			Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+		}, []string{"operation", "table"}),
+		resultBytes: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "norm_query_result_bytes",
+			Help:    "Approximate bytes received per query (raw column values), by operation and table.",
+			Buckets: prometheus.ExponentialBuckets(256, 4, 10),
+		}, []string{"operation", "table"}),
+		pools: newPoolCollector(),
+	}
+	for _, c := range []prometheus.Collector{m.queryDuration, m.errors, m.circuitState, m.connections, m.cacheLookups, m.templateLookup, m.retries, m.txDuration, m.txStatements, m.deadlineLeft, m.noDeadline, m.poolExhausted, m.resultRows, m.resultBytes, m.pools} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// ObservePool exports the stats of pool (acquired, idle, total and max connections, acquire waits) under
+// the given pool label, read on every scrape. Observing the same name again replaces the pool.
+func (m *PrometheusMetrics) ObservePool(name string, pool *pgxpool.Pool) {
+	m.pools.set(name, pool)
+}
+
+func (m *PrometheusMetrics) QueryDuration(duration time.Duration, query string) {
+	op, table := statementLabels(query)
+	m.queryDuration.WithLabelValues(op, table).Observe(duration.Seconds())
+}
+
+func (m *PrometheusMetrics) ConnectionCount(active, idle int32) {
+	m.connections.WithLabelValues("active").Set(float64(active))
+	m.connections.WithLabelValues("idle").Set(float64(idle))
+}
+
+func (m *PrometheusMetrics) ErrorCount(errorType string) {
+	m.errors.WithLabelValues(errorType).Inc()
+}
+
+func (m *PrometheusMetrics) CircuitStateChanged(state string) {
+	for _, s := range circuitStates {
+		m.circuitState.WithLabelValues(s).Set(0)
+	}
+	m.circuitState.WithLabelValues(state).Set(1)
+}
+
+func (m *PrometheusMetrics) QueryTemplateCacheHit()  { m.templateLookup.WithLabelValues("hit").Inc() }
+func (m *PrometheusMetrics) QueryTemplateCacheMiss() { m.templateLookup.WithLabelValues("miss").Inc() }
+func (m *PrometheusMetrics) CacheHit()               { m.cacheLookups.WithLabelValues("hit").Inc() }
+func (m *PrometheusMetrics) CacheMiss()              { m.cacheLookups.WithLabelValues("miss").Inc() }
+func (m *PrometheusMetrics) Retry(int)               { m.retries.Inc() }
+
+// QueryResult observes the size of a query's result set
+func (m *PrometheusMetrics) QueryResult(rows, bytes int64, query string) {
+	op, table := statementLabels(query)
+	m.resultRows.WithLabelValues(op, table).Observe(float64(rows))
+	m.resultBytes.WithLabelValues(op, table).Observe(float64(bytes))
+}
+
+// PoolExhausted counts a request failed with norm.ErrPoolExhausted
+func (m *PrometheusMetrics) PoolExhausted(reason string) {
+	m.poolExhausted.WithLabelValues(reason).Inc()
+}
+
+// TransactionDone observes a finished transaction
+func (m *PrometheusMetrics) TransactionDone(s norm.TxStats) {
+	m.txDuration.WithLabelValues(string(s.Result)).Observe(s.Duration.Seconds())
+	m.txStatements.Observe(float64(s.Statements))
+}
+
+// QueryDeadline observes the deadline left on a statement's context
+func (m *PrometheusMetrics) QueryDeadline(remaining time.Duration, ok bool, query string) {
+	op, _ := statementLabels(query)
+	if !ok {
+		m.noDeadline.WithLabelValues(op).Inc()
+		return
+	}
+	m.deadlineLeft.WithLabelValues(op).Observe(max(remaining, 0).Seconds())
+}
+
+// statementLabels derives low-cardinality labels from SQL: the leading keyword and the target table
+func statementLabels(query string) (op, table string) {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "other", ""
+	}
+	op = strings.ToLower(fields[0])
+	var after string
+	switch op {
+	case "select", "delete":
+		after = "from"
+	case "insert":
+		after = "into"
+	case "update", "copy":
+		return op, tableName(fields, 1)
+	case "with":
+		return op, ""
+	default:
+		return "other", ""
+	}
+	for i, f := range fields {
+		if strings.EqualFold(f, after) {
+			return op, tableName(fields, i+1)
+		}
+	}
+	return op, ""
+}
+
+func tableName(fields []string, i int) string {
+	if i >= len(fields) || strings.HasPrefix(fields[i], "(") {
+		return ""
+	}
+	name := fields[i]
+	if j := strings.IndexAny(name, "(,;"); j >= 0 {
+		name = name[:j]
+	}
+	return strings.ReplaceAll(name, `"`, "")
+}
+
+// poolCollector reads pgxpool stats at scrape time
+type poolCollector struct {
+	mu    sync.RWMutex
+	pools map[string]*pgxpool.Pool
+
+	acquired, idle, total, max, emptyAcquire, acquireWait *prometheus.Desc
+}
+
+func newPoolCollector() *poolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(name, help, []string{"pool"}, nil)
+	}
+	return &poolCollector{
+		pools:        map[string]*pgxpool.Pool{},
+		acquired:     desc("norm_pool_acquired_connections", "Connections currently in use."),
+		idle:         desc("norm_pool_idle_connections", "Idle connections."),
+		total:        desc("norm_pool_total_connections", "Open connections."),
+		max:          desc("norm_pool_max_connections", "Maximum pool size."),
+		emptyAcquire: desc("norm_pool_empty_acquire_total", "Acquires that had to wait for a connection."),
+		acquireWait:  desc("norm_pool_acquire_wait_seconds_total", "Time spent waiting for connections."),
+	}
+}
+
+func (c *poolCollector) set(name string, pool *pgxpool.Pool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pools[name] = pool
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.acquired, c.idle, c.total, c.max, c.emptyAcquire, c.acquireWait} {
+		ch <- d
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for name, pool := range c.pools {
+		if pool == nil {
+			continue
+		}
+		s := pool.Stat()
+		ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(s.AcquiredConns()), name)
+		ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(s.IdleConns()), name)
+		ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(s.TotalConns()), name)
+		ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(s.MaxConns()), name)
+		ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(s.EmptyAcquireCount()), name)
+		ch <- prometheus.MustNewConstMetric(c.acquireWait, prometheus.CounterValue, s.EmptyAcquireWaitTime().Seconds(), name)
+	}
+}
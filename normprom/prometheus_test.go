@@ -0,0 +1,85 @@
+package normprom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/kintsdev/norm"
+)
+
+func TestStatementLabels(t *testing.T) {
+	cases := map[string][2]string{
+		`SELECT * FROM "public"."users" WHERE id = $1`: {"select", "public.users"},
+		"INSERT INTO orders (id) VALUES ($1)":          {"insert", "orders"},
+		"update accounts SET n = $1":                   {"update", "accounts"},
+		"DELETE FROM t":                                {"delete", "t"},
+		"SELECT count(*) FROM (SELECT 1) t":            {"select", ""},
+		"WITH x AS (SELECT 1) SELECT * FROM x":         {"with", ""},
+		"VACUUM":                                       {"other", ""},
+	}
+	for q, want := range cases {
+		if op, table := statementLabels(q); op != want[0] || table != want[1] {
+			t.Fatalf("%q: got %s/%s", q, op, table)
+		}
+	}
+}
+
+func TestPrometheusMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := New(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.QueryDuration(20*time.Millisecond, "SELECT * FROM users")
+	m.QueryDuration(30*time.Millisecond, "SELECT id FROM users")
+	m.CircuitStateChanged("open")
+	m.CircuitStateChanged("closed")
+	m.CacheHit()
+	m.CacheMiss()
+	m.CacheMiss()
+	m.Retry(1)
+	m.ErrorCount("timeout")
+	m.TransactionDone(norm.TxStats{Duration: time.Second, Statements: 3, Result: norm.TxCommitted})
+	m.TransactionDone(norm.TxStats{Duration: time.Millisecond, Statements: 1, Result: norm.TxRolledBack})
+	m.QueryDeadline(-time.Millisecond, true, "SELECT 1 FROM users")
+	m.QueryDeadline(0, false, "UPDATE users SET a = 1")
+	m.PoolExhausted("timeout")
+	m.QueryResult(120, 4096, "SELECT * FROM users")
+
+	if n := testutil.CollectAndCount(m.queryDuration); n != 1 {
+		t.Fatalf("histogram series=%d", n)
+	}
+	if v := testutil.ToFloat64(m.circuitState.WithLabelValues("closed")); v != 1 {
+		t.Fatalf("closed=%v", v)
+	}
+	if v := testutil.ToFloat64(m.circuitState.WithLabelValues("open")); v != 0 {
+		t.Fatalf("open=%v", v)
+	}
+	if v := testutil.ToFloat64(m.cacheLookups.WithLabelValues("miss")); v != 2 {
+		t.Fatalf("misses=%v", v)
+	}
+	if v := testutil.ToFloat64(m.retries); v != 1 {
+		t.Fatalf("retries=%v", v)
+	}
+	if n := testutil.CollectAndCount(m.txDuration); n != 2 {
+		t.Fatalf("tx duration series=%d", n)
+	}
+	if n := testutil.CollectAndCount(m.deadlineLeft); n != 1 {
+		t.Fatalf("deadline series=%d", n)
+	}
+	if v := testutil.ToFloat64(m.noDeadline.WithLabelValues("update")); v != 1 {
+		t.Fatalf("without deadline=%v", v)
+	}
+	if v := testutil.ToFloat64(m.poolExhausted.WithLabelValues("timeout")); v != 1 {
+		t.Fatalf("pool exhausted=%v", v)
+	}
+	if n := testutil.CollectAndCount(m.resultRows); n != 1 {
+		t.Fatalf("result rows series=%d", n)
+	}
+	if _, err := New(reg); err == nil {
+		t.Fatalf("expected duplicate registration error")
+	}
+}
@@ -0,0 +1,60 @@
+package e2e
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	kintsnorm "github.com/kintsdev/norm"
+)
+
+var kn *kintsnorm.KintsNorm
+
+func TestMain(m *testing.M) {
+	host := getenvDefault("PGHOST", "127.0.0.1")
+	port := getenvDefault("PGPORT", "5432")
+	user := getenvDefault("PGUSER", "postgres")
+	pass := getenvDefault("PGPASSWORD", "postgres")
+	db := getenvDefault("PGDATABASE", "postgres")
+
+	if err := waitTCP(host, port, 30*time.Second); err != nil {
+		fmt.Println("postgres not reachable:", err)
+		os.Exit(1)
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=disable", host, port, db, user, pass)
+	var err error
+	kn, err = kintsnorm.NewWithConnString(dsn)
+	if err != nil {
+		fmt.Println("failed to connect pg:", err)
+		os.Exit(1)
+	}
+
+	code := m.Run()
+	_ = kn.Close()
+	os.Exit(code)
+}
+
+func getenvDefault(k, def string) string {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	return v
+}
+
+func waitTCP(host, port string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	addr := net.JoinHostPort(host, port)
+	for time.Now().Before(deadline) {
+		c, err := net.DialTimeout("tcp", addr, 1*time.Second)
+		if err == nil {
+			_ = c.Close()
+			return nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return fmt.Errorf("timeout waiting for %s:%s", host, port)
+}
@@ -0,0 +1,77 @@
+package e2e
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kintsdev/norm/normtest"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	for _, ddl := range []string{
+		`CREATE TABLE IF NOT EXISTS fixture_authors (id BIGSERIAL PRIMARY KEY, name TEXT NOT NULL, created_at TIMESTAMPTZ NOT NULL)`,
+		`CREATE TABLE IF NOT EXISTS fixture_books (id BIGSERIAL PRIMARY KEY, author_id BIGINT NOT NULL REFERENCES fixture_authors(id), ref UUID NOT NULL)`,
+	} {
+		if _, err := kn.Pool().Exec(ctx, ddl); err != nil {
+			t.Fatalf("ddl: %v", err)
+		}
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		// books sort first but reference authors
+		"fixture_books.yml":    "- id: 1\n  author_id: 7\n  ref: \"{{ uuid() }}\"\n",
+		"fixture_authors.json": `[{"id": 7, "name": "Le Guin", "created_at": "{{ now() - 24h }}"}]`,
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// loading twice replaces the rows instead of conflicting with them
+	for range 2 {
+		if err := normtest.LoadFixtures(ctx, kn, dir); err != nil {
+			t.Fatalf("load: %v", err)
+		}
+	}
+	var books int
+	if err := kn.Pool().QueryRow(ctx, `SELECT count(*) FROM fixture_books b JOIN fixture_authors a ON a.id = b.author_id`).Scan(&books); err != nil || books != 1 {
+		t.Fatalf("books=%d err=%v", books, err)
+	}
+	// the sequence moved past the fixture ids
+	var id int64
+	if err := kn.Pool().QueryRow(ctx, `INSERT INTO fixture_authors (name, created_at) VALUES ('Butler', now()) RETURNING id`).Scan(&id); err != nil || id != 8 {
+		t.Fatalf("id=%d err=%v", id, err)
+	}
+}
+
+func TestNormtestRollsBackPerTest(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if _, err := kn.Pool().Exec(ctx, `CREATE TABLE IF NOT EXISTS normtest_notes (id BIGSERIAL PRIMARY KEY, body TEXT NOT NULL)`); err != nil {
+		t.Fatalf("ddl: %v", err)
+	}
+	_, _ = kn.Pool().Exec(ctx, `TRUNCATE normtest_notes`)
+
+	t.Run("writes", func(t *testing.T) {
+		db := normtest.New(t, kn)
+		if err := kn.Query().Table("normtest_notes").Insert("body").Values("hello").Exec(db.Context()); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+		// a builder from db runs in the transaction even with another context
+		if n, err := db.Query().Table("normtest_notes").Count(ctx); err != nil || n != 1 {
+			t.Fatalf("count in tx=%d err=%v", n, err)
+		}
+		// outside the transaction nothing is visible yet
+		if n, err := kn.Query().Table("normtest_notes").Count(ctx); err != nil || n != 0 {
+			t.Fatalf("count outside=%d err=%v", n, err)
+		}
+	})
+	if n, err := kn.Query().Table("normtest_notes").Count(ctx); err != nil || n != 0 {
+		t.Fatalf("count after test=%d err=%v", n, err)
+	}
+}
@@ -0,0 +1,288 @@
+// Package normtest helps tests seed a database through norm.
+//
+//	func TestOrders(t *testing.T) {
+//		if err := normtest.LoadFixtures(ctx, db, "testdata/fixtures"); err != nil {
+//			t.Fatal(err)
+//		}
+//		...
+//	}
+package normtest
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gopkg.in/yaml.v3"
+
+	"github.com/kintsdev/norm"
+)
+
+// Fixture is the content of one fixture file: the rows of one table
+type Fixture struct {
+	Table string           // file name without extension, optionally schema-qualified (billing.invoices.yml)
+	Rows  []map[string]any // column name to value
+}
+
+// LoadFixtures replaces the content of the tables named by the .yml, .yaml and .json files of dir with
+// the rows they list. Each file holds a list of rows, each a map of column to value:
+//
+//	# users.yml
+//	- id: 1
+//	  email: alice@example.com
+//	  created_at: "{{ now() }}"
+//	- id: 2
+//	  email: bob@example.com
+//	  created_at: "{{ now() - 24h }}"
+//
+// All tables are truncated in one statement (RESTART IDENTITY), then filled parents first according to
+// their foreign keys, in one transaction. Identity and serial columns given explicit values have their
+// sequence moved past the largest one, so rows inserted by the test do not collide with the fixtures.
+// String values of the form "{{ func() }}" are templates; see ExpandTemplate.
+func LoadFixtures(ctx context.Context, kn *norm.KintsNorm, dir string) error {
+	return LoadFixturesFS(ctx, kn, os.DirFS(dir), ".")
+}
+
+// LoadFixturesFS is LoadFixtures reading the files under root of fsys, e.g. an embed.FS
+func LoadFixturesFS(ctx context.Context, kn *norm.KintsNorm, fsys fs.FS, root string) error {
+	fixtures, err := ReadFixturesFS(fsys, root)
+	if err != nil {
+		return err
+	}
+	if len(fixtures) == 0 {
+		return nil
+	}
+	return pgx.BeginFunc(ctx, kn.Pool(), func(tx pgx.Tx) error {
+		return load(ctx, tx, fixtures)
+	})
+}
+
+// execer is the part of pgx.Tx and norm's executors used to load fixtures
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// ReadFixturesFS parses the fixture files under root of fsys (subdirectories are ignored) and expands
+// their templates, without touching the database
+func ReadFixturesFS(fsys fs.FS, root string) ([]Fixture, error) {
+	if root == "" {
+		root = "."
+	}
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+	var out []Fixture
+	seen := map[string]string{}
+	for _, e := range entries {
+		ext := path.Ext(e.Name())
+		if e.IsDir() || (ext != ".yml" && ext != ".yaml" && ext != ".json") {
+			continue
+		}
+		table := strings.TrimSuffix(e.Name(), ext)
+		if prev, ok := seen[table]; ok {
+			return nil, fmt.Errorf("fixtures: %s and %s both fill table %s", prev, e.Name(), table)
+		}
+		seen[table] = e.Name()
+		data, err := fs.ReadFile(fsys, path.Join(root, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		// JSON is a subset of YAML, so one decoder reads both
+		var rows []map[string]any
+		if err := yaml.Unmarshal(data, &rows); err != nil {
+			return nil, fmt.Errorf("fixtures: %s: %w", e.Name(), err)
+		}
+		for i, row := range rows {
+			for col, v := range row {
+				s, ok := v.(string)
+				if !ok {
+					continue
+				}
+				if row[col], err = ExpandTemplate(s); err != nil {
+					return nil, fmt.Errorf("fixtures: %s row %d column %s: %w", e.Name(), i+1, col, err)
+				}
+			}
+		}
+		out = append(out, Fixture{Table: table, Rows: rows})
+	}
+	return out, nil
+}
+
+var (
+	templateRe = regexp.MustCompile(`^\{\{\s*(.*?)\s*\}\}$`)
+	nowRe      = regexp.MustCompile(`^now\(\)(?:\s*([+-])\s*(\S+))?$`)
+)
+
+// ExpandTemplate evaluates a "{{ ... }}" fixture value; other strings are returned as-is. Supported:
+//
+//	{{ now() }}          the current time
+//	{{ now() - 36h }}    the current time moved by a time.ParseDuration offset
+//	{{ uuid() }}         a random (version 4) UUID
+func ExpandTemplate(s string) (any, error) {
+	m := templateRe.FindStringSubmatch(s)
+	if m == nil {
+		return s, nil
+	}
+	expr := m[1]
+	if expr == "uuid()" {
+		return newUUID(), nil
+	}
+	if n := nowRe.FindStringSubmatch(expr); n != nil {
+		now := time.Now()
+		if n[1] == "" {
+			return now, nil
+		}
+		d, err := time.ParseDuration(n[2])
+		if err != nil {
+			return nil, fmt.Errorf("template %q: %w", s, err)
+		}
+		if n[1] == "-" {
+			d = -d
+		}
+		return now.Add(d), nil
+	}
+	return nil, fmt.Errorf("unknown template %q", s)
+}
+
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// load truncates and fills the fixture tables, running its statements on tx
+func load(ctx context.Context, tx execer, fixtures []Fixture) error {
+	// resolve names the way the catalog reports them, following search_path
+	names := make([]string, len(fixtures))
+	byName := map[string]Fixture{}
+	for i, f := range fixtures {
+		var name *string
+		if err := tx.QueryRow(ctx, `SELECT to_regclass($1)::text`, quoteQualified(f.Table)).Scan(&name); err != nil {
+			return err
+		}
+		if name == nil {
+			return fmt.Errorf("fixtures: table %s does not exist", f.Table)
+		}
+		names[i] = *name
+		byName[names[i]] = f
+	}
+	rows, err := tx.Query(ctx, `SELECT conrelid::regclass::text, confrelid::regclass::text FROM pg_constraint WHERE contype = 'f' AND conrelid <> confrelid`)
+	if err != nil {
+		return err
+	}
+	var deps [][2]string
+	for rows.Next() {
+		var child, parent string
+		if err := rows.Scan(&child, &parent); err != nil {
+			rows.Close()
+			return err
+		}
+		deps = append(deps, [2]string{child, parent})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	order, err := insertOrder(names, deps)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "TRUNCATE "+strings.Join(names, ", ")+" RESTART IDENTITY"); err != nil {
+		return fmt.Errorf("fixtures: truncate: %w", err)
+	}
+	for _, name := range order {
+		f := byName[name]
+		filled := map[string]bool{}
+		for i, row := range f.Rows {
+			stmt, args := insertStatement(name, row)
+			if _, err := tx.Exec(ctx, stmt, args...); err != nil {
+				return fmt.Errorf("fixtures: %s row %d: %w", f.Table, i+1, err)
+			}
+			for col := range row {
+				filled[col] = true
+			}
+		}
+		for col := range filled {
+			var seq *string
+			if err := tx.QueryRow(ctx, `SELECT pg_get_serial_sequence($1, $2)`, name, col).Scan(&seq); err != nil {
+				return err
+			}
+			if seq == nil {
+				continue
+			}
+			c := norm.QuoteIdentifier(col)
+			if _, err := tx.Exec(ctx, `SELECT setval($1, (SELECT max(`+c+`) FROM `+name+`))`, *seq); err != nil {
+				return fmt.Errorf("fixtures: sequence of %s.%s: %w", f.Table, col, err)
+			}
+		}
+	}
+	return nil
+}
+
+// insertOrder sorts tables so that every table comes after the tables its foreign keys reference
+// (deps holds child, parent pairs; pairs involving other tables are ignored). Ties keep name order.
+func insertOrder(tables []string, deps [][2]string) ([]string, error) {
+	parents := map[string][]string{}
+	for _, d := range deps {
+		if slices.Contains(tables, d[0]) && slices.Contains(tables, d[1]) {
+			parents[d[0]] = append(parents[d[0]], d[1])
+		}
+	}
+	pending := slices.Sorted(slices.Values(tables))
+	done := map[string]bool{}
+	order := make([]string, 0, len(tables))
+	for len(pending) > 0 {
+		i := slices.IndexFunc(pending, func(t string) bool {
+			return !slices.ContainsFunc(parents[t], func(p string) bool { return !done[p] })
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("fixtures: foreign keys form a cycle between %s", strings.Join(pending, ", "))
+		}
+		done[pending[i]] = true
+		order = append(order, pending[i])
+		pending = slices.Delete(pending, i, i+1)
+	}
+	return order, nil
+}
+
+// insertStatement renders the INSERT of one row, columns in name order
+func insertStatement(table string, row map[string]any) (string, []any) {
+	cols := slices.Sorted(maps.Keys(row))
+	quoted := make([]string, len(cols))
+	marks := make([]string, len(cols))
+	args := make([]any, len(cols))
+	for i, c := range cols {
+		quoted[i] = norm.QuoteIdentifier(c)
+		marks[i] = fmt.Sprintf("$%d", i+1)
+		args[i] = row[c]
+	}
+	if len(cols) == 0 {
+		return "INSERT INTO " + table + " DEFAULT VALUES", nil
+	}
+	return "INSERT INTO " + table + " (" + strings.Join(quoted, ", ") + ") VALUES (" + strings.Join(marks, ", ") + ")", args
+}
+
+// quoteQualified quotes each part of a possibly schema-qualified name
+func quoteQualified(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = norm.QuoteIdentifier(p)
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,67 @@
+package normtest
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+func TestReadFixturesFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"fixtures/users.yml":             {Data: []byte("- id: 1\n  email: a@example.com\n  created_at: \"{{ now() - 1h }}\"\n- id: 2\n  token: \"{{uuid()}}\"\n")},
+		"fixtures/billing.invoices.json": {Data: []byte(`[{"id": 10, "user_id": 1, "meta": {"paid": true}}]`)},
+		"fixtures/README.md":             {Data: []byte("not a fixture")},
+	}
+	fixtures, err := ReadFixturesFS(fsys, "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fixtures) != 2 || fixtures[0].Table != "billing.invoices" || fixtures[1].Table != "users" {
+		t.Fatalf("fixtures: %+v", fixtures)
+	}
+	if inv := fixtures[0].Rows[0]; inv["id"] != 10 || inv["meta"].(map[string]any)["paid"] != true {
+		t.Fatalf("invoice: %+v", inv)
+	}
+	users := fixtures[1].Rows
+	if at, ok := users[0]["created_at"].(time.Time); !ok || time.Since(at) < 59*time.Minute {
+		t.Fatalf("created_at: %v", users[0]["created_at"])
+	}
+	if tok, _ := users[1]["token"].(string); len(tok) != 36 || tok[14] != '4' {
+		t.Fatalf("token: %v", users[1]["token"])
+	}
+}
+
+func TestReadFixturesFS_Errors(t *testing.T) {
+	if _, err := ReadFixturesFS(fstest.MapFS{"users.yml": {Data: []byte("- id: \"{{ random() }}\"")}}, ""); err == nil || !strings.Contains(err.Error(), "users.yml row 1 column id") {
+		t.Fatalf("unknown template: %v", err)
+	}
+	if _, err := ReadFixturesFS(fstest.MapFS{"users.yml": {}, "users.json": {}}, "."); err == nil {
+		t.Fatal("expected duplicate table error")
+	}
+}
+
+func TestInsertOrder(t *testing.T) {
+	deps := [][2]string{{"orders", "users"}, {"order_items", "orders"}, {"order_items", "products"}, {"audit", "other"}}
+	order, err := insertOrder([]string{"order_items", "users", "orders", "products", "audit"}, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"audit", "products", "users", "orders", "order_items"}; !slices.Equal(order, want) {
+		t.Fatalf("order %v", order)
+	}
+	if _, err := insertOrder([]string{"a", "b"}, [][2]string{{"a", "b"}, {"b", "a"}}); err == nil {
+		t.Fatal("expected cycle error")
+	}
+}
+
+func TestInsertStatement(t *testing.T) {
+	stmt, args := insertStatement("billing.invoices", map[string]any{"user_id": 1, "id": 10})
+	if stmt != `INSERT INTO billing.invoices ("id", "user_id") VALUES ($1, $2)` || !slices.Equal(args, []any{10, 1}) {
+		t.Fatalf("%s %v", stmt, args)
+	}
+	if stmt, _ := insertStatement("t", nil); stmt != "INSERT INTO t DEFAULT VALUES" {
+		t.Fatal(stmt)
+	}
+}
@@ -0,0 +1,20 @@
+module github.com/kintsdev/norm/normtest
+
+go 1.26
+
+require (
+	github.com/jackc/pgx/v5 v5.9.1
+	github.com/kintsdev/norm v0.0.0-00010101000000-000000000000
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/jackc/pgpassfile v1.0.0 // indirect
+	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
+	github.com/jackc/puddle/v2 v2.2.2 // indirect
+	golang.org/x/sync v0.22.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+)
+
+// resolve norm from this checkout during local development
+replace github.com/kintsdev/norm => ../
@@ -0,0 +1,81 @@
+package normtest
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/kintsdev/norm"
+)
+
+// DB runs a test inside one transaction that is rolled back when the test ends, so tests sharing a
+// database do not see each other's writes and need no cleanup
+type DB struct {
+	kn  *norm.KintsNorm
+	tx  norm.Transaction
+	ctx context.Context
+}
+
+// New begins the transaction of t on kn and registers its rollback with t.Cleanup.
+//
+// Statements join the transaction when they are called with Context: repositories from NewRepository
+// and builders from Query or Model pick up the transaction it carries (see norm.WithTx), as does
+// WithContextTransaction, which then reuses it instead of committing. Code that bypasses the context,
+// such as kn.Tx(), kn.Pool() or migrations, runs outside the transaction and is not rolled back.
+//
+//	func TestCreateOrder(t *testing.T) {
+//		db := normtest.New(t, kn)
+//		repo := norm.NewRepository[Order](kn)
+//		if err := repo.Create(db.Context(), &Order{Total: 10}); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+//
+// A failed statement aborts the transaction, like any PostgreSQL transaction: later statements of the
+// test fail until it ends.
+func New(t testing.TB, kn *norm.KintsNorm) *DB {
+	t.Helper()
+	tx, err := kn.Tx().BeginTx(context.Background(), &norm.TxOptions{})
+	if err != nil {
+		t.Fatalf("normtest: begin: %v", err)
+	}
+	t.Cleanup(func() {
+		// t.Context() is already canceled when cleanups run
+		if err := tx.Rollback(context.Background()); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			t.Errorf("normtest: rollback: %v", err)
+		}
+	})
+	return &DB{kn: kn, tx: tx, ctx: norm.WithTx(t.Context(), tx)}
+}
+
+// Context returns the test's context carrying the transaction; it is canceled when the test ends
+func (db *DB) Context() context.Context { return db.ctx }
+
+// Tx returns the transaction of the test
+func (db *DB) Tx() norm.Transaction { return db.tx }
+
+// Query returns a query builder bound to the transaction, whatever context it is called with
+func (db *DB) Query() *norm.QueryBuilder { return db.tx.Query() }
+
+// Model is Query().Model(model)
+func (db *DB) Model(model any) *norm.QueryBuilder { return db.tx.Query().Model(model) }
+
+// LoadFixtures is the package-level LoadFixtures run inside the transaction, so the truncation and the
+// rows are rolled back with the test. TRUNCATE locks the fixture tables until then, which serializes
+// tests loading fixtures into the same tables.
+func (db *DB) LoadFixtures(dir string) error {
+	return db.LoadFixturesFS(os.DirFS(dir), ".")
+}
+
+// LoadFixturesFS is LoadFixtures reading the files under root of fsys
+func (db *DB) LoadFixturesFS(fsys fs.FS, root string) error {
+	fixtures, err := ReadFixturesFS(fsys, root)
+	if err != nil || len(fixtures) == 0 {
+		return err
+	}
+	return load(db.ctx, db.tx.Exec(), fixtures)
+}
@@ -0,0 +1,38 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// ErrOptimisticLock is wrapped (in an ORMError with ErrCodeTransaction) when a versioned update matches no row:
+// the row changed since it was read, or it no longer exists
+var ErrOptimisticLock = errors.New("optimistic lock conflict")
+
+func optimisticLockError(query string, args []any) error {
+	return &ORMError{Code: ErrCodeTransaction, Message: ErrOptimisticLock.Error(), Internal: ErrOptimisticLock, Query: query, Args: args}
+}
+
+// UpdateStructByPKVersion is UpdateStructByPK under optimistic locking. The entity's `norm:"version"` column
+// is incremented instead of written, and the row is updated only while it still holds version; otherwise
+// ErrOptimisticLock is returned.
+func (qb *QueryBuilder) UpdateStructByPKVersion(ctx context.Context, entity any, pkColumn string, version any) (int64, error) {
+	t := reflect.Indirect(reflect.ValueOf(entity)).Type()
+	versionCol := core.StructMapper(t).VersionColumn
+	if versionCol == "" {
+		return 0, &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("no version column on %s", t.Name())}
+	}
+	n, err := qb.updateStructByPK(ctx, entity, pkColumn, versionCol, version)
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		query, args := qb.buildUpdate()
+		return 0, optimisticLockError(query, args)
+	}
+	return n, nil
+}
@@ -0,0 +1,68 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// lockExec records statements and reports affected rows as UPDATE <affected>
+type lockExec struct {
+	affected string
+	lastSQL  string
+	lastArgs []any
+}
+
+func (e *lockExec) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	e.lastSQL, e.lastArgs = sql, args
+	return pgconn.NewCommandTag("UPDATE " + e.affected), nil
+}
+func (e *lockExec) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return &fakeRowsRU{}, nil
+}
+func (e *lockExec) QueryRow(context.Context, string, ...any) pgx.Row { return errorRow{} }
+
+func TestUpdatePartialVersion_SQL(t *testing.T) {
+	ex := &lockExec{affected: "1"}
+	r := &repo[repUser]{kn: &KintsNorm{}, exec: ex}
+	if err := r.UpdatePartialVersion(context.Background(), 7, int64(3), map[string]any{"name": "b"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	want := `UPDATE rep_users SET "name" = $1, "version" = "version" + 1 WHERE "id" = $2 AND "version" = $3`
+	if ex.lastSQL != want || len(ex.lastArgs) != 3 || ex.lastArgs[2] != int64(3) {
+		t.Fatalf("sql=%q args=%v", ex.lastSQL, ex.lastArgs)
+	}
+}
+
+func TestUpdatePartialVersion_Conflict(t *testing.T) {
+	r := &repo[repUser]{kn: &KintsNorm{}, exec: &lockExec{affected: "0"}}
+	err := r.UpdatePartialVersion(context.Background(), 7, int64(3), map[string]any{"name": "b"})
+	if !errors.Is(err, ErrOptimisticLock) {
+		t.Fatalf("want ErrOptimisticLock, got %v", err)
+	}
+	if err := r.UpdatePartialVersion(context.Background(), 7, int64(3), map[string]any{"version": 9}); err == nil {
+		t.Fatalf("explicit version should be rejected")
+	}
+}
+
+func TestUpdateStructByPKVersion(t *testing.T) {
+	ex := &lockExec{affected: "0"}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: ex}).Table("rep_users")
+	_, err := qb.UpdateStructByPKVersion(context.Background(), &repUser{ID: 1, Name: "a", Version: 4}, "id", int64(4))
+	if !errors.Is(err, ErrOptimisticLock) {
+		t.Fatalf("want ErrOptimisticLock, got %v", err)
+	}
+	if !strings.Contains(ex.lastSQL, `"version" = "version" + 1`) || !strings.HasSuffix(ex.lastSQL, `"version" = $3`) {
+		t.Fatalf("sql=%q", ex.lastSQL)
+	}
+	type plain struct {
+		ID int64 `db:"id"`
+	}
+	if _, err := (&QueryBuilder{kn: &KintsNorm{}, exec: ex}).Table("p").UpdateStructByPKVersion(context.Background(), &plain{ID: 1}, "id", 1); err == nil {
+		t.Fatalf("expected error without version column")
+	}
+}
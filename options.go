@@ -3,6 +3,8 @@ package norm
 import (
 	"context"
 	"time"
+
+	"github.com/jackc/pgx/v5"
 )
 
 type options struct {
@@ -14,22 +16,51 @@ type options struct {
 	logContextFields   func(ctx context.Context) []Field
 	slowQueryThreshold time.Duration
 	maskParams         bool
+	// warn for statements started this close to their context deadline (0 = disabled)
+	deadlineWarning time.Duration
 	// audit
 	auditHook AuditHook
+	// pgx connection setup
+	typeRegistrations []func(conn *pgx.Conn) error
+	// query builder SQL template cache
+	queryTemplateCacheSize int
+	// rows per multi-row INSERT in CreateBatch (0 = default)
+	batchSize int
+	// latency-driven chunk sizing for CreateBatch/UpsertBatch/CreateCopyFrom (nil = fixed)
+	adaptiveBatch *AdaptiveBatchConfig
+	// statement guard rules checked before execution
+	guardRules []QueryGuardRule
+	// reuse scan buffers across Repository.Find calls
+	findBufferPool bool
+	// schema of tenant X is tenantSchemaPrefix + X
+	tenantSchemaPrefix string
+	// interceptors wrapped around every statement, outermost first
+	interceptors []Interceptor
+	// parent spans for Tx() transactions (nil = disabled)
+	txTracer TxTracer
+	// NOTIFY channel fanning out cache invalidations ("" = local only)
+	cacheChannel string
+	// fills zero primary keys on insert (nil = disabled)
+	idGenerator IDGenerator
+	// periodic Stats push (nil = disabled)
+	statsCallback func(Stats)
+	statsInterval time.Duration
 }
 
 type Option func(*options)
 
 func defaultOptions() options {
 	return options{
-		logger:             NoopLogger{},
-		metrics:            NoopMetrics{},
-		cache:              nil,
-		logMode:            LogSilent,
-		logContextFields:   nil,
-		slowQueryThreshold: 0,
-		maskParams:         false,
-		auditHook:          nil,
+		logger:                 NoopLogger{},
+		metrics:                NoopMetrics{},
+		cache:                  nil,
+		logMode:                LogSilent,
+		logContextFields:       nil,
+		slowQueryThreshold:     0,
+		maskParams:             false,
+		auditHook:              nil,
+		queryTemplateCacheSize: defaultQueryTemplateCacheSize,
+		tenantSchemaPrefix:     defaultTenantSchemaPrefix,
 	}
 }
 
@@ -59,3 +90,75 @@ func WithLogParameterMasking(mask bool) Option {
 func WithAuditHook(hook AuditHook) Option {
 	return func(o *options) { o.auditHook = hook }
 }
+
+// WithQueryTemplateCacheSize bounds the number of builder shapes whose compiled SQL is reused (0 disables the cache)
+func WithQueryTemplateCacheSize(n int) Option {
+	return func(o *options) { o.queryTemplateCacheSize = n }
+}
+
+// WithBatchSize sets how many rows Repository.CreateBatch sends per multi-row INSERT (default 1000); chunks are
+// always kept under PostgreSQL's 65535 bind parameter limit
+func WithBatchSize(n int) Option {
+	return func(o *options) { o.batchSize = n }
+}
+
+// WithAdaptiveBatchSize lets CreateBatch, UpsertBatch and CreateCopyFrom tune their chunk size per table from the
+// observed latency of previous chunks, within cfg's bounds. It takes precedence over WithBatchSize.
+func WithAdaptiveBatchSize(cfg AdaptiveBatchConfig) Option {
+	return func(o *options) { o.adaptiveBatch = &cfg }
+}
+
+// WithFindBufferPool makes Repository.Find scan into pooled buffers and copy the rows into one exact-size
+// allocation, reducing GC pressure for high-throughput list endpoints
+func WithFindBufferPool(enabled bool) Option {
+	return func(o *options) { o.findBufferPool = enabled }
+}
+
+// WithInterceptor adds interceptors around every Exec/Query/QueryRow on the pool, the read pool and transactions
+// (tracing, auditing, query rewriting, retries). Interceptors run in the order added, across calls.
+func WithInterceptor(interceptors ...Interceptor) Option {
+	return func(o *options) { o.interceptors = append(o.interceptors, interceptors...) }
+}
+
+// WithStatsCallback calls fn with a Stats snapshot every interval (default 15s) until Close, e.g. to push
+// pool and breaker state to a monitoring system
+func WithStatsCallback(interval time.Duration, fn func(Stats)) Option {
+	return func(o *options) { o.statsInterval, o.statsCallback = interval, fn }
+}
+
+// WithTenantSchemaPrefix sets the prefix of per-tenant schemas (default "tenant_"): ForTenant("acme") uses
+// schema prefix+"acme". An empty prefix names schemas after the tenants themselves.
+func WithTenantSchemaPrefix(prefix string) Option {
+	return func(o *options) { o.tenantSchemaPrefix = prefix }
+}
+
+// WithQueryGuard rejects statements failing any rule (DenyDDL, DenyUnfilteredWrites, AllowSchemas, ...) with
+// ErrCodeValidation before they reach the database, as a safety net for dynamic query features
+func WithQueryGuard(rules ...QueryGuardRule) Option {
+	return func(o *options) { o.guardRules = append(o.guardRules, rules...) }
+}
+
+// WithTypeRegistrations registers functions run on every new connection of both the primary and read pools
+// (pgxpool AfterConnect), e.g. to register codecs for composite types, domains or extensions like ltree/hstore
+func WithTypeRegistrations(fns ...func(conn *pgx.Conn) error) Option {
+	return func(o *options) { o.typeRegistrations = append(o.typeRegistrations, fns...) }
+}
+
+// afterConnect combines registered type registrations into a single pool hook (nil when none)
+func (o options) afterConnect() connHook {
+	if len(o.typeRegistrations) == 0 {
+		return nil
+	}
+	fns := o.typeRegistrations
+	return func(_ context.Context, conn *pgx.Conn) error {
+		for _, fn := range fns {
+			if fn == nil {
+				continue
+			}
+			if err := fn(conn); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
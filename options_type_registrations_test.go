@@ -0,0 +1,26 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestWithTypeRegistrations(t *testing.T) {
+	o := defaultOptions()
+	if o.afterConnect() != nil {
+		t.Fatalf("expected nil hook without registrations")
+	}
+	calls := 0
+	WithTypeRegistrations(func(*pgx.Conn) error { calls++; return nil })(&o)
+	WithTypeRegistrations(func(*pgx.Conn) error { calls++; return errors.New("boom") }, func(*pgx.Conn) error { calls++; return nil })(&o)
+	hook := o.afterConnect()
+	if hook == nil {
+		t.Fatalf("expected hook")
+	}
+	if err := hook(context.Background(), nil); err == nil || calls != 2 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+}
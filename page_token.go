@@ -0,0 +1,169 @@
+package norm
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// ErrInvalidPageToken is wrapped (in an ORMError with ErrCodeValidation) when a page token is malformed,
+// was not issued by the codec, or was issued for a different filter
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// pageTokenMACSize is the number of HMAC-SHA256 bytes appended to a token
+const pageTokenMACSize = 16
+
+// PageTokenCodec issues opaque page tokens in the style of Google AIP-158. A token carries a keyset cursor
+// (the ordered column values of the last row served) and a hash of the request filter; it is signed, so
+// clients can neither forge cursors nor reuse a token after changing the filter.
+//
+//	codec := norm.NewPageTokenCodec(secret)
+//	qb, err := codec.Apply(db.Query().Table("users").OrderBy("id ASC").Limit(50), req.PageToken, []string{"id"}, req.Filter, req.OrderBy)
+//	...
+//	next, _ := codec.Encode([]any{last.ID}, req.Filter, req.OrderBy) // when the page was full
+type PageTokenCodec struct {
+	secret []byte
+}
+
+// NewPageTokenCodec returns a codec signing tokens with secret. Use the same secret on every instance that
+// may receive a token; an empty secret still detects corruption and filter changes, but not forgery.
+func NewPageTokenCodec(secret []byte) *PageTokenCodec {
+	return &PageTokenCodec{secret: bytes.Clone(secret)}
+}
+
+type pageTokenPayload struct {
+	Filter string `json:"f"`
+	Cursor []any  `json:"c"`
+}
+
+// filterHash identifies the request parameters a token is bound to (filter, order_by, ...)
+func filterHash(filter []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(filter, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (c *PageTokenCodec) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, c.secret)
+	h.Write(payload)
+	return h.Sum(nil)[:pageTokenMACSize]
+}
+
+// Encode returns the token resuming after cursor for the given filter parts. Cursor values are stored as
+// JSON: numbers decode as int64 (or float64), time.Time values as RFC 3339 strings, which Postgres casts back.
+func (c *PageTokenCodec) Encode(cursor []any, filter ...string) (string, error) {
+	payload, err := json.Marshal(pageTokenPayload{Filter: filterHash(filter), Cursor: cursor})
+	if err != nil {
+		return "", &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("encode page token: %s", err.Error()), Internal: err}
+	}
+	return base64.RawURLEncoding.EncodeToString(append(payload, c.mac(payload)...)), nil
+}
+
+// Decode validates token against the current filter parts and returns its cursor. An empty token is the
+// first page and yields a nil cursor.
+func (c *PageTokenCodec) Decode(token string, filter ...string) ([]any, error) {
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(raw) <= pageTokenMACSize {
+		return nil, invalidPageToken("malformed")
+	}
+	payload, sig := raw[:len(raw)-pageTokenMACSize], raw[len(raw)-pageTokenMACSize:]
+	if !hmac.Equal(sig, c.mac(payload)) {
+		return nil, invalidPageToken("bad signature")
+	}
+	var p pageTokenPayload
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&p); err != nil {
+		return nil, invalidPageToken("malformed")
+	}
+	if p.Filter != filterHash(filter) {
+		return nil, invalidPageToken("filter changed since the token was issued")
+	}
+	for i, v := range p.Cursor {
+		if n, ok := v.(json.Number); ok {
+			if iv, err := n.Int64(); err == nil {
+				p.Cursor[i] = iv
+			} else {
+				p.Cursor[i], _ = n.Float64()
+			}
+		}
+	}
+	return p.Cursor, nil
+}
+
+// Apply decodes token and continues qb after its cursor using keyset pagination on columns, which must match
+// the builder's ORDER BY term for term. Each column compares in its own term's direction: when all terms
+// share one the cursor compares as a row value, (a, b) > (?, ?), or < for DESC; mixed directions expand to
+// a > ? OR (a = ? AND b < ?). An empty token leaves qb unchanged.
+func (c *PageTokenCodec) Apply(qb *QueryBuilder, token string, columns []string, filter ...string) (*QueryBuilder, error) {
+	cursor, err := c.Decode(token, filter...)
+	if err != nil || cursor == nil {
+		return qb, err
+	}
+	if len(cursor) != len(columns) {
+		return qb, invalidPageToken(fmt.Sprintf("cursor has %d values, want %d", len(cursor), len(columns)))
+	}
+	terms := core.SplitTagTokens(qb.orderBy)
+	cols := make([]string, len(columns))
+	desc := make([]bool, len(columns))
+	mixed := false
+	for i, col := range columns {
+		cols[i] = quoteQualified(col)
+		if i < len(terms) {
+			desc[i] = orderTermDesc(terms[i])
+		}
+		mixed = mixed || desc[i] != desc[0]
+	}
+	if len(cols) == 1 {
+		if desc[0] {
+			return qb.WhereCond(Lt(cols[0], cursor[0])), nil
+		}
+		return qb.WhereCond(Gt(cols[0], cursor[0])), nil
+	}
+	if !mixed {
+		if desc[0] {
+			return qb.WhereCond(TupleLt(cols, cursor)), nil
+		}
+		return qb.WhereCond(TupleGt(cols, cursor)), nil
+	}
+	// (a, b) > (x, y) needs one direction; spell it out per column instead
+	alts := make([]Condition, len(cols))
+	for i := range cols {
+		conds := make([]Condition, 0, i+1)
+		for j := 0; j < i; j++ {
+			conds = append(conds, Eq(cols[j], cursor[j]))
+		}
+		if desc[i] {
+			conds = append(conds, Lt(cols[i], cursor[i]))
+		} else {
+			conds = append(conds, Gt(cols[i], cursor[i]))
+		}
+		alts[i] = And(conds...)
+	}
+	return qb.WhereCond(Or(alts...)), nil
+}
+
+// orderTermDesc reports whether an ORDER BY term such as "created_at DESC NULLS LAST" sorts descending
+func orderTermDesc(term string) bool {
+	fields := strings.Fields(strings.ToLower(term))
+	for i := len(fields) - 1; i > 0; i-- {
+		if f := fields[i]; f != "nulls" && f != "first" && f != "last" {
+			return f == "desc"
+		}
+	}
+	return false
+}
+
+func invalidPageToken(reason string) error {
+	return &ORMError{Code: ErrCodeValidation, Message: ErrInvalidPageToken.Error() + ": " + reason, Internal: ErrInvalidPageToken}
+}
@@ -0,0 +1,87 @@
+package norm
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPageTokenCodec_RoundTrip(t *testing.T) {
+	c := NewPageTokenCodec([]byte("s3cret"))
+	tok, err := c.Encode([]any{int64(9007199254740993), "b", 1.5}, "status=active", "id asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Decode(tok, "status=active", "id asc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(got, []any{int64(9007199254740993), "b", 1.5}) {
+		t.Fatalf("cursor %#v", got)
+	}
+	if cur, err := c.Decode(""); cur != nil || err != nil {
+		t.Fatalf("empty token: %v %v", cur, err)
+	}
+}
+
+func TestPageTokenCodec_Rejects(t *testing.T) {
+	c := NewPageTokenCodec([]byte("s3cret"))
+	tok, _ := c.Encode([]any{int64(10)}, "status=active")
+	other, _ := NewPageTokenCodec([]byte("other")).Encode([]any{int64(10)}, "status=active")
+	for name, tc := range map[string]struct{ token, filter string }{
+		"filter":    {tok, "status=trial"},
+		"forged":    {other, "status=active"},
+		"malformed": {"!!", "status=active"},
+		"truncated": {tok[:len(tok)-3], "status=active"},
+	} {
+		_, err := c.Decode(tc.token, tc.filter)
+		var oe *ORMError
+		if !errors.Is(err, ErrInvalidPageToken) || !errors.As(err, &oe) || oe.Code != ErrCodeValidation {
+			t.Fatalf("%s: got %v", name, err)
+		}
+	}
+}
+
+func TestPageTokenCodec_Apply(t *testing.T) {
+	c := NewPageTokenCodec(nil)
+	tok, _ := c.Encode([]any{"2026-01-02T00:00:00Z", int64(7)})
+	qb, err := c.Apply((&QueryBuilder{}).Table("events").OrderBy("created_at DESC, id DESC").Limit(20), tok, []string{"created_at", "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, args := qb.buildSelect()
+	if !strings.Contains(sql, `WHERE ("created_at", "id") < ($1, $2)`) || len(args) != 2 || args[1] != int64(7) {
+		t.Fatalf("sql %q args %v", sql, args)
+	}
+	single, _ := c.Encode([]any{int64(7)})
+	qb, _ = c.Apply((&QueryBuilder{}).Table("events").OrderBy("id ASC"), single, []string{"id"})
+	if sql, _ := qb.buildSelect(); !strings.Contains(sql, `"id" > $1`) {
+		t.Fatalf("sql %q", sql)
+	}
+	if _, err := c.Apply(&QueryBuilder{}, single, []string{"created_at", "id"}); !errors.Is(err, ErrInvalidPageToken) {
+		t.Fatalf("length mismatch: %v", err)
+	}
+}
+
+func TestPageTokenCodec_ApplyPerTermDirection(t *testing.T) {
+	c := NewPageTokenCodec(nil)
+	tok, _ := c.Encode([]any{"2026-01-02T00:00:00Z", int64(7)})
+	qb, err := c.Apply((&QueryBuilder{}).Table("events").OrderBy("created_at DESC, id ASC"), tok, []string{"created_at", "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql, args := qb.buildSelect()
+	if !strings.Contains(sql, `WHERE (("created_at" < $1)) OR (("created_at" = $2) AND ("id" > $3))`) || len(args) != 3 || args[2] != int64(7) {
+		t.Fatalf("sql %q args %v", sql, args)
+	}
+	qb, _ = c.Apply((&QueryBuilder{}).Table("events").OrderBy("created_at ASC, id DESC NULLS LAST"), tok, []string{"created_at", "id"})
+	if sql, _ := qb.buildSelect(); !strings.Contains(sql, `("created_at" > $1)) OR (("created_at" = $2) AND ("id" < $3))`) {
+		t.Fatalf("sql %q", sql)
+	}
+	single, _ := c.Encode([]any{int64(7)})
+	qb, _ = c.Apply((&QueryBuilder{}).Table("events").OrderBy("id DESC, name ASC"), single, []string{"id"})
+	if sql, _ := qb.buildSelect(); !strings.Contains(sql, `WHERE "id" < $1`) {
+		t.Fatalf("sql %q", sql)
+	}
+}
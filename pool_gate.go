@@ -0,0 +1,199 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrPoolExhausted is wrapped (in an ORMError with ErrCodeConnection) when no connection could be acquired
+// within Config.AcquireTimeout, or Config.MaxWaiters callers are already queued on a saturated pool
+var ErrPoolExhausted = errors.New("connection pool exhausted")
+
+// PoolMetrics can optionally be implemented by a Metrics collector to count requests failed with
+// ErrPoolExhausted; reason is "timeout" or "max_waiters"
+type PoolMetrics interface {
+	PoolExhausted(reason string)
+}
+
+// poolGate bounds how long and how many callers wait for a connection of one pool
+type poolGate struct {
+	timeout    time.Duration
+	maxWaiters int64
+	waiters    atomic.Int64
+	saturated  func() bool // reports whether every connection is in use
+	metrics    Metrics
+}
+
+// newPoolGate returns the gate of p configured by cfg, or nil when neither limit is set
+func newPoolGate(cfg *Config, p *pgxpool.Pool, metrics Metrics) *poolGate {
+	if cfg == nil || p == nil || (cfg.AcquireTimeout <= 0 && cfg.MaxWaiters <= 0) {
+		return nil
+	}
+	return &poolGate{
+		timeout:    cfg.AcquireTimeout,
+		maxWaiters: int64(cfg.MaxWaiters),
+		saturated: func() bool {
+			st := p.Stat()
+			return st.AcquiredConns() >= st.MaxConns()
+		},
+		metrics: metrics,
+	}
+}
+
+// acquire runs acquire within the gate's limits. It fails fast when too many callers already wait on a
+// saturated pool, and turns an acquire cut short by AcquireTimeout into ErrPoolExhausted; cancellation of
+// ctx itself is returned unchanged.
+func (g *poolGate) acquire(ctx context.Context, acquire func(ctx context.Context) error) error {
+	n := g.waiters.Add(1)
+	defer g.waiters.Add(-1)
+	if g.maxWaiters > 0 && n > g.maxWaiters && g.saturated() {
+		return g.exhausted("max_waiters", fmt.Sprintf("%d callers already waiting for a connection", n-1))
+	}
+	if g.timeout <= 0 {
+		return acquire(ctx)
+	}
+	actx, cancel := context.WithTimeout(ctx, g.timeout)
+	defer cancel()
+	err := acquire(actx)
+	if err != nil && ctx.Err() == nil && errors.Is(actx.Err(), context.DeadlineExceeded) {
+		return g.exhausted("timeout", fmt.Sprintf("no connection within %s", g.timeout))
+	}
+	return err
+}
+
+func (g *poolGate) exhausted(reason, detail string) error {
+	if pm, ok := g.metrics.(PoolMetrics); ok {
+		pm.PoolExhausted(reason)
+	}
+	return &ORMError{Code: ErrCodeConnection, Message: ErrPoolExhausted.Error() + ": " + detail, Internal: ErrPoolExhausted}
+}
+
+// poolExecuter is a pool as used by norm: statements and new transactions
+type poolExecuter interface {
+	dbExecuter
+	txBeginner
+}
+
+// poolExec returns p, or p behind its acquire gate when Config.AcquireTimeout or Config.MaxWaiters is set
+func (kn *KintsNorm) poolExec(p *pgxpool.Pool) poolExecuter {
+	if kn != nil {
+		if g := kn.gates[p]; g != nil {
+			return gatedPool{pool: p, gate: g}
+		}
+	}
+	return p
+}
+
+// acquire checks out a connection of p through its acquire gate, if any
+func (kn *KintsNorm) acquire(ctx context.Context, p *pgxpool.Pool) (*pgxpool.Conn, error) {
+	if kn == nil || kn.gates[p] == nil {
+		return p.Acquire(ctx)
+	}
+	return gatedPool{pool: p, gate: kn.gates[p]}.conn(ctx)
+}
+
+// gatedPool runs every statement on a connection acquired explicitly through gate, released once the
+// statement (or its rows, or its transaction) is done, the way pgxpool does it internally
+type gatedPool struct {
+	pool *pgxpool.Pool
+	gate *poolGate
+}
+
+func (g gatedPool) conn(ctx context.Context) (*pgxpool.Conn, error) {
+	var c *pgxpool.Conn
+	err := g.gate.acquire(ctx, func(ctx context.Context) (err error) {
+		c, err = g.pool.Acquire(ctx)
+		return err
+	})
+	return c, err
+}
+
+func (g gatedPool) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	c, err := g.conn(ctx)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	defer c.Release()
+	return c.Exec(ctx, sql, arguments...)
+}
+
+func (g gatedPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	c, err := g.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := c.Query(ctx, sql, args...)
+	if err != nil {
+		c.Release()
+		return nil, err
+	}
+	return &gatedRows{Rows: rows, release: c.Release}, nil
+}
+
+func (g gatedPool) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	c, err := g.conn(ctx)
+	if err != nil {
+		return errorRow{err: err}
+	}
+	return &rowWithAfter{Row: c.QueryRow(ctx, sql, args...), after: func(error) { c.Release() }}
+}
+
+func (g gatedPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	c, err := g.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.Begin(ctx)
+	if err != nil {
+		c.Release()
+		return nil, err
+	}
+	return &gatedTx{Tx: tx, release: c.Release}, nil
+}
+
+// gatedRows releases the connection when the rows are closed or exhausted
+type gatedRows struct {
+	pgx.Rows
+	release func()
+	once    sync.Once
+}
+
+func (r *gatedRows) Next() bool {
+	if r.Rows.Next() {
+		return true
+	}
+	r.Close()
+	return false
+}
+
+func (r *gatedRows) Close() {
+	r.Rows.Close()
+	r.once.Do(r.release)
+}
+
+// gatedTx releases the connection once the transaction is committed or rolled back
+type gatedTx struct {
+	pgx.Tx
+	release func()
+	once    sync.Once
+}
+
+func (t *gatedTx) Commit(ctx context.Context) error {
+	err := t.Tx.Commit(ctx)
+	t.once.Do(t.release)
+	return err
+}
+
+func (t *gatedTx) Rollback(ctx context.Context) error {
+	err := t.Tx.Rollback(ctx)
+	t.once.Do(t.release)
+	return err
+}
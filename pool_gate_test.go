@@ -0,0 +1,83 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type exhaustedMetrics struct {
+	NoopMetrics
+	reasons []string
+}
+
+func (m *exhaustedMetrics) PoolExhausted(reason string) { m.reasons = append(m.reasons, reason) }
+
+func TestPoolGate_AcquireTimeout(t *testing.T) {
+	m := &exhaustedMetrics{}
+	g := &poolGate{timeout: 10 * time.Millisecond, saturated: func() bool { return true }, metrics: m}
+	block := func(ctx context.Context) error { <-ctx.Done(); return ctx.Err() }
+
+	err := g.acquire(context.Background(), block)
+	var oe *ORMError
+	if !errors.Is(err, ErrPoolExhausted) || !errors.As(err, &oe) || oe.Code != ErrCodeConnection {
+		t.Fatalf("err=%v", err)
+	}
+	if len(m.reasons) != 1 || m.reasons[0] != "timeout" {
+		t.Fatalf("reasons=%v", m.reasons)
+	}
+
+	// the caller's own cancellation is not pool exhaustion
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := g.acquire(ctx, block); errors.Is(err, ErrPoolExhausted) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("canceled: %v", err)
+	}
+	if err := g.acquire(context.Background(), func(context.Context) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPoolGate_MaxWaiters(t *testing.T) {
+	m := &exhaustedMetrics{}
+	saturated := true
+	g := &poolGate{maxWaiters: 1, saturated: func() bool { return saturated }, metrics: m}
+	entered, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- g.acquire(context.Background(), func(context.Context) error { close(entered); <-release; return nil })
+	}()
+	<-entered
+
+	called := false
+	err := g.acquire(context.Background(), func(context.Context) error { called = true; return nil })
+	if !errors.Is(err, ErrPoolExhausted) || called {
+		t.Fatalf("second waiter: err=%v called=%v", err, called)
+	}
+	if len(m.reasons) != 1 || m.reasons[0] != "max_waiters" {
+		t.Fatalf("reasons=%v", m.reasons)
+	}
+	// with free connections the caller would not queue, so it is let through
+	saturated = false
+	if err := g.acquire(context.Background(), func(context.Context) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if g.waiters.Load() != 0 {
+		t.Fatalf("waiters=%d", g.waiters.Load())
+	}
+}
+
+func TestPoolExec_UngatedByDefault(t *testing.T) {
+	kn := &KintsNorm{}
+	if _, ok := kn.poolExec(nil).(gatedPool); ok {
+		t.Fatal("pool gated without limits")
+	}
+	if newPoolGate(&Config{}, nil, nil) != nil {
+		t.Fatal("gate without limits")
+	}
+}
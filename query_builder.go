@@ -16,18 +16,30 @@ import (
 
 // QueryBuilder provides a fluent API for building SQL queries
 type QueryBuilder struct {
-	kn      *KintsNorm
-	exec    dbExecuter
-	table   string
-	columns []string
-	joins   []string
-	wheres  []string
-	args    []any
-	orderBy string
-	limit   int
-	offset  int
-	raw     string
-	isRaw   bool
+	kn    *KintsNorm
+	exec  dbExecuter
+	table string
+	// ctes are WITH clauses (see With); their args come first
+	ctes []cteClause
+	// fromArgs bind the $n placeholders of a FROM subquery (see FromSub); they precede args
+	fromArgs []any
+	columns  []string
+	joins    []string
+	wheres   []string
+	args     []any
+	orderBy  string
+	// orderArgs bind the '?' placeholders of OrderByExpr; they come last
+	orderArgs []any
+	groupBy   []string
+	havings   []string
+	// havingArgs follow args (and keyset values) in placeholder order
+	havingArgs []any
+	limit      int
+	offset     int
+	// lock is the row-locking clause appended to SELECT (see ForUpdate)
+	lock  string
+	raw   string
+	isRaw bool
 	// write ops
 	op            string // "insert" | "update" | "delete"
 	deleteHard    bool   // when true, build hard DELETE instead of soft delete
@@ -45,15 +57,36 @@ type QueryBuilder struct {
 	// cache options
 	cacheKey   string
 	cacheTTL   time.Duration
+	cacheMode  qbCacheMode
 	invalidate []string
+	// cacheScope prefixes cache and invalidation keys of tenant/RLS builders (see cacheKeyFor)
+	cacheScope string
 	// logging
 	forceDebug bool
+	// idempotent writes
+	idempotencyKey string
+	// concurrencyKey/concurrencyMax cap cluster-wide parallel runs of the query (see WithConcurrencyKey)
+	concurrencyKey string
+	concurrencyMax int
 	// soft delete scoping
 	qbSoftMode         qbSoftDeleteMode
 	modelHasSoftDelete bool
-	err                error
+	// softDeleteColumn qualifies the soft-delete filter of the base model (see ModelAs)
+	softDeleteColumn string
+	// joinSoftDeletes are the models joined by JoinModelAs that have soft delete
+	joinSoftDeletes []joinSoftDelete
+	err             error
 }
 
+// qbCacheMode controls how Find uses the WithCacheKey entry
+type qbCacheMode int
+
+const (
+	qbCacheModeDefault qbCacheMode = iota
+	qbCacheModeBypass              // neither read nor write the cache
+	qbCacheModeRefresh             // skip the read, then overwrite the entry
+)
+
 // qbSoftDeleteMode controls soft-delete scoping for QueryBuilder
 type qbSoftDeleteMode int
 
@@ -66,15 +99,11 @@ const (
 // Query creates a new query builder
 func (kn *KintsNorm) Query() *QueryBuilder {
 	// If read pool is configured, route reads automatically using routingExecuter
+	// A transaction carried by the call context (WithTx) takes precedence at execution time
 	if kn.readPool != nil {
-		exec := dbExecuter(routingExecuter{kn: kn})
-		return &QueryBuilder{kn: kn, exec: exec}
-	}
-	exec := dbExecuter(kn.pool)
-	if kn.breaker != nil {
-		exec = breakerExecuter{kn: kn, exec: exec}
+		return &QueryBuilder{kn: kn, exec: ctxTxExecuter{base: wrapRouting(kn)}}
 	}
-	return &QueryBuilder{kn: kn, exec: exec}
+	return &QueryBuilder{kn: kn, exec: ctxTxExecuter{base: wrapExec(kn, kn.pool)}}
 }
 
 // Model initializes a new query builder and sets its table name inferred from the provided model type.
@@ -130,11 +159,7 @@ func (qb *QueryBuilder) SelectQI(columns ...string) *QueryBuilder {
 
 // UsePrimary routes subsequent calls (Query/Find/First/Last) through the primary pool (overrides auto read routing)
 func (qb *QueryBuilder) UsePrimary() *QueryBuilder {
-	exec := dbExecuter(qb.kn.pool)
-	if qb.kn.breaker != nil {
-		exec = breakerExecuter{kn: qb.kn, exec: exec}
-	}
-	qb.exec = exec
+	qb.exec = ctxTxExecuter{base: wrapExec(qb.kn, qb.kn.pool)}
 	return qb
 }
 
@@ -147,11 +172,7 @@ func (qb *QueryBuilder) Debug() *QueryBuilder {
 // UseReadPool forces using the read pool for reads even if no auto routing is enabled
 // Note: Do not use this for writes; Exec/insert/update/delete should go to primary
 func (qb *QueryBuilder) UseReadPool() *QueryBuilder {
-	exec := dbExecuter(qb.kn.ReadPool())
-	if qb.kn.breaker != nil {
-		exec = breakerExecuter{kn: qb.kn, exec: exec}
-	}
-	qb.exec = exec
+	qb.exec = wrapExec(qb.kn, qb.kn.ReadPool())
 	return qb
 }
 
@@ -163,7 +184,8 @@ func (qb *QueryBuilder) Table(name string) *QueryBuilder {
 }
 
 // Model sets the table name by inferring it from a provided model type/value.
-// It follows the same convention used by the repository: snake_case(type name) + "s".
+// It follows the same convention used by the repository: snake_case(type name) + "s", or the
+// NamingStrategy set with SetNamingStrategy.
 // Examples:
 //
 //	qb.Model(&User{})
@@ -176,8 +198,9 @@ func (qb *QueryBuilder) Model(model any) *QueryBuilder {
 	for t.Kind() == reflect.Pointer {
 		t = t.Elem()
 	}
-	qb.table = core.ToSnakeCase(t.Name()) + "s"
+	qb.table = core.Qualify(core.SchemaName(t), core.TableNameOf(model))
 	qb.modelHasSoftDelete = core.ModelHasSoftDelete(t)
+	qb.softDeleteColumn = ""
 	return qb
 }
 
@@ -238,9 +261,35 @@ func (qb *QueryBuilder) WhereNamed(condition string, namedArgs map[string]any) *
 	return qb
 }
 
-func (qb *QueryBuilder) OrderBy(ob string) *QueryBuilder { qb.orderBy = ob; return qb }
-func (qb *QueryBuilder) Limit(n int) *QueryBuilder       { qb.limit = n; return qb }
-func (qb *QueryBuilder) Offset(n int) *QueryBuilder      { qb.offset = n; return qb }
+// GroupBy adds GROUP BY columns/expressions
+func (qb *QueryBuilder) GroupBy(columns ...string) *QueryBuilder {
+	qb.groupBy = append(qb.groupBy, columns...)
+	return qb
+}
+
+// Having adds a HAVING predicate using '?' placeholders; multiple calls are ANDed
+func (qb *QueryBuilder) Having(condition string, args ...any) *QueryBuilder {
+	qb.havings = append(qb.havings, condition)
+	qb.havingArgs = append(qb.havingArgs, args...)
+	return qb
+}
+
+func (qb *QueryBuilder) OrderBy(ob string) *QueryBuilder {
+	qb.orderBy, qb.orderArgs = ob, nil
+	return qb
+}
+func (qb *QueryBuilder) Limit(n int) *QueryBuilder  { qb.limit = n; return qb }
+func (qb *QueryBuilder) Offset(n int) *QueryBuilder { qb.offset = n; return qb }
+
+// OrderByExpr sets an ORDER BY expression using '?' placeholders, e.g.
+//
+//	qb.OrderByExpr("similarity(name, ?) DESC", term)
+//
+// The placeholders are numbered after those of the rest of the query.
+func (qb *QueryBuilder) OrderByExpr(expr string, args ...any) *QueryBuilder {
+	qb.orderBy, qb.orderArgs = expr, args
+	return qb
+}
 
 // Keyset pagination helpers
 func (qb *QueryBuilder) After(column string, value any) *QueryBuilder {
@@ -279,6 +328,11 @@ func (qb *QueryBuilder) WhereCond(c Condition) *QueryBuilder {
 	return qb.Where(c.Expr, c.Args...)
 }
 
+// WhereJSON filters on the text value at a dotted path of a json/jsonb column: col #>> '{a,b}' = value
+func (qb *QueryBuilder) WhereJSON(column string, path string, value any) *QueryBuilder {
+	return qb.WhereCond(JSONEq(column, path, value))
+}
+
 // WithCacheKey enables read-through caching for Find/First on this builder. TTL<=0 means no Set.
 func (qb *QueryBuilder) WithCacheKey(key string, ttl time.Duration) *QueryBuilder {
 	qb.cacheKey = key
@@ -286,12 +340,79 @@ func (qb *QueryBuilder) WithCacheKey(key string, ttl time.Duration) *QueryBuilde
 	return qb
 }
 
+// CacheBypass makes Find ignore the WithCacheKey entry: it reads from the database and leaves the
+// cache as it is. Used by admin tooling that must see fresh rows.
+func (qb *QueryBuilder) CacheBypass() *QueryBuilder { qb.cacheMode = qbCacheModeBypass; return qb }
+
+// CacheRefresh makes Find skip the cached entry, read from the database and store the result under the
+// WithCacheKey key, e.g. to repopulate the cache after a backfill instead of waiting for the TTL.
+func (qb *QueryBuilder) CacheRefresh() *QueryBuilder { qb.cacheMode = qbCacheModeRefresh; return qb }
+
 // WithInvalidateKeys sets keys to invalidate after write operations (Exec/Insert/Update/Delete)
 func (qb *QueryBuilder) WithInvalidateKeys(keys ...string) *QueryBuilder {
 	qb.invalidate = append(qb.invalidate, keys...)
 	return qb
 }
 
+// cacheKeyFor returns the cache entry name of key. Builders of a Tenant, or bound to an RLS context,
+// prefix it with their scope ("tenant:acme:users:1"), so the same key never reads another tenant's
+// cached rows and writes only invalidate their own tenant's entries.
+func (qb *QueryBuilder) cacheKeyFor(key string) string {
+	if qb.cacheScope == "" {
+		return key
+	}
+	return qb.cacheScope + ":" + key
+}
+
+func (qb *QueryBuilder) invalidateKeys() []string {
+	if qb.cacheScope == "" {
+		return qb.invalidate
+	}
+	keys := make([]string, len(qb.invalidate))
+	for i, k := range qb.invalidate {
+		keys[i] = qb.cacheKeyFor(k)
+	}
+	return keys
+}
+
+// findLoading is the read-through Find of a LoadingCache: concurrent misses of the key share one query.
+// The caller that ran it keeps its own rows; the others decode the stored entry, like a cache hit.
+func (qb *QueryBuilder) findLoading(ctx context.Context, lc LoadingCache, dest *[]map[string]any) error {
+	var loaded []map[string]any
+	ran := false
+	data, err := lc.GetOrLoad(ctx, qb.cacheKeyFor(qb.cacheKey), qb.cacheTTL, func(ctx context.Context) ([]byte, error) {
+		q := *qb
+		q.cacheMode = qbCacheModeBypass // the cache stores what load returns
+		if err := q.Find(ctx, &loaded); err != nil {
+			return nil, err
+		}
+		ran = true
+		return json.Marshal(loaded)
+	})
+	if err != nil {
+		return err
+	}
+	cm, _ := qb.kn.metrics.(CacheMetrics)
+	if ran {
+		if cm != nil {
+			cm.CacheMiss()
+		}
+		*dest = append((*dest)[:0], loaded...)
+		return nil
+	}
+	var cached []map[string]any
+	if err := json.Unmarshal(data, &cached); err != nil {
+		q := *qb
+		q.cacheMode = qbCacheModeBypass
+		return q.Find(ctx, dest)
+	}
+	if cm != nil {
+		cm.CacheHit()
+	}
+	*dest = append((*dest)[:0], cached...)
+	return nil
+}
+
 // WithTrashed includes soft-deleted rows (deleted_at IS NOT NULL or NULL) in results
 func (qb *QueryBuilder) WithTrashed() *QueryBuilder { qb.qbSoftMode = qbSoftModeWithTrashed; return qb }
 
@@ -315,6 +436,28 @@ func (qb *QueryBuilder) queryError() error {
 	return qb.err
 }
 
+// quoteColumn quotes a column name only when Postgres would not read it back as written unquoted
+// (upper-case letters or other characters, as in legacy columns like "EmailAddress"), keeping the SQL of
+// plain snake_case columns unchanged
+func quoteColumn(name string) string {
+	for i, r := range name {
+		if r == '_' || (r >= 'a' && r <= 'z') || (i > 0 && (r >= '0' && r <= '9' || r == '$')) {
+			continue
+		}
+		return QuoteIdentifier(name)
+	}
+	return name
+}
+
+// quoteColumns applies quoteColumn to each name
+func quoteColumns(names []string) []string {
+	out := make([]string, len(names))
+	for i, n := range names {
+		out[i] = quoteColumn(n)
+	}
+	return out
+}
+
 func quoteIdentifiers(names []string) []string {
 	if len(names) == 0 {
 		return nil
@@ -331,18 +474,28 @@ func (qb *QueryBuilder) buildSelect() (string, []any) {
 		// Add explicit type casts to placeholders based on Go arg types to help Postgres infer types in raw queries
 		return addTypeCastsToPlaceholders(qb.raw, qb.args), qb.args
 	}
+	return qb.cachedSQL("select", qb.compileSelect, qb.selectArgs)
+}
+
+func (qb *QueryBuilder) compileSelect() (string, []any) {
 	cols := "*"
 	if len(qb.columns) > 0 {
 		cols = strings.Join(qb.columns, ", ")
 	}
 	var sb strings.Builder
+	args := qb.leadingArgs()
+	if len(qb.ctes) > 0 {
+		sb.WriteString(qb.compileWith())
+		sb.WriteString(" ")
+	}
 	sb.WriteString("SELECT ")
 	sb.WriteString(cols)
 	sb.WriteString(" FROM ")
-	sb.WriteString(qb.table)
+	// a FROM subquery is numbered from $1; shift it past the CTE args
+	sb.WriteString(sqlutil.RenumberPlaceholders(qb.table, len(args)-len(qb.fromArgs)))
 	if len(qb.joins) > 0 {
 		sb.WriteString(" ")
-		sb.WriteString(strings.Join(qb.joins, " "))
+		sb.WriteString(qb.compileJoins())
 	}
 	// collect where clauses including default soft-delete scoping for Model-based queries
 	whereClauses := make([]string, 0, len(qb.wheres)+1)
@@ -350,20 +503,21 @@ func (qb *QueryBuilder) buildSelect() (string, []any) {
 	if qb.modelHasSoftDelete {
 		switch qb.qbSoftMode {
 		case qbSoftModeOnlyTrashed:
-			whereClauses = append(whereClauses, "deleted_at IS NOT NULL")
+			whereClauses = append(whereClauses, qb.softDeleteTarget()+" IS NOT NULL")
 		case qbSoftModeWithTrashed:
 			// no default filter
 		default:
-			whereClauses = append(whereClauses, "deleted_at IS NULL")
+			whereClauses = append(whereClauses, qb.softDeleteTarget()+" IS NULL")
 		}
 	}
 	if len(whereClauses) > 0 {
 		sb.WriteString(" WHERE ")
 		where := strings.Join(whereClauses, " AND ")
-		where = sqlutil.ConvertQMarksToPgPlaceholders(where)
+		// placeholders continue after those of CTEs and a FROM subquery
+		where = sqlutil.RenumberPlaceholders(sqlutil.ConvertQMarksToPgPlaceholders(where), len(args))
 		sb.WriteString(where)
 	}
-	args := append([]any(nil), qb.args...)
+	args = append(args, qb.args...)
 	// keyset
 	keyset, keysetArgs := qb.buildKeysetPredicate(len(args))
 	if keyset != "" {
@@ -375,9 +529,24 @@ func (qb *QueryBuilder) buildSelect() (string, []any) {
 		sb.WriteString(keyset)
 		args = append(args, keysetArgs...)
 	}
+	if len(qb.groupBy) > 0 {
+		sb.WriteString(" GROUP BY ")
+		sb.WriteString(strings.Join(qb.groupBy, ", "))
+	}
+	if len(qb.havings) > 0 {
+		sb.WriteString(" HAVING ")
+		having := sqlutil.ConvertQMarksToPgPlaceholders(strings.Join(qb.havings, " AND "))
+		sb.WriteString(sqlutil.RenumberPlaceholders(having, len(args)))
+		args = append(args, qb.havingArgs...)
+	}
 	if qb.orderBy != "" {
 		sb.WriteString(" ORDER BY ")
-		sb.WriteString(qb.orderBy)
+		if len(qb.orderArgs) > 0 {
+			sb.WriteString(sqlutil.RenumberPlaceholders(sqlutil.ConvertQMarksToPgPlaceholders(qb.orderBy), len(args)))
+			args = append(args, qb.orderArgs...)
+		} else {
+			sb.WriteString(qb.orderBy)
+		}
 	}
 	if qb.limit > 0 {
 		sb.WriteString(" LIMIT ")
@@ -387,6 +556,10 @@ func (qb *QueryBuilder) buildSelect() (string, []any) {
 		sb.WriteString(" OFFSET ")
 		sb.WriteString(strconv.Itoa(qb.offset))
 	}
+	if qb.lock != "" {
+		sb.WriteString(" ")
+		sb.WriteString(qb.lock)
+	}
 	return sb.String(), args
 }
 
@@ -459,20 +632,35 @@ func (qb *QueryBuilder) Find(ctx context.Context, dest any) error {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if qb.concurrencyKey != "" {
+		return qb.runLimited(ctx, func(q *QueryBuilder) error { return q.Find(ctx, dest) })
+	}
 	// optional read-through cache
-	if qb.kn.cache != nil && qb.cacheKey != "" {
-		if data, ok, _ := qb.kn.cache.Get(ctx, qb.cacheKey); ok {
+	if qb.kn.cache != nil && qb.cacheKey != "" && qb.cacheMode == qbCacheModeDefault {
+		if lc, ok := qb.kn.cache.(LoadingCache); ok && qb.cacheTTL > 0 {
+			if dptr, ok := dest.(*[]map[string]any); ok {
+				return qb.findLoading(ctx, lc, dptr)
+			}
+		}
+		cm, _ := qb.kn.metrics.(CacheMetrics)
+		if data, ok, _ := qb.kn.cache.Get(ctx, qb.cacheKeyFor(qb.cacheKey)); ok {
 			// Only support *[]map[string]any for now
 			if dptr, ok2 := dest.(*[]map[string]any); ok2 {
 				var cached []map[string]any
 				if err := json.Unmarshal(data, &cached); err == nil {
 					*dptr = append((*dptr)[:0], cached...)
+					if cm != nil {
+						cm.CacheHit()
+					}
 					return nil
 				}
 			}
 		}
+		if cm != nil {
+			cm.CacheMiss()
+		}
 	}
-	query, args := qb.buildSelect()
+	query, args := qb.withPrefixes(sliceElem(dest)).buildSelect()
 	started := time.Now()
 	rows, err := qb.exec.Query(ctx, query, args...)
 	// logging governed by global mode or forced via Debug()
@@ -529,9 +717,9 @@ func (qb *QueryBuilder) Find(ctx context.Context, dest any) error {
 			return wrapPgError(err, query, args)
 		}
 		// cache set for *[]map[string]any only for now
-		if qb.kn.cache != nil && qb.cacheKey != "" && qb.cacheTTL > 0 {
+		if qb.kn.cache != nil && qb.cacheKey != "" && qb.cacheTTL > 0 && qb.cacheMode != qbCacheModeBypass {
 			if out, err := json.Marshal(*d); err == nil {
-				_ = qb.kn.cache.Set(ctx, qb.cacheKey, out, qb.cacheTTL)
+				_ = qb.kn.cache.Set(ctx, qb.cacheKeyFor(qb.cacheKey), out, qb.cacheTTL)
 			}
 		}
 		return nil
@@ -550,14 +738,15 @@ func (qb *QueryBuilder) Find(ctx context.Context, dest any) error {
 				return wrapPgError(err, query, args)
 			}
 			fds := rows.FieldDescriptions()
-			elemPtr := reflect.New(elemType)
+			// grow by a zero element and decode in place, avoiding a temporary per row
+			sliceVal.Set(reflect.Append(sliceVal, reflect.Zero(elemType)))
+			elemPtr := sliceVal.Index(sliceVal.Len() - 1).Addr()
 			for i, v := range vals {
 				col := strings.ToLower(string(fds[i].Name))
-				if fi, ok := mapper.FieldsByColumn[col]; ok {
-					core.SetFieldByIndex(elemPtr, fi.Index, v)
+				if fi, ok := mapper.Column(col); ok {
+					core.SetField(elemPtr, fi, v)
 				}
 			}
-			sliceVal.Set(reflect.Append(sliceVal, elemPtr.Elem()))
 		}
 		if err := rows.Err(); err != nil {
 			return wrapPgError(err, query, args)
@@ -621,6 +810,10 @@ func (qb *QueryBuilder) Last(ctx context.Context, dest any) error {
 
 // buildDelete builds a DELETE statement from the current builder state
 func (qb *QueryBuilder) buildDelete() (string, []any) {
+	return qb.cachedSQL("delete", qb.compileDelete, qb.deleteArgs)
+}
+
+func (qb *QueryBuilder) compileDelete() (string, []any) {
 	// Hard delete path remains the same
 	if qb.deleteHard {
 		var sb strings.Builder
@@ -661,6 +854,9 @@ func (qb *QueryBuilder) Delete(ctx context.Context) (int64, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if qb.idempotencyKey != "" {
+		return qb.runIdempotent(ctx, func(q *QueryBuilder) (int64, error) { return q.Delete(ctx) })
+	}
 	query, args := qb.buildDelete()
 	started := time.Now()
 	tag, err := qb.exec.Exec(ctx, query, args...)
@@ -696,7 +892,7 @@ func (qb *QueryBuilder) Delete(ctx context.Context) (int64, error) {
 		return 0, wrapPgError(err, query, args)
 	}
 	if qb.kn.cache != nil && len(qb.invalidate) > 0 {
-		_ = qb.kn.cache.Invalidate(ctx, qb.invalidate...)
+		_ = qb.kn.cache.Invalidate(ctx, qb.invalidateKeys()...)
 	}
 	return int64(tag.RowsAffected()), nil
 }
@@ -718,6 +914,10 @@ func (qb *QueryBuilder) Exec(ctx context.Context) error {
 	if !qb.isRaw {
 		return errors.New("Exec only allowed with Raw query")
 	}
+	if qb.idempotencyKey != "" {
+		_, err := qb.runIdempotent(ctx, func(q *QueryBuilder) (int64, error) { return 0, q.Exec(ctx) })
+		return err
+	}
 	started := time.Now()
 	_, err := qb.exec.Exec(ctx, qb.raw, qb.args...)
 	if qb.kn != nil && qb.kn.logger != nil {
@@ -752,7 +952,7 @@ func (qb *QueryBuilder) Exec(ctx context.Context) error {
 		return wrapPgError(err, qb.raw, qb.args)
 	}
 	if qb.kn.cache != nil && len(qb.invalidate) > 0 {
-		_ = qb.kn.cache.Invalidate(ctx, qb.invalidate...)
+		_ = qb.kn.cache.Invalidate(ctx, qb.invalidateKeys()...)
 	}
 	return nil
 }
@@ -771,7 +971,20 @@ func (qb *QueryBuilder) ValuesRows(rows [][]any) *QueryBuilder {
 	qb.insertRows = append(qb.insertRows, rows...)
 	return qb
 }
-func (qb *QueryBuilder) Returning(cols ...string) *QueryBuilder  { qb.returningCols = cols; return qb }
+
+// Returning adds a RETURNING clause. Plain column names are quoted; other entries are expressions with an
+// optional alias, e.g. Returning("id", "xmax = 0 AS inserted", "now() AS at"). Expressions containing ';',
+// comments or unbalanced quotes/parentheses make the builder fail with ErrCodeValidation.
+func (qb *QueryBuilder) Returning(cols ...string) *QueryBuilder {
+	for _, c := range cols {
+		if _, err := returningItem(c); err != nil {
+			qb.setError(err)
+		}
+	}
+	qb.returningCols = cols
+	return qb
+}
+
 func (qb *QueryBuilder) OnConflict(cols ...string) *QueryBuilder { qb.conflictCols = cols; return qb }
 func (qb *QueryBuilder) DoUpdateSet(setExpr string, args ...any) *QueryBuilder {
 	qb.updateSetExpr = setExpr
@@ -780,6 +993,10 @@ func (qb *QueryBuilder) DoUpdateSet(setExpr string, args ...any) *QueryBuilder {
 }
 
 func (qb *QueryBuilder) buildInsert() (string, []any) {
+	return qb.cachedSQL("insert", qb.compileInsert, qb.insertArgs)
+}
+
+func (qb *QueryBuilder) compileInsert() (string, []any) {
 	var sb strings.Builder
 	sb.WriteString("INSERT INTO ")
 	sb.WriteString(qb.table)
@@ -801,16 +1018,20 @@ func (qb *QueryBuilder) buildInsert() (string, []any) {
 			sb.WriteString(", ")
 		}
 		sb.WriteByte('(')
-		for ci := range r {
+		for ci, v := range r {
 			if ci > 0 {
 				sb.WriteString(", ")
 			}
+			if _, ok := v.(insertDefault); ok {
+				sb.WriteString("DEFAULT")
+				continue
+			}
 			sb.WriteByte('$')
 			sb.WriteString(strconv.Itoa(argIdx))
 			argIdx++
+			args = append(args, v)
 		}
 		sb.WriteByte(')')
-		args = append(args, r...)
 	}
 	if len(qb.conflictCols) > 0 {
 		sb.WriteString(" ON CONFLICT (")
@@ -831,7 +1052,7 @@ func (qb *QueryBuilder) buildInsert() (string, []any) {
 	}
 	if len(qb.returningCols) > 0 {
 		sb.WriteString(" RETURNING ")
-		sb.WriteString(strings.Join(quoteIdentifiers(qb.returningCols), ", "))
+		sb.WriteString(returningSQL(qb.returningCols))
 	}
 	return sb.String(), args
 }
@@ -846,6 +1067,9 @@ func (qb *QueryBuilder) ExecInsert(ctx context.Context, dest any) (int64, error)
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if qb.idempotencyKey != "" {
+		return qb.runIdempotent(ctx, func(q *QueryBuilder) (int64, error) { return q.ExecInsert(ctx, dest) })
+	}
 	query, args := qb.buildInsert()
 	if len(qb.returningCols) == 0 {
 		started := time.Now()
@@ -873,7 +1097,7 @@ func (qb *QueryBuilder) ExecInsert(ctx context.Context, dest any) (int64, error)
 			return 0, wrapPgError(err, query, args)
 		}
 		if qb.kn.cache != nil && len(qb.invalidate) > 0 {
-			_ = qb.kn.cache.Invalidate(ctx, qb.invalidate...)
+			_ = qb.kn.cache.Invalidate(ctx, qb.invalidateKeys()...)
 		}
 		return int64(tag.RowsAffected()), nil
 	}
@@ -905,32 +1129,14 @@ func (qb *QueryBuilder) ExecInsert(ctx context.Context, dest any) (int64, error)
 		return 0, wrapPgError(err, query, args)
 	}
 	defer rows.Close()
-	switch d := dest.(type) {
-	case *[]map[string]any:
-		var count int64
-		for rows.Next() {
-			vals, err := rows.Values()
-			if err != nil {
-				return count, err
-			}
-			fds := rows.FieldDescriptions()
-			m := make(map[string]any, len(vals))
-			for i, v := range vals {
-				m[string(fds[i].Name)] = v
-			}
-			*d = append(*d, m)
-			count++
-		}
-		if err := rows.Err(); err != nil {
-			return count, err
-		}
-		if qb.kn.cache != nil && len(qb.invalidate) > 0 {
-			_ = qb.kn.cache.Invalidate(ctx, qb.invalidate...)
-		}
-		return count, nil
-	default:
-		return 0, &ORMError{Code: ErrCodeValidation, Message: "dest must be *[]map[string]any for RETURNING"}
+	count, err := scanReturning(rows, dest, query, args)
+	if err != nil {
+		return count, err
+	}
+	if qb.kn.cache != nil && len(qb.invalidate) > 0 {
+		_ = qb.kn.cache.Invalidate(ctx, qb.invalidateKeys()...)
 	}
+	return count, nil
 }
 
 // Update builder (simple form): provide SET expr and args
@@ -942,6 +1148,10 @@ func (qb *QueryBuilder) Set(setExpr string, args ...any) *QueryBuilder {
 }
 
 func (qb *QueryBuilder) buildUpdate() (string, []any) {
+	return qb.cachedSQL("update", qb.compileUpdate, qb.updateArgs)
+}
+
+func (qb *QueryBuilder) compileUpdate() (string, []any) {
 	var sb strings.Builder
 	sb.WriteString("UPDATE ")
 	sb.WriteString(qb.table)
@@ -962,7 +1172,7 @@ func (qb *QueryBuilder) buildUpdate() (string, []any) {
 	}
 	if len(qb.returningCols) > 0 {
 		sb.WriteString(" RETURNING ")
-		sb.WriteString(strings.Join(quoteIdentifiers(qb.returningCols), ", "))
+		sb.WriteString(returningSQL(qb.returningCols))
 	}
 	return sb.String(), args
 }
@@ -977,6 +1187,9 @@ func (qb *QueryBuilder) ExecUpdate(ctx context.Context, dest any) (int64, error)
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	if qb.idempotencyKey != "" {
+		return qb.runIdempotent(ctx, func(q *QueryBuilder) (int64, error) { return q.ExecUpdate(ctx, dest) })
+	}
 	query, args := qb.buildUpdate()
 	if len(qb.returningCols) == 0 {
 		started := time.Now()
@@ -988,7 +1201,7 @@ func (qb *QueryBuilder) ExecUpdate(ctx context.Context, dest any) (int64, error)
 			return 0, wrapPgError(err, query, args)
 		}
 		if qb.kn.cache != nil && len(qb.invalidate) > 0 {
-			_ = qb.kn.cache.Invalidate(ctx, qb.invalidate...)
+			_ = qb.kn.cache.Invalidate(ctx, qb.invalidateKeys()...)
 		}
 		return int64(tag.RowsAffected()), nil
 	}
@@ -1019,60 +1232,39 @@ func (qb *QueryBuilder) ExecUpdate(ctx context.Context, dest any) (int64, error)
 		return 0, wrapPgError(err, query, args)
 	}
 	defer rows.Close()
-	switch d := dest.(type) {
-	case *[]map[string]any:
-		var count int64
-		for rows.Next() {
-			vals, err := rows.Values()
-			if err != nil {
-				return count, err
-			}
-			fds := rows.FieldDescriptions()
-			m := make(map[string]any, len(vals))
-			for i, v := range vals {
-				m[string(fds[i].Name)] = v
-			}
-			*d = append(*d, m)
-			count++
-		}
-		if err := rows.Err(); err != nil {
-			return count, err
-		}
-		if qb.kn.cache != nil && len(qb.invalidate) > 0 {
-			_ = qb.kn.cache.Invalidate(ctx, qb.invalidate...)
-		}
-		return count, nil
-	default:
-		return 0, &ORMError{Code: ErrCodeValidation, Message: "dest must be *[]map[string]any for RETURNING"}
+	count, err := scanReturning(rows, dest, query, args)
+	if err != nil {
+		return count, err
+	}
+	if qb.kn.cache != nil && len(qb.invalidate) > 0 {
+		_ = qb.kn.cache.Invalidate(ctx, qb.invalidateKeys()...)
 	}
+	return count, nil
 }
 
 // InsertStruct inserts a struct using its `db` tags. Zero values with default: tag are skipped to allow DB defaults
 func (qb *QueryBuilder) InsertStruct(ctx context.Context, entity any) (int64, error) {
 	v := reflect.Indirect(reflect.ValueOf(entity))
 	t := v.Type()
+	mapper := core.StructMapper(t)
 	cols := []string{}
 	row := []any{}
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
+	for _, f := range core.Fields(t) {
 		if f.PkgPath != "" {
 			continue
 		}
-		col := f.Tag.Get("db")
-		if col == "" {
-			col = core.ToSnakeCase(f.Name)
-		}
+		col := core.ColumnName(f)
 		// Prefer `norm` tag; fallback to legacy `orm`
 		orm := f.Tag.Get("norm")
 		if orm == "" {
 			orm = f.Tag.Get("orm")
 		}
 		low := strings.ToLower(orm)
-		if strings.Contains(low, "-") || strings.Contains(low, "ignore") {
+		if strings.Contains(low, "-") || strings.Contains(low, "ignore") || core.IsRelationTag(orm) {
 			continue
 		}
-		fv := v.Field(i)
-		if strings.Contains(orm, "default:") && fv.IsZero() {
+		fv := v.FieldByIndex(f.Index)
+		if fi := mapper.FieldsByColumn[strings.ToLower(col)]; (fv.IsZero() && (fi.OmitEmpty || strings.Contains(orm, "default:"))) || fi.Generated {
 			continue
 		}
 		cols = append(cols, col)
@@ -1083,25 +1275,39 @@ func (qb *QueryBuilder) InsertStruct(ctx context.Context, entity any) (int64, er
 
 // UpdateStructByPK updates a row by its primary key using `db` tags
 func (qb *QueryBuilder) UpdateStructByPK(ctx context.Context, entity any, pkColumn string) (int64, error) {
+	return qb.updateStructByPK(ctx, entity, pkColumn, "", nil)
+}
+
+// updateStructByPK increments versionCol and requires it to equal version when versionCol is set
+func (qb *QueryBuilder) updateStructByPK(ctx context.Context, entity any, pkColumn, versionCol string, version any) (int64, error) {
 	v := reflect.Indirect(reflect.ValueOf(entity))
 	t := v.Type()
+	mapper := core.StructMapper(t)
 	sets := []string{}
 	args := []any{}
 	var id any
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
+	for _, f := range core.Fields(t) {
 		if f.PkgPath != "" {
 			continue
 		}
-		col := f.Tag.Get("db")
-		if col == "" {
-			col = core.ToSnakeCase(f.Name)
+		if core.IsRelationField(f) {
+			continue
 		}
-		fv := v.Field(i).Interface()
+		col := core.ColumnName(f)
+		fv := v.FieldByIndex(f.Index).Interface()
 		if strings.EqualFold(col, pkColumn) {
 			id = fv
 			continue
 		}
+		if versionCol != "" && strings.EqualFold(col, versionCol) {
+			quoted := quoteQualified(col)
+			sets = append(sets, fmt.Sprintf("%s = %s + 1", quoted, quoted))
+			continue
+		}
+		// write-once and generated columns keep their stored value, as in Repository.Update
+		if fi := mapper.FieldsByColumn[strings.ToLower(col)]; fi.Immutable || fi.Generated || (fi.OmitEmpty && v.FieldByIndex(f.Index).IsZero()) {
+			continue
+		}
 		sets = append(sets, fmt.Sprintf("%s = ?", quoteQualified(col)))
 		args = append(args, fv)
 	}
@@ -1112,6 +1318,9 @@ func (qb *QueryBuilder) UpdateStructByPK(ctx context.Context, entity any, pkColu
 	qb.updateSetExpr = strings.Join(sets, ", ")
 	qb.updateSetArgs = args
 	qb.Where(quoteQualified(pkColumn)+" = ?", id)
+	if versionCol != "" {
+		qb.Where(quoteQualified(versionCol)+" = ?", version)
+	}
 	return qb.ExecUpdate(ctx, nil)
 }
 
@@ -1,6 +1,7 @@
 package norm
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -36,3 +37,18 @@ func TestBuildUpdate_WithWhereAndReturning(t *testing.T) {
 		t.Fatalf("args")
 	}
 }
+
+func TestBuildInsert_ReturningExpressions(t *testing.T) {
+	kn := &KintsNorm{}
+	qb := (&QueryBuilder{kn: kn}).Table("users").Insert("id").Values(1).OnConflict("id").DoUpdateSet("name = ?", "b").Returning("id", "xmax = 0 AS inserted", "now() as at", "users.*")
+	sql, _ := qb.buildInsert()
+	if !strings.HasSuffix(sql, ` RETURNING "id", xmax = 0 AS "inserted", now() AS "at", users.*`) {
+		t.Fatalf("sql=%s", sql)
+	}
+	for _, bad := range []string{"id; DROP TABLE users", "now() -- x", "lower(name", "'x AS y"} {
+		qb := (&QueryBuilder{kn: kn}).Table("users").Set("name = ?", "b").Where("id = ?", 1).Returning(bad)
+		if err := qb.queryError(); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
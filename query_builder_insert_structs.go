@@ -0,0 +1,134 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// insertDefault is an insert value rendered as the DEFAULT keyword instead of a placeholder
+type insertDefault struct{}
+
+// InsertStructs inserts a slice of structs (or struct pointers), or a pointer to one, as a single multi-row
+// INSERT using their `db` tags. Columns are chosen like InsertStruct: unexported, ignored, relation and
+// generated fields are left out, and so is a column whose zero value has default: or omitempty in every
+// row; in the other rows such zero values, and zero auto-increment keys, are sent as DEFAULT. With
+// Returning the returned rows are scanned back into the elements in VALUES order, so
+//
+//	_, err := db.Query().Table("users").Returning("id", "created_at").InsertStructs(ctx, users)
+//
+// fills each user's id and created_at. Returning cannot be combined with OnConflict DO NOTHING, whose
+// skipped rows would shift that order.
+func (qb *QueryBuilder) InsertStructs(ctx context.Context, entities any) (int64, error) {
+	v := reflect.Indirect(reflect.ValueOf(entities))
+	if v.Kind() != reflect.Slice {
+		return 0, &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("InsertStructs needs a slice of structs, got %T", entities)}
+	}
+	t := v.Type().Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return 0, &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("InsertStructs needs a slice of structs, got %T", entities)}
+	}
+	if v.Len() == 0 {
+		return 0, nil
+	}
+	if len(qb.returningCols) > 0 && len(qb.conflictCols) > 0 && qb.updateSetExpr == "" {
+		return 0, &ORMError{Code: ErrCodeValidation, Message: "InsertStructs cannot scan RETURNING rows back with ON CONFLICT DO NOTHING"}
+	}
+	elems := make([]reflect.Value, v.Len())
+	for i := range elems {
+		e := v.Index(i)
+		if e.Kind() == reflect.Pointer {
+			if e.IsNil() {
+				return 0, &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("InsertStructs: element %d is nil", i)}
+			}
+			e = e.Elem()
+		}
+		elems[i] = e
+	}
+	mapper := core.StructMapper(t)
+	type insertCol struct {
+		name     string
+		index    []int
+		defaults bool // a zero value is sent as DEFAULT
+	}
+	var cols []insertCol
+	for _, f := range core.Fields(t) {
+		if f.PkgPath != "" {
+			continue
+		}
+		col := core.ColumnName(f)
+		// Prefer `norm` tag; fallback to legacy `orm`
+		orm := f.Tag.Get("norm")
+		if orm == "" {
+			orm = f.Tag.Get("orm")
+		}
+		low := strings.ToLower(orm)
+		if strings.Contains(low, "-") || strings.Contains(low, "ignore") || core.IsRelationTag(orm) {
+			continue
+		}
+		fi := mapper.FieldsByColumn[strings.ToLower(col)]
+		if fi.Generated {
+			continue
+		}
+		c := insertCol{name: col, index: f.Index, defaults: fi.OmitEmpty || strings.Contains(orm, "default:") ||
+			(mapper.AutoIncrement && strings.EqualFold(col, mapper.PrimaryColumn))}
+		if c.defaults && allZero(elems, f.Index) {
+			continue
+		}
+		cols = append(cols, c)
+	}
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.name
+	}
+	rows := make([][]any, len(elems))
+	for i, e := range elems {
+		row := make([]any, len(cols))
+		for j, c := range cols {
+			fv := e.FieldByIndex(c.index)
+			if c.defaults && fv.IsZero() {
+				row[j] = insertDefault{}
+			} else {
+				row[j] = fv.Interface()
+			}
+		}
+		rows[i] = row
+	}
+	qb.Insert(names...).ValuesRows(rows)
+	if len(qb.returningCols) == 0 {
+		return qb.ExecInsert(ctx, nil)
+	}
+	var returned []map[string]any
+	n, err := qb.ExecInsert(ctx, &returned)
+	if err != nil {
+		return n, err
+	}
+	for i, m := range returned {
+		if i >= len(elems) {
+			break
+		}
+		ptr := elems[i].Addr()
+		for col, val := range m {
+			if fi, ok := mapper.Column(strings.ToLower(col)); ok {
+				core.SetField(ptr, fi, val)
+			}
+		}
+	}
+	return n, nil
+}
+
+// allZero reports whether the field at index is zero in every element
+func allZero(elems []reflect.Value, index []int) bool {
+	for _, e := range elems {
+		if !e.FieldByIndex(index).IsZero() {
+			return false
+		}
+	}
+	return true
+}
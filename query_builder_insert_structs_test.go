@@ -0,0 +1,66 @@
+package norm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type insStructsUser struct {
+	ID        int64     `db:"id" norm:"primary_key,auto_increment"`
+	Name      string    `db:"name"`
+	Nick      string    `db:"nick,omitempty"`
+	Status    string    `db:"status" norm:"default:'active'"`
+	CreatedAt time.Time `db:"created_at" norm:"default:now()"`
+	Slug      string    `db:"slug" norm:"generated:(lower(name))"`
+	Note      string    `db:"note" norm:"-"`
+}
+
+func TestInsertStructsDefaults(t *testing.T) {
+	f := &fakeExec{}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: f}).Table("users")
+	users := []insStructsUser{{Name: "a", Status: "new"}, {Name: "b", Nick: "bee"}}
+	if _, err := qb.InsertStructs(context.Background(), users); err != nil {
+		t.Fatal(err)
+	}
+	want := `INSERT INTO users ("name", "nick", "status") VALUES ($1, DEFAULT, $2), ($3, $4, DEFAULT)`
+	if f.lastSQL != want {
+		t.Fatalf("sql=%s", f.lastSQL)
+	}
+	if !reflect.DeepEqual(f.lastArgs, []any{"a", "new", "b", "bee"}) {
+		t.Fatalf("args=%v", f.lastArgs)
+	}
+}
+
+func TestInsertStructsReturningScansBack(t *testing.T) {
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	f := &fakeExecRU{rows: [][]any{{int64(7), at}, {int64(8), at}}, fields: []string{"id", "created_at"}}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: f}).Table("users").Returning("id", "created_at")
+	users := []*insStructsUser{{Name: "a"}, {ID: 5, Name: "b"}}
+	n, err := qb.InsertStructs(context.Background(), &users)
+	if err != nil || n != 2 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	want := `INSERT INTO users ("id", "name") VALUES (DEFAULT, $1), ($2, $3) RETURNING "id", "created_at"`
+	if f.lastSQL != want {
+		t.Fatalf("sql=%s", f.lastSQL)
+	}
+	if users[0].ID != 7 || users[1].ID != 8 || !users[0].CreatedAt.Equal(at) {
+		t.Fatalf("users=%+v %+v", users[0], users[1])
+	}
+}
+
+func TestInsertStructsValidation(t *testing.T) {
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: &fakeExec{}}).Table("users")
+	if _, err := qb.InsertStructs(context.Background(), insStructsUser{}); err == nil {
+		t.Fatal("expected error for non-slice")
+	}
+	if n, err := qb.InsertStructs(context.Background(), []insStructsUser{}); err != nil || n != 0 {
+		t.Fatalf("empty: n=%d err=%v", n, err)
+	}
+	qb = (&QueryBuilder{kn: &KintsNorm{}, exec: &fakeExec{}}).Table("users").OnConflict("name").Returning("id")
+	if _, err := qb.InsertStructs(context.Background(), []insStructsUser{{Name: "a"}}); err == nil {
+		t.Fatal("expected error for RETURNING with DO NOTHING")
+	}
+}
@@ -3,6 +3,7 @@ package norm
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/jackc/pgx/v5"
@@ -194,3 +195,22 @@ func TestWhereNamed_StoresValidationError(t *testing.T) {
 		t.Fatalf("unexpected execution: %s", f.lastSQL)
 	}
 }
+
+func TestBuildSelect_OrderByExpr(t *testing.T) {
+	kn := &KintsNorm{templates: newQueryTemplateCache(8)}
+	for _, term := range []string{"alice", "bob"} {
+		qb := (&QueryBuilder{kn: kn}).Table("users").Where("active = ?", true).Having("count(*) > ?", 1).OrderByExpr("similarity(name, ?) DESC", term).Limit(5)
+		sql, args := qb.buildSelect()
+		want := "SELECT * FROM users WHERE active = $1 HAVING count(*) > $2 ORDER BY similarity(name, $3) DESC LIMIT 5"
+		if sql != want || !reflect.DeepEqual(args, []any{true, 1, term}) {
+			t.Fatalf("sql=%s args=%v", sql, args)
+		}
+	}
+	qb := (&QueryBuilder{}).Table("users").OrderByExpr("similarity(name, ?) DESC", "x")
+	if sql, args := qb.buildCount(); sql != "SELECT count(*) FROM users" || len(args) != 0 {
+		t.Fatalf("count sql=%s args=%v", sql, args)
+	}
+	if sql, args := qb.OrderBy("id").buildSelect(); sql != "SELECT * FROM users ORDER BY id" || len(args) != 0 {
+		t.Fatalf("sql=%s args=%v", sql, args)
+	}
+}
@@ -0,0 +1,108 @@
+package norm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// mapCache is an in-memory Cache counting lookups
+type mapCache struct {
+	data map[string][]byte
+	gets int
+}
+
+func (c *mapCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	c.gets++
+	b, ok := c.data[key]
+	return b, ok, nil
+}
+
+func (c *mapCache) Set(_ context.Context, key string, value []byte, _ time.Duration) error {
+	c.data[key] = value
+	return nil
+}
+
+func (c *mapCache) Invalidate(_ context.Context, keys ...string) error {
+	for _, k := range keys {
+		delete(c.data, k)
+	}
+	return nil
+}
+
+func TestQueryBuilder_CacheBypassAndRefresh(t *testing.T) {
+	stale, _ := json.Marshal([]map[string]any{{"id": 1}})
+	cache := &mapCache{data: map[string][]byte{"users": stale}}
+	kn := &KintsNorm{cache: cache}
+	f := &fakeExec{rows: [][]any{{int64(2)}}, fields: []string{"id"}}
+	find := func(qb *QueryBuilder) []map[string]any {
+		t.Helper()
+		var out []map[string]any
+		if err := qb.Find(context.Background(), &out); err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+	newQB := func() *QueryBuilder {
+		return (&QueryBuilder{kn: kn, exec: f}).Table("users").WithCacheKey("users", time.Minute)
+	}
+
+	if out := find(newQB().CacheBypass()); len(out) != 1 || out[0]["id"] != int64(2) {
+		t.Fatalf("bypass read %v", out)
+	}
+	if cache.gets != 0 || string(cache.data["users"]) != string(stale) {
+		t.Fatalf("bypass touched the cache: gets=%d entry=%s", cache.gets, cache.data["users"])
+	}
+
+	if out := find(newQB().CacheRefresh()); len(out) != 1 || out[0]["id"] != int64(2) {
+		t.Fatalf("refresh read %v", out)
+	}
+	if cache.gets != 0 || string(cache.data["users"]) != `[{"id":2}]` {
+		t.Fatalf("refresh: gets=%d entry=%s", cache.gets, cache.data["users"])
+	}
+
+	f.rows = nil
+	if out := find(newQB()); len(out) != 1 || out[0]["id"] != float64(2) {
+		t.Fatalf("cached read %v", out)
+	}
+}
+
+func TestQueryBuilder_TenantScopedCacheKeys(t *testing.T) {
+	cache := &mapCache{data: map[string][]byte{}}
+	kn := &KintsNorm{cache: cache, metrics: NoopMetrics{}}
+	f := &fakeExec{rows: [][]any{{int64(1)}}, fields: []string{"id"}}
+	forTenant := func(name string) *QueryBuilder {
+		qb := kn.ForTenant(name).Query()
+		qb.exec = f
+		return qb.Table("users")
+	}
+	var out []map[string]any
+	if err := forTenant("acme").WithCacheKey("users", time.Minute).Find(context.Background(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.data["tenant:acme:users"]; !ok || len(cache.data) != 1 {
+		t.Fatalf("entries: %v", cache.data)
+	}
+	// another tenant misses acme's entry and reads its own rows
+	f.rows = [][]any{{int64(2)}}
+	out = nil
+	if err := forTenant("globex").WithCacheKey("users", time.Minute).Find(context.Background(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 1 || out[0]["id"] != int64(2) || len(cache.data) != 2 {
+		t.Fatalf("globex read %v, entries %v", out, cache.data)
+	}
+	if _, err := forTenant("globex").WithInvalidateKeys("users").Where("id = ?", 2).Delete(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.data["tenant:globex:users"]; ok || len(cache.data) != 1 {
+		t.Fatalf("entries after invalidation: %v", cache.data)
+	}
+
+	a := rlsCacheScope(RLSContext{SessionVars: map[string]string{"app.tenant_id": "1"}})
+	b := rlsCacheScope(RLSContext{SessionVars: map[string]string{"app.tenant_id": "2"}})
+	if a == b || a == "" || rlsCacheScope(RLSContext{}) != "" {
+		t.Fatalf("rls scopes %q %q", a, b)
+	}
+}
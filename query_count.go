@@ -0,0 +1,136 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// buildCount derives a COUNT query from the builder, ignoring ORDER BY/LIMIT/OFFSET.
+// Grouped, DISTINCT and raw queries are wrapped as SELECT count(*) FROM (...) t so the total counts result rows
+// rather than the rows of each group.
+func (qb *QueryBuilder) buildCount() (string, []any) {
+	c := *qb
+	c.orderBy, c.limit, c.offset, c.lock = "", 0, 0, ""
+	if c.isRaw || len(c.groupBy) > 0 || c.selectsDistinct() {
+		inner, args := c.buildSelect()
+		return "SELECT count(*) FROM (" + inner + ") t", args
+	}
+	c.columns = []string{"count(*)"}
+	return c.buildSelect()
+}
+
+func (qb *QueryBuilder) selectsDistinct() bool {
+	return len(qb.columns) > 0 && strings.HasPrefix(strings.ToUpper(strings.TrimSpace(qb.columns[0])), "DISTINCT")
+}
+
+// Count returns the number of rows the query would return without ORDER BY/LIMIT/OFFSET
+func (qb *QueryBuilder) Count(ctx context.Context) (int64, error) {
+	if err := qb.queryError(); err != nil {
+		return 0, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if qb.concurrencyKey != "" {
+		var n int64
+		err := qb.runLimited(ctx, func(q *QueryBuilder) (err error) {
+			n, err = q.Count(ctx)
+			return err
+		})
+		return n, err
+	}
+	query, args := qb.buildCount()
+	rows, err := qb.runQuery(ctx, query, args)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	var n int64
+	if rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			return 0, wrapPgError(err, query, args)
+		}
+		if len(vals) > 0 {
+			switch v := vals[0].(type) {
+			case int64:
+				n = v
+			case int32:
+				n = int64(v)
+			case int:
+				n = int64(v)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, wrapPgError(err, query, args)
+	}
+	return n, nil
+}
+
+// buildExists derives SELECT EXISTS(SELECT 1 ... LIMIT 1) from the builder, ignoring ORDER BY/OFFSET
+func (qb *QueryBuilder) buildExists() (string, []any) {
+	c := *qb
+	c.orderBy, c.limit, c.offset, c.lock = "", 1, 0, ""
+	if !c.isRaw {
+		c.columns = []string{"1"}
+	}
+	inner, args := c.buildSelect()
+	return "SELECT EXISTS(" + inner + ")", args
+}
+
+// Exists reports whether the query matches at least one row; the server stops at the first match
+func (qb *QueryBuilder) Exists(ctx context.Context) (bool, error) {
+	if err := qb.queryError(); err != nil {
+		return false, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if qb.concurrencyKey != "" {
+		var found bool
+		err := qb.runLimited(ctx, func(q *QueryBuilder) (err error) {
+			found, err = q.Exists(ctx)
+			return err
+		})
+		return found, err
+	}
+	query, args := qb.buildExists()
+	started := time.Now()
+	var found bool
+	err := qb.exec.QueryRow(ctx, query, args...).Scan(&found)
+	if qb.kn != nil && qb.kn.logger != nil && (qb.kn.logMode == LogDebug || qb.kn.logMode == LogInfo || (qb.kn.logMode == LogSilent && qb.forceDebug)) {
+		qb.kn.logger.Debug("query", qb.kn.makeLogFields(ctx, query, args)...)
+	}
+	if qb.kn != nil && qb.kn.metrics != nil {
+		qb.kn.metrics.QueryDuration(time.Since(started), query)
+	}
+	if err != nil {
+		return false, wrapPgError(err, query, args)
+	}
+	return found, nil
+}
+
+// FindPage scans one page of the query into dest and returns the total row count (grouping-aware, see Count).
+// page.OrderBy, when set, overrides the builder's ORDER BY.
+func (qb *QueryBuilder) FindPage(ctx context.Context, dest any, page PageRequest) (int64, error) {
+	total, err := qb.Count(ctx)
+	if err != nil {
+		return 0, err
+	}
+	p := *qb
+	if page.OrderBy != "" {
+		p.orderBy, p.orderArgs = page.OrderBy, nil
+	}
+	if page.Limit > 0 {
+		p.limit = page.Limit
+	}
+	if page.Offset > 0 {
+		p.offset = page.Offset
+	}
+	if err := p.Find(ctx, dest); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
@@ -0,0 +1,83 @@
+package norm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestBuildSelect_GroupByHaving(t *testing.T) {
+	qb := (&QueryBuilder{}).Table("orders").Select("user_id", "sum(amount)").Where("status = ?", "paid").GroupBy("user_id").Having("sum(amount) > ?", 100).OrderBy("user_id").Limit(10)
+	sql, args := qb.buildSelect()
+	want := "SELECT user_id, sum(amount) FROM orders WHERE status = $1 GROUP BY user_id HAVING sum(amount) > $2 ORDER BY user_id LIMIT 10"
+	if sql != want || !reflect.DeepEqual(args, []any{"paid", 100}) {
+		t.Fatalf("sql=%s args=%v", sql, args)
+	}
+}
+
+func TestBuildCount_WrapsGroupedQueries(t *testing.T) {
+	grouped := (&QueryBuilder{}).Table("orders").Select("user_id").Where("status = ?", "paid").GroupBy("user_id").Having("count(*) > ?", 1).OrderBy("user_id").Limit(5).Offset(10)
+	sql, args := grouped.buildCount()
+	want := "SELECT count(*) FROM (SELECT user_id FROM orders WHERE status = $1 GROUP BY user_id HAVING count(*) > $2) t"
+	if sql != want || len(args) != 2 {
+		t.Fatalf("grouped sql=%s args=%v", sql, args)
+	}
+	if grouped.limit != 5 {
+		t.Fatalf("builder mutated")
+	}
+	plain := (&QueryBuilder{}).Table("orders").Where("status = ?", "paid").OrderBy("id").Limit(5)
+	if sql, _ := plain.buildCount(); sql != "SELECT count(*) FROM orders WHERE status = $1" {
+		t.Fatalf("plain sql=%s", sql)
+	}
+	distinct := (&QueryBuilder{}).Table("orders").Select("DISTINCT user_id")
+	if sql, _ := distinct.buildCount(); sql != "SELECT count(*) FROM (SELECT DISTINCT user_id FROM orders) t" {
+		t.Fatalf("distinct sql=%s", sql)
+	}
+}
+
+func TestQueryBuilder_FindPage_Grouped(t *testing.T) {
+	ex := &fakeExecRU{rows: [][]any{{int64(3)}}, fields: []string{"count"}}
+	qb := &QueryBuilder{kn: &KintsNorm{}, exec: ex}
+	var out []map[string]any
+	total, err := qb.Table("orders").Select("user_id").GroupBy("user_id").FindPage(context.Background(), &out, PageRequest{Limit: 2, Offset: 2, OrderBy: "user_id"})
+	if err != nil || total != 3 {
+		t.Fatalf("total=%d err=%v", total, err)
+	}
+	if ex.lastSQL != "SELECT user_id FROM orders GROUP BY user_id ORDER BY user_id LIMIT 2 OFFSET 2" {
+		t.Fatalf("page sql=%s", ex.lastSQL)
+	}
+}
+
+// existsExec answers QueryRow with a fixed bool and records the statement
+type existsExec struct {
+	recExecRepo
+	found bool
+}
+
+func (e *existsExec) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	e.lastSQL, e.lastArgs = sql, args
+	return boolRow(e.found)
+}
+
+func TestExists_SelectOneLimitOne(t *testing.T) {
+	ex := &existsExec{found: true}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: ex}).Table("orders").Select("id", "total").Where("status = ?", "paid").OrderBy("id").Offset(20)
+	ok, err := qb.Exists(context.Background())
+	if err != nil || !ok {
+		t.Fatalf("ok=%v err=%v", ok, err)
+	}
+	if ex.lastSQL != "SELECT EXISTS(SELECT 1 FROM orders WHERE status = $1 LIMIT 1)" {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+
+	r := &repo[softUser]{kn: &KintsNorm{}, exec: ex}
+	ex.found = false
+	if ok, err := r.Exists(context.Background(), Eq("id", 1)); err != nil || ok {
+		t.Fatalf("ok=%v err=%v", ok, err)
+	}
+	if ex.lastSQL != "SELECT EXISTS(SELECT 1 FROM soft_users WHERE id = $1 AND deleted_at IS NULL LIMIT 1)" {
+		t.Fatalf("repo sql=%s", ex.lastSQL)
+	}
+}
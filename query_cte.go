@@ -0,0 +1,94 @@
+package norm
+
+import (
+	"strings"
+
+	sqlutil "github.com/kintsdev/norm/internal/sqlutil"
+)
+
+// cteClause is one "name AS (sql)" entry of a WITH clause; sql is numbered from $1
+type cteClause struct {
+	name      string
+	sql       string
+	args      []any
+	recursive bool
+}
+
+// With adds a common table expression to the query: WITH name AS (sub) SELECT ...
+// name may carry a column list, e.g. "totals(user_id, spent)". Select from it with Table(name).
+func (qb *QueryBuilder) With(name string, sub *QueryBuilder) *QueryBuilder {
+	if sub == nil {
+		qb.setError(errNilSubquery)
+		return qb
+	}
+	if err := sub.queryError(); err != nil {
+		qb.inheritError(err)
+		return qb
+	}
+	sql, args := sub.buildSelect()
+	qb.ctes = append(qb.ctes, cteClause{name: name, sql: sql, args: args})
+	return qb
+}
+
+// WithRecursive adds a recursive CTE: WITH RECURSIVE name AS (base UNION ALL recursive).
+// The recursive builder references name itself, e.g. for a comment tree:
+//
+//	base := kn.Query().Table("comments").Where("id = ?", rootID)
+//	step := kn.Query().Table("comments c").Select("c.*").Join("tree t", "c.parent_id = t.id")
+//	kn.Query().WithRecursive("tree", base, step).Table("tree").Find(ctx, &comments)
+func (qb *QueryBuilder) WithRecursive(name string, base, recursive *QueryBuilder) *QueryBuilder {
+	if base == nil || recursive == nil {
+		qb.setError(errNilSubquery)
+		return qb
+	}
+	for _, sub := range []*QueryBuilder{base, recursive} {
+		if err := sub.queryError(); err != nil {
+			qb.inheritError(err)
+			return qb
+		}
+	}
+	baseSQL, baseArgs := base.buildSelect()
+	stepSQL, stepArgs := recursive.buildSelect()
+	args := make([]any, 0, len(baseArgs)+len(stepArgs))
+	args = append(append(args, baseArgs...), stepArgs...)
+	sql := baseSQL + " UNION ALL " + sqlutil.RenumberPlaceholders(stepSQL, len(baseArgs))
+	qb.ctes = append(qb.ctes, cteClause{name: name, sql: sql, args: args, recursive: true})
+	return qb
+}
+
+// compileWith renders the WITH clause, numbering each CTE after the args of the previous ones
+func (qb *QueryBuilder) compileWith() string {
+	var sb strings.Builder
+	sb.WriteString("WITH ")
+	for _, c := range qb.ctes {
+		if c.recursive {
+			sb.WriteString("RECURSIVE ")
+			break
+		}
+	}
+	offset := 0
+	for i, c := range qb.ctes {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(c.name)
+		sb.WriteString(" AS (")
+		sb.WriteString(sqlutil.RenumberPlaceholders(c.sql, offset))
+		sb.WriteString(")")
+		offset += len(c.args)
+	}
+	return sb.String()
+}
+
+// leadingArgs returns the args bound before the WHERE clause: CTEs first, then a FROM subquery
+func (qb *QueryBuilder) leadingArgs() []any {
+	n := len(qb.fromArgs) + len(qb.args)
+	for _, c := range qb.ctes {
+		n += len(c.args)
+	}
+	args := make([]any, 0, n)
+	for _, c := range qb.ctes {
+		args = append(args, c.args...)
+	}
+	return append(args, qb.fromArgs...)
+}
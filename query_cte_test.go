@@ -0,0 +1,51 @@
+package norm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWith_NumbersCTEArgsFirst(t *testing.T) {
+	kn := &KintsNorm{}
+	paid := kn.Query().Table("orders").Select("user_id", "sum(total) AS spent").Where("status = ?", "paid").GroupBy("user_id")
+	q, args := kn.Query().With("totals", paid).Table("totals").Where("spent > ?", 100).OrderBy("spent DESC").buildSelect()
+	want := `WITH totals AS (SELECT user_id, sum(total) AS spent FROM orders WHERE status = $1 GROUP BY user_id) SELECT * FROM totals WHERE spent > $2 ORDER BY spent DESC`
+	if q != want {
+		t.Fatalf("sql:\n got %s\nwant %s", q, want)
+	}
+	if !reflect.DeepEqual(args, []any{"paid", 100}) {
+		t.Fatalf("args: %v", args)
+	}
+}
+
+func TestWithRecursive_CommentTree(t *testing.T) {
+	kn := &KintsNorm{}
+	base := kn.Query().Table("comments").Where("id = ?", 1)
+	step := kn.Query().Table("comments c").Select("c.*").Join("tree t", "c.parent_id = t.id").Where("c.hidden = ?", false)
+	q, args := kn.Query().WithRecursive("tree", base, step).Table("tree").Where("depth < ?", 5).buildSelect()
+	want := `WITH RECURSIVE tree AS (SELECT * FROM comments WHERE id = $1 UNION ALL SELECT c.* FROM comments c JOIN tree t ON c.parent_id = t.id WHERE c.hidden = $2) SELECT * FROM tree WHERE depth < $3`
+	if q != want {
+		t.Fatalf("sql:\n got %s\nwant %s", q, want)
+	}
+	if !reflect.DeepEqual(args, []any{1, false, 5}) {
+		t.Fatalf("args: %v", args)
+	}
+}
+
+func TestWith_CombinedWithFromSubAndCache(t *testing.T) {
+	kn := &KintsNorm{templates: newQueryTemplateCache(8)}
+	build := func(v int) (string, []any) {
+		cte := kn.Query().Table("a").Where("x = ?", v)
+		sub := kn.Query().Table("b").Where("y = ?", v+1)
+		return kn.Query().With("ca", cte).FromSub(sub, "s").Where("z = ?", v+2).buildSelect()
+	}
+	q1, a1 := build(1)
+	q2, a2 := build(10)
+	want := `WITH ca AS (SELECT * FROM a WHERE x = $1) SELECT * FROM (SELECT * FROM b WHERE y = $2) AS s WHERE z = $3`
+	if q1 != want || q2 != want {
+		t.Fatalf("sql:\n got %s / %s\nwant %s", q1, q2, want)
+	}
+	if !reflect.DeepEqual(a1, []any{1, 2, 3}) || !reflect.DeepEqual(a2, []any{10, 11, 12}) {
+		t.Fatalf("args: %v %v", a1, a2)
+	}
+}
@@ -0,0 +1,77 @@
+package norm
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DeadlineMetrics can optionally be implemented by a Metrics collector to observe how much of the caller's
+// context deadline is left when each statement starts. ok is false for a context without a deadline;
+// remaining is zero or negative when the context has already expired.
+type DeadlineMetrics interface {
+	QueryDeadline(remaining time.Duration, ok bool, query string)
+}
+
+// WithDeadlineWarning logs a "query_near_deadline" warning for every statement started with less than
+// threshold left on its context's deadline (or with the deadline already passed). Such statements are
+// likely to fail with a timeout no matter how fast the database is, which usually points at a deadline
+// spent upstream rather than at the query itself.
+func WithDeadlineWarning(threshold time.Duration) Option {
+	return func(o *options) { o.deadlineWarning = threshold }
+}
+
+// withDeadlineAudit wraps exec to report the remaining deadline of each statement to the metrics and
+// logger of kn; it is a no-op unless the collector implements DeadlineMetrics or WithDeadlineWarning is set
+func withDeadlineAudit(kn *KintsNorm, exec dbExecuter) dbExecuter {
+	if kn == nil {
+		return exec
+	}
+	dm, _ := kn.metrics.(DeadlineMetrics)
+	if dm == nil && (kn.deadlineWarning <= 0 || kn.logger == nil) {
+		return exec
+	}
+	return deadlineExecuter{kn: kn, metrics: dm, exec: exec}
+}
+
+// deadlineExecuter observes the context deadline at the start of every statement
+type deadlineExecuter struct {
+	kn      *KintsNorm
+	metrics DeadlineMetrics
+	exec    dbExecuter
+}
+
+func (e deadlineExecuter) unwrap() dbExecuter { return e.exec }
+
+func (e deadlineExecuter) observe(ctx context.Context, sql string, args []any) {
+	deadline, ok := ctx.Deadline()
+	var remaining time.Duration
+	if ok {
+		remaining = time.Until(deadline)
+	}
+	if e.metrics != nil {
+		e.metrics.QueryDeadline(remaining, ok, sql)
+	}
+	if ok && e.kn.deadlineWarning > 0 && e.kn.logger != nil && remaining < e.kn.deadlineWarning {
+		fields := e.kn.makeLogFields(ctx, sql, args)
+		fields = append(fields, Field{Key: "remaining_ms", Value: remaining.Milliseconds()})
+		e.kn.logger.Warn("query_near_deadline", fields...)
+	}
+}
+
+func (e deadlineExecuter) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	e.observe(ctx, sql, arguments)
+	return e.exec.Exec(ctx, sql, arguments...)
+}
+
+func (e deadlineExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	e.observe(ctx, sql, args)
+	return e.exec.Query(ctx, sql, args...)
+}
+
+func (e deadlineExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	e.observe(ctx, sql, args)
+	return e.exec.QueryRow(ctx, sql, args...)
+}
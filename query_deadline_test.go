@@ -0,0 +1,63 @@
+package norm
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type deadlineMetrics struct {
+	NoopMetrics
+	remaining []time.Duration
+	without   int
+}
+
+func (m *deadlineMetrics) QueryDeadline(remaining time.Duration, ok bool, _ string) {
+	if !ok {
+		m.without++
+		return
+	}
+	m.remaining = append(m.remaining, remaining)
+}
+
+type warnLogger struct {
+	testLogger
+	warnings []string
+}
+
+func (l *warnLogger) Warn(msg string, _ ...Field) { l.warnings = append(l.warnings, msg) }
+
+func TestDeadlineAudit(t *testing.T) {
+	m := &deadlineMetrics{}
+	l := &warnLogger{}
+	kn := &KintsNorm{metrics: m, logger: l, deadlineWarning: 100 * time.Millisecond}
+	exec := wrapExec(kn, &recExecRepo{})
+
+	if _, err := exec.Exec(context.Background(), "UPDATE t SET a = 1"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err := exec.Exec(ctx, "UPDATE t SET a = 2"); err != nil {
+		t.Fatal(err)
+	}
+	near, cancelNear := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelNear()
+	if _, err := exec.Exec(near, "UPDATE t SET a = 3"); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.without != 1 || len(m.remaining) != 2 || m.remaining[0] < 59*time.Second || m.remaining[1] > 10*time.Millisecond {
+		t.Fatalf("without=%d remaining=%v", m.without, m.remaining)
+	}
+	if len(l.warnings) != 1 || l.warnings[0] != "query_near_deadline" {
+		t.Fatalf("warnings: %v", l.warnings)
+	}
+}
+
+func TestDeadlineAudit_DisabledByDefault(t *testing.T) {
+	kn := &KintsNorm{metrics: NoopMetrics{}, logger: NoopLogger{}}
+	if _, ok := wrapExec(kn, &recExecRepo{}).(deadlineExecuter); ok {
+		t.Fatal("deadline audit wrapped without a collector or threshold")
+	}
+}
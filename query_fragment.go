@@ -0,0 +1,60 @@
+package norm
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/kintsdev/norm/internal/sqlutil"
+)
+
+var fragmentRegistry struct {
+	mu  sync.RWMutex
+	sql map[string]string
+}
+
+// Fragment registers a reusable SQL snippet under name, so predicates repeated across queries live in
+// one place. Values are :name placeholders bound by Apply. Registering a name again replaces its SQL;
+// call it from init() or main, next to the models it belongs to.
+//
+//	norm.Fragment("active_users", "deleted_at IS NULL AND last_seen_at > now() - :idle::interval")
+//	db.Query().Table("users").Apply("active_users", map[string]any{"idle": "30 days"}).Find(ctx, &rows)
+func Fragment(name, sql string) {
+	fragmentRegistry.mu.Lock()
+	defer fragmentRegistry.mu.Unlock()
+	if fragmentRegistry.sql == nil {
+		fragmentRegistry.sql = map[string]string{}
+	}
+	fragmentRegistry.sql[name] = sql
+}
+
+// LookupFragment returns the SQL registered under name. Fragments without placeholders, such as window
+// expressions, can be passed on to Select or OrderBy.
+func LookupFragment(name string) (string, bool) {
+	fragmentRegistry.mu.RLock()
+	defer fragmentRegistry.mu.RUnlock()
+	sql, ok := fragmentRegistry.sql[name]
+	return sql, ok
+}
+
+// Apply adds the fragment registered under name as a WHERE condition, binding its :name placeholders from
+// args (later maps win). An unknown fragment or a missing value fails the query with a validation error.
+func (qb *QueryBuilder) Apply(name string, args ...map[string]any) *QueryBuilder {
+	sql, ok := LookupFragment(name)
+	if !ok {
+		qb.setError(fmt.Errorf("unknown SQL fragment %q", name))
+		return qb
+	}
+	named := map[string]any{}
+	for _, a := range args {
+		maps.Copy(named, a)
+	}
+	conv, ordered, err := sqlutil.ConvertNamedToPgPlaceholders(sql, named)
+	if err != nil {
+		qb.setError(fmt.Errorf("SQL fragment %q: %w", name, err))
+		return qb
+	}
+	// back to ? placeholders, so the fragment is numbered with the other conditions
+	where, whereArgs := sqlutil.PgPlaceholdersToQMarks(conv, ordered)
+	return qb.Where(where, whereArgs...)
+}
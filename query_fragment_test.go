@@ -0,0 +1,28 @@
+package norm
+
+import "testing"
+
+func TestApplyFragment(t *testing.T) {
+	Fragment("test_active_users", "deleted_at IS NULL AND last_seen_at > now() - :idle::interval")
+	Fragment("test_rank", "rank() OVER (ORDER BY score DESC)")
+
+	qb := (&QueryBuilder{kn: &KintsNorm{}}).Table("users").Where("tenant_id = ?", 5).
+		Apply("test_active_users", map[string]any{"idle": "1 day"}, map[string]any{"idle": "30 days"})
+	sql, args := qb.buildSelect()
+	if sql != "SELECT * FROM users WHERE tenant_id = $1 AND deleted_at IS NULL AND last_seen_at > now() - $2::interval" {
+		t.Fatalf("sql=%s", sql)
+	}
+	if len(args) != 2 || args[1] != "30 days" {
+		t.Fatalf("args=%v", args)
+	}
+	if rank, ok := LookupFragment("test_rank"); !ok || rank != "rank() OVER (ORDER BY score DESC)" {
+		t.Fatalf("lookup: %q %v", rank, ok)
+	}
+
+	if err := (&QueryBuilder{kn: &KintsNorm{}}).Table("users").Apply("test_missing").queryError(); err == nil {
+		t.Fatal("unknown fragment accepted")
+	}
+	if err := (&QueryBuilder{kn: &KintsNorm{}}).Table("users").Apply("test_active_users").queryError(); err == nil {
+		t.Fatal("missing named value accepted")
+	}
+}
@@ -0,0 +1,287 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/kintsdev/norm/internal/sqlutil"
+)
+
+// ErrQueryDenied is wrapped (in an ORMError with ErrCodeValidation) by statements rejected by a query guard rule
+var ErrQueryDenied = errors.New("query denied by guard")
+
+// QueryGuardRule inspects a statement before it is sent; a non-nil error rejects it
+type QueryGuardRule func(sql string) error
+
+// guardExecuter runs the configured guard rules before delegating to exec
+type guardExecuter struct {
+	rules []QueryGuardRule
+	exec  dbExecuter
+}
+
+func (g guardExecuter) unwrap() dbExecuter { return g.exec }
+
+func (g guardExecuter) check(sql string) error {
+	for _, rule := range g.rules {
+		if err := rule(sql); err != nil {
+			return &ORMError{Code: ErrCodeValidation, Message: "query guard: " + err.Error(), Internal: fmt.Errorf("%w: %w", ErrQueryDenied, err), Query: sql}
+		}
+	}
+	return nil
+}
+
+func (g guardExecuter) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	if err := g.check(sql); err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	return g.exec.Exec(ctx, sql, arguments...)
+}
+
+func (g guardExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if err := g.check(sql); err != nil {
+		return nil, err
+	}
+	return g.exec.Query(ctx, sql, args...)
+}
+
+func (g guardExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if err := g.check(sql); err != nil {
+		return errorRow{err: err}
+	}
+	return g.exec.QueryRow(ctx, sql, args...)
+}
+
+// wrapExec applies the circuit breaker, query guard, interceptors, default query timeout, deadline audit and
+// result metrics of kn to exec (once; wrapped executors are returned as-is)
+func wrapExec(kn *KintsNorm, exec dbExecuter) dbExecuter {
+	switch exec.(type) {
+	case guardExecuter, interceptExecuter, timeoutExecuter, deadlineExecuter, resultExecuter:
+		return exec
+	}
+	if p, ok := exec.(*pgxpool.Pool); ok {
+		exec = kn.poolExec(p)
+	}
+	exec = withBreaker(kn, exec)
+	if kn != nil && len(kn.guardRules) > 0 {
+		exec = guardExecuter{rules: kn.guardRules, exec: exec}
+	}
+	return withResultAudit(kn, withDeadlineAudit(kn, withDefaultTimeout(kn, withInterceptors(kn, exec))))
+}
+
+// wrapRouting is wrapExec for the read/write routing executer, which applies the breaker itself
+func wrapRouting(kn *KintsNorm) dbExecuter {
+	exec := dbExecuter(routingExecuter{kn: kn})
+	if len(kn.guardRules) > 0 {
+		exec = guardExecuter{rules: kn.guardRules, exec: exec}
+	}
+	return withResultAudit(kn, withDeadlineAudit(kn, withDefaultTimeout(kn, withInterceptors(kn, exec))))
+}
+
+// statementVerb returns the leading keyword of sql in upper case, skipping whitespace and comments
+func statementVerb(sql string) string {
+	s := strings.TrimSpace(sql)
+	for {
+		switch {
+		case strings.HasPrefix(s, "--"):
+			if i := strings.IndexByte(s, '\n'); i >= 0 {
+				s = strings.TrimSpace(s[i+1:])
+				continue
+			}
+			return ""
+		case strings.HasPrefix(s, "/*"):
+			if i := strings.Index(s, "*/"); i >= 0 {
+				s = strings.TrimSpace(s[i+2:])
+				continue
+			}
+			return ""
+		}
+		break
+	}
+	end := strings.IndexFunc(s, func(r rune) bool { return r == ' ' || r == '\t' || r == '\n' || r == '(' || r == ';' })
+	if end < 0 {
+		end = len(s)
+	}
+	return strings.ToUpper(s[:end])
+}
+
+// DenyDDL rejects schema changes (CREATE, ALTER, DROP, TRUNCATE, GRANT, REVOKE, COMMENT). Migrations run through
+// the migrator's own connection and are not affected.
+func DenyDDL() QueryGuardRule {
+	return func(sql string) error {
+		switch v := statementVerb(sql); v {
+		case "CREATE", "ALTER", "DROP", "TRUNCATE", "GRANT", "REVOKE", "COMMENT":
+			return fmt.Errorf("%s statements are not allowed outside migrations", v)
+		}
+		return nil
+	}
+}
+
+// DenyUnfilteredWrites rejects UPDATE and DELETE statements without a WHERE clause of their own, also
+// when they follow or sit inside leading WITH queries. Comments, literals, quoted identifiers and
+// dollar-quoted bodies never count as the filter.
+func DenyUnfilteredWrites() QueryGuardRule {
+	return func(sql string) error {
+		return checkFiltered(sqlutil.Blank(sql))
+	}
+}
+
+// checkFiltered applies DenyUnfilteredWrites to a blanked statement and to each of its WITH queries
+func checkFiltered(s string) error {
+	ctes, main := splitWith(s)
+	for _, c := range ctes {
+		if err := checkFiltered(c); err != nil {
+			return err
+		}
+	}
+	v, _, _ := sqlToken(main, 0)
+	v = strings.ToUpper(v)
+	if (v == "UPDATE" || v == "DELETE") && !hasTopLevelWord(main, "WHERE") {
+		return fmt.Errorf("%s without WHERE", v)
+	}
+	return nil
+}
+
+// splitWith splits a blanked statement into the bodies of its leading WITH queries and the statement
+// that follows them; a statement without WITH is returned as-is
+func splitWith(s string) (ctes []string, main string) {
+	tok, _, i := sqlToken(s, 0)
+	if !strings.EqualFold(tok, "WITH") {
+		return nil, s
+	}
+	for {
+		// name [ (columns) ] AS [ [NOT] MATERIALIZED ] ( body )
+		afterAS := false
+	query:
+		for {
+			tok, start, end := sqlToken(s, i)
+			switch {
+			case tok == "":
+				return ctes, ""
+			case tok == "(":
+				closing := matchParen(s, start)
+				i = closing + 1
+				if afterAS {
+					ctes = append(ctes, s[start+1:min(closing, len(s))])
+					break query
+				}
+			case strings.EqualFold(tok, "AS"):
+				afterAS = true
+				i = end
+			default:
+				i = end
+			}
+		}
+		tok, start, end := sqlToken(s, i)
+		if tok != "," {
+			return ctes, s[start:]
+		}
+		i = end
+	}
+}
+
+// sqlToken returns the word or punctuation character of a blanked statement at or after i, with its
+// bounds; tok is empty at the end of s
+func sqlToken(s string, i int) (tok string, start, end int) {
+	for i < len(s) && s[i] <= ' ' {
+		i++
+	}
+	if i >= len(s) {
+		return "", len(s), len(s)
+	}
+	if !sqlutil.IsIdentByte(s[i]) {
+		return s[i : i+1], i, i + 1
+	}
+	j := i
+	for j < len(s) && sqlutil.IsIdentByte(s[j]) {
+		j++
+	}
+	return s[i:j], i, j
+}
+
+// matchParen returns the index of the parenthesis closing the one at s[i], or len(s) when it is unclosed
+func matchParen(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// hasTopLevelWord reports whether keyword appears in a blanked statement outside parentheses
+func hasTopLevelWord(s, keyword string) bool {
+	depth := 0
+	for i := 0; i < len(s); {
+		tok, _, end := sqlToken(s, i)
+		switch {
+		case tok == "":
+			return false
+		case tok == "(":
+			depth++
+		case tok == ")":
+			depth--
+		case depth == 0 && strings.EqualFold(tok, keyword):
+			return true
+		}
+		i = end
+	}
+	return false
+}
+
+var schemaQualifiedRef = regexp.MustCompile(`(?i)\b(?:FROM|JOIN|INTO|UPDATE|TABLE)\s+(?:ONLY\s+)?("(?:[^"]|"")+"|[a-z_][a-z0-9_$]*)\s*\.`)
+
+// AllowSchemas rejects statements referencing tables of schemas other than those listed (unqualified names pass)
+func AllowSchemas(schemas ...string) QueryGuardRule {
+	allowed := make([]string, len(schemas))
+	for i, s := range schemas {
+		allowed[i] = strings.ToLower(s)
+	}
+	return func(sql string) error {
+		for _, m := range schemaQualifiedRef.FindAllStringSubmatch(sql, -1) {
+			schema := m[1]
+			if strings.HasPrefix(schema, `"`) {
+				schema = strings.ReplaceAll(schema[1:len(schema)-1], `""`, `"`)
+			}
+			if !slices.Contains(allowed, strings.ToLower(schema)) {
+				return fmt.Errorf("access to schema %q is not allowed", schema)
+			}
+		}
+		return nil
+	}
+}
+
+// DenyMatching rejects statements matching pattern (e.g. `(?i)\bpg_sleep\b`)
+func DenyMatching(pattern *regexp.Regexp) QueryGuardRule {
+	return func(sql string) error {
+		if pattern.MatchString(sql) {
+			return fmt.Errorf("statement matches denied pattern %s", pattern)
+		}
+		return nil
+	}
+}
+
+// AllowOnly rejects statements that match none of the patterns (an allow-list)
+func AllowOnly(patterns ...*regexp.Regexp) QueryGuardRule {
+	return func(sql string) error {
+		for _, p := range patterns {
+			if p.MatchString(sql) {
+				return nil
+			}
+		}
+		return errors.New("statement is not on the allow-list")
+	}
+}
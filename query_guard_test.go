@@ -0,0 +1,67 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestQueryGuard_Rules(t *testing.T) {
+	cases := []struct {
+		rule QueryGuardRule
+		sql  string
+		deny bool
+	}{
+		{DenyDDL(), "DROP TABLE users", true},
+		{DenyDDL(), "-- cleanup\n  truncate audit", true},
+		{DenyDDL(), "SELECT * FROM users", false},
+		{DenyUnfilteredWrites(), "DELETE FROM users", true},
+		{DenyUnfilteredWrites(), "UPDATE users SET note = 'where'", true},
+		{DenyUnfilteredWrites(), "UPDATE users SET x = $1 WHERE id = $2", false},
+		{DenyUnfilteredWrites(), "WITH x AS (SELECT 1) DELETE FROM t", true},
+		{DenyUnfilteredWrites(), "WITH x AS (SELECT id FROM a WHERE b) DELETE FROM t", true},
+		{DenyUnfilteredWrites(), "WITH RECURSIVE x(n) AS MATERIALIZED (SELECT 1), y AS (SELECT 2) UPDATE t SET a = 1 WHERE id IN (SELECT n FROM x)", false},
+		{DenyUnfilteredWrites(), "WITH d AS (DELETE FROM t RETURNING id) SELECT count(*) FROM d WHERE true", true},
+		{DenyUnfilteredWrites(), "UPDATE t SET body = $$ WHERE $$", true},
+		{DenyUnfilteredWrites(), "UPDATE t SET body = $fn$ x WHERE y $fn$", true},
+		{DenyUnfilteredWrites(), `DELETE FROM "where"`, true},
+		{DenyUnfilteredWrites(), "DELETE FROM t -- WHERE id = 1", true},
+		{DenyUnfilteredWrites(), "DELETE FROM t /* WHERE */", true},
+		{DenyUnfilteredWrites(), "UPDATE t SET a = (SELECT b FROM c WHERE d)", true},
+		{DenyUnfilteredWrites(), "DELETE FROM t WHERE body = $$x$$", false},
+		{AllowSchemas("public", "app"), `SELECT * FROM app.users JOIN "public".orgs o ON true`, false},
+		{AllowSchemas("public"), `SELECT * FROM users u JOIN billing.invoices i ON i.user_id = u.id`, true},
+		{AllowSchemas("public"), `INSERT INTO "Secret"."t" (a) VALUES ($1)`, true},
+		{DenyMatching(regexp.MustCompile(`(?i)\bpg_sleep\b`)), "SELECT pg_sleep(10)", true},
+		{AllowOnly(regexp.MustCompile(`^SELECT `)), "SELECT 1", false},
+		{AllowOnly(regexp.MustCompile(`^SELECT `)), "DELETE FROM t WHERE id = 1", true},
+	}
+	for _, c := range cases {
+		if err := c.rule(c.sql); (err != nil) != c.deny {
+			t.Errorf("%q: deny=%v err=%v", c.sql, c.deny, err)
+		}
+	}
+}
+
+func TestQueryGuard_ExecutorRejectsBeforeSending(t *testing.T) {
+	kn := &KintsNorm{guardRules: []QueryGuardRule{DenyUnfilteredWrites()}}
+	ex := &recExecRepo{}
+	r := NewRepositoryWithExecutor[repUser](kn, ex)
+	if err := r.Delete(context.Background(), 1); err != nil {
+		t.Fatalf("filtered delete should pass: %v", err)
+	}
+	qb := &QueryBuilder{kn: kn, exec: wrapExec(kn, ex)}
+	ex.lastSQL = ""
+	_, err := qb.Table("rep_users").HardDelete().Delete(context.Background())
+	var oe *ORMError
+	if !errors.Is(err, ErrQueryDenied) || !errors.As(err, &oe) || oe.Code != ErrCodeValidation {
+		t.Fatalf("expected guard error, got %v", err)
+	}
+	if ex.lastSQL != "" {
+		t.Fatalf("statement reached executor: %s", ex.lastSQL)
+	}
+	if _, ok := wrapExec(kn, wrapExec(kn, ex)).(guardExecuter); !ok {
+		t.Fatalf("expected guard executor")
+	}
+}
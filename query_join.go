@@ -0,0 +1,161 @@
+package norm
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// JoinRelation joins the table of to, deriving the ON clause from the fk:table(column) tag of fkColumn,
+// which may sit on either model:
+//
+//	db.Query().Model(&User{}).JoinRelation(&User{}, &Post{}, "user_id")
+//	// JOIN "posts" ON "posts"."user_id" = "users"."id"
+//
+// Renaming a column or table in the models therefore updates the join. A column without an fk tag
+// referencing the other model fails the query with ErrCodeValidation.
+func (qb *QueryBuilder) JoinRelation(from, to any, fkColumn string) *QueryBuilder {
+	return qb.joinRelation("JOIN", reflect.TypeOf(from), reflect.TypeOf(to), fkColumn)
+}
+
+// LeftJoinRelation is JoinRelation with a LEFT JOIN
+func (qb *QueryBuilder) LeftJoinRelation(from, to any, fkColumn string) *QueryBuilder {
+	return qb.joinRelation("LEFT JOIN", reflect.TypeOf(from), reflect.TypeOf(to), fkColumn)
+}
+
+// JoinOn is the type-parameter form of JoinRelation: it joins R's table to T's via fkColumn
+//
+//	qb := norm.JoinOn[User, Post](db.Model(&User{}), "user_id")
+func JoinOn[T, R any](qb *QueryBuilder, fkColumn string) *QueryBuilder {
+	return qb.joinRelation("JOIN", reflect.TypeFor[T](), reflect.TypeFor[R](), fkColumn)
+}
+
+func (qb *QueryBuilder) joinRelation(kind string, from, to reflect.Type, fkColumn string) *QueryBuilder {
+	on, err := relationJoinOn(from, to, fkColumn)
+	if err != nil {
+		qb.setError(err)
+		return qb
+	}
+	for to.Kind() == reflect.Pointer {
+		to = to.Elem()
+	}
+	qb.joins = append(qb.joins, kind+" "+quoteQualified(core.QualifiedTableName(to))+" ON "+on)
+	return qb
+}
+
+// relationJoinOn renders "child"."fk" = "parent"."pk" from whichever model declares fkColumn with an fk tag
+// referencing the other one
+func relationJoinOn(from, to reflect.Type, fkColumn string) (string, error) {
+	if from == nil || to == nil {
+		return "", fmt.Errorf("join relation: nil model")
+	}
+	for from.Kind() == reflect.Pointer {
+		from = from.Elem()
+	}
+	for to.Kind() == reflect.Pointer {
+		to = to.Elem()
+	}
+	// the joined model usually holds the fk (has-many); fall back to the base model (belongs-to)
+	for _, pair := range [][2]reflect.Type{{to, from}, {from, to}} {
+		child, parent := pair[0], pair[1]
+		fi, ok := core.StructMapper(child).FieldsByColumn[strings.ToLower(fkColumn)]
+		if !ok || fi.FKTable == "" || !sameTable(fi.FKTable, core.QualifiedTableName(parent)) {
+			continue
+		}
+		return fmt.Sprintf("%s.%s = %s.%s", quoteQualified(core.QualifiedTableName(child)), QuoteIdentifier(fkColumn),
+			quoteQualified(core.QualifiedTableName(parent)), QuoteIdentifier(fi.FKColumn)), nil
+	}
+	return "", fmt.Errorf("join relation: no fk:%s(...) tag on %s.%s or fk:%s(...) on %s.%s",
+		core.QualifiedTableName(from), to.Name(), fkColumn, core.QualifiedTableName(to), from.Name(), fkColumn)
+}
+
+// sameTable matches an fk reference against a table, ignoring the schema when the reference has none
+func sameTable(ref, table string) bool {
+	if strings.EqualFold(ref, table) {
+		return true
+	}
+	if strings.Contains(ref, ".") {
+		return false
+	}
+	_, name, _ := strings.Cut(table, ".")
+	return name != "" && strings.EqualFold(ref, name)
+}
+
+// ModelAs is Model with a table alias: FROM "users" AS "u". The default soft-delete filter is qualified
+// with the alias ("u"."deleted_at" IS NULL), so it stays unambiguous when joined tables have a deleted_at
+// column too. Combine with JoinModelAs / LeftJoinModelAs:
+//
+//	db.Query().ModelAs(&User{}, "u").LeftJoinModelAs(&Post{}, "p", "p.user_id = u.id")
+//	// FROM "users" AS "u" LEFT JOIN "posts" AS "p" ON (p.user_id = u.id) AND "p"."deleted_at" IS NULL
+//	// WHERE "u"."deleted_at" IS NULL
+func (qb *QueryBuilder) ModelAs(model any, alias string) *QueryBuilder {
+	qb.Model(model)
+	if qb.table == "" {
+		return qb
+	}
+	qb.table = quoteQualified(qb.table) + " AS " + QuoteIdentifier(alias)
+	qb.softDeleteColumn = QuoteIdentifier(alias) + `."deleted_at"`
+	return qb
+}
+
+// JoinModelAs joins the table of model under alias. When model has soft delete, its deleted rows are
+// filtered in the ON clause, qualified with the alias; WithTrashed/Unscoped lift that filter too.
+func (qb *QueryBuilder) JoinModelAs(model any, alias, on string) *QueryBuilder {
+	return qb.joinModelAs("JOIN", model, alias, on)
+}
+
+// LeftJoinModelAs is JoinModelAs with a LEFT JOIN. The soft-delete filter sits in the ON clause, so base
+// rows without a live match are kept.
+func (qb *QueryBuilder) LeftJoinModelAs(model any, alias, on string) *QueryBuilder {
+	return qb.joinModelAs("LEFT JOIN", model, alias, on)
+}
+
+func (qb *QueryBuilder) joinModelAs(kind string, model any, alias, on string) *QueryBuilder {
+	t := reflect.TypeOf(model)
+	if t == nil {
+		qb.setError(fmt.Errorf("join model: nil model"))
+		return qb
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	table := core.Qualify(core.SchemaName(t), core.TableNameOf(model))
+	qb.joins = append(qb.joins, kind+" "+quoteQualified(table)+" AS "+QuoteIdentifier(alias)+" ON ("+on+")")
+	if core.ModelHasSoftDelete(t) {
+		qb.joinSoftDeletes = append(qb.joinSoftDeletes, joinSoftDelete{join: len(qb.joins) - 1, column: QuoteIdentifier(alias) + `."deleted_at"`})
+	}
+	return qb
+}
+
+// joinSoftDelete is the soft-delete column of a model joined by JoinModelAs
+type joinSoftDelete struct {
+	join   int // index in QueryBuilder.joins
+	column string
+}
+
+// compileJoins renders the joins with the soft-delete filters of joined models
+func (qb *QueryBuilder) compileJoins() string {
+	if len(qb.joinSoftDeletes) == 0 || qb.qbSoftMode == qbSoftModeWithTrashed {
+		return strings.Join(qb.joins, " ")
+	}
+	joins := slices.Clone(qb.joins)
+	for _, j := range qb.joinSoftDeletes {
+		joins[j.join] += " AND " + j.column + " IS NULL"
+	}
+	return strings.Join(joins, " ")
+}
+
+// softDeleteTarget is the deleted_at column of the base model as the WHERE clause must name it
+func (qb *QueryBuilder) softDeleteTarget() string {
+	if qb.softDeleteColumn != "" {
+		return qb.softDeleteColumn
+	}
+	if len(qb.joins) > 0 {
+		// joined tables may have a deleted_at column too
+		return quoteQualified(qb.table) + `."deleted_at"`
+	}
+	return "deleted_at"
+}
@@ -0,0 +1,76 @@
+package norm
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type joinAuthor struct {
+	ID   int64  `db:"id" norm:"primary_key"`
+	Name string `db:"name"`
+}
+
+type joinArticle struct {
+	ID       int64  `db:"id" norm:"primary_key"`
+	AuthorID int64  `db:"author_id" norm:"fk:join_authors(id),on_delete:cascade"`
+	Title    string `db:"title"`
+}
+
+func TestQueryBuilder_JoinRelation(t *testing.T) {
+	want := `SELECT * FROM join_authors JOIN "join_articles" ON "join_articles"."author_id" = "join_authors"."id"`
+	if got, _ := (&QueryBuilder{}).Model(&joinAuthor{}).JoinRelation(&joinAuthor{}, &joinArticle{}, "author_id").buildSelect(); got != want {
+		t.Fatalf("has-many:\n got %s\nwant %s", got, want)
+	}
+	want = `SELECT * FROM join_articles LEFT JOIN "join_authors" ON "join_articles"."author_id" = "join_authors"."id"`
+	if got, _ := (&QueryBuilder{}).Model(joinArticle{}).LeftJoinRelation(joinArticle{}, joinAuthor{}, "author_id").buildSelect(); got != want {
+		t.Fatalf("belongs-to:\n got %s\nwant %s", got, want)
+	}
+	if got, _ := JoinOn[joinAuthor, joinArticle]((&QueryBuilder{}).Model(&joinAuthor{}), "author_id").buildSelect(); !strings.Contains(got, `JOIN "join_articles" ON`) {
+		t.Fatalf("JoinOn: %s", got)
+	}
+}
+
+func TestQueryBuilder_JoinRelation_MissingFK(t *testing.T) {
+	qb := (&QueryBuilder{}).Model(&joinAuthor{}).JoinRelation(&joinAuthor{}, &joinArticle{}, "title")
+	if err := qb.queryError(); err == nil || !strings.Contains(err.Error(), "no fk:") {
+		t.Fatalf("got %v", err)
+	}
+}
+
+type aliasUser struct {
+	ID        int64      `db:"id" norm:"primary_key"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+type aliasPost struct {
+	ID        int64      `db:"id" norm:"primary_key"`
+	UserID    int64      `db:"user_id"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+func TestQueryBuilder_ModelAs(t *testing.T) {
+	got, _ := (&QueryBuilder{}).ModelAs(&aliasUser{}, "u").LeftJoinModelAs(&aliasPost{}, "p", "p.user_id = u.id").Where("u.id = ?", 1).buildSelect()
+	want := `SELECT * FROM "alias_users" AS "u" LEFT JOIN "alias_posts" AS "p" ON (p.user_id = u.id) AND "p"."deleted_at" IS NULL WHERE u.id = $1 AND "u"."deleted_at" IS NULL`
+	if got != want {
+		t.Fatalf("\n got %s\nwant %s", got, want)
+	}
+	got, _ = (&QueryBuilder{}).ModelAs(&aliasUser{}, "u").JoinModelAs(&aliasPost{}, "p", "p.user_id = u.id").OnlyTrashed().buildSelect()
+	if !strings.HasSuffix(got, `JOIN "alias_posts" AS "p" ON (p.user_id = u.id) AND "p"."deleted_at" IS NULL WHERE "u"."deleted_at" IS NOT NULL`) {
+		t.Fatalf("only trashed: %s", got)
+	}
+	got, _ = (&QueryBuilder{}).ModelAs(&aliasUser{}, "u").JoinModelAs(&aliasPost{}, "p", "p.user_id = u.id").WithTrashed().buildSelect()
+	if strings.Contains(got, "deleted_at") {
+		t.Fatalf("with trashed: %s", got)
+	}
+}
+
+func TestQueryBuilder_ModelSoftDeleteQualifiedWithJoins(t *testing.T) {
+	got, _ := (&QueryBuilder{}).Model(&aliasUser{}).Join("alias_posts", "alias_posts.user_id = alias_users.id").buildSelect()
+	if !strings.HasSuffix(got, `WHERE "alias_users"."deleted_at" IS NULL`) {
+		t.Fatalf("got %s", got)
+	}
+	if got, _ := (&QueryBuilder{}).Model(&aliasUser{}).buildSelect(); !strings.HasSuffix(got, "WHERE deleted_at IS NULL") {
+		t.Fatalf("got %s", got)
+	}
+}
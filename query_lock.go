@@ -0,0 +1,83 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ForUpdate appends FOR UPDATE to the SELECT, locking the matched rows until the transaction ends.
+// Use it inside a transaction: in autocommit mode the lock is released as soon as the statement finishes.
+func (qb *QueryBuilder) ForUpdate() *QueryBuilder {
+	qb.lock = "FOR UPDATE"
+	return qb
+}
+
+// ForShare appends FOR SHARE, which blocks concurrent updates and deletes but not other FOR SHARE readers
+func (qb *QueryBuilder) ForShare() *QueryBuilder {
+	qb.lock = "FOR SHARE"
+	return qb
+}
+
+// SkipLocked makes the row lock skip rows locked by other transactions (e.g. job queues); it implies ForUpdate
+// when no lock was requested
+func (qb *QueryBuilder) SkipLocked() *QueryBuilder {
+	return qb.lockOption("SKIP LOCKED")
+}
+
+// NoWait makes the row lock fail immediately instead of waiting for rows locked by other transactions;
+// it implies ForUpdate when no lock was requested
+func (qb *QueryBuilder) NoWait() *QueryBuilder {
+	return qb.lockOption("NOWAIT")
+}
+
+func (qb *QueryBuilder) lockOption(opt string) *QueryBuilder {
+	base, _, _ := strings.Cut(qb.lock, " SKIP LOCKED")
+	base, _, _ = strings.Cut(base, " NOWAIT")
+	if base == "" {
+		base = "FOR UPDATE"
+	}
+	qb.lock = base + " " + opt
+	return qb
+}
+
+// inTx reports whether statements on exec run in a transaction, either the bound executor or one carried by ctx
+func inTx(ctx context.Context, exec dbExecuter) bool {
+	_, ok := baseExec(ctx, exec).(pgx.Tx)
+	return ok
+}
+
+func (r *repo[T]) lockQuery(ctx context.Context, method string, conditions []Condition) (*QueryBuilder, error) {
+	if !inTx(ctx, r.exec) {
+		return nil, &ORMError{Code: ErrCodeTransaction, Message: fmt.Sprintf("%s requires a transaction", method)}
+	}
+	return r.conditionQuery(conditions).ForUpdate(), nil
+}
+
+// FindForUpdate is Find with FOR UPDATE: the rows stay locked until the surrounding transaction ends.
+// The repository must be bound to a transaction (WithExecutor(tx.Exec()) or a WithTx context).
+func (r *repo[T]) FindForUpdate(ctx context.Context, conditions ...Condition) ([]*T, error) {
+	qb, err := r.lockQuery(ctx, "FindForUpdate", conditions)
+	if err != nil {
+		return nil, err
+	}
+	out, err := r.findRows(ctx, qb, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.applyPreloads(ctx, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// FindOneForUpdate is FindOne with FOR UPDATE (see FindForUpdate)
+func (r *repo[T]) FindOneForUpdate(ctx context.Context, conditions ...Condition) (*T, error) {
+	qb, err := r.lockQuery(ctx, "FindOneForUpdate", conditions)
+	if err != nil {
+		return nil, err
+	}
+	return r.findOne(ctx, qb.Limit(1))
+}
@@ -0,0 +1,60 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestQueryBuilder_LockClauses(t *testing.T) {
+	cases := []struct {
+		qb   *QueryBuilder
+		want string
+	}{
+		{(&QueryBuilder{}).Table("jobs").Limit(5).ForUpdate(), "SELECT * FROM jobs LIMIT 5 FOR UPDATE"},
+		{(&QueryBuilder{}).Table("jobs").SkipLocked(), "SELECT * FROM jobs FOR UPDATE SKIP LOCKED"},
+		{(&QueryBuilder{}).Table("jobs").ForShare().NoWait(), "SELECT * FROM jobs FOR SHARE NOWAIT"},
+		{(&QueryBuilder{}).Table("jobs").ForUpdate().NoWait().SkipLocked(), "SELECT * FROM jobs FOR UPDATE SKIP LOCKED"},
+	}
+	for _, c := range cases {
+		if got, _ := c.qb.buildSelect(); got != c.want {
+			t.Fatalf("got %q want %q", got, c.want)
+		}
+	}
+	if got, _ := (&QueryBuilder{}).Table("jobs").ForUpdate().buildCount(); strings.Contains(got, "FOR UPDATE") {
+		t.Fatalf("count kept the lock: %q", got)
+	}
+}
+
+func TestRepo_FindForUpdate_RequiresTx(t *testing.T) {
+	r := &repo[repUser]{kn: &KintsNorm{}, exec: &recExecRepo{}}
+	var oe *ORMError
+	if _, err := r.FindForUpdate(context.Background()); !errors.As(err, &oe) || oe.Code != ErrCodeTransaction {
+		t.Fatalf("want transaction error, got %v", err)
+	}
+	if _, err := r.FindOneForUpdate(context.Background()); !errors.As(err, &oe) || oe.Code != ErrCodeTransaction {
+		t.Fatalf("want transaction error, got %v", err)
+	}
+}
+
+func TestRepo_FindForUpdate_InTx(t *testing.T) {
+	db := &batchDB{seqExec: seqExec{fields: []string{"id"}, results: [][][]any{{{int64(4)}}}}}
+	tx, _ := db.Begin(context.Background())
+	kn := &KintsNorm{}
+	r := NewRepositoryWithExecutor[repUser](kn, tx)
+	var oe *ORMError
+	rows, err := r.FindForUpdate(context.Background(), Eq("name", "a"))
+	if err != nil || len(rows) != 1 || rows[0].ID != 4 {
+		t.Fatalf("rows=%v err=%v", rows, err)
+	}
+	if want := `SELECT * FROM rep_users WHERE name = $1 FOR UPDATE`; len(db.sqls) != 1 || db.sqls[0] != want {
+		t.Fatalf("sqls=%v", db.sqls)
+	}
+	// a transaction carried by the context counts too
+	ctx := WithTx(context.Background(), &txImpl{kn: kn, tx: tx})
+	ctxRepo := &repo[repUser]{kn: kn, exec: ctxTxExecuter{base: &recExecRepo{}}}
+	if _, err := ctxRepo.FindOneForUpdate(ctx); errors.As(err, &oe) && oe.Code == ErrCodeTransaction {
+		t.Fatalf("ctx tx: %v", err)
+	}
+}
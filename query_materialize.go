@@ -0,0 +1,73 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// MaterializeOptions controls the CREATE TABLE ... AS statement generated by Materialize
+type MaterializeOptions struct {
+	// Temp creates a session-local TEMP table; run inside a transaction (or WithContextTransaction) so later
+	// steps use the same connection
+	Temp bool
+	// Unlogged skips WAL for the table (ignored with Temp, which is never logged)
+	Unlogged bool
+	// IfNotExists leaves an existing table untouched instead of failing
+	IfNotExists bool
+	// WithData copies the rows; when false only the structure is created (WITH NO DATA)
+	WithData bool
+}
+
+// Materialize stores the builder's SELECT as a new table: CREATE [TEMP|UNLOGGED] TABLE name AS SELECT ... and
+// returns the number of rows copied. Utility statements cannot be prepared with bind parameters, so args are
+// interpolated by pgx using the simple protocol.
+//
+//	db.Query().Table("orders").Select("user_id", "sum(total) AS spent").GroupBy("user_id").
+//	    Materialize(ctx, "tmp_spend", norm.MaterializeOptions{Temp: true, WithData: true})
+func (qb *QueryBuilder) Materialize(ctx context.Context, table string, opts MaterializeOptions) (int64, error) {
+	if err := qb.queryError(); err != nil {
+		return 0, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	query, args := qb.buildMaterialize(table, opts)
+	started := time.Now()
+	tag, err := qb.exec.Exec(ctx, query, append([]any{pgx.QueryExecModeSimpleProtocol}, args...)...)
+	if qb.kn != nil && qb.kn.logger != nil && (qb.kn.logMode == LogDebug || qb.kn.logMode == LogInfo || qb.forceDebug) {
+		qb.kn.logger.Debug("exec", qb.kn.makeLogFields(ctx, query, args)...)
+	}
+	if qb.kn != nil && qb.kn.metrics != nil {
+		qb.kn.metrics.QueryDuration(time.Since(started), query)
+	}
+	if err != nil {
+		return 0, wrapPgError(err, query, args)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func (qb *QueryBuilder) buildMaterialize(table string, opts MaterializeOptions) (string, []any) {
+	sel, args := qb.buildSelect()
+	var sb strings.Builder
+	sb.WriteString("CREATE ")
+	switch {
+	case opts.Temp:
+		sb.WriteString("TEMP ")
+	case opts.Unlogged:
+		sb.WriteString("UNLOGGED ")
+	}
+	sb.WriteString("TABLE ")
+	if opts.IfNotExists {
+		sb.WriteString("IF NOT EXISTS ")
+	}
+	sb.WriteString(quoteQualified(table))
+	sb.WriteString(" AS ")
+	sb.WriteString(sel)
+	if !opts.WithData {
+		sb.WriteString(" WITH NO DATA")
+	}
+	return sb.String(), args
+}
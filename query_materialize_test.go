@@ -0,0 +1,32 @@
+package norm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+func TestMaterialize_BuildsCreateTableAs(t *testing.T) {
+	f := &fakeExecRU{}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: f}).Table("orders").Select("user_id", "sum(total) AS spent").Where("status = ?", "paid").GroupBy("user_id")
+	if _, err := qb.Materialize(context.Background(), "tmp_spend", MaterializeOptions{Temp: true, WithData: true}); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `CREATE TEMP TABLE "tmp_spend" AS SELECT user_id, sum(total) AS spent FROM orders WHERE status = $1 GROUP BY user_id`
+	if f.lastSQL != want {
+		t.Fatalf("sql:\n got %s\nwant %s", f.lastSQL, want)
+	}
+	if !reflect.DeepEqual(f.lastArgs, []any{pgx.QueryExecModeSimpleProtocol, "paid"}) {
+		t.Fatalf("args=%v", f.lastArgs)
+	}
+}
+
+func TestMaterialize_StructureOnly(t *testing.T) {
+	q, _ := (&QueryBuilder{}).Table("events").buildMaterialize("analytics.events_copy", MaterializeOptions{Unlogged: true, IfNotExists: true})
+	want := `CREATE UNLOGGED TABLE IF NOT EXISTS "analytics"."events_copy" AS SELECT * FROM events WITH NO DATA`
+	if q != want {
+		t.Fatalf("sql:\n got %s\nwant %s", q, want)
+	}
+}
@@ -0,0 +1,72 @@
+package norm
+
+import (
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// withPrefixes returns a builder whose "alias.*" select items are expanded for scanning into elemType:
+// when elemType has a nested struct field tagged db:"alias", the item becomes one
+// "alias"."column" AS "alias.column" per column of that struct, so the join's columns no longer clash.
+//
+//	type UserWithProfile struct {
+//		User    User     `db:"u"`
+//		Profile *Profile `db:"p"` // nil when the LEFT JOIN finds no profile
+//	}
+//	db.Query().Table("users u").LeftJoin("profiles p", "p.user_id = u.id").Select("u.*", "p.*").Find(ctx, &rows)
+//
+// Other items, and builders without a matching item, are left as they are.
+func (qb *QueryBuilder) withPrefixes(elemType reflect.Type) *QueryBuilder {
+	if qb.isRaw || elemType == nil || len(qb.columns) == 0 {
+		return qb
+	}
+	for elemType.Kind() == reflect.Pointer {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return qb
+	}
+	prefixes := core.StructMapper(elemType).Prefixes
+	if len(prefixes) == 0 {
+		return qb
+	}
+	cols := make([]string, 0, len(qb.columns))
+	expanded := false
+	for _, c := range qb.columns {
+		alias, ok := strings.CutSuffix(strings.TrimSpace(c), ".*")
+		alias = strings.Trim(alias, `"`)
+		p, found := prefixes[strings.ToLower(alias)]
+		if !ok || !found {
+			cols = append(cols, c)
+			continue
+		}
+		expanded = true
+		prefix := strings.ToLower(alias)
+		nested := core.StructMapper(p.Type)
+		for _, col := range slices.Sorted(maps.Keys(nested.FieldsByColumn)) {
+			if _, deeper := nested.Prefixes[col]; deeper {
+				continue
+			}
+			cols = append(cols, QuoteIdentifier(alias)+"."+QuoteIdentifier(col)+" AS "+QuoteIdentifier(prefix+"."+col))
+		}
+	}
+	if !expanded {
+		return qb
+	}
+	q := *qb
+	q.columns = cols
+	return &q
+}
+
+// sliceElem returns the element type of a pointer-to-slice dest, or nil
+func sliceElem(dest any) reflect.Type {
+	t := reflect.TypeOf(dest)
+	if t == nil || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Slice {
+		return nil
+	}
+	return t.Elem().Elem()
+}
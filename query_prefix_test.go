@@ -0,0 +1,63 @@
+package norm
+
+import (
+	"context"
+	"testing"
+)
+
+type prefUser struct {
+	ID    int64  `db:"id"`
+	Email string `db:"email"`
+}
+
+type prefProfile struct {
+	UserID int64  `db:"user_id"`
+	Bio    string `db:"bio"`
+}
+
+type prefRow struct {
+	User    prefUser     `db:"u"`
+	Profile *prefProfile `db:"p"`
+	Orders  int64        `db:"orders"`
+}
+
+func TestFind_PrefixedJoinIntoNestedStructs(t *testing.T) {
+	f := &fakeExec{
+		fields: []string{"u.id", "u.email", "p.bio", "p.user_id", "orders"},
+		rows: [][]any{
+			{int64(1), "a@x", "hi", int64(1), int64(2)},
+			{int64(2), "b@x", nil, nil, int64(0)},
+		},
+	}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: f}).Table("users u").LeftJoin("profiles p", "p.user_id = u.id").
+		Select("u.*", `"p".*`, "count(o.id) AS orders")
+	var rows []prefRow
+	if err := qb.Find(context.Background(), &rows); err != nil {
+		t.Fatal(err)
+	}
+	want := `SELECT "u"."email" AS "u.email", "u"."id" AS "u.id", "p"."bio" AS "p.bio", "p"."user_id" AS "p.user_id", count(o.id) AS orders FROM users u LEFT JOIN profiles p ON p.user_id = u.id`
+	if f.lastSQL != want {
+		t.Fatalf("sql=%s", f.lastSQL)
+	}
+	if len(rows) != 2 || rows[0].User != (prefUser{1, "a@x"}) || rows[0].Profile == nil || rows[0].Profile.Bio != "hi" || rows[0].Orders != 2 {
+		t.Fatalf("row 0: %+v", rows[0])
+	}
+	if rows[1].User.Email != "b@x" || rows[1].Profile != nil {
+		t.Fatalf("row 1: %+v profile=%+v", rows[1], rows[1].Profile)
+	}
+	// the builder itself keeps its select list
+	if qb.columns[0] != "u.*" {
+		t.Fatalf("columns=%v", qb.columns)
+	}
+}
+
+func TestFind_PrefixesLeaveOtherDestsAlone(t *testing.T) {
+	f := &fakeExec{}
+	var out []map[string]any
+	if err := (&QueryBuilder{kn: &KintsNorm{}, exec: f}).Table("users u").Select("u.*").Find(context.Background(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if f.lastSQL != "SELECT u.* FROM users u" {
+		t.Fatalf("sql=%s", f.lastSQL)
+	}
+}
@@ -0,0 +1,66 @@
+package norm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// QueryRows runs hand-written SQL ($1-style placeholders, e.g. from sqlc) through the same executor
+// chain as Query(): a transaction carried by ctx, read routing, the circuit breaker, interceptors,
+// logging and metrics. Close the rows, or hand them to Collect.
+//
+//	rows, err := db.QueryRows(ctx, `SELECT u.id, u.email, count(o.id) AS orders
+//		FROM users u LEFT JOIN orders o ON o.user_id = u.id GROUP BY u.id`)
+//	stats, err := norm.Collect[UserStats](rows)
+func (kn *KintsNorm) QueryRows(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return kn.Query().runQuery(ctx, sql, args)
+}
+
+// Collect reads every row into a T and closes rows. Structs are mapped by their `db` tags like Find
+// (unknown columns are ignored); other types, and structs without `db` fields such as time.Time,
+// are scanned from a single column.
+func Collect[T any](rows pgx.Rows) ([]T, error) {
+	defer rows.Close()
+	typ := reflect.TypeFor[T]()
+	var out []T
+	if typ.Kind() != reflect.Struct || len(core.StructMapper(typ).FieldsByColumn) == 0 {
+		for rows.Next() {
+			var v T
+			if err := rows.Scan(&v); err != nil {
+				return out, wrapPgError(err, "", nil)
+			}
+			out = append(out, v)
+		}
+		if err := rows.Err(); err != nil {
+			return out, wrapPgError(err, "", nil)
+		}
+		return out, nil
+	}
+	mapper := core.StructMapper(typ)
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			return out, wrapPgError(err, "", nil)
+		}
+		fds := rows.FieldDescriptions()
+		var v T
+		elemPtr := reflect.ValueOf(&v)
+		for i, val := range vals {
+			if fi, ok := mapper.Column(strings.ToLower(fds[i].Name)); ok {
+				core.SetField(elemPtr, fi, val)
+			}
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		return out, wrapPgError(err, "", nil)
+	}
+	return out, nil
+}
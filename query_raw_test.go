@@ -0,0 +1,63 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type rawStat struct {
+	ID     int64  `db:"id"`
+	Email  string `db:"email"`
+	Orders int64  `db:"orders"`
+}
+
+// scalarRows is fakeRows scanning the single column of each row
+type scalarRows struct{ fakeRows }
+
+func (r *scalarRows) Scan(dest ...any) error {
+	v, err := r.Values()
+	if err != nil {
+		return err
+	}
+	*(dest[0].(*int64)) = v[0].(int64)
+	return nil
+}
+
+func TestQueryRows_UsesContextTx(t *testing.T) {
+	ex := &recExecRepo{}
+	ctx := WithTx(context.Background(), ctxTx{ex: ex})
+	rows, err := (&KintsNorm{}).QueryRows(ctx, "SELECT id FROM users WHERE id > $1", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows.Close()
+	if ex.lastSQL != "SELECT id FROM users WHERE id > $1" || len(ex.lastArgs) != 1 || ex.lastArgs[0] != 3 {
+		t.Fatalf("sql=%q args=%v", ex.lastSQL, ex.lastArgs)
+	}
+}
+
+func TestCollect_Structs(t *testing.T) {
+	rows := &fakeRows{
+		fields: []string{"id", "EMAIL", "orders", "extra"},
+		rows:   [][]any{{int64(1), "a@x", int64(3), "ignored"}, {int64(2), "b@x", int64(0), nil}},
+	}
+	got, err := Collect[rawStat](rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0] != (rawStat{1, "a@x", 3}) || got[1] != (rawStat{2, "b@x", 0}) {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestCollect_ScalarsAndErrors(t *testing.T) {
+	got, err := Collect[int64](&scalarRows{fakeRows{fields: []string{"id"}, rows: [][]any{{int64(4)}, {int64(5)}}}})
+	if err != nil || len(got) != 2 || got[1] != 5 {
+		t.Fatalf("got=%v err=%v", got, err)
+	}
+	if _, err := Collect[time.Time](&fakeRows{err: errors.New("boom")}); err == nil {
+		t.Fatal("expected rows error")
+	}
+}
@@ -0,0 +1,110 @@
+package norm
+
+import (
+	"context"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ResultMetrics can optionally be implemented by a Metrics collector to observe the size of result sets:
+// rows returned by each query and the bytes received for them (the raw wire size of the column values,
+// an approximation of the data moved). It is reported once the rows are exhausted or closed.
+type ResultMetrics interface {
+	QueryResult(rows, bytes int64, query string)
+}
+
+// withResultAudit wraps exec to report result set sizes to the metrics of kn; it is a no-op unless the
+// collector implements ResultMetrics
+func withResultAudit(kn *KintsNorm, exec dbExecuter) dbExecuter {
+	if kn == nil {
+		return exec
+	}
+	rm, ok := kn.metrics.(ResultMetrics)
+	if !ok {
+		return exec
+	}
+	return resultExecuter{metrics: rm, exec: exec}
+}
+
+// resultExecuter counts the rows and bytes read from every query
+type resultExecuter struct {
+	metrics ResultMetrics
+	exec    dbExecuter
+}
+
+func (e resultExecuter) unwrap() dbExecuter { return e.exec }
+
+func (e resultExecuter) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return e.exec.Exec(ctx, sql, arguments...)
+}
+
+func (e resultExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	rows, err := e.exec.Query(ctx, sql, args...)
+	if err != nil || rows == nil {
+		return rows, err
+	}
+	return &resultRows{Rows: rows, metrics: e.metrics, query: sql}, nil
+}
+
+// QueryRow reads the row through Query, the way pgx implements QueryRow, so its size is counted too
+func (e resultExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rows, err := e.Query(ctx, sql, args...)
+	return resultRow{rows: rows, err: err}
+}
+
+// resultRows reports the rows and bytes read once they are exhausted or closed
+type resultRows struct {
+	pgx.Rows
+	metrics ResultMetrics
+	query   string
+	n       int64
+	bytes   int64
+	once    sync.Once
+}
+
+func (r *resultRows) Next() bool {
+	if !r.Rows.Next() {
+		r.report()
+		return false
+	}
+	r.n++
+	for _, v := range r.Rows.RawValues() {
+		r.bytes += int64(len(v))
+	}
+	return true
+}
+
+func (r *resultRows) Close() {
+	r.Rows.Close()
+	r.report()
+}
+
+func (r *resultRows) report() {
+	r.once.Do(func() { r.metrics.QueryResult(r.n, r.bytes, r.query) })
+}
+
+// resultRow scans the first row of rows, with pgx.ErrNoRows when there is none
+type resultRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r resultRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	r.rows.Close()
+	return r.rows.Err()
+}
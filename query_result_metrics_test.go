@@ -0,0 +1,71 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// rawRows returns fixed raw rows; Scan copies nothing
+type rawRows struct {
+	okRows
+	raw [][][]byte
+	i   int
+}
+
+func (r *rawRows) Next() bool          { r.i++; return r.i <= len(r.raw) }
+func (r *rawRows) RawValues() [][]byte { return r.raw[r.i-1] }
+
+type rawExec struct {
+	okExec
+	raw [][][]byte
+}
+
+func (e rawExec) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return &rawRows{raw: e.raw}, nil
+}
+
+type resultRecorder struct {
+	NoopMetrics
+	rows, bytes []int64
+}
+
+func (m *resultRecorder) QueryResult(rows, bytes int64, _ string) {
+	m.rows, m.bytes = append(m.rows, rows), append(m.bytes, bytes)
+}
+
+func TestResultMetrics_RowsAndBytes(t *testing.T) {
+	m := &resultRecorder{}
+	kn := &KintsNorm{metrics: m}
+	exec := wrapExec(kn, rawExec{raw: [][][]byte{{[]byte("1"), []byte("alice")}, {[]byte("22"), nil}}})
+	rows, err := exec.Query(context.Background(), "SELECT id, name FROM users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for rows.Next() {
+	}
+	rows.Close()
+	if len(m.rows) != 1 || m.rows[0] != 2 || m.bytes[0] != 8 {
+		t.Fatalf("rows=%v bytes=%v", m.rows, m.bytes)
+	}
+
+	if err := exec.QueryRow(context.Background(), "SELECT id FROM users LIMIT 1").Scan(); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.rows) != 2 || m.rows[1] != 1 {
+		t.Fatalf("row: rows=%v", m.rows)
+	}
+	empty := wrapExec(kn, rawExec{})
+	if err := empty.QueryRow(context.Background(), "SELECT 1").Scan(); !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("no rows: %v", err)
+	}
+	if _, ok := baseExec(context.Background(), exec).(rawExec); !ok {
+		t.Fatalf("base=%T", baseExec(context.Background(), exec))
+	}
+	// collectors without ResultMetrics are not wrapped
+	if _, ok := wrapExec(&KintsNorm{metrics: NoopMetrics{}}, okExec{}).(resultExecuter); ok {
+		t.Fatal("wrapped without ResultMetrics")
+	}
+}
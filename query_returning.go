@@ -0,0 +1,169 @@
+package norm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// scanReturning scans RETURNING rows into dest and returns the number of rows read. dest may be
+// *[]map[string]any, a pointer to a slice of structs (or struct pointers) or a pointer to a single struct,
+// which receives the first row; structs are mapped by their `db` tags like Find.
+func scanReturning(rows pgx.Rows, dest any, query string, args []any) (int64, error) {
+	if d, ok := dest.(*[]map[string]any); ok {
+		var count int64
+		for rows.Next() {
+			vals, err := rows.Values()
+			if err != nil {
+				return count, wrapPgError(err, query, args)
+			}
+			fds := rows.FieldDescriptions()
+			m := make(map[string]any, len(vals))
+			for i, v := range vals {
+				m[string(fds[i].Name)] = v
+			}
+			*d = append(*d, m)
+			count++
+		}
+		if err := rows.Err(); err != nil {
+			return count, wrapPgError(err, query, args)
+		}
+		return count, nil
+	}
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return 0, errReturningDest
+	}
+	target := rv.Elem()
+	var (
+		slice    reflect.Value
+		elemType reflect.Type
+		elemPtr  bool
+	)
+	switch {
+	case target.Kind() == reflect.Struct:
+		elemType = target.Type()
+	case target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Struct:
+		slice, elemType = target, target.Type().Elem()
+	case target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Pointer && target.Type().Elem().Elem().Kind() == reflect.Struct:
+		slice, elemType, elemPtr = target, target.Type().Elem().Elem(), true
+	default:
+		return 0, errReturningDest
+	}
+	mapper := core.StructMapper(elemType)
+	var count int64
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			return count, wrapPgError(err, query, args)
+		}
+		elem := target.Addr()
+		if slice.IsValid() {
+			elem = reflect.New(elemType)
+		} else if count > 0 {
+			// single struct: keep the first row, but drain the rest so the count stays accurate
+			count++
+			continue
+		}
+		fds := rows.FieldDescriptions()
+		for i, v := range vals {
+			if fi, ok := mapper.Column(strings.ToLower(string(fds[i].Name))); ok {
+				core.SetField(elem, fi, v)
+			}
+		}
+		if slice.IsValid() {
+			if elemPtr {
+				slice.Set(reflect.Append(slice, elem))
+			} else {
+				slice.Set(reflect.Append(slice, elem.Elem()))
+			}
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, wrapPgError(err, query, args)
+	}
+	return count, nil
+}
+
+var errReturningDest = &ORMError{Code: ErrCodeValidation, Message: "dest must be *[]map[string]any, a pointer to a struct slice or a struct pointer for RETURNING"}
+
+var (
+	returningIdent = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*(\.[A-Za-z_][A-Za-z0-9_$]*)*$`)
+	returningAlias = regexp.MustCompile(`(?is)^(.*\S)\s+AS\s+("?)([A-Za-z_][A-Za-z0-9_$]*)"?$`)
+)
+
+// returningItem renders one RETURNING entry: plain (dotted) column names are quoted, anything else is an
+// expression with an optional AS alias, e.g. "xmax = 0 AS inserted"
+func returningItem(item string) (string, error) {
+	item = strings.TrimSpace(item)
+	if returningIdent.MatchString(item) {
+		return quoteQualified(item), nil
+	}
+	expr, alias := item, ""
+	if m := returningAlias.FindStringSubmatch(item); m != nil {
+		expr, alias = m[1], m[3]
+	}
+	if err := checkReturningExpr(expr); err != nil {
+		return "", fmt.Errorf("invalid RETURNING expression %q: %w", item, err)
+	}
+	if alias != "" {
+		return expr + " AS " + QuoteIdentifier(alias), nil
+	}
+	return expr, nil
+}
+
+// checkReturningExpr rejects statement separators, comments, and unbalanced quotes or parentheses
+func checkReturningExpr(expr string) error {
+	if expr == "" {
+		return errors.New("empty expression")
+	}
+	depth := 0
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '\'', '"':
+			quote = c
+		case '(':
+			depth++
+		case ')':
+			if depth--; depth < 0 {
+				return errors.New("unbalanced parentheses")
+			}
+		case ';':
+			return errors.New("statement separator")
+		case '-', '/':
+			if i+1 < len(expr) && (expr[i+1] == '-' && c == '-' || expr[i+1] == '*' && c == '/') {
+				return errors.New("comment")
+			}
+		}
+	}
+	if quote != 0 {
+		return errors.New("unterminated quote")
+	}
+	if depth != 0 {
+		return errors.New("unbalanced parentheses")
+	}
+	return nil
+}
+
+// returningSQL joins the rendered RETURNING entries; entries were validated by Returning
+func returningSQL(items []string) string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i], _ = returningItem(item)
+	}
+	return strings.Join(out, ", ")
+}
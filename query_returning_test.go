@@ -0,0 +1,47 @@
+package norm
+
+import (
+	"context"
+	"testing"
+)
+
+type returnedUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestExecInsertReturningIntoStructs(t *testing.T) {
+	kn := &KintsNorm{}
+	f := &fakeExecRU{rows: [][]any{{int64(1), "a"}, {int64(2), "b"}}, fields: []string{"id", "name"}}
+	var out []returnedUser
+	n, err := (&QueryBuilder{kn: kn, exec: f}).Table("users").Insert("name").Values("a").Values("b").Returning("id", "name").ExecInsert(context.Background(), &out)
+	if err != nil || n != 2 {
+		t.Fatalf("err=%v n=%d", err, n)
+	}
+	if len(out) != 2 || out[1].ID != 2 || out[1].Name != "b" {
+		t.Fatalf("out=%+v", out)
+	}
+
+	f = &fakeExecRU{rows: [][]any{{int64(3), "c"}}, fields: []string{"id", "name"}}
+	var ptrs []*returnedUser
+	if _, err := (&QueryBuilder{kn: kn, exec: f}).Table("users").Insert("name").Values("c").Returning("id", "name").ExecInsert(context.Background(), &ptrs); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if len(ptrs) != 1 || ptrs[0].ID != 3 {
+		t.Fatalf("ptrs=%+v", ptrs)
+	}
+}
+
+func TestExecUpdateReturningIntoStruct(t *testing.T) {
+	kn := &KintsNorm{}
+	f := &fakeExecRU{rows: [][]any{{int64(7), "b"}}, fields: []string{"id", "name"}}
+	var u returnedUser
+	n, err := (&QueryBuilder{kn: kn, exec: f}).Table("users").Set("name = ?", "b").Where("id = ?", 7).Returning("id", "name").ExecUpdate(context.Background(), &u)
+	if err != nil || n != 1 || u.ID != 7 || u.Name != "b" {
+		t.Fatalf("err=%v n=%d u=%+v", err, n, u)
+	}
+	var bad int
+	if _, err := (&QueryBuilder{kn: kn, exec: f}).Table("users").Set("name = ?", "b").Where("id = ?", 7).Returning("id").ExecUpdate(context.Background(), &bad); err == nil {
+		t.Fatalf("expected error for unsupported dest")
+	}
+}
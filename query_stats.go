@@ -0,0 +1,134 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// defaultTopQueries is the number of statements TopQueries returns when n <= 0
+const defaultTopQueries = 10
+
+// ErrStatStatementsUnavailable is returned (wrapped) by TopQueries and ResetQueryStats when the
+// pg_stat_statements extension is not installed or not loaded via shared_preload_libraries
+var ErrStatStatementsUnavailable = errors.New("pg_stat_statements is not available")
+
+// QueryStat is one normalized statement from pg_stat_statements (constants replaced by $n)
+type QueryStat struct {
+	QueryID        int64
+	Query          string
+	Calls          int64
+	Rows           int64
+	TotalTime      time.Duration
+	MeanTime       time.Duration
+	MaxTime        time.Duration
+	SharedBlksHit  int64
+	SharedBlksRead int64
+}
+
+// HitRatio returns the shared buffer hit ratio of the statement, or 0 when it read no blocks
+func (s QueryStat) HitRatio() float64 {
+	total := s.SharedBlksHit + s.SharedBlksRead
+	if total == 0 {
+		return 0
+	}
+	return float64(s.SharedBlksHit) / float64(total)
+}
+
+const topQueriesSQL = `SELECT s.queryid, s.query, s.calls, s.rows, s.total_exec_time, s.mean_exec_time, s.max_exec_time, s.shared_blks_hit, s.shared_blks_read ` +
+	`FROM pg_stat_statements s WHERE s.dbid = (SELECT oid FROM pg_database WHERE datname = current_database()) ` +
+	`ORDER BY s.total_exec_time DESC LIMIT $1`
+
+// TopQueries returns the n statements of the current database with the highest total execution time
+// (PostgreSQL 13+ column names). Without the extension it returns an error wrapping
+// ErrStatStatementsUnavailable, so dashboards can show a hint instead of failing.
+func (kn *KintsNorm) TopQueries(ctx context.Context, n int) ([]QueryStat, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if n <= 0 {
+		n = defaultTopQueries
+	}
+	return topQueries(ctx, wrapExec(kn, kn.pool), n)
+}
+
+func topQueries(ctx context.Context, exec dbExecuter, n int) ([]QueryStat, error) {
+	rows, err := exec.Query(ctx, topQueriesSQL, n)
+	if err != nil {
+		return nil, statStatementsError(err, topQueriesSQL, []any{n})
+	}
+	defer rows.Close()
+	var out []QueryStat
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			return nil, wrapPgError(err, topQueriesSQL, []any{n})
+		}
+		if len(vals) < 9 {
+			continue
+		}
+		s := QueryStat{
+			QueryID:        statInt(vals[0]),
+			Calls:          statInt(vals[2]),
+			Rows:           statInt(vals[3]),
+			TotalTime:      statMillis(vals[4]),
+			MeanTime:       statMillis(vals[5]),
+			MaxTime:        statMillis(vals[6]),
+			SharedBlksHit:  statInt(vals[7]),
+			SharedBlksRead: statInt(vals[8]),
+		}
+		s.Query, _ = vals[1].(string)
+		out = append(out, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, statStatementsError(err, topQueriesSQL, []any{n})
+	}
+	return out, nil
+}
+
+// ResetQueryStats discards the statistics gathered so far by pg_stat_statements
+func (kn *KintsNorm) ResetQueryStats(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	const q = "SELECT pg_stat_statements_reset()"
+	if _, err := wrapExec(kn, kn.pool).Exec(ctx, q); err != nil {
+		return statStatementsError(err, q, nil)
+	}
+	return nil
+}
+
+// statStatementsError maps a missing relation/function (extension not created) or 55000 (library not
+// preloaded) to ErrStatStatementsUnavailable
+func statStatementsError(err error, query string, args []any) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "42P01", "42883", "55000":
+			return fmt.Errorf("%w: %s", ErrStatStatementsUnavailable, pgErr.Message)
+		}
+	}
+	return wrapPgError(err, query, args)
+}
+
+func statInt(v any) int64 {
+	switch n := v.(type) {
+	case int64:
+		return n
+	case int32:
+		return int64(n)
+	case float64:
+		return int64(n)
+	}
+	return 0
+}
+
+func statMillis(v any) time.Duration {
+	if f, ok := v.(float64); ok {
+		return time.Duration(f * float64(time.Millisecond))
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTopQueries_Scan(t *testing.T) {
+	f := &fakeExecRU{rows: [][]any{
+		{int64(42), "SELECT * FROM users WHERE id = $1", int64(10), int64(10), 25.5, 2.55, 7.0, int64(90), int64(10)},
+		{nil, "<insufficient privilege>", int64(1), int64(0), 1.0, 1.0, 1.0, int64(0), int64(0)},
+	}}
+	stats, err := topQueries(context.Background(), f, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 || f.lastArgs[0] != 5 {
+		t.Fatalf("stats=%v args=%v", stats, f.lastArgs)
+	}
+	s := stats[0]
+	if s.QueryID != 42 || s.Calls != 10 || s.TotalTime != 25500*time.Microsecond || s.MaxTime != 7*time.Millisecond || s.HitRatio() != 0.9 {
+		t.Fatalf("stat=%+v", s)
+	}
+	if stats[1].QueryID != 0 || stats[1].HitRatio() != 0 {
+		t.Fatalf("stat=%+v", stats[1])
+	}
+}
+
+// missingExtExec fails like a database without pg_stat_statements
+type missingExtExec struct{ fakeExecRU }
+
+func (*missingExtExec) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return nil, &pgconn.PgError{Code: "42P01", Message: `relation "pg_stat_statements" does not exist`}
+}
+
+func TestTopQueries_Unavailable(t *testing.T) {
+	_, err := topQueries(context.Background(), &missingExtExec{}, 5)
+	if !errors.Is(err, ErrStatStatementsUnavailable) {
+		t.Fatalf("err=%v", err)
+	}
+}
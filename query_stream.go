@@ -0,0 +1,141 @@
+package norm
+
+import (
+	"context"
+	"iter"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// FindEach runs the builder query and invokes fn for every row as it is read from the connection,
+// without buffering the full result set. T may be a struct (mapped via db tags) or map[string]any.
+// Returning an error from fn stops iteration and is returned as-is.
+func FindEach[T any](ctx context.Context, qb *QueryBuilder, fn func(row *T) error) error {
+	for row, err := range Iterate[T](ctx, qb) {
+		if err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Iterate returns a range-over-func iterator streaming rows of the builder query.
+// Breaking out of the loop closes the underlying rows. A non-nil error is yielded at most once, last.
+//
+//	for u, err := range norm.Iterate[User](ctx, db.Model(&User{})) { ... }
+func Iterate[T any](ctx context.Context, qb *QueryBuilder) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		rows, query, args, err := qb.withPrefixes(reflect.TypeFor[T]()).queryRows(ctx)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		defer rows.Close()
+		var zero T
+		elemType := reflect.TypeOf(zero)
+		isMap := elemType != nil && elemType.Kind() == reflect.Map
+		var mapper core.StructMapping
+		if !isMap {
+			if elemType == nil || reflect.Indirect(reflect.New(elemType)).Kind() != reflect.Struct {
+				yield(nil, &ORMError{Code: ErrCodeValidation, Message: "row type must be a struct or map[string]any"})
+				return
+			}
+			mapper = core.StructMapper(elemType)
+		}
+		for rows.Next() {
+			vals, err := rows.Values()
+			if err != nil {
+				yield(nil, wrapPgError(err, query, args))
+				return
+			}
+			fds := rows.FieldDescriptions()
+			out := new(T)
+			if isMap {
+				m := make(map[string]any, len(vals))
+				for i, v := range vals {
+					m[string(fds[i].Name)] = v
+				}
+				if mv, ok := any(m).(T); ok {
+					*out = mv
+				}
+			} else {
+				elemPtr := reflect.ValueOf(out)
+				for i, v := range vals {
+					if fi, ok := mapper.Column(strings.ToLower(string(fds[i].Name))); ok {
+						core.SetField(elemPtr, fi, v)
+					}
+				}
+			}
+			if !yield(out, nil) {
+				return
+			}
+		}
+		if err := rows.Err(); err != nil {
+			yield(nil, wrapPgError(err, query, args))
+		}
+	}
+}
+
+// queryRows builds and executes the select, applying the same logging and metrics as Find,
+// and returns the open rows to the caller (which must close them)
+func (qb *QueryBuilder) queryRows(ctx context.Context) (pgx.Rows, string, []any, error) {
+	if err := qb.queryError(); err != nil {
+		return nil, "", nil, err
+	}
+	if qb.concurrencyKey != "" {
+		q, tx, err := qb.acquireConcurrencySlot(ctx)
+		if err != nil {
+			return nil, "", nil, err
+		}
+		rows, query, args, err := q.queryRows(ctx)
+		if err != nil {
+			_ = tx.Rollback(context.WithoutCancel(ctx))
+			return nil, query, args, err
+		}
+		return &slotRows{Rows: rows, ctx: ctx, tx: tx}, query, args, nil
+	}
+	query, args := qb.buildSelect()
+	rows, err := qb.runQuery(ctx, query, args)
+	return rows, query, args, err
+}
+
+// runQuery executes query with Find's logging and metrics
+func (qb *QueryBuilder) runQuery(ctx context.Context, query string, args []any) (pgx.Rows, error) {
+	started := time.Now()
+	rows, err := qb.exec.Query(ctx, query, args...)
+	if qb.kn != nil && qb.kn.logger != nil {
+		switch qb.kn.logMode {
+		case LogDebug, LogInfo:
+			qb.kn.logger.Debug("query", qb.kn.makeLogFields(ctx, query, args)...)
+		case LogWarn, LogError:
+		case LogSilent:
+			if qb.forceDebug {
+				qb.kn.logger.Debug("query", qb.kn.makeLogFields(ctx, query, args)...)
+			}
+		}
+	}
+	if qb.kn != nil && qb.kn.metrics != nil {
+		qb.kn.metrics.QueryDuration(time.Since(started), query)
+	}
+	if err != nil {
+		if qb.kn != nil && qb.kn.logger != nil {
+			if qb.kn.logMode != LogSilent || qb.forceDebug {
+				fields := qb.kn.makeLogFields(ctx, query, args)
+				fields = append(fields, Field{Key: "error", Value: err})
+				qb.kn.logger.Error("query_error", fields...)
+			}
+		}
+		return nil, wrapPgError(err, query, args)
+	}
+	return rows, nil
+}
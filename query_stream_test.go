@@ -0,0 +1,55 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type streamUser struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+func TestFindEach_StreamsStructRows(t *testing.T) {
+	f := &fakeExecRU{rows: [][]any{{int64(1), "a"}, {int64(2), "b"}}, fields: []string{"id", "name"}}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: f}).Table("stream_users")
+	var names []string
+	err := FindEach(context.Background(), qb, func(u *streamUser) error {
+		names = append(names, u.Name)
+		return nil
+	})
+	if err != nil || len(names) != 2 || names[1] != "b" {
+		t.Fatalf("names=%v err=%v", names, err)
+	}
+}
+
+func TestFindEach_StopsOnCallbackError(t *testing.T) {
+	f := &fakeExecRU{rows: [][]any{{int64(1)}, {int64(2)}}, fields: []string{"id"}}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: f}).Table("t")
+	stop := errors.New("stop")
+	calls := 0
+	err := FindEach(context.Background(), qb, func(m *map[string]any) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Fatalf("err=%v calls=%d", err, calls)
+	}
+}
+
+func TestIterate_Break(t *testing.T) {
+	f := &fakeExecRU{rows: [][]any{{int64(1), "a"}, {int64(2), "b"}}, fields: []string{"id", "name"}}
+	r := &repo[streamUser]{kn: &KintsNorm{}, exec: f}
+	n := 0
+	for u, err := range Iterate[streamUser](context.Background(), r.query().Table("stream_users")) {
+		if err != nil || u.ID != 1 {
+			t.Fatalf("u=%v err=%v", u, err)
+		}
+		n++
+		break
+	}
+	if n != 1 {
+		t.Fatalf("n=%d", n)
+	}
+}
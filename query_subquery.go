@@ -0,0 +1,85 @@
+package norm
+
+import (
+	"errors"
+
+	sqlutil "github.com/kintsdev/norm/internal/sqlutil"
+)
+
+var errNilSubquery = errors.New("subquery builder is nil")
+
+// subquerySQL compiles sub into a '?'-placeholder fragment with its args, propagating sub's build errors to qb
+func (qb *QueryBuilder) subquerySQL(sub *QueryBuilder) (string, []any, bool) {
+	if sub == nil {
+		qb.setError(errNilSubquery)
+		return "", nil, false
+	}
+	if err := sub.queryError(); err != nil {
+		qb.inheritError(err)
+		return "", nil, false
+	}
+	sql, args := sub.buildSelect()
+	q, qargs := sqlutil.PgPlaceholdersToQMarks(sql, args)
+	return q, qargs, true
+}
+
+// WhereInSub adds "column IN (subquery)", e.g.
+//
+//	qb.WhereInSub("id", kn.Query().Table("posts").Select("user_id").Where("published = ?", true))
+func (qb *QueryBuilder) WhereInSub(column string, sub *QueryBuilder) *QueryBuilder {
+	q, args, ok := qb.subquerySQL(sub)
+	if !ok {
+		return qb
+	}
+	return qb.Where(column+" IN ("+q+")", args...)
+}
+
+// WhereNotInSub adds "column NOT IN (subquery)"
+func (qb *QueryBuilder) WhereNotInSub(column string, sub *QueryBuilder) *QueryBuilder {
+	q, args, ok := qb.subquerySQL(sub)
+	if !ok {
+		return qb
+	}
+	return qb.Where(column+" NOT IN ("+q+")", args...)
+}
+
+// WhereExists adds "EXISTS (subquery)"; correlate through the subquery's own Where, e.g. Where("p.user_id = u.id")
+func (qb *QueryBuilder) WhereExists(sub *QueryBuilder) *QueryBuilder {
+	q, args, ok := qb.subquerySQL(sub)
+	if !ok {
+		return qb
+	}
+	return qb.Where("EXISTS ("+q+")", args...)
+}
+
+// WhereNotExists adds "NOT EXISTS (subquery)"
+func (qb *QueryBuilder) WhereNotExists(sub *QueryBuilder) *QueryBuilder {
+	q, args, ok := qb.subquerySQL(sub)
+	if !ok {
+		return qb
+	}
+	return qb.Where("NOT EXISTS ("+q+")", args...)
+}
+
+// FromSub selects from a derived table: SELECT ... FROM (subquery) AS alias
+func (qb *QueryBuilder) FromSub(sub *QueryBuilder, alias string) *QueryBuilder {
+	if sub == nil {
+		qb.setError(errNilSubquery)
+		return qb
+	}
+	if err := sub.queryError(); err != nil {
+		qb.inheritError(err)
+		return qb
+	}
+	sql, args := sub.buildSelect()
+	qb.table = "(" + sql + ") AS " + alias
+	qb.fromArgs = args
+	return qb
+}
+
+// inheritError keeps a subquery's build error as-is (it is already an *ORMError)
+func (qb *QueryBuilder) inheritError(err error) {
+	if qb.err == nil {
+		qb.err = err
+	}
+}
@@ -0,0 +1,57 @@
+package norm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWhereInSub_NumbersPlaceholdersAcrossQueries(t *testing.T) {
+	kn := &KintsNorm{}
+	sub := kn.Query().Table("posts").Select("user_id").Where("published = ?", true).Where("score > ?", 5)
+	q, args := kn.Query().Table("users").Where("tenant_id = ?", 7).WhereInSub("id", sub).Where("active = ?", true).buildSelect()
+	want := `SELECT * FROM users WHERE tenant_id = $1 AND id IN (SELECT user_id FROM posts WHERE published = $2 AND score > $3) AND active = $4`
+	if q != want {
+		t.Fatalf("sql:\n got %s\nwant %s", q, want)
+	}
+	if !reflect.DeepEqual(args, []any{7, true, 5, true}) {
+		t.Fatalf("args: %v", args)
+	}
+}
+
+func TestWhereExists_Correlated(t *testing.T) {
+	kn := &KintsNorm{}
+	sub := kn.Query().Table("posts p").Select("1").Where("p.user_id = u.id").Where("p.title = ?", "x")
+	q, args := kn.Query().Table("users u").Where("u.active = ?", true).WhereNotExists(sub).buildSelect()
+	want := `SELECT * FROM users u WHERE u.active = $1 AND NOT EXISTS (SELECT 1 FROM posts p WHERE p.user_id = u.id AND p.title = $2)`
+	if q != want {
+		t.Fatalf("sql:\n got %s\nwant %s", q, want)
+	}
+	if !reflect.DeepEqual(args, []any{true, "x"}) {
+		t.Fatalf("args: %v", args)
+	}
+}
+
+func TestFromSub_ArgsPrecedeOuterWhere(t *testing.T) {
+	kn := &KintsNorm{}
+	sub := kn.Query().Table("orders").Select("user_id", "sum(total) AS spent").Where("status = ?", "paid").GroupBy("user_id")
+	q, args := kn.Query().FromSub(sub, "t").Where("spent > ?", 100).Limit(10).buildSelect()
+	want := `SELECT * FROM (SELECT user_id, sum(total) AS spent FROM orders WHERE status = $1 GROUP BY user_id) AS t WHERE spent > $2 LIMIT 10`
+	if q != want {
+		t.Fatalf("sql:\n got %s\nwant %s", q, want)
+	}
+	if !reflect.DeepEqual(args, []any{"paid", 100}) {
+		t.Fatalf("args: %v", args)
+	}
+}
+
+func TestSubquery_PropagatesBuildError(t *testing.T) {
+	kn := &KintsNorm{}
+	sub := kn.Query().Table("posts").WhereNamed("id = :missing", map[string]any{})
+	qb := kn.Query().Table("users").WhereInSub("id", sub)
+	if qb.queryError() == nil {
+		t.Fatalf("expected sub error to propagate")
+	}
+	if kn.Query().Table("users").WhereExists(nil).queryError() == nil {
+		t.Fatalf("expected error for nil subquery")
+	}
+}
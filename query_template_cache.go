@@ -0,0 +1,219 @@
+package norm
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// defaultQueryTemplateCacheSize bounds the number of distinct builder shapes kept in memory
+const defaultQueryTemplateCacheSize = 1024
+
+// QueryTemplateCacheMetrics can optionally be implemented by a Metrics collector to observe the
+// query template cache (e.g. to export a hit rate)
+type QueryTemplateCacheMetrics interface {
+	QueryTemplateCacheHit()
+	QueryTemplateCacheMiss()
+}
+
+// QueryTemplateCacheStats is a point-in-time snapshot of the query template cache
+type QueryTemplateCacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Size   int
+}
+
+// HitRate returns hits / (hits + misses), or 0 when nothing was looked up yet
+func (s QueryTemplateCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// queryTemplateCache stores compiled SQL keyed on builder shape (table, columns, where skeleton, ...).
+// Only SQL text is cached; args are always collected from the builder so values never leak between queries.
+type queryTemplateCache struct {
+	mu      sync.RWMutex
+	max     int
+	entries map[string]string
+	hits    atomic.Uint64
+	misses  atomic.Uint64
+}
+
+func newQueryTemplateCache(max int) *queryTemplateCache {
+	if max <= 0 {
+		return nil
+	}
+	return &queryTemplateCache{max: max, entries: make(map[string]string, min(max, 64))}
+}
+
+func (c *queryTemplateCache) get(key string) (string, bool) {
+	c.mu.RLock()
+	sql, ok := c.entries[key]
+	c.mu.RUnlock()
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return sql, ok
+}
+
+func (c *queryTemplateCache) put(key, sql string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.max {
+		// evict an arbitrary entry; shapes are few and hot ones are re-added on the next miss
+		for k := range c.entries {
+			delete(c.entries, k)
+			break
+		}
+	}
+	c.entries[key] = sql
+}
+
+func (c *queryTemplateCache) stats() QueryTemplateCacheStats {
+	c.mu.RLock()
+	n := len(c.entries)
+	c.mu.RUnlock()
+	return QueryTemplateCacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Size: n}
+}
+
+// QueryTemplateCacheStats reports hit/miss counters of the query template cache (zero when disabled)
+func (kn *KintsNorm) QueryTemplateCacheStats() QueryTemplateCacheStats {
+	if kn == nil || kn.templates == nil {
+		return QueryTemplateCacheStats{}
+	}
+	return kn.templates.stats()
+}
+
+// cachedSQL returns the SQL for the builder's current shape, compiling and storing it on a miss.
+// argsFn must produce the same args, in the same order, as compile.
+func (qb *QueryBuilder) cachedSQL(op string, compile func() (string, []any), argsFn func() []any) (string, []any) {
+	if qb.kn == nil || qb.kn.templates == nil {
+		return compile()
+	}
+	c := qb.kn.templates
+	key := qb.shapeKey(op)
+	m, _ := qb.kn.metrics.(QueryTemplateCacheMetrics)
+	if sql, ok := c.get(key); ok {
+		if m != nil {
+			m.QueryTemplateCacheHit()
+		}
+		return sql, argsFn()
+	}
+	if m != nil {
+		m.QueryTemplateCacheMiss()
+	}
+	sql, args := compile()
+	c.put(key, sql)
+	return sql, args
+}
+
+// shapeKey encodes every builder field that influences the generated SQL text (but none of the values)
+func (qb *QueryBuilder) shapeKey(op string) string {
+	var sb strings.Builder
+	sb.Grow(128)
+	part := func(s string) {
+		sb.WriteString(s)
+		sb.WriteByte(0)
+	}
+	list := func(ss []string) {
+		for _, s := range ss {
+			sb.WriteString(s)
+			sb.WriteByte(1)
+		}
+		sb.WriteByte(0)
+	}
+	part(op)
+	for _, c := range qb.ctes {
+		part(c.name)
+		part(c.sql)
+		part(strconv.FormatBool(c.recursive))
+	}
+	part(qb.table)
+	list(qb.columns)
+	list(qb.joins)
+	list(qb.wheres)
+	list(qb.groupBy)
+	list(qb.havings)
+	part(qb.orderBy)
+	part(strconv.Itoa(len(qb.orderArgs)))
+	part(strconv.Itoa(qb.limit))
+	part(strconv.Itoa(qb.offset))
+	part(qb.lock)
+	part(qb.afterColumn)
+	part(qb.beforeColumn)
+	part(strconv.FormatBool(qb.modelHasSoftDelete))
+	part(qb.softDeleteColumn)
+	for _, j := range qb.joinSoftDeletes {
+		part(strconv.Itoa(j.join) + j.column)
+	}
+	part(strconv.Itoa(int(qb.qbSoftMode)))
+	part(strconv.FormatBool(qb.deleteHard))
+	list(qb.insertColumns)
+	for _, r := range qb.insertRows {
+		sb.WriteString(strconv.Itoa(len(r)))
+		for i, v := range r {
+			if _, ok := v.(insertDefault); ok {
+				sb.WriteByte('d')
+				sb.WriteString(strconv.Itoa(i))
+			}
+		}
+		sb.WriteByte(1)
+	}
+	sb.WriteByte(0)
+	list(qb.conflictCols)
+	part(qb.updateSetExpr)
+	list(qb.returningCols)
+	return sb.String()
+}
+
+func (qb *QueryBuilder) selectArgs() []any {
+	args := append(qb.leadingArgs(), qb.args...)
+	if qb.afterColumn != "" {
+		args = append(args, qb.afterValue)
+	}
+	if qb.beforeColumn != "" {
+		args = append(args, qb.beforeValue)
+	}
+	if len(qb.havings) > 0 {
+		args = append(args, qb.havingArgs...)
+	}
+	if qb.orderBy != "" {
+		args = append(args, qb.orderArgs...)
+	}
+	return args
+}
+
+func (qb *QueryBuilder) deleteArgs() []any { return qb.args }
+
+func (qb *QueryBuilder) insertArgs() []any {
+	total := len(qb.updateSetArgs)
+	for _, r := range qb.insertRows {
+		total += len(r)
+	}
+	args := make([]any, 0, total)
+	for _, r := range qb.insertRows {
+		for _, v := range r {
+			if _, ok := v.(insertDefault); !ok {
+				args = append(args, v)
+			}
+		}
+	}
+	if len(qb.conflictCols) > 0 && qb.updateSetExpr != "" {
+		args = append(args, qb.updateSetArgs...)
+	}
+	return args
+}
+
+func (qb *QueryBuilder) updateArgs() []any {
+	args := append([]any(nil), qb.updateSetArgs...)
+	if len(qb.wheres) > 0 {
+		args = append(args, qb.args...)
+	}
+	return args
+}
@@ -0,0 +1,77 @@
+package norm
+
+import (
+	"reflect"
+	"testing"
+)
+
+type templateMetrics struct {
+	NoopMetrics
+	hits, misses int
+}
+
+func (m *templateMetrics) QueryTemplateCacheHit()  { m.hits++ }
+func (m *templateMetrics) QueryTemplateCacheMiss() { m.misses++ }
+
+func TestQueryTemplateCache_ReusesSQLWithFreshArgs(t *testing.T) {
+	m := &templateMetrics{}
+	kn := &KintsNorm{templates: newQueryTemplateCache(8), metrics: m}
+	build := func(id int, after any) (string, []any) {
+		return kn.Query().Table("users").Where("id = ?", id).OrderBy("id").After("id", after).Limit(5).buildSelect()
+	}
+	s1, a1 := build(1, 10)
+	s2, a2 := build(2, 20)
+	if s1 != s2 {
+		t.Fatalf("sql differs: %q vs %q", s1, s2)
+	}
+	if !reflect.DeepEqual(a1, []any{1, 10}) || !reflect.DeepEqual(a2, []any{2, 20}) {
+		t.Fatalf("args %v %v", a1, a2)
+	}
+	// a different shape must not hit
+	if s3, _ := kn.Query().Table("users").Where("email = ?", "x").buildSelect(); s3 == s1 {
+		t.Fatalf("shape collision")
+	}
+	st := kn.QueryTemplateCacheStats()
+	if st.Hits != 1 || st.Misses != 2 || st.Size != 2 || m.hits != 1 || m.misses != 2 {
+		t.Fatalf("stats %+v metrics %+v", st, m)
+	}
+	if st.HitRate() <= 0.3 || st.HitRate() >= 0.4 {
+		t.Fatalf("hit rate %v", st.HitRate())
+	}
+}
+
+func TestQueryTemplateCache_WriteArgsMatchCompiled(t *testing.T) {
+	kn := &KintsNorm{templates: newQueryTemplateCache(8)}
+	ins := func() *QueryBuilder {
+		return kn.Query().Table("t").Insert("a", "b").Values(1, 2).OnConflict("a").DoUpdateSet("b = ?", 3).Returning("a")
+	}
+	upd := func() *QueryBuilder { return kn.Query().Table("t").Set("a = ?", 1).Where("b = ?", 2) }
+	del := func() *QueryBuilder { return kn.Query().Table("t").Where("a = ?", 1).HardDelete() }
+	for _, mk := range []func() (string, []any){
+		func() (string, []any) { return ins().buildInsert() },
+		func() (string, []any) { return upd().buildUpdate() },
+		func() (string, []any) { return del().buildDelete() },
+	} {
+		s1, a1 := mk() // miss, compiled
+		s2, a2 := mk() // hit
+		if s1 != s2 || !reflect.DeepEqual(a1, a2) {
+			t.Fatalf("mismatch %q %v / %q %v", s1, a1, s2, a2)
+		}
+	}
+	if st := kn.QueryTemplateCacheStats(); st.Hits != 3 {
+		t.Fatalf("stats %+v", st)
+	}
+}
+
+func TestQueryTemplateCache_EvictsAtCapacity(t *testing.T) {
+	c := newQueryTemplateCache(2)
+	c.put("a", "1")
+	c.put("b", "2")
+	c.put("c", "3")
+	if st := c.stats(); st.Size != 2 {
+		t.Fatalf("size %d", st.Size)
+	}
+	if newQueryTemplateCache(0) != nil {
+		t.Fatalf("0 should disable")
+	}
+}
@@ -0,0 +1,101 @@
+package norm
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// queryTimeoutKey marks a context whose statement deadline was already set by an outer timeoutExecuter, so a
+// per-query or per-repository timeout replaces Config.DefaultQueryTimeout instead of being capped by it
+type queryTimeoutKey struct{}
+
+// WithTimeout bounds each statement of this builder to d (a context deadline; pgx cancels the statement
+// server-side when it expires), overriding Config.DefaultQueryTimeout. An earlier deadline already on the
+// caller's context still wins. Rows returned by Query keep the deadline until they are closed.
+func (qb *QueryBuilder) WithTimeout(d time.Duration) *QueryBuilder {
+	qb.exec = withTimeout(qb.exec, d)
+	return qb
+}
+
+// WithQueryTimeout bounds each statement of the repository to d, overriding Config.DefaultQueryTimeout
+func WithQueryTimeout(d time.Duration) RepositoryOption {
+	return func(o *repoOptions) { o.timeout = d }
+}
+
+// withDefaultTimeout applies Config.DefaultQueryTimeout of kn to exec
+func withDefaultTimeout(kn *KintsNorm, exec dbExecuter) dbExecuter {
+	if kn == nil || kn.config == nil {
+		return exec
+	}
+	return withTimeout(exec, kn.config.DefaultQueryTimeout)
+}
+
+func withTimeout(exec dbExecuter, d time.Duration) dbExecuter {
+	if d <= 0 {
+		return exec
+	}
+	return timeoutExecuter{exec: exec, timeout: d}
+}
+
+// timeoutExecuter runs every statement under a context deadline of timeout, unless an outer
+// timeoutExecuter already set one
+type timeoutExecuter struct {
+	exec    dbExecuter
+	timeout time.Duration
+}
+
+func (e timeoutExecuter) unwrap() dbExecuter { return e.exec }
+
+func (e timeoutExecuter) start(ctx context.Context) (context.Context, context.CancelFunc) {
+	if ctx.Value(queryTimeoutKey{}) != nil {
+		return ctx, func() {}
+	}
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	return context.WithValue(ctx, queryTimeoutKey{}, true), cancel
+}
+
+func (e timeoutExecuter) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	ctx, cancel := e.start(ctx)
+	defer cancel()
+	return e.exec.Exec(ctx, sql, arguments...)
+}
+
+func (e timeoutExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	ctx, cancel := e.start(ctx)
+	rows, err := e.exec.Query(ctx, sql, args...)
+	if err != nil || rows == nil {
+		cancel()
+		return rows, err
+	}
+	return &timeoutRows{Rows: rows, cancel: cancel}, nil
+}
+
+func (e timeoutExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	ctx, cancel := e.start(ctx)
+	return &timeoutRow{row: e.exec.QueryRow(ctx, sql, args...), cancel: cancel}
+}
+
+// timeoutRows releases the statement deadline when the rows are closed
+type timeoutRows struct {
+	pgx.Rows
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRows) Close() {
+	r.Rows.Close()
+	r.cancel()
+}
+
+// timeoutRow releases the statement deadline after Scan
+type timeoutRow struct {
+	row    pgx.Row
+	cancel context.CancelFunc
+}
+
+func (r *timeoutRow) Scan(dest ...any) error {
+	defer r.cancel()
+	return r.row.Scan(dest...)
+}
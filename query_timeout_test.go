@@ -0,0 +1,70 @@
+package norm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// deadlineExec records the deadline of the context each statement ran with
+type deadlineExec struct {
+	recExecRepo
+	deadline time.Time
+	ctx      context.Context
+}
+
+func (e *deadlineExec) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	e.deadline, _ = ctx.Deadline()
+	return e.recExecRepo.Exec(ctx, sql, args...)
+}
+
+func (e *deadlineExec) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	e.deadline, _ = ctx.Deadline()
+	e.ctx = ctx
+	return e.recExecRepo.Query(ctx, sql, args...)
+}
+
+func TestWithTimeout_OverridesDefault(t *testing.T) {
+	kn := &KintsNorm{config: &Config{DefaultQueryTimeout: time.Second}}
+	ex := &deadlineExec{}
+
+	start := time.Now()
+	qb := &QueryBuilder{kn: kn, exec: wrapExec(kn, ex)}
+	if _, err := qb.Table("t").Where("id = ?", 1).Delete(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if d := ex.deadline.Sub(start); d <= 0 || d > 2*time.Second {
+		t.Fatalf("default deadline in %v", d)
+	}
+
+	// a longer per-query timeout replaces the default rather than being capped by it
+	qb = (&QueryBuilder{kn: kn, exec: wrapExec(kn, ex)}).WithTimeout(time.Minute)
+	var rows []map[string]any
+	if err := qb.Table("t").Find(context.Background(), &rows); err != nil {
+		t.Fatal(err)
+	}
+	if d := ex.deadline.Sub(start); d < 30*time.Second {
+		t.Fatalf("override deadline in %v", d)
+	}
+	// the deadline is released once the rows are closed
+	if ex.ctx.Err() == nil {
+		t.Fatalf("statement context still live after Find")
+	}
+}
+
+func TestRepo_WithQueryTimeout(t *testing.T) {
+	ex := &deadlineExec{}
+	r := NewRepositoryWithExecutor[rUser](&KintsNorm{}, ex, WithQueryTimeout(50*time.Millisecond)).(*repo[rUser])
+	_ = r.Delete(context.Background(), 1)
+	if ex.deadline.IsZero() {
+		t.Fatalf("no deadline")
+	}
+	ex.deadline = time.Time{}
+	_ = r.WithExecutor(ex).(*repo[rUser]).Delete(context.Background(), 1)
+	if ex.deadline.IsZero() {
+		t.Fatalf("WithExecutor dropped the timeout")
+	}
+}
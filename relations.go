@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 
 	core "github.com/kintsdev/norm/internal/core"
 )
@@ -24,7 +25,7 @@ func EagerLoadMany[T any, R any](ctx context.Context, kn *KintsNorm, parents []*
 	// Query children by IN
 	var rvar R
 	rType := reflect.TypeOf(rvar)
-	childTable := core.ToSnakeCase(rType.Name()) + "s"
+	childTable := core.QualifiedTableName(rType)
 	var children []R
 	if err := kn.Query().Table(childTable).WhereNamed(childForeignKey+" IN :ids", map[string]any{"ids": ids}).Find(ctx, &children); err != nil {
 		return err
@@ -54,7 +55,7 @@ func EagerLoadMany[T any, R any](ctx context.Context, kn *KintsNorm, parents []*
 func LazyLoadMany[R any](ctx context.Context, kn *KintsNorm, parentID any, childForeignKey string) ([]*R, error) {
 	var rvar R
 	rType := reflect.TypeOf(rvar)
-	childTable := core.ToSnakeCase(rType.Name()) + "s"
+	childTable := core.QualifiedTableName(rType)
 	var rows []R
 	if err := kn.Query().Table(childTable).Where(childForeignKey+" = ?", parentID).Find(ctx, &rows); err != nil {
 		return nil, err
@@ -65,3 +66,132 @@ func LazyLoadMany[R any](ctx context.Context, kn *KintsNorm, parentID any, child
 	}
 	return out, nil
 }
+
+// preload populates the named relation fields (declared with hasmany:/hasone:/belongsto: tags) on parents,
+// issuing one IN query per relation. newQuery supplies builders bound to the caller's executor.
+func preload[T any](ctx context.Context, newQuery func() *QueryBuilder, parents []*T, names []string) error {
+	if len(parents) == 0 || len(names) == 0 {
+		return nil
+	}
+	mapping := core.StructMapper(reflect.TypeFor[T]())
+	vals := make([]reflect.Value, 0, len(parents))
+	for _, p := range parents {
+		if p != nil {
+			vals = append(vals, reflect.ValueOf(p).Elem())
+		}
+	}
+	for _, name := range names {
+		ri, ok := mapping.Relations[name]
+		if !ok {
+			return &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("unknown relation: %s", name)}
+		}
+		if err := loadRelation(ctx, newQuery, vals, mapping, ri); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadRelation(ctx context.Context, newQuery func() *QueryBuilder, parents []reflect.Value, mapping core.StructMapping, ri core.RelationInfo) error {
+	many := ri.Type.Kind() == reflect.Slice
+	elemType := ri.Type
+	if many {
+		elemType = elemType.Elem()
+	}
+	elemPtr := elemType.Kind() == reflect.Pointer
+	relType := elemType
+	if elemPtr {
+		relType = relType.Elem()
+	}
+	if relType.Kind() != reflect.Struct || ri.ForeignKey == "" {
+		return &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("invalid relation field type %s (or missing fk)", ri.Type)}
+	}
+	relMapping := core.StructMapper(relType)
+	table := ri.Table
+	if table == "" {
+		table = core.QualifiedTableName(relType)
+	}
+	// local: the parent field providing keys; remote: the related column/field matched against them
+	localCol, remoteCol := mapping.PrimaryColumn, ri.ForeignKey
+	if ri.Kind == core.RelationBelongsTo {
+		localCol, remoteCol = ri.ForeignKey, relMapping.PrimaryColumn
+	}
+	localFi, ok := mapping.FieldsByColumn[strings.ToLower(localCol)]
+	if !ok {
+		return &ORMError{Code: ErrCodeInvalidColumn, Message: fmt.Sprintf("relation key column not found: %s", localCol)}
+	}
+	remoteFi, ok := relMapping.FieldsByColumn[strings.ToLower(remoteCol)]
+	if !ok {
+		return &ORMError{Code: ErrCodeInvalidColumn, Message: fmt.Sprintf("relation key column not found in %s: %s", relType.Name(), remoteCol)}
+	}
+	keys := make([]any, 0, len(parents))
+	seen := make(map[string]struct{}, len(parents))
+	for _, p := range parents {
+		k, ok := relationKey(p.FieldByIndex(localFi.Index))
+		if !ok {
+			continue
+		}
+		if _, dup := seen[fmt.Sprint(k)]; dup {
+			continue
+		}
+		seen[fmt.Sprint(k)] = struct{}{}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	qb := newQuery().Table(quoteQualified(table)).WhereCond(In(quoteQualified(remoteCol), keys))
+	if relMapping.HasSoftDelete {
+		qb = qb.Where("deleted_at IS NULL")
+	}
+	rows := reflect.New(reflect.SliceOf(relType))
+	if err := qb.Find(ctx, rows.Interface()); err != nil {
+		return err
+	}
+	groups := make(map[string][]reflect.Value)
+	for i := range rows.Elem().Len() {
+		row := rows.Elem().Index(i)
+		if k, ok := relationKey(row.FieldByIndex(remoteFi.Index)); ok {
+			groups[fmt.Sprint(k)] = append(groups[fmt.Sprint(k)], row.Addr())
+		}
+	}
+	for _, p := range parents {
+		k, ok := relationKey(p.FieldByIndex(localFi.Index))
+		if !ok {
+			continue
+		}
+		matches := groups[fmt.Sprint(k)]
+		field := p.FieldByIndex(ri.Index)
+		if many {
+			out := reflect.MakeSlice(ri.Type, 0, len(matches))
+			for _, m := range matches {
+				if elemPtr {
+					out = reflect.Append(out, m)
+				} else {
+					out = reflect.Append(out, m.Elem())
+				}
+			}
+			field.Set(out)
+			continue
+		}
+		if len(matches) > 0 {
+			if elemPtr {
+				field.Set(matches[0])
+			} else {
+				field.Set(matches[0].Elem())
+			}
+		}
+	}
+	return nil
+}
+
+// relationKey returns the comparable key value of a field, dereferencing pointers; false for nil
+func relationKey(v reflect.Value) (any, bool) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	return v.Interface(), true
+}
@@ -0,0 +1,95 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type plAuthor struct {
+	ID    int64     `db:"id" norm:"primary_key"`
+	Name  string    `db:"name"`
+	Posts []*plPost `norm:"hasmany:pl_posts,fk:author_id"`
+}
+
+type plPost struct {
+	ID       int64     `db:"id" norm:"primary_key"`
+	AuthorID int64     `db:"author_id"`
+	Title    string    `db:"title"`
+	Author   *plAuthor `norm:"belongsto:pl_authors,fk:author_id"`
+}
+
+type plResult struct {
+	fields []string
+	rows   [][]any
+}
+
+// plExec answers successive Query calls with result sets carrying their own columns
+type plExec struct {
+	results []plResult
+	sqls    []string
+}
+
+func (e *plExec) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	e.sqls = append(e.sqls, sql)
+	return pgconn.CommandTag{}, nil
+}
+func (e *plExec) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	e.sqls = append(e.sqls, sql)
+	var r plResult
+	if len(e.results) > 0 {
+		r, e.results = e.results[0], e.results[1:]
+	}
+	return &fakeRowsRU{rows: r.rows, fields: r.fields}, nil
+}
+func (e *plExec) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row { return errorRow{} }
+
+func TestRepo_PreloadHasManyAndBelongsTo(t *testing.T) {
+	ex := &plExec{results: []plResult{
+		{fields: []string{"id", "name"}, rows: [][]any{{int64(1), "ann"}, {int64(2), "bob"}}},
+		{fields: []string{"id", "author_id", "title"}, rows: [][]any{{int64(10), int64(1), "a"}, {int64(11), int64(1), "b"}}},
+	}}
+	r := &repo[plAuthor]{kn: &KintsNorm{}, exec: ex}
+	authors, err := r.Preload("Posts").Find(context.Background())
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if len(authors[0].Posts) != 2 || authors[0].Posts[1].Title != "b" || len(authors[1].Posts) != 0 {
+		t.Fatalf("posts not assigned: %+v / %+v", authors[0].Posts, authors[1].Posts)
+	}
+	if !strings.Contains(ex.sqls[1], `FROM "pl_posts" WHERE "author_id" IN ($1, $2)`) {
+		t.Fatalf("preload sql=%s", ex.sqls[1])
+	}
+
+	ex = &plExec{results: []plResult{
+		{fields: []string{"id", "author_id", "title"}, rows: [][]any{{int64(10), int64(1), "a"}}},
+		{fields: []string{"id", "name"}, rows: [][]any{{int64(1), "ann"}}},
+	}}
+	pr := &repo[plPost]{kn: &KintsNorm{}, exec: ex}
+	p, err := pr.Preload("Author").FindOne(context.Background(), Eq("id", 10))
+	if err != nil || p.Author == nil || p.Author.Name != "ann" {
+		t.Fatalf("author not assigned: %+v err=%v", p, err)
+	}
+}
+
+func TestRepo_PreloadUnknownRelation(t *testing.T) {
+	ex := &plExec{results: []plResult{{fields: []string{"id", "name"}, rows: [][]any{{int64(1), "ann"}}}}}
+	r := &repo[plAuthor]{kn: &KintsNorm{}, exec: ex}
+	if _, err := r.Preload("Comments").Find(context.Background()); err == nil || !strings.Contains(err.Error(), "unknown relation") {
+		t.Fatalf("expected unknown relation error, got %v", err)
+	}
+}
+
+func TestRelationFieldsAreNotColumns(t *testing.T) {
+	ex := &recExecRepo{}
+	r := &repo[plPost]{kn: &KintsNorm{}, exec: ex}
+	if err := r.Update(context.Background(), &plPost{ID: 1, AuthorID: 2, Title: "t"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if strings.Contains(ex.lastSQL, "author\"") || len(ex.lastArgs) != 3 {
+		t.Fatalf("relation field written: %s %v", ex.lastSQL, ex.lastArgs)
+	}
+}
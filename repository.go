@@ -3,10 +3,15 @@ package norm
 import (
 	"context"
 	"fmt"
+	"iter"
+	"maps"
 	"reflect"
+	"slices"
 	"strings"
+	"time"
 
 	pgxv5 "github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
 	core "github.com/kintsdev/norm/internal/core"
 )
 
@@ -17,30 +22,70 @@ import (
 type Repository[T any] interface {
 	Create(ctx context.Context, entity *T) error
 	CreateBatch(ctx context.Context, entities []*T) error
+	// GetByID loads a row by primary key; a composite key is given as []any in column order or map[string]any
 	GetByID(ctx context.Context, id any) (*T, error)
+	// FindByPK is GetByID taking the key values as arguments, e.g. FindByPK(ctx, orderID, lineNo)
+	FindByPK(ctx context.Context, key ...any) (*T, error)
+	// GetByIDs loads several rows in one query; the result is aligned with ids, nil where a row is missing
+	GetByIDs(ctx context.Context, ids []any) ([]*T, error)
 	Update(ctx context.Context, entity *T) error
 	UpdatePartial(ctx context.Context, id any, fields map[string]any) error
+	// UpdatePartialVersion applies fields only while the row's version column equals version (ErrOptimisticLock otherwise)
+	UpdatePartialVersion(ctx context.Context, id any, version any, fields map[string]any) error
 	Delete(ctx context.Context, id any) error
 	SoftDelete(ctx context.Context, id any) error
+	// SoftDeleteCascade soft-deletes the row and its children (T's relations or explicit specs) in one transaction
+	SoftDeleteCascade(ctx context.Context, id any, cascades ...CascadeSpec) error
 	SoftDeleteAll(ctx context.Context) (int64, error)
 	Restore(ctx context.Context, id any) error
 	PurgeTrashed(ctx context.Context) (int64, error)
+	// DeleteWhere, SoftDeleteWhere and UpdateWhere are set-based writes returning rows affected
+	DeleteWhere(ctx context.Context, conditions ...Condition) (int64, error)
+	SoftDeleteWhere(ctx context.Context, conditions ...Condition) (int64, error)
+	UpdateWhere(ctx context.Context, fields map[string]any, conditions ...Condition) (int64, error)
 	Find(ctx context.Context, conditions ...Condition) ([]*T, error)
 	FindOne(ctx context.Context, conditions ...Condition) (*T, error)
+	// FindForUpdate and FindOneForUpdate lock the matched rows (FOR UPDATE); they must run in a transaction
+	FindForUpdate(ctx context.Context, conditions ...Condition) ([]*T, error)
+	FindOneForUpdate(ctx context.Context, conditions ...Condition) (*T, error)
+	FindEach(ctx context.Context, fn func(row *T) error, conditions ...Condition) error
+	// Stream pages through matching rows by primary key, stopping when ctx is cancelled
+	Stream(ctx context.Context, conditions ...Condition) iter.Seq2[*T, error]
 	Count(ctx context.Context, conditions ...Condition) (int64, error)
 	Exists(ctx context.Context, conditions ...Condition) (bool, error)
 	WithTrashed() Repository[T]
 	OnlyTrashed() Repository[T]
+	// DryRun returns a copy of the repository recording its writes instead of executing them
+	DryRun() *DryRunRepository[T]
+	// WithExecutor returns a copy of the repository bound to another executor (pool or transaction)
+	WithExecutor(exec dbExecuter) Repository[T]
+	// Scoped returns a repository whose reads (GetByID, Find, FindOne, FindEach, Count, Exists, FindPage) apply scopes
+	Scoped(scopes ...Scope) Repository[T]
+	// Preload returns a repository that populates the named relation fields on GetByID/Find/FindOne/FindPage results
+	Preload(relations ...string) Repository[T]
+	// DefaultOrder returns a repository whose FindPage orders by orderBy when PageRequest.OrderBy is empty
+	DefaultOrder(orderBy string) Repository[T]
+	// Cached returns a repository caching GetByID/FindOne rows by primary key for ttl, invalidated by its writes
+	Cached(ttl time.Duration) Repository[T]
 	FindPage(ctx context.Context, page PageRequest, conditions ...Condition) (Page[T], error)
 	CreateCopyFrom(ctx context.Context, entities []*T, columns ...string) (int64, error)
+	EnsureAll(ctx context.Context, entities []*T, conflictCols []string) ([]*T, error)
 	Upsert(ctx context.Context, entity *T, conflictCols []string, updateCols []string) error
+	UpsertBatch(ctx context.Context, entities []*T, conflictCols []string, updateCols []string) error
 }
 
 // repo is a minimal placeholder implementation to compile
 type repo[T any] struct {
-	kn   *KintsNorm
-	exec dbExecuter
-	mode softDeleteMode
+	kn       *KintsNorm
+	exec     dbExecuter
+	mode     softDeleteMode
+	preloads []string
+	scopes   []Scope
+	schema   string          // set by InSchema; overrides the model's schema tag
+	timeout  time.Duration   // set by WithQueryTimeout; reapplied by WithExecutor
+	order    string          // set by DefaultOrder
+	dry      *dryRunRecorder // set by DryRun; writes are recorded, not executed
+	cacheTTL time.Duration   // set by Cached; > 0 caches rows by primary key
 }
 
 type softDeleteMode int
@@ -52,31 +97,107 @@ const (
 )
 
 // NewRepository creates a new generic repository
-func NewRepository[T any](kn *KintsNorm) Repository[T] {
+func NewRepository[T any](kn *KintsNorm, opts ...RepositoryOption) Repository[T] {
 	var exec dbExecuter
 	// auto-route reads to readPool when configured
 	if kn.readPool != nil {
-		exec = routingExecuter{kn: kn}
+		exec = wrapRouting(kn)
 	} else {
-		exec = kn.pool
-		if kn.breaker != nil {
-			exec = breakerExecuter{kn: kn, exec: exec}
-		}
+		exec = wrapExec(kn, kn.pool)
 	}
-	return &repo[T]{kn: kn, exec: exec}
+	o := applyRepoOptions(opts)
+	// statements join a transaction carried by the call context (WithTx)
+	return &repo[T]{kn: kn, exec: withTimeout(ctxTxExecuter{base: exec}, o.timeout), schema: o.schema, timeout: o.timeout}
 }
 
 // NewRepositoryWithExecutor creates a repository bound to a specific executor (pool or tx)
-func NewRepositoryWithExecutor[T any](kn *KintsNorm, exec dbExecuter) Repository[T] {
-	return &repo[T]{kn: kn, exec: exec}
+func NewRepositoryWithExecutor[T any](kn *KintsNorm, exec dbExecuter, opts ...RepositoryOption) Repository[T] {
+	o := applyRepoOptions(opts)
+	return &repo[T]{kn: kn, exec: withTimeout(wrapExec(kn, exec), o.timeout), schema: o.schema, timeout: o.timeout}
+}
+
+// withBreaker wraps exec with the circuit breaker when enabled and not already wrapped
+func withBreaker(kn *KintsNorm, exec dbExecuter) dbExecuter {
+	if kn == nil || kn.breaker == nil {
+		return exec
+	}
+	if _, ok := exec.(breakerExecuter); ok {
+		return exec
+	}
+	return breakerExecuter{kn: kn, exec: exec}
+}
+
+// WithExecutor returns a copy bound to exec (e.g. tx.Exec()) that keeps the soft-delete mode, preloads and
+// circuit breaker of the original repository
+func (r *repo[T]) WithExecutor(exec dbExecuter) Repository[T] {
+	nr := *r
+	nr.exec = withTimeout(wrapExec(r.kn, exec), r.timeout)
+	if r.dry != nil {
+		nr.exec = dryRunExecuter{kn: r.kn, rec: r.dry, exec: nr.exec}
+	}
+	nr.preloads = append([]string(nil), r.preloads...)
+	nr.scopes = append([]Scope(nil), r.scopes...)
+	return &nr
 }
 
 func (r *repo[T]) WithTrashed() Repository[T] { nr := *r; nr.mode = softModeWithTrashed; return &nr }
 func (r *repo[T]) OnlyTrashed() Repository[T] { nr := *r; nr.mode = softModeOnlyTrashed; return &nr }
 
+func (r *repo[T]) Scoped(scopes ...Scope) Repository[T] {
+	nr := *r
+	nr.scopes = append(append([]Scope(nil), r.scopes...), scopes...)
+	return &nr
+}
+
+func (r *repo[T]) DefaultOrder(orderBy string) Repository[T] {
+	nr := *r
+	nr.order = orderBy
+	return &nr
+}
+
+// scopedQuery starts a read on the repository table with its scopes applied
+func (r *repo[T]) scopedQuery() *QueryBuilder {
+	return r.query().Table(r.tableName()).Scopes(r.scopes...)
+}
+
+// conditionQuery is the scoped query for Find-style reads: conditions plus the soft-delete filter of the mode
+func (r *repo[T]) conditionQuery(conditions []Condition) *QueryBuilder {
+	qb := r.scopedQuery()
+	for _, c := range conditions {
+		qb = qb.Where(c.Expr, c.Args...)
+	}
+	var t T
+	if core.ModelHasSoftDelete(reflect.TypeOf(t)) {
+		switch r.mode {
+		case softModeOnlyTrashed:
+			qb = qb.Where("deleted_at IS NOT NULL")
+		case softModeWithTrashed:
+			// no filter
+		default:
+			qb = qb.Where("deleted_at IS NULL")
+		}
+	}
+	return qb
+}
+
+func (r *repo[T]) Preload(relations ...string) Repository[T] {
+	nr := *r
+	nr.preloads = append(append([]string(nil), r.preloads...), relations...)
+	return &nr
+}
+
+// applyPreloads loads requested relations for rows using the repository's executor
+func (r *repo[T]) applyPreloads(ctx context.Context, rows []*T) error {
+	if len(r.preloads) == 0 {
+		return nil
+	}
+	return preload(ctx, r.query, rows, r.preloads)
+}
+
 // audit emits an audit entry if a global audit hook is registered
 func (r *repo[T]) audit(ctx context.Context, action AuditAction, entityID any, entity any, query string, err error) {
-	if r.kn == nil || r.kn.auditHook == nil {
+	// dry-run writes did not happen
+	if r.kn == nil || r.kn.auditHook == nil || r.dry != nil {
 		return
 	}
 	r.kn.auditHook.OnAudit(ctx, AuditEntry{
@@ -89,13 +210,28 @@ func (r *repo[T]) audit(ctx context.Context, action AuditAction, entityID any, e
 	})
 }
 
+// query returns a builder bound to the repository executor (pool, routing, replica or tx)
+func (r *repo[T]) query() *QueryBuilder {
+	return &QueryBuilder{kn: r.kn, exec: r.exec}
+}
+
+// Tabler can be implemented by models to override their table name (default: snake_case(type) + "s", or
+// the NamingStrategy set with SetNamingStrategy).
+// A `norm:"table:name"` tag on a blank `_` field does the same without a method:
+//
+//	type Person struct {
+//		_  struct{} `norm:"table:people"`
+//		ID int64    `db:"id" norm:"primary_key"`
+//	}
+type Tabler interface {
+	TableName() string
+}
+
 func (r *repo[T]) tableName() string {
-	var t T
-	typ := reflect.TypeOf(t)
-	for typ.Kind() == reflect.Pointer {
-		typ = typ.Elem()
+	if r.schema != "" {
+		return core.Qualify(r.schema, core.TableName(reflect.TypeFor[T]()))
 	}
-	return core.ToSnakeCase(typ.Name()) + "s"
+	return core.QualifiedTableName(reflect.TypeFor[T]())
 }
 
 func (r *repo[T]) Create(ctx context.Context, entity *T) error {
@@ -108,6 +244,15 @@ func (r *repo[T]) Create(ctx context.Context, entity *T) error {
 			return err
 		}
 	}
+	if err := checkEnumFields(reflect.ValueOf(entity), true); err != nil {
+		return err
+	}
+	if err := generateUUIDs(entity); err != nil {
+		return err
+	}
+	if err := r.generateID(ctx, entity); err != nil {
+		return err
+	}
 	execFn := func() error {
 		val := reflect.Indirect(reflect.ValueOf(entity))
 		typ := val.Type()
@@ -115,17 +260,19 @@ func (r *repo[T]) Create(ctx context.Context, entity *T) error {
 		cols := make([]string, 0, typ.NumField())
 		placeholders := make([]string, 0, typ.NumField())
 		args := make([]any, 0, typ.NumField())
+		// DB-generated columns (auto-increment PK, zero-valued default: columns) are returned and written back
+		returning := []string{}
+		targets := []any{}
 		idx := 1
-		for i := 0; i < typ.NumField(); i++ {
-			f := typ.Field(i)
+		for _, f := range core.Fields(typ) {
 			if f.PkgPath != "" {
 				continue
 			}
-			col := f.Tag.Get("db")
-			if col == "" {
-				col = core.ToSnakeCase(f.Name)
-			}
+			col := core.ColumnName(f)
+			fv := val.FieldByIndex(f.Index)
 			if mapper.AutoIncrement && strings.EqualFold(col, mapper.PrimaryColumn) {
+				returning = append(returning, quoteQualified(col))
+				targets = append(targets, fv.Addr().Interface())
 				continue
 			}
 			// Prefer `norm` tag; fallback to legacy `orm`
@@ -135,11 +282,15 @@ func (r *repo[T]) Create(ctx context.Context, entity *T) error {
 			}
 			// skip ignored fields
 			low := strings.ToLower(orm)
-			if strings.Contains(low, "-") || strings.Contains(low, "ignore") {
+			if strings.Contains(low, "-") || strings.Contains(low, "ignore") || core.IsRelationTag(orm) {
+				continue
+			}
+			if (strings.Contains(orm, "default:") && fv.IsZero()) || mapper.FieldsByColumn[strings.ToLower(col)].Generated {
+				returning = append(returning, quoteQualified(col))
+				targets = append(targets, fv.Addr().Interface())
 				continue
 			}
-			fv := val.Field(i)
-			if strings.Contains(orm, "default:") && fv.IsZero() {
+			if core.OmitEmpty(f) && fv.IsZero() {
 				continue
 			}
 			cols = append(cols, quoteQualified(col))
@@ -148,8 +299,18 @@ func (r *repo[T]) Create(ctx context.Context, entity *T) error {
 			idx++
 		}
 		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", r.tableName(), strings.Join(cols, ", "), strings.Join(placeholders, ", "))
-		_, err := r.exec.Exec(ctx, query, args...)
-		if err != nil {
+		if len(cols) == 0 {
+			query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", r.tableName())
+		}
+		if len(returning) == 0 {
+			_, err := r.exec.Exec(ctx, query, args...)
+			if err != nil {
+				return wrapPgError(err, query, args)
+			}
+			return nil
+		}
+		query += " RETURNING " + strings.Join(returning, ", ")
+		if err := r.exec.QueryRow(ctx, query, args...).Scan(targets...); err != nil {
 			return wrapPgError(err, query, args)
 		}
 		return nil
@@ -173,44 +334,80 @@ func (r *repo[T]) Create(ctx context.Context, entity *T) error {
 	return nil
 }
 
+// CreateBatch inserts entities with multi-row INSERT statements of up to batchSize rows (see WithBatchSize),
+// writing generated columns back via RETURNING. Several chunks run in one transaction.
 func (r *repo[T]) CreateBatch(ctx context.Context, entities []*T) error {
 	if len(entities) == 0 {
 		return nil
 	}
-	// Wrap in a transaction for atomicity when pool is available
-	if r.kn != nil && r.kn.pool != nil {
-		tx, err := r.kn.pool.Begin(ctx)
-		if err != nil {
-			return err
-		}
-		defer tx.Rollback(ctx) //nolint:errcheck
-		txExec := dbExecuter(tx)
-		if r.kn.breaker != nil {
-			txExec = breakerExecuter{kn: r.kn, exec: tx}
+	for _, e := range entities {
+		if e == nil {
+			return &ORMError{Code: ErrCodeValidation, Message: "nil entity"}
 		}
-		txRepo := &repo[T]{kn: r.kn, exec: txExec, mode: r.mode}
-		for _, e := range entities {
-			if err := txRepo.Create(ctx, e); err != nil {
+		// model hook: BeforeCreate
+		if bc, ok := any(e).(BeforeCreate); ok {
+			if err := bc.BeforeCreate(ctx); err != nil {
 				return err
 			}
 		}
-		return tx.Commit(ctx)
+		if err := checkEnumFields(reflect.ValueOf(e), true); err != nil {
+			return err
+		}
+		if err := generateUUIDs(e); err != nil {
+			return err
+		}
+		if err := r.generateID(ctx, e); err != nil {
+			return err
+		}
+	}
+	var t T
+	typ := reflect.TypeOf(t)
+	fields := writableFields(typ)
+	err := r.inChunks(ctx, len(entities), len(fields), func(exec dbExecuter, start, end int) error {
+		return r.insertChunk(ctx, exec, entities[start:end], fields)
+	})
+	if err != nil {
+		return err
 	}
-	// Fallback: sequential creates when pool is not directly available
 	for _, e := range entities {
-		if err := r.Create(ctx, e); err != nil {
-			return err
+		// model hook: AfterCreate
+		if ac, ok := any(e).(AfterCreate); ok {
+			if err := ac.AfterCreate(ctx); err != nil {
+				return err
+			}
 		}
+		r.audit(ctx, AuditActionCreate, nil, e, "", nil)
 	}
 	return nil
 }
 
 func (r *repo[T]) GetByID(ctx context.Context, id any) (*T, error) {
+	cols := keyColumns(core.StructMapper(reflect.TypeFor[T]()))
+	key, err := keyValues(cols, id)
+	if err != nil {
+		return nil, err
+	}
+	var e *T
+	if r.cacheReads(ctx) {
+		e, err = r.cachedGet(ctx, key, func(ctx context.Context) (*T, error) { return r.getByKey(ctx, cols, key) })
+	} else {
+		e, err = r.getByKey(ctx, cols, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := r.applyPreloads(ctx, []*T{e}); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// getByKey loads the row with the given key values, without preloads
+func (r *repo[T]) getByKey(ctx context.Context, cols []string, key []any) (*T, error) {
 	var out []T
-	qb := r.kn.Query().Table(r.tableName()).Where("id = ?", id).Limit(1)
+	qb := r.scopedQuery().WhereCond(keyCondition(cols, key)).Limit(1)
 	// Apply soft-delete default filter if model has deleted_at
-	var t T
-	if core.ModelHasSoftDelete(reflect.TypeOf(t)) {
+	if core.ModelHasSoftDelete(reflect.TypeFor[T]()) {
 		switch r.mode {
 		case softModeOnlyTrashed:
 			qb = qb.Where("deleted_at IS NOT NULL")
@@ -236,31 +433,41 @@ func (r *repo[T]) Update(ctx context.Context, entity *T) error {
 			return err
 		}
 	}
+	if err := checkEnumFields(reflect.ValueOf(entity), false); err != nil {
+		return err
+	}
 	val := reflect.Indirect(reflect.ValueOf(entity))
 	typ := val.Type()
 	mapper := core.StructMapper(typ)
-	if mapper.PrimaryColumn == "" {
+	if len(mapper.PrimaryColumns) == 0 {
 		return &ORMError{Code: ErrCodeValidation, Message: "no primary key"}
 	}
+	isKey := make(map[string]bool, len(mapper.PrimaryColumns))
+	for _, c := range mapper.PrimaryColumns {
+		isKey[strings.ToLower(c)] = true
+	}
 
 	sets := []string{}
 	args := []any{}
 	idx := 1
-	var id any
+	var key []any
 	// discover columns that should be set to NOW() on update
 	onUpdateNow := r.onUpdateNowColumns(typ)
-	for i := 0; i < typ.NumField(); i++ {
-		f := typ.Field(i)
+	for _, f := range core.Fields(typ) {
 		if f.PkgPath != "" {
 			continue
 		}
-		col := f.Tag.Get("db")
-		if col == "" {
-			col = core.ToSnakeCase(f.Name)
+		if core.IsRelationField(f) {
+			continue
+		}
+		col := core.ColumnName(f)
+		v := val.FieldByIndex(f.Index).Interface()
+		if isKey[strings.ToLower(col)] {
+			key = append(key, v)
+			continue
 		}
-		v := val.Field(i).Interface()
-		if strings.EqualFold(col, mapper.PrimaryColumn) {
-			id = v
+		// write-once and generated columns keep their stored value
+		if fi := mapper.FieldsByColumn[strings.ToLower(col)]; fi.Immutable || fi.Generated {
 			continue
 		}
 		// optimistic locking: version column gets incremented
@@ -273,35 +480,47 @@ func (r *repo[T]) Update(ctx context.Context, entity *T) error {
 			sets = append(sets, fmt.Sprintf("%s = NOW()", quoteQualified(col)))
 			continue
 		}
+		if mapper.FieldsByColumn[strings.ToLower(col)].OmitEmpty && val.FieldByIndex(f.Index).IsZero() {
+			continue
+		}
 		sets = append(sets, fmt.Sprintf("%s = $%d", quoteQualified(col), idx))
 		args = append(args, v)
 		idx++
 	}
-	if id == nil {
+	if slices.ContainsFunc(key, isMissingKey) {
 		return &ORMError{Code: ErrCodeValidation, Message: "missing primary key value"}
 	}
+	// the audit entry names a single key by its value, a composite one by the values in column order
+	var id any = key
+	if len(key) == 1 {
+		id = key[0]
+	}
+	where := keyPredicate(quoteIdentifiers(mapper.PrimaryColumns), idx)
+	idx += len(key)
 	// add conditions for optimistic locking if versionColumn present
 	if mapper.VersionColumn != "" {
 		// read current version value from entity
-		curVersion := reflect.Indirect(reflect.ValueOf(entity)).FieldByNameFunc(func(n string) bool { return strings.EqualFold(core.ToSnakeCase(n), mapper.VersionColumn) }).Interface()
-		args = append(args, id, curVersion)
-		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d AND %s = $%d", r.tableName(), strings.Join(sets, ", "), quoteQualified(mapper.PrimaryColumn), idx, quoteQualified(mapper.VersionColumn), idx+1)
+		curVersion := reflect.Indirect(reflect.ValueOf(entity)).FieldByIndex(mapper.FieldsByColumn[strings.ToLower(mapper.VersionColumn)].Index).Interface()
+		args = append(append(args, key...), curVersion)
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s AND %s = $%d", r.tableName(), strings.Join(sets, ", "), where, quoteQualified(mapper.VersionColumn), idx)
 		tag, err := r.exec.Exec(ctx, query, args...)
 		if err != nil {
 			return wrapPgError(err, query, args)
 		}
-		if tag.RowsAffected() == 0 {
-			return &ORMError{Code: ErrCodeTransaction, Message: "optimistic lock conflict"}
+		if tag.RowsAffected() == 0 && r.dry == nil {
+			return optimisticLockError(query, args)
 		}
+		r.uncache(ctx, id)
 		r.audit(ctx, AuditActionUpdate, id, entity, query, nil)
 		return nil
 	}
-	args = append(args, id)
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d", r.tableName(), strings.Join(sets, ", "), quoteQualified(mapper.PrimaryColumn), idx)
+	args = append(args, key...)
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", r.tableName(), strings.Join(sets, ", "), where)
 	_, err := r.exec.Exec(ctx, query, args...)
 	if err != nil {
 		return wrapPgError(err, query, args)
 	}
+	r.uncache(ctx, id)
 	// model hook: AfterUpdate
 	if au, ok := any(entity).(AfterUpdate); ok {
 		if err := au.AfterUpdate(ctx); err != nil {
@@ -313,6 +532,14 @@ func (r *repo[T]) Update(ctx context.Context, entity *T) error {
 }
 
 func (r *repo[T]) UpdatePartial(ctx context.Context, id any, fields map[string]any) error {
+	if err := r.updatePartial(ctx, id, fields, false, nil); err != nil {
+		return err
+	}
+	r.uncache(ctx, id)
+	return nil
+}
+
+func (r *repo[T]) updatePartial(ctx context.Context, id any, fields map[string]any, versioned bool, version any) error {
 	// discover on_update:now() columns for T
 	var t T
 	typ := reflect.TypeOf(t)
@@ -320,7 +547,32 @@ func (r *repo[T]) UpdatePartial(ctx context.Context, id any, fields map[string]a
 		typ = typ.Elem()
 	}
 	onUpdateNow := r.onUpdateNowColumns(typ)
-	if len(fields) == 0 {
+	mapper := core.StructMapper(typ)
+	fields = resolveFields(mapper, fields)
+	if err := checkMutable(mapper, slices.Sorted(maps.Keys(fields))); err != nil {
+		return err
+	}
+	if err := checkEnumMap(typ, fields); err != nil {
+		return err
+	}
+	versionCol := ""
+	if versioned {
+		if mapper.VersionColumn == "" {
+			return &ORMError{Code: ErrCodeValidation, Message: "no version column"}
+		}
+		versionCol = mapper.VersionColumn
+		for col := range fields {
+			if strings.EqualFold(col, versionCol) {
+				return &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("version column %s is incremented automatically", versionCol)}
+			}
+		}
+	}
+	cols := keyColumns(mapper)
+	key, err := keyValues(cols, id)
+	if err != nil {
+		return err
+	}
+	if len(fields) == 0 && !versioned {
 		if len(onUpdateNow) == 0 {
 			return nil
 		}
@@ -328,13 +580,13 @@ func (r *repo[T]) UpdatePartial(ctx context.Context, id any, fields map[string]a
 		for col := range onUpdateNow {
 			sets = append(sets, fmt.Sprintf("%s = NOW()", quoteQualified(col)))
 		}
-		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $1", r.tableName(), strings.Join(sets, ", "), quoteQualified("id"))
-		_, err := r.exec.Exec(ctx, query, id)
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", r.tableName(), strings.Join(sets, ", "), keyPredicate(quoteIdentifiers(cols), 1))
+		_, err := r.exec.Exec(ctx, query, key...)
 		return err
 	}
 	idx := 1
-	sets := make([]string, 0, len(fields))
-	args := make([]any, 0, len(fields)+1)
+	sets := make([]string, 0, len(fields)+1)
+	args := make([]any, 0, len(fields)+2)
 	provided := map[string]struct{}{}
 	for col, v := range fields {
 		sets = append(sets, fmt.Sprintf("%s = $%d", quoteQualified(col), idx))
@@ -348,12 +600,38 @@ func (r *repo[T]) UpdatePartial(ctx context.Context, id any, fields map[string]a
 			sets = append(sets, fmt.Sprintf("%s = NOW()", quoteQualified(col)))
 		}
 	}
-	args = append(args, id)
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s = $%d", r.tableName(), strings.Join(sets, ", "), quoteQualified("id"), idx)
-	_, err := r.exec.Exec(ctx, query, args...)
+	args = append(args, key...)
+	where := keyPredicate(quoteIdentifiers(cols), idx)
+	idx += len(key)
+	if versioned {
+		quoted := quoteQualified(versionCol)
+		sets = append(sets, fmt.Sprintf("%s = %s + 1", quoted, quoted))
+		args = append(args, version)
+		query := fmt.Sprintf("UPDATE %s SET %s WHERE %s AND %s = $%d", r.tableName(), strings.Join(sets, ", "), where, quoted, idx)
+		tag, err := r.exec.Exec(ctx, query, args...)
+		if err != nil {
+			return wrapPgError(err, query, args)
+		}
+		if tag.RowsAffected() == 0 && r.dry == nil {
+			return optimisticLockError(query, args)
+		}
+		return nil
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s WHERE %s", r.tableName(), strings.Join(sets, ", "), where)
+	_, err = r.exec.Exec(ctx, query, args...)
 	return err
 }
 
+// UpdatePartialVersion is UpdatePartial under optimistic locking: the row is updated only while its version
+// column equals version, and the version is incremented. A mismatch (or a missing row) returns ErrOptimisticLock.
+func (r *repo[T]) UpdatePartialVersion(ctx context.Context, id any, version any, fields map[string]any) error {
+	if err := r.updatePartial(ctx, id, fields, true, version); err != nil {
+		return err
+	}
+	r.uncache(ctx, id)
+	return nil
+}
+
 func (r *repo[T]) Delete(ctx context.Context, id any) error {
 	// dispatch hooks on zero-value model if implemented
 	var t T
@@ -366,12 +644,18 @@ func (r *repo[T]) Delete(ctx context.Context, id any) error {
 			return err
 		}
 	}
-	query := fmt.Sprintf("DELETE FROM %s WHERE id = $1", r.tableName())
-	_, err := r.exec.Exec(ctx, query, id)
+	cols := keyColumns(core.StructMapper(reflect.TypeOf(t)))
+	key, err := keyValues(cols, id)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s", r.tableName(), keyPredicate(quoteColumns(cols), 1))
+	_, err = r.exec.Exec(ctx, query, key...)
 	if err != nil {
 		r.audit(ctx, AuditActionDelete, id, nil, query, err)
 		return err
 	}
+	r.uncache(ctx, id)
 	r.audit(ctx, AuditActionDelete, id, nil, query, nil)
 	if ad, ok := any(&t).(AfterDelete); ok {
 		if err := ad.AfterDelete(ctx, id); err != nil {
@@ -400,13 +684,19 @@ func (r *repo[T]) SoftDelete(ctx context.Context, id any) error {
 			return err
 		}
 	}
+	cols := keyColumns(core.StructMapper(reflect.TypeOf(t)))
+	key, err := keyValues(cols, id)
+	if err != nil {
+		return err
+	}
 	// expects a deleted_at column
-	query := fmt.Sprintf("UPDATE %s SET deleted_at = NOW() WHERE id = $1", r.tableName())
-	_, err := r.exec.Exec(ctx, query, id)
+	query := fmt.Sprintf("UPDATE %s SET deleted_at = NOW() WHERE %s", r.tableName(), keyPredicate(quoteColumns(cols), 1))
+	_, err = r.exec.Exec(ctx, query, key...)
 	if err != nil {
 		r.audit(ctx, AuditActionSoftDelete, id, nil, query, err)
 		return err
 	}
+	r.uncache(ctx, id)
 	r.audit(ctx, AuditActionSoftDelete, id, nil, query, nil)
 	if asd, ok := any(&t).(AfterSoftDelete); ok {
 		if err := asd.AfterSoftDelete(ctx, id); err != nil {
@@ -447,12 +737,18 @@ func (r *repo[T]) Restore(ctx context.Context, id any) error {
 			return err
 		}
 	}
-	query := fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE id = $1", r.tableName())
-	_, err := r.exec.Exec(ctx, query, id)
+	cols := keyColumns(core.StructMapper(reflect.TypeOf(t)))
+	key, err := keyValues(cols, id)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("UPDATE %s SET deleted_at = NULL WHERE %s", r.tableName(), keyPredicate(quoteColumns(cols), 1))
+	_, err = r.exec.Exec(ctx, query, key...)
 	if err != nil {
 		r.audit(ctx, AuditActionRestore, id, nil, query, err)
-		return wrapPgError(err, query, []any{id})
+		return wrapPgError(err, query, key)
 	}
+	r.uncache(ctx, id)
 	r.audit(ctx, AuditActionRestore, id, nil, query, nil)
 	if ar, ok := any(&t).(AfterRestore); ok {
 		if err := ar.AfterRestore(ctx, id); err != nil {
@@ -501,49 +797,26 @@ func (r *repo[T]) PurgeTrashed(ctx context.Context) (int64, error) {
 }
 
 func (r *repo[T]) Find(ctx context.Context, conditions ...Condition) ([]*T, error) {
-	qb := r.kn.Query().Table(r.tableName())
-	for _, c := range conditions {
-		qb = qb.Where(c.Expr, c.Args...)
-	}
-	var t T
-	if core.ModelHasSoftDelete(reflect.TypeOf(t)) {
-		switch r.mode {
-		case softModeOnlyTrashed:
-			qb = qb.Where("deleted_at IS NOT NULL")
-		case softModeWithTrashed:
-			// no filter
-		default:
-			qb = qb.Where("deleted_at IS NULL")
-		}
-	}
-	var out []*T
-	// scan to non-pointer, then take address
-	var tmp []T
-	if err := qb.Find(ctx, &tmp); err != nil {
+	out, err := r.findRows(ctx, r.conditionQuery(conditions), 0)
+	if err != nil {
 		return nil, err
 	}
-	for i := range tmp {
-		out = append(out, &tmp[i])
+	if err := r.applyPreloads(ctx, out); err != nil {
+		return nil, err
 	}
 	return out, nil
 }
 
+// FindEach streams matching rows to fn one at a time instead of materializing a slice
+func (r *repo[T]) FindEach(ctx context.Context, fn func(row *T) error, conditions ...Condition) error {
+	return FindEach(ctx, r.conditionQuery(conditions), fn)
+}
+
 func (r *repo[T]) FindOne(ctx context.Context, conditions ...Condition) (*T, error) {
-	qb := r.kn.Query().Table(r.tableName()).Limit(1)
-	for _, c := range conditions {
-		qb = qb.Where(c.Expr, c.Args...)
-	}
-	var t T
-	if core.ModelHasSoftDelete(reflect.TypeOf(t)) {
-		switch r.mode {
-		case softModeOnlyTrashed:
-			qb = qb.Where("deleted_at IS NOT NULL")
-		case softModeWithTrashed:
-			// no filter
-		default:
-			qb = qb.Where("deleted_at IS NULL")
-		}
-	}
+	return r.findOne(ctx, r.conditionQuery(conditions).Limit(1))
+}
+
+func (r *repo[T]) findOne(ctx context.Context, qb *QueryBuilder) (*T, error) {
 	var out []T
 	if err := qb.Find(ctx, &out); err != nil {
 		return nil, err
@@ -551,11 +824,17 @@ func (r *repo[T]) FindOne(ctx context.Context, conditions ...Condition) (*T, err
 	if len(out) == 0 {
 		return nil, &ORMError{Code: ErrCodeNotFound, Message: "not found"}
 	}
+	if r.cacheReads(ctx) {
+		r.storeEntity(ctx, &out[0])
+	}
+	if err := r.applyPreloads(ctx, []*T{&out[0]}); err != nil {
+		return nil, err
+	}
 	return &out[0], nil
 }
 
 func (r *repo[T]) Count(ctx context.Context, conditions ...Condition) (int64, error) {
-	qb := r.kn.Query().Table(r.tableName()).Select("COUNT(*)")
+	qb := r.scopedQuery().Select("COUNT(*)")
 	for _, c := range conditions {
 		qb = qb.Where(c.Expr, c.Args...)
 	}
@@ -589,16 +868,16 @@ func (r *repo[T]) Count(ctx context.Context, conditions ...Condition) (int64, er
 	}
 }
 
+// Exists reports whether any row matches, using SELECT EXISTS(SELECT 1 ... LIMIT 1) instead of counting
 func (r *repo[T]) Exists(ctx context.Context, conditions ...Condition) (bool, error) {
-	c, err := r.Count(ctx, conditions...)
-	return c > 0, err
+	return r.matching(conditions).Exists(ctx)
 }
 
 // PageRequest describes pagination and ordering
 type PageRequest struct {
 	Limit   int
 	Offset  int
-	OrderBy string // e.g., "id ASC" or "created_at DESC"
+	OrderBy string // e.g., "id ASC" or "created_at DESC"; empty uses the repository's DefaultOrder, then the primary key
 }
 
 // Page represents a paginated result
@@ -615,7 +894,7 @@ func (r *repo[T]) FindPage(ctx context.Context, page PageRequest, conditions ...
 	if err != nil {
 		return Page[T]{}, err
 	}
-	qb := r.kn.Query().Table(r.tableName())
+	qb := r.scopedQuery()
 	for _, c := range conditions {
 		qb = qb.Where(c.Expr, c.Args...)
 	}
@@ -629,8 +908,8 @@ func (r *repo[T]) FindPage(ctx context.Context, page PageRequest, conditions ...
 			qb = qb.Where("deleted_at IS NULL")
 		}
 	}
-	if page.OrderBy != "" {
-		qb = qb.OrderBy(page.OrderBy)
+	if order := r.pageOrder(page); order != "" {
+		qb = qb.OrderBy(order)
 	}
 	if page.Limit > 0 {
 		qb = qb.Limit(page.Limit)
@@ -638,42 +917,137 @@ func (r *repo[T]) FindPage(ctx context.Context, page PageRequest, conditions ...
 	if page.Offset > 0 {
 		qb = qb.Offset(page.Offset)
 	}
-	var tmp []T
-	if err := qb.Find(ctx, &tmp); err != nil {
+	// the total bounds the page, so the scan slice is allocated once
+	expected := max(total-int64(page.Offset), 0)
+	if page.Limit > 0 {
+		expected = min(expected, int64(page.Limit))
+	}
+	items, err := r.findRows(ctx, qb, int(min(expected, maxPooledFindRows)))
+	if err != nil {
 		return Page[T]{}, err
 	}
-	items := make([]*T, 0, len(tmp))
-	for i := range tmp {
-		items = append(items, &tmp[i])
+	if err := r.applyPreloads(ctx, items); err != nil {
+		return Page[T]{}, err
 	}
 	return Page[T]{Items: items, Total: total, Limit: page.Limit, Offset: page.Offset}, nil
 }
 
+// pageOrder is the ORDER BY of a page: the request's, else DefaultOrder, else the primary key. Without one
+// Postgres returns rows in no particular order, and consecutive pages may repeat or skip rows.
+func (r *repo[T]) pageOrder(page PageRequest) string {
+	if page.OrderBy != "" {
+		return page.OrderBy
+	}
+	if r.order != "" {
+		return r.order
+	}
+	if pk := core.StructMapper(reflect.TypeFor[T]()).PrimaryColumn; pk != "" {
+		return quoteQualified(pk) + " ASC"
+	}
+	return ""
+}
+
 // CreateCopyFrom performs bulk insert using pgx CopyFrom for high-throughput writes.
-// columns must be provided in db column names order.
+// With WithAdaptiveBatchSize the rows are streamed as several COPY chunks in one transaction.
+// The COPY runs on the repository's executor; one that cannot run it fails with ErrCodeValidation.
 func (r *repo[T]) CreateCopyFrom(ctx context.Context, entities []*T, columns ...string) (int64, error) {
+	columns = resolveColumns(core.StructMapper(reflect.TypeFor[T]()), columns)
 	rows := make([][]any, 0, len(entities))
 	for _, e := range entities {
+		if err := checkEnumFields(reflect.ValueOf(e), true); err != nil {
+			return 0, err
+		}
+		if err := generateUUIDs(e); err != nil {
+			return 0, err
+		}
+		if err := r.generateID(ctx, e); err != nil {
+			return 0, err
+		}
 		vals, err := r.extractValuesByColumns(e, columns)
 		if err != nil {
 			return 0, err
 		}
 		rows = append(rows, vals)
 	}
+	if r.dry != nil {
+		return r.dryRunCopy(ctx, columns, rows), nil
+	}
+	// COPY runs where the repository's statements run: a transaction (from the context too) or dedicated
+	// connection is used as is, a tenant executor opens a transaction with its search_path applied
+	var pool *pgxpool.Pool
+	switch e := baseExec(ctx, r.exec).(type) {
+	case tenantExecuter:
+		tx, err := e.Begin(ctx)
+		if err != nil {
+			return 0, err
+		}
+		return r.copyInTx(ctx, tx, columns, rows)
+	case routingExecuter:
+		pool = e.kn.pool
+	case gatedPool:
+		pool = e.pool
+	case *pgxpool.Pool:
+		pool = e
+	case copier:
+		return r.copyRows(ctx, e, columns, rows)
+	default:
+		return 0, &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("CreateCopyFrom: executor %T cannot run COPY", e)}
+	}
 	// Acquire a connection from the pool directly for CopyFrom
-	conn, err := r.kn.pool.Acquire(ctx)
+	conn, err := r.kn.acquire(ctx, pool)
 	if err != nil {
 		return 0, err
 	}
 	defer conn.Release()
-	src := pgxv5.CopyFromRows(rows)
-	n, err := conn.CopyFrom(ctx, pgxv5.Identifier{r.tableName()}, columns, src)
+	if r.kn.batchTuner == nil || len(rows) <= r.batchSize(0) {
+		return r.copyRows(ctx, conn, columns, rows)
+	}
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return 0, &ORMError{Code: ErrCodeTransaction, Message: err.Error(), Internal: err}
+	}
+	return r.copyInTx(ctx, tx, columns, rows)
+}
+
+// copyInTx copies rows in tx and commits it, rolling back on failure
+func (r *repo[T]) copyInTx(ctx context.Context, tx pgxv5.Tx, columns []string, rows [][]any) (int64, error) {
+	defer tx.Rollback(ctx) //nolint:errcheck
+	n, err := r.copyRows(ctx, tx, columns, rows)
 	if err != nil {
-		return 0, wrapPgError(err, fmt.Sprintf("COPY %s (...)", r.tableName()), nil)
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, &ORMError{Code: ErrCodeTransaction, Message: err.Error(), Internal: err}
 	}
 	return n, nil
 }
 
+// copier is implemented by pgx.Tx and pooled connections
+type copier interface {
+	CopyFrom(ctx context.Context, tableName pgxv5.Identifier, columnNames []string, rowSrc pgxv5.CopyFromSource) (int64, error)
+}
+
+// copyRows sends rows with one COPY, or with tuned chunks when adaptive batch sizing is enabled
+func (r *repo[T]) copyRows(ctx context.Context, c copier, columns []string, rows [][]any) (int64, error) {
+	var total int64
+	for start, end := 0, 0; ; start = end {
+		end = len(rows)
+		if r.kn != nil && r.kn.batchTuner != nil {
+			end = min(start+r.batchSize(0), len(rows))
+		}
+		started := time.Now()
+		n, err := c.CopyFrom(ctx, pgxv5.Identifier(strings.Split(r.tableName(), ".")), columns, pgxv5.CopyFromRows(rows[start:end]))
+		if err != nil {
+			return total, wrapPgError(err, fmt.Sprintf("COPY %s (...)", r.tableName()), nil)
+		}
+		r.observeBatch(end-start, started)
+		total += n
+		if end == len(rows) {
+			return total, nil
+		}
+	}
+}
+
 func (r *repo[T]) extractValuesByColumns(entity *T, columns []string) ([]any, error) {
 	val := reflect.Indirect(reflect.ValueOf(entity))
 	typ := val.Type()
@@ -689,6 +1063,60 @@ func (r *repo[T]) extractValuesByColumns(entity *T, columns []string) ([]any, er
 	return out, nil
 }
 
+// writableField describes a struct field written by INSERT statements
+type writableField struct {
+	col        string
+	index      []int
+	hasDefault bool // norm:"default:..." - zero values fall back to the column default
+	omitEmpty  bool // db:"col,omitempty" - zero values are sent as DEFAULT
+}
+
+// writableFields returns the insertable fields of typ, skipping unexported, ignored and auto-increment PK fields
+func writableFields(typ reflect.Type) []writableField {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	mapper := core.StructMapper(typ)
+	out := make([]writableField, 0, typ.NumField())
+	for _, f := range core.Fields(typ) {
+		if f.PkgPath != "" {
+			continue
+		}
+		col := core.ColumnName(f)
+		if mapper.AutoIncrement && strings.EqualFold(col, mapper.PrimaryColumn) {
+			continue
+		}
+		// Prefer `norm` tag; fallback to legacy `orm`
+		orm := f.Tag.Get("norm")
+		if orm == "" {
+			orm = f.Tag.Get("orm")
+		}
+		low := strings.ToLower(orm)
+		if strings.Contains(low, "-") || strings.Contains(low, "ignore") || core.IsRelationTag(orm) {
+			continue
+		}
+		if mapper.FieldsByColumn[strings.ToLower(col)].Generated {
+			continue
+		}
+		out = append(out, writableField{col: col, index: f.Index, hasDefault: strings.Contains(orm, "default:"), omitEmpty: core.OmitEmpty(f)})
+	}
+	return out
+}
+
+// checkMutable rejects explicit updates of norm:"immutable" and generated columns
+func checkMutable(mapper core.StructMapping, cols []string) error {
+	for _, c := range cols {
+		fi := mapper.FieldsByColumn[strings.ToLower(c)]
+		if fi.Immutable {
+			return &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("column %s is immutable", c)}
+		}
+		if fi.Generated {
+			return &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("column %s is generated", c)}
+		}
+	}
+	return nil
+}
+
 // Upsert performs INSERT ... ON CONFLICT (...) DO UPDATE SET col = EXCLUDED.col for given columns
 func (r *repo[T]) Upsert(ctx context.Context, entity *T, conflictCols []string, updateCols []string) error {
 	// model hook: BeforeUpsert
@@ -697,31 +1125,40 @@ func (r *repo[T]) Upsert(ctx context.Context, entity *T, conflictCols []string,
 			return err
 		}
 	}
+	if err := checkEnumFields(reflect.ValueOf(entity), false); err != nil {
+		return err
+	}
 	// Build from reflection
 	val := reflect.Indirect(reflect.ValueOf(entity))
 	typ := val.Type()
 	mapper := core.StructMapper(typ)
+	conflictCols, updateCols = resolveColumns(mapper, conflictCols), resolveColumns(mapper, updateCols)
 	cols := []string{}
 	placeholders := []string{}
 	args := []any{}
 	idx := 1
-	for i := 0; i < typ.NumField(); i++ {
-		f := typ.Field(i)
+	for _, f := range core.Fields(typ) {
 		if f.PkgPath != "" {
 			continue
 		}
-		col := f.Tag.Get("db")
-		if col == "" {
-			col = core.ToSnakeCase(f.Name)
+		if core.IsRelationField(f) {
+			continue
 		}
+		col := core.ColumnName(f)
 		if mapper.AutoIncrement && strings.EqualFold(col, mapper.PrimaryColumn) {
 			continue
 		}
+		if fi := mapper.FieldsByColumn[strings.ToLower(col)]; fi.Generated || (fi.OmitEmpty && val.FieldByIndex(f.Index).IsZero()) {
+			continue
+		}
 		cols = append(cols, quoteQualified(col))
 		placeholders = append(placeholders, fmt.Sprintf("$%d", idx))
-		args = append(args, val.Field(i).Interface())
+		args = append(args, val.FieldByIndex(f.Index).Interface())
 		idx++
 	}
+	if err := checkMutable(mapper, updateCols); err != nil {
+		return err
+	}
 	setParts := make([]string, 0, len(updateCols))
 	for _, c := range updateCols {
 		quoted := quoteQualified(c)
@@ -748,7 +1185,7 @@ func (r *repo[T]) onUpdateNowColumns(typ reflect.Type) map[string]bool {
 		typ = typ.Elem()
 	}
 	out := make(map[string]bool)
-	for f := range typ.Fields() {
+	for _, f := range core.Fields(typ) {
 		if f.PkgPath != "" {
 			continue
 		}
@@ -758,7 +1195,7 @@ func (r *repo[T]) onUpdateNowColumns(typ reflect.Type) map[string]bool {
 			orm = f.Tag.Get("orm")
 		}
 		low := strings.ToLower(orm)
-		if strings.Contains(low, "-") || strings.Contains(low, "ignore") {
+		if strings.Contains(low, "-") || strings.Contains(low, "ignore") || core.IsRelationTag(orm) {
 			continue
 		}
 		if orm == "" {
@@ -768,10 +1205,7 @@ func (r *repo[T]) onUpdateNowColumns(typ reflect.Type) map[string]bool {
 		for p := range parts {
 			p = strings.TrimSpace(p)
 			if strings.EqualFold(p, "on_update:now()") {
-				col := f.Tag.Get("db")
-				if col == "" {
-					col = core.ToSnakeCase(f.Name)
-				}
+				col := core.ColumnName(f)
 				out[col] = true
 			}
 		}
@@ -0,0 +1,300 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// maxBindParams is PostgreSQL's limit on bind parameters per statement
+const maxBindParams = 65535
+
+// defaultBatchSize is the number of rows per multi-row INSERT when WithBatchSize is not set
+const defaultBatchSize = 1000
+
+// batchSize returns the rows per chunk for a statement binding perRow params, kept under maxBindParams
+func (r *repo[T]) batchSize(perRow int) int {
+	size := defaultBatchSize
+	if r.kn != nil && r.kn.batchTuner != nil {
+		size = r.kn.batchTuner.size(r.tableName())
+	} else if r.kn != nil && r.kn.batchSize > 0 {
+		size = r.kn.batchSize
+	}
+	if perRow > 0 {
+		size = min(size, maxBindParams/perRow)
+	}
+	return max(size, 1)
+}
+
+// observeBatch feeds a completed chunk to the adaptive tuner, if enabled
+func (r *repo[T]) observeBatch(rows int, started time.Time) {
+	if r.kn != nil && r.kn.batchTuner != nil {
+		r.kn.batchTuner.observe(r.tableName(), rows, time.Since(started))
+	}
+}
+
+// inChunks calls fn for consecutive [start, end) ranges of n items sized by batchSize(perRow), re-read before
+// every chunk so adaptive sizing applies mid-batch. A single chunk runs on the repository executor (with
+// retries); several chunks share one transaction so the batch stays atomic.
+func (r *repo[T]) inChunks(ctx context.Context, n, perRow int, fn func(exec dbExecuter, start, end int) error) error {
+	if size := r.batchSize(perRow); n <= size {
+		execFn := func() error {
+			started := time.Now()
+			if err := fn(r.exec, 0, n); err != nil {
+				return err
+			}
+			r.observeBatch(n, started)
+			return nil
+		}
+		if r.kn != nil {
+			return r.kn.withRetry(ctx, execFn)
+		}
+		return execFn()
+	}
+	tx, err := r.query().beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+	txExec := wrapExec(r.kn, tx)
+	for start := 0; start < n; {
+		end := min(start+r.batchSize(perRow), n)
+		started := time.Now()
+		if err := fn(txExec, start, end); err != nil {
+			return err
+		}
+		r.observeBatch(end-start, started)
+		start = end
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return &ORMError{Code: ErrCodeTransaction, Message: err.Error(), Internal: err}
+	}
+	return nil
+}
+
+// insertValues renders the VALUES tuples of entities; zero-valued default: and omitempty fields are sent as DEFAULT
+func insertValues[T any](entities []*T, fields []writableField) (string, []any) {
+	var sb strings.Builder
+	args := make([]any, 0, len(entities)*len(fields))
+	for ei, e := range entities {
+		if ei > 0 {
+			sb.WriteString(", ")
+		}
+		val := reflect.ValueOf(e).Elem()
+		sb.WriteByte('(')
+		for fi, f := range fields {
+			if fi > 0 {
+				sb.WriteString(", ")
+			}
+			fv := val.FieldByIndex(f.index)
+			if (f.hasDefault || f.omitEmpty) && fv.IsZero() {
+				sb.WriteString("DEFAULT")
+				continue
+			}
+			args = append(args, fv.Interface())
+			fmt.Fprintf(&sb, "$%d", len(args))
+		}
+		sb.WriteByte(')')
+	}
+	return sb.String(), args
+}
+
+// insertChunk inserts entities with one multi-row INSERT. The auto-increment PK and default: columns are returned
+// and written back in VALUES order.
+func (r *repo[T]) insertChunk(ctx context.Context, exec dbExecuter, entities []*T, fields []writableField) error {
+	var t T
+	typ := reflect.TypeOf(t)
+	mapper := core.StructMapper(typ)
+	cols := make([]string, len(fields))
+	returning := make([]string, 0, 2)
+	if mapper.AutoIncrement && mapper.PrimaryColumn != "" {
+		returning = append(returning, quoteQualified(mapper.PrimaryColumn))
+	}
+	for i, f := range fields {
+		cols[i] = quoteQualified(f.col)
+		if f.hasDefault {
+			returning = append(returning, cols[i])
+		}
+	}
+	// generated columns are computed by the database and read back, as Create does
+	for _, f := range core.Fields(typ) {
+		if col := core.ColumnName(f); f.PkgPath == "" && mapper.FieldsByColumn[strings.ToLower(col)].Generated {
+			returning = append(returning, quoteQualified(col))
+		}
+	}
+	values, args := insertValues(entities, fields)
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", r.tableName(), strings.Join(cols, ", "), values)
+	if len(returning) == 0 {
+		if _, err := exec.Exec(ctx, query, args...); err != nil {
+			return wrapPgError(err, query, args)
+		}
+		return nil
+	}
+	query += " RETURNING " + strings.Join(returning, ", ")
+	rows, err := exec.Query(ctx, query, args...)
+	if err != nil {
+		return wrapPgError(err, query, args)
+	}
+	defer rows.Close()
+	// rows come back in VALUES order
+	return writeReturning(rows, mapper, func(_ reflect.Value, i int) []*T {
+		if i < len(entities) {
+			return entities[i : i+1]
+		}
+		return nil
+	}, query, args)
+}
+
+// writeReturning scans RETURNING rows back into the entities chosen by match, which receives each row decoded into
+// a scratch value (so keys compare with the entities' Go types) and its position.
+func writeReturning[T any](rows pgx.Rows, mapper core.StructMapping, match func(row reflect.Value, i int) []*T, query string, args []any) error {
+	for i := 0; rows.Next(); i++ {
+		vals, err := rows.Values()
+		if err != nil {
+			return wrapPgError(err, query, args)
+		}
+		scratch := reflect.New(reflect.TypeFor[T]())
+		fds := rows.FieldDescriptions()
+		for j, v := range vals {
+			if fi, ok := mapper.FieldsByColumn[strings.ToLower(string(fds[j].Name))]; ok {
+				core.SetField(scratch, fi, v)
+			}
+		}
+		for _, e := range match(scratch.Elem(), i) {
+			dst := reflect.ValueOf(e).Elem()
+			for _, fd := range fds {
+				if fi, ok := mapper.FieldsByColumn[strings.ToLower(string(fd.Name))]; ok {
+					dst.FieldByIndex(fi.Index).Set(scratch.Elem().FieldByIndex(fi.Index))
+				}
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return wrapPgError(err, query, args)
+	}
+	return nil
+}
+
+// batchKey joins the values of the key fields of val into a comparable string
+func batchKey(val reflect.Value, keyFields [][]int) string {
+	parts := make([]string, len(keyFields))
+	for i, idx := range keyFields {
+		parts[i] = fmt.Sprint(val.FieldByIndex(idx).Interface())
+	}
+	return strings.Join(parts, "\x00")
+}
+
+// UpsertBatch upserts entities with multi-row INSERT ... ON CONFLICT (conflictCols) DO UPDATE SET updateCols
+// (DO NOTHING when updateCols is empty), chunked like CreateBatch. Entities repeating a conflict key are collapsed
+// to the last one, since a single statement cannot update the same row twice. The PK and default: columns of
+// every inserted or updated row are written back to the entities with that conflict key.
+func (r *repo[T]) UpsertBatch(ctx context.Context, entities []*T, conflictCols []string, updateCols []string) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	if len(conflictCols) == 0 {
+		return &ORMError{Code: ErrCodeValidation, Message: "UpsertBatch requires conflict columns"}
+	}
+	var t T
+	typ := reflect.TypeOf(t)
+	mapper := core.StructMapper(typ)
+	conflictCols, updateCols = resolveColumns(mapper, conflictCols), resolveColumns(mapper, updateCols)
+	if err := checkMutable(mapper, updateCols); err != nil {
+		return err
+	}
+	keyFields := make([][]int, len(conflictCols))
+	for i, c := range conflictCols {
+		fi, ok := mapper.FieldsByColumn[strings.ToLower(c)]
+		if !ok {
+			return &ORMError{Code: ErrCodeInvalidColumn, Message: fmt.Sprintf("unknown column: %s", c)}
+		}
+		keyFields[i] = fi.Index
+	}
+	for _, e := range entities {
+		if e == nil {
+			return &ORMError{Code: ErrCodeValidation, Message: "nil entity"}
+		}
+		// model hook: BeforeUpsert
+		if bu, ok := any(e).(BeforeUpsert); ok {
+			if err := bu.BeforeUpsert(ctx); err != nil {
+				return err
+			}
+		}
+		if err := checkEnumFields(reflect.ValueOf(e), true); err != nil {
+			return err
+		}
+	}
+	// collapse duplicate conflict keys, keeping the last entity in its first position
+	pos := make(map[string]int, len(entities))
+	byKey := make(map[string][]*T, len(entities))
+	rows := make([]*T, 0, len(entities))
+	for _, e := range entities {
+		k := batchKey(reflect.ValueOf(e).Elem(), keyFields)
+		byKey[k] = append(byKey[k], e)
+		if i, ok := pos[k]; ok {
+			rows[i] = e
+			continue
+		}
+		pos[k] = len(rows)
+		rows = append(rows, e)
+	}
+	fields := writableFields(typ)
+	cols := make([]string, len(fields))
+	// RETURNING carries the conflict key (to correlate rows), the PK and default: columns
+	returning := quoteIdentifiers(conflictCols)
+	seen := make(map[string]bool, len(conflictCols))
+	for _, c := range conflictCols {
+		seen[strings.ToLower(c)] = true
+	}
+	if pk := mapper.PrimaryColumn; pk != "" && !seen[strings.ToLower(pk)] {
+		seen[strings.ToLower(pk)] = true
+		returning = append(returning, quoteQualified(pk))
+	}
+	for i, f := range fields {
+		cols[i] = quoteQualified(f.col)
+		if f.hasDefault && !seen[strings.ToLower(f.col)] {
+			seen[strings.ToLower(f.col)] = true
+			returning = append(returning, cols[i])
+		}
+	}
+	action := "DO NOTHING"
+	if len(updateCols) > 0 {
+		setParts := make([]string, len(updateCols))
+		for i, c := range updateCols {
+			quoted := quoteQualified(c)
+			setParts[i] = fmt.Sprintf("%s = EXCLUDED.%s", quoted, quoted)
+		}
+		action = "DO UPDATE SET " + strings.Join(setParts, ", ")
+	}
+	err := r.inChunks(ctx, len(rows), len(fields), func(exec dbExecuter, start, end int) error {
+		values, args := insertValues(rows[start:end], fields)
+		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT (%s) %s RETURNING %s", r.tableName(), strings.Join(cols, ", "), values, strings.Join(quoteIdentifiers(conflictCols), ", "), action, strings.Join(returning, ", "))
+		res, err := exec.Query(ctx, query, args...)
+		if err != nil {
+			return wrapPgError(err, query, args)
+		}
+		defer res.Close()
+		// rows skipped by DO NOTHING are absent, so match the rest by conflict key
+		return writeReturning(res, mapper, func(row reflect.Value, _ int) []*T {
+			return byKey[batchKey(row, keyFields)]
+		}, query, args)
+	})
+	if err != nil {
+		return err
+	}
+	for _, e := range entities {
+		// model hook: AfterUpsert
+		if au, ok := any(e).(AfterUpsert); ok {
+			if err := au.AfterUpsert(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	r.audit(ctx, AuditActionUpsert, nil, entities, "", nil)
+	return nil
+}
@@ -0,0 +1,110 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestRepo_CreateBatch_SingleMultiRowInsert(t *testing.T) {
+	ex := &seqExec{fields: []string{"id"}, results: [][][]any{{{int64(10)}, {int64(11)}}}}
+	r := &repo[repUser]{kn: &KintsNorm{}, exec: ex}
+	users := []*repUser{{Name: "a"}, {Name: "b"}}
+	if err := r.CreateBatch(context.Background(), users); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `INSERT INTO rep_users ("name", "version") VALUES ($1, $2), ($3, $4) RETURNING "id"`
+	if len(ex.sqls) != 1 || ex.sqls[0] != want {
+		t.Fatalf("sqls=%v", ex.sqls)
+	}
+	if users[0].ID != 10 || users[1].ID != 11 {
+		t.Fatalf("ids=%d,%d", users[0].ID, users[1].ID)
+	}
+}
+
+// batchDB hands out a transaction that serves sequential RETURNING results
+type batchDB struct {
+	seqExec
+	commits int
+}
+
+func (d *batchDB) Begin(context.Context) (pgx.Tx, error) { return &batchTx{db: d}, nil }
+
+type batchTx struct {
+	pgx.Tx
+	db *batchDB
+}
+
+func (t *batchTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	return t.db.seqExec.Exec(ctx, sql, args...)
+}
+func (t *batchTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return t.db.seqExec.Query(ctx, sql, args...)
+}
+func (t *batchTx) Commit(context.Context) error   { t.db.commits++; return nil }
+func (t *batchTx) Rollback(context.Context) error { return nil }
+
+func TestRepo_CreateBatch_ChunksInTransaction(t *testing.T) {
+	db := &batchDB{seqExec: seqExec{fields: []string{"id"}, results: [][][]any{{{int64(1)}, {int64(2)}}, {{int64(3)}}}}}
+	r := &repo[repUser]{kn: &KintsNorm{batchSize: 2}, exec: db}
+	users := []*repUser{{Name: "a"}, {Name: "b"}, {Name: "c"}}
+	if err := r.CreateBatch(context.Background(), users); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if len(db.sqls) != 2 || !strings.Contains(db.sqls[1], `VALUES ($1, $2) RETURNING`) || db.commits != 1 {
+		t.Fatalf("sqls=%v commits=%d", db.sqls, db.commits)
+	}
+	if users[2].ID != 3 {
+		t.Fatalf("third id=%d", users[2].ID)
+	}
+}
+
+func TestRepo_BatchSize_StaysUnderParamLimit(t *testing.T) {
+	r := &repo[repUser]{kn: &KintsNorm{batchSize: 100000}}
+	if got := r.batchSize(10); got != maxBindParams/10 {
+		t.Fatalf("got %d", got)
+	}
+	if got := (&repo[repUser]{}).batchSize(2); got != defaultBatchSize {
+		t.Fatalf("default got %d", got)
+	}
+}
+
+func TestRepo_UpsertBatch_CollapsesDuplicateKeys(t *testing.T) {
+	ex := &recExecRepo{}
+	r := &repo[ensureTag]{kn: &KintsNorm{}, exec: ex}
+	tags := []*ensureTag{{Name: "go"}, {Name: "rust"}, {Name: "go"}}
+	if err := r.UpsertBatch(context.Background(), tags, []string{"name"}, nil); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `INSERT INTO ensure_tags ("name") VALUES ($1), ($2) ON CONFLICT ("name") DO NOTHING RETURNING "name", "id"`
+	if ex.lastSQL != want || len(ex.lastArgs) != 2 {
+		t.Fatalf("sql=%s args=%v", ex.lastSQL, ex.lastArgs)
+	}
+	users := []*repUser{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	if err := (&repo[repUser]{kn: &KintsNorm{}, exec: ex}).UpsertBatch(context.Background(), users, []string{"id"}, []string{"name"}); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want = `INSERT INTO rep_users ("name", "version") VALUES ($1, $2), ($3, $4) ON CONFLICT ("id") DO UPDATE SET "name" = EXCLUDED."name" RETURNING "id"`
+	if ex.lastSQL != want {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+	if err := r.UpsertBatch(context.Background(), tags, []string{"nope"}, nil); err == nil {
+		t.Fatalf("expected unknown column error")
+	}
+}
+
+func TestRepo_UpsertBatch_WritesBackByConflictKey(t *testing.T) {
+	// rows come back out of order and "rust" was skipped by DO NOTHING
+	ex := &fakeExecRU{rows: [][]any{{"sql", int64(12)}, {"go", int64(10)}}, fields: []string{"name", "id"}}
+	r := &repo[ensureTag]{kn: &KintsNorm{}, exec: ex}
+	tags := []*ensureTag{{Name: "go"}, {Name: "rust"}, {Name: "sql"}, {Name: "go"}}
+	if err := r.UpsertBatch(context.Background(), tags, []string{"name"}, nil); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if tags[0].ID != 10 || tags[1].ID != 0 || tags[2].ID != 12 || tags[3].ID != 10 {
+		t.Fatalf("ids=%d,%d,%d,%d", tags[0].ID, tags[1].ID, tags[2].ID, tags[3].ID)
+	}
+}
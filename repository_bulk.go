@@ -0,0 +1,109 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// matching returns a builder on the repository table filtered by scopes, conditions and the soft-delete mode
+func (r *repo[T]) matching(conditions []Condition) *QueryBuilder {
+	qb := r.scopedQuery()
+	for _, c := range conditions {
+		qb = qb.Where(c.Expr, c.Args...)
+	}
+	var t T
+	if core.ModelHasSoftDelete(reflect.TypeOf(t)) {
+		switch r.mode {
+		case softModeOnlyTrashed:
+			qb = qb.Where("deleted_at IS NOT NULL")
+		case softModeWithTrashed:
+			// no filter
+		default:
+			qb = qb.Where("deleted_at IS NULL")
+		}
+	}
+	return qb
+}
+
+func requireConditions(op string, conditions []Condition) error {
+	if len(conditions) == 0 {
+		return &ORMError{Code: ErrCodeValidation, Message: op + " requires at least one condition"}
+	}
+	return nil
+}
+
+// DeleteWhere permanently deletes the rows matching conditions (honoring scopes and the soft-delete mode,
+// so trashed rows are kept unless WithTrashed/OnlyTrashed) and returns how many were removed
+func (r *repo[T]) DeleteWhere(ctx context.Context, conditions ...Condition) (int64, error) {
+	if err := requireConditions("DeleteWhere", conditions); err != nil {
+		return 0, err
+	}
+	n, err := r.matching(conditions).HardDelete().Delete(ctx)
+	r.audit(ctx, AuditActionDelete, nil, nil, "", err)
+	return n, err
+}
+
+// SoftDeleteWhere sets deleted_at on the live rows matching conditions and returns how many were marked
+func (r *repo[T]) SoftDeleteWhere(ctx context.Context, conditions ...Condition) (int64, error) {
+	var t T
+	if !core.ModelHasSoftDelete(reflect.TypeOf(t)) {
+		return 0, &ORMError{Code: ErrCodeValidation, Message: "soft delete not supported: missing deleted_at column"}
+	}
+	if err := requireConditions("SoftDeleteWhere", conditions); err != nil {
+		return 0, err
+	}
+	// the soft-delete UPDATE adds deleted_at IS NULL itself
+	all := *r
+	all.mode = softModeWithTrashed
+	n, err := all.matching(conditions).Delete(ctx)
+	r.audit(ctx, AuditActionSoftDelete, nil, nil, "", err)
+	return n, err
+}
+
+// UpdateWhere sets fields on the rows matching conditions (plus on_update:now() columns not provided)
+// and returns how many were changed
+func (r *repo[T]) UpdateWhere(ctx context.Context, fields map[string]any, conditions ...Condition) (int64, error) {
+	if err := requireConditions("UpdateWhere", conditions); err != nil {
+		return 0, err
+	}
+	var t T
+	if err := checkEnumMap(reflect.TypeOf(t), fields); err != nil {
+		return 0, err
+	}
+	onUpdateNow := r.onUpdateNowColumns(reflect.TypeOf(t))
+	if len(fields) == 0 && len(onUpdateNow) == 0 {
+		return 0, nil
+	}
+	// sorted so the statement shape (and its cached SQL) is stable across calls
+	cols := make([]string, 0, len(fields))
+	for col := range fields {
+		cols = append(cols, col)
+	}
+	slices.Sort(cols)
+	sets := make([]string, 0, len(cols)+len(onUpdateNow))
+	args := make([]any, 0, len(cols))
+	provided := make(map[string]struct{}, len(cols))
+	for _, col := range cols {
+		sets = append(sets, fmt.Sprintf("%s = ?", quoteQualified(col)))
+		args = append(args, fields[col])
+		provided[strings.ToLower(col)] = struct{}{}
+	}
+	nowCols := make([]string, 0, len(onUpdateNow))
+	for col := range onUpdateNow {
+		if _, ok := provided[strings.ToLower(col)]; !ok {
+			nowCols = append(nowCols, col)
+		}
+	}
+	slices.Sort(nowCols)
+	for _, col := range nowCols {
+		sets = append(sets, fmt.Sprintf("%s = NOW()", quoteQualified(col)))
+	}
+	n, err := r.matching(conditions).Set(strings.Join(sets, ", "), args...).ExecUpdate(ctx, nil)
+	r.audit(ctx, AuditActionUpdate, nil, fields, "", err)
+	return n, err
+}
@@ -0,0 +1,56 @@
+package norm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestRepo_DeleteWhere_HonorsSoftDeleteMode(t *testing.T) {
+	ex := &recExecRepo{}
+	r := &repo[softUser]{kn: &KintsNorm{}, exec: ex}
+	if _, err := r.DeleteWhere(context.Background(), Lt("id", 10)); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if ex.lastSQL != "DELETE FROM soft_users WHERE id < $1 AND deleted_at IS NULL" {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+	if _, err := r.OnlyTrashed().DeleteWhere(context.Background(), Lt("id", 10)); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if ex.lastSQL != "DELETE FROM soft_users WHERE id < $1 AND deleted_at IS NOT NULL" {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+	if _, err := r.DeleteWhere(context.Background()); err == nil {
+		t.Fatalf("expected error without conditions")
+	}
+}
+
+func TestRepo_SoftDeleteWhere(t *testing.T) {
+	ex := &recExecRepo{}
+	r := &repo[softUser]{kn: &KintsNorm{}, exec: ex}
+	if _, err := r.SoftDeleteWhere(context.Background(), Eq("id", 3)); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if ex.lastSQL != "UPDATE soft_users SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL" {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+	if _, err := (&repo[repoUser]{kn: &KintsNorm{}, exec: ex}).SoftDeleteWhere(context.Background(), Eq("id", 3)); err == nil {
+		t.Fatalf("expected error for model without deleted_at")
+	}
+}
+
+func TestRepo_UpdateWhere_AddsOnUpdateColumns(t *testing.T) {
+	ex := &recExecRepo{}
+	r := &repo[repoUser]{kn: &KintsNorm{}, exec: ex}
+	if _, err := r.UpdateWhere(context.Background(), map[string]any{"email": "x@y"}, Eq("email", "old@y"), Gt("id", 5)); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `UPDATE repo_users SET "email" = $1, "updated_at" = NOW() WHERE email = $2 AND id > $3`
+	if ex.lastSQL != want {
+		t.Fatalf("sql:\n got %s\nwant %s", ex.lastSQL, want)
+	}
+	if !reflect.DeepEqual(ex.lastArgs, []any{"x@y", "old@y", 5}) {
+		t.Fatalf("args=%v", ex.lastArgs)
+	}
+}
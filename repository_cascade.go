@@ -0,0 +1,154 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/kintsdev/norm/internal/core"
+)
+
+// CascadeSpec names a child table soft-deleted together with its parent by SoftDeleteCascade
+type CascadeSpec struct {
+	Table      string        // child table, optionally schema-qualified
+	ForeignKey string        // child column referencing the parent's primary key
+	PrimaryKey string        // child primary key matched by Children's foreign keys (default "id")
+	Children   []CascadeSpec // tables referencing this child, soft-deleted with it
+}
+
+// SoftDeleteCascade soft-deletes the row with the given id and the live rows referencing it, in one
+// transaction (a savepoint when the repository already runs in one). ON DELETE CASCADE only fires on
+// real deletes, so soft-deleted parents would otherwise leave their children visible.
+//
+// Without cascades the hasmany and hasone relations of T are followed recursively, skipping related
+// models without a deleted_at column; explicit cascades replace them. Every row gets the same
+// deleted_at (the transaction's NOW()), and children already in the trash keep theirs.
+func (r *repo[T]) SoftDeleteCascade(ctx context.Context, id any, cascades ...CascadeSpec) error {
+	var t T
+	typ := reflect.TypeOf(t)
+	if !core.ModelHasSoftDelete(typ) {
+		return &ORMError{Code: ErrCodeValidation, Message: "soft delete not supported: missing deleted_at column"}
+	}
+	cols := keyColumns(core.StructMapper(typ))
+	if len(cols) > 1 {
+		return &ORMError{Code: ErrCodeValidation, Message: "soft delete cascade needs a single-column primary key"}
+	}
+	if _, err := keyValues(cols, id); err != nil {
+		return err
+	}
+	if len(cascades) == 0 {
+		cascades = relationCascades(typ, map[reflect.Type]bool{typ: true})
+	}
+	if len(cascades) == 0 {
+		return r.SoftDelete(ctx, id)
+	}
+	if bsd, ok := any(&t).(BeforeSoftDelete); ok {
+		if err := bsd.BeforeSoftDelete(ctx, id); err != nil {
+			return err
+		}
+	} else if bsdv, ok := any(t).(BeforeSoftDelete); ok {
+		if err := bsdv.BeforeSoftDelete(ctx, id); err != nil {
+			return err
+		}
+	}
+	query := fmt.Sprintf("UPDATE %s SET deleted_at = NOW() WHERE %s", r.tableName(), keyPredicate(quoteColumns(cols), 1))
+	err := r.softDeleteCascade(ctx, query, id, cascades)
+	r.audit(ctx, AuditActionSoftDelete, id, nil, query, err)
+	if err != nil {
+		return err
+	}
+	r.uncache(ctx, id)
+	if asd, ok := any(&t).(AfterSoftDelete); ok {
+		if err := asd.AfterSoftDelete(ctx, id); err != nil {
+			return err
+		}
+	} else if asdv, ok := any(t).(AfterSoftDelete); ok {
+		if err := asdv.AfterSoftDelete(ctx, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *repo[T]) softDeleteCascade(ctx context.Context, query string, id any, cascades []CascadeSpec) error {
+	tx, err := r.query().beginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+	exec := wrapExec(r.kn, tx)
+	if _, err := exec.Exec(ctx, query, id); err != nil {
+		return wrapPgError(err, query, []any{id})
+	}
+	for _, stmt := range cascadeStatements(cascades, "= $1") {
+		if _, err := exec.Exec(ctx, stmt, id); err != nil {
+			return wrapPgError(err, stmt, []any{id})
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return &ORMError{Code: ErrCodeTransaction, Message: err.Error(), Internal: err}
+	}
+	return nil
+}
+
+// cascadeStatements renders the UPDATE of every spec, depth first. parent is the predicate matching the
+// foreign key against the parent rows: "= $1" at the top, "IN (subquery over the parent table)" below.
+func cascadeStatements(cascades []CascadeSpec, parent string) []string {
+	var out []string
+	for _, c := range cascades {
+		match := QuoteIdentifier(c.ForeignKey) + " " + parent
+		table := quoteQualified(c.Table)
+		out = append(out, fmt.Sprintf("UPDATE %s SET deleted_at = NOW() WHERE %s AND deleted_at IS NULL", table, match))
+		if len(c.Children) > 0 {
+			pk := c.PrimaryKey
+			if pk == "" {
+				pk = "id"
+			}
+			keys := fmt.Sprintf("IN (SELECT %s FROM %s WHERE %s)", QuoteIdentifier(pk), table, match)
+			out = append(out, cascadeStatements(c.Children, keys)...)
+		}
+	}
+	return out
+}
+
+// relationCascades derives cascades from the hasmany/hasone relations of typ; seen stops cycles
+func relationCascades(typ reflect.Type, seen map[reflect.Type]bool) []CascadeSpec {
+	var out []CascadeSpec
+	mapping := core.StructMapper(typ)
+	// map order is random; keep the statements stable
+	for _, name := range slices.Sorted(maps.Keys(mapping.Relations)) {
+		ri := mapping.Relations[name]
+		if (ri.Kind != core.RelationHasMany && ri.Kind != core.RelationHasOne) || ri.ForeignKey == "" {
+			continue
+		}
+		relType := ri.Type
+		if relType.Kind() == reflect.Slice {
+			relType = relType.Elem()
+		}
+		if relType.Kind() == reflect.Pointer {
+			relType = relType.Elem()
+		}
+		if relType.Kind() != reflect.Struct || seen[relType] {
+			continue
+		}
+		relMapping := core.StructMapper(relType)
+		if !relMapping.HasSoftDelete {
+			continue
+		}
+		table := ri.Table
+		if table == "" {
+			table = core.QualifiedTableName(relType)
+		}
+		seen[relType] = true
+		out = append(out, CascadeSpec{
+			Table:      table,
+			ForeignKey: ri.ForeignKey,
+			PrimaryKey: relMapping.PrimaryColumn,
+			Children:   relationCascades(relType, seen),
+		})
+		delete(seen, relType)
+	}
+	return out
+}
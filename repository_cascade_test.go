@@ -0,0 +1,116 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type cascadeComment struct {
+	ID        int64  `db:"id" norm:"primary_key"`
+	PostID    int64  `db:"post_id"`
+	DeletedAt *int64 `db:"deleted_at"`
+}
+
+type cascadePost struct {
+	ID        int64             `db:"id" norm:"primary_key"`
+	AuthorID  int64             `db:"author_id"`
+	DeletedAt *int64            `db:"deleted_at"`
+	Comments  []*cascadeComment `norm:"hasmany:comments,fk:post_id"`
+	Author    *cascadeAuthor    `norm:"belongsto:cascade_authors,fk:author_id"`
+}
+
+type cascadeAuthor struct {
+	ID        int64          `db:"id" norm:"primary_key"`
+	DeletedAt *int64         `db:"deleted_at"`
+	Posts     []*cascadePost `norm:"hasmany:posts,fk:author_id"`
+	Profile   *repUser       `norm:"hasone:profiles,fk:author_id"` // no deleted_at: skipped
+}
+
+// cascadeDB opens transactions recording their statements; failOn makes matching statements fail
+type cascadeDB struct {
+	recExecRepo
+	sqls      []string
+	failOn    string
+	commits   int
+	rollbacks int
+}
+
+func (d *cascadeDB) Begin(context.Context) (pgx.Tx, error) { return &cascadeTx{db: d}, nil }
+
+type cascadeTx struct {
+	pgx.Tx
+	db   *cascadeDB
+	done bool
+}
+
+func (t *cascadeTx) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	t.db.sqls = append(t.db.sqls, sql)
+	if t.db.failOn != "" && strings.Contains(sql, t.db.failOn) {
+		return pgconn.CommandTag{}, errors.New("boom")
+	}
+	return pgconn.CommandTag{}, nil
+}
+
+func (t *cascadeTx) Commit(context.Context) error {
+	t.done = true
+	t.db.commits++
+	return nil
+}
+
+func (t *cascadeTx) Rollback(context.Context) error {
+	if !t.done {
+		t.done = true
+		t.db.rollbacks++
+	}
+	return nil
+}
+
+func TestSoftDeleteCascade_FollowsRelations(t *testing.T) {
+	db := &cascadeDB{}
+	r := NewRepositoryWithExecutor[cascadeAuthor](&KintsNorm{}, db)
+	if err := r.SoftDeleteCascade(context.Background(), int64(7)); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		`UPDATE cascade_authors SET deleted_at = NOW() WHERE id = $1`,
+		`UPDATE "posts" SET deleted_at = NOW() WHERE "author_id" = $1 AND deleted_at IS NULL`,
+		`UPDATE "comments" SET deleted_at = NOW() WHERE "post_id" IN (SELECT "id" FROM "posts" WHERE "author_id" = $1) AND deleted_at IS NULL`,
+	}
+	if strings.Join(db.sqls, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("sqls:\n%s", strings.Join(db.sqls, "\n"))
+	}
+	if db.commits != 1 || db.rollbacks != 0 {
+		t.Fatalf("commits=%d rollbacks=%d", db.commits, db.rollbacks)
+	}
+}
+
+func TestSoftDeleteCascade_ExplicitSpecsAndRollback(t *testing.T) {
+	db := &cascadeDB{failOn: "audit_logs"}
+	r := NewRepositoryWithExecutor[cascadeAuthor](&KintsNorm{}, db)
+	err := r.SoftDeleteCascade(context.Background(), int64(7),
+		CascadeSpec{Table: "billing.invoices", ForeignKey: "author_id"},
+		CascadeSpec{Table: "audit_logs", ForeignKey: "author_id"},
+	)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if len(db.sqls) != 3 || !strings.HasPrefix(db.sqls[1], `UPDATE "billing"."invoices" SET`) {
+		t.Fatalf("sqls: %v", db.sqls)
+	}
+	if db.commits != 0 || db.rollbacks != 1 {
+		t.Fatalf("commits=%d rollbacks=%d", db.commits, db.rollbacks)
+	}
+}
+
+func TestSoftDeleteCascade_RequiresSoftDelete(t *testing.T) {
+	r := NewRepositoryWithExecutor[repUser](&KintsNorm{}, &cascadeDB{})
+	var oe *ORMError
+	if err := r.SoftDeleteCascade(context.Background(), 1); !errors.As(err, &oe) || oe.Code != ErrCodeValidation {
+		t.Fatalf("err=%v", err)
+	}
+}
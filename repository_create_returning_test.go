@@ -0,0 +1,95 @@
+package norm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// scanRow fills scan targets with the given values in order
+type scanRow struct{ vals []any }
+
+func (s scanRow) Scan(dest ...any) error {
+	for i, d := range dest {
+		switch p := d.(type) {
+		case *int64:
+			*p = s.vals[i].(int64)
+		case *time.Time:
+			*p = s.vals[i].(time.Time)
+		}
+	}
+	return nil
+}
+
+type retExec struct {
+	lastSQL string
+	row     scanRow
+}
+
+func (r *retExec) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	r.lastSQL = sql
+	return pgconn.CommandTag{}, nil
+}
+func (r *retExec) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	r.lastSQL = sql
+	return nil, nil
+}
+func (r *retExec) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row {
+	r.lastSQL = sql
+	return r.row
+}
+
+type retUser struct {
+	ID        int64     `db:"id" norm:"primary_key,auto_increment"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at" norm:"not_null,default:now()"`
+}
+
+func TestRepo_Create_ReturningPopulatesEntity(t *testing.T) {
+	now := time.Now()
+	ex := &retExec{row: scanRow{vals: []any{int64(42), now}}}
+	r := &repo[retUser]{kn: &KintsNorm{}, exec: ex}
+	u := &retUser{Name: "a"}
+	if err := r.Create(context.Background(), u); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if ex.lastSQL != `INSERT INTO ret_users ("name") VALUES ($1) RETURNING "id", "created_at"` {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+	if u.ID != 42 || !u.CreatedAt.Equal(now) {
+		t.Fatalf("entity=%+v", u)
+	}
+}
+
+func TestIsWriteSQL(t *testing.T) {
+	if !isWriteSQL(" insert into t values (1) returning id") || !isWriteSQL("UPDATE t SET a = 1") {
+		t.Fatalf("expected write")
+	}
+	if isWriteSQL("SELECT 1") || isWriteSQL("del") {
+		t.Fatalf("expected read")
+	}
+}
+
+func TestIsWriteSQL_Shapes(t *testing.T) {
+	cases := map[string]bool{
+		"WITH d AS (DELETE FROM jobs WHERE done RETURNING id) SELECT count(*) FROM d":          true,
+		"WITH a AS (SELECT 1), u AS (UPDATE t SET n = n + 1 RETURNING n) SELECT * FROM u":      true,
+		"WITH s AS (SELECT id FROM src) INSERT INTO dst SELECT id FROM s RETURNING id":         true,
+		"MERGE INTO stock s USING moves m ON s.id = m.id WHEN MATCHED THEN UPDATE SET n = m.n": true,
+		"-- refresh\nUPDATE t SET a = 1 RETURNING a":                                           true,
+		"/* job */ DELETE FROM t WHERE id = $1 RETURNING id":                                   true,
+		"(INSERT INTO t VALUES (1) RETURNING id)":                                              true,
+		"WITH r AS (SELECT 'DELETE FROM t' AS q) SELECT q FROM r":                              false,
+		"WITH \"update\" AS (SELECT 1) SELECT * FROM \"update\"":                               false,
+		"(SELECT 1) UNION (SELECT 2)":                                                          false,
+		"-- DELETE\nSELECT 1":                                                                  false,
+	}
+	for sql, want := range cases {
+		if got := isWriteSQL(sql); got != want {
+			t.Errorf("isWriteSQL(%q) = %v, want %v", sql, got, want)
+		}
+	}
+}
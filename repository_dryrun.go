@@ -0,0 +1,141 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// DryRunRepository is a Repository whose writes are recorded instead of executed; see Repository.DryRun
+type DryRunRepository[T any] struct {
+	Repository[T]
+	rec *dryRunRecorder
+}
+
+// Statements returns the writes recorded so far, in order, including those of repositories derived from
+// this one (WithTrashed, Scoped, WithExecutor, ...)
+func (d *DryRunRepository[T]) Statements() []Statement {
+	d.rec.mu.Lock()
+	defer d.rec.mu.Unlock()
+	return append([]Statement(nil), d.rec.stmts...)
+}
+
+// Reset forgets the recorded statements
+func (d *DryRunRepository[T]) Reset() {
+	d.rec.mu.Lock()
+	defer d.rec.mu.Unlock()
+	d.rec.stmts = nil
+}
+
+type dryRunRecorder struct {
+	mu    sync.Mutex
+	stmts []Statement
+}
+
+// DryRun returns a copy of the repository whose write methods build their SQL and record it, logging each
+// statement as "dry_run", without sending it. Reads (including those a write performs, like EnsureAll's
+// lookup) still run. Recorded writes report zero rows affected and RETURNING yields no rows, so
+// generated keys stay unset; optimistic-lock checks are skipped. Hooks run as usual.
+func (r *repo[T]) DryRun() *DryRunRepository[T] {
+	nr := *r
+	nr.dry = &dryRunRecorder{}
+	nr.exec = dryRunExecuter{kn: r.kn, rec: nr.dry, exec: r.exec}
+	return &DryRunRepository[T]{Repository: &nr, rec: nr.dry}
+}
+
+// dryRunExecuter records every Exec and data-modifying Query/QueryRow; other statements reach exec
+type dryRunExecuter struct {
+	kn   *KintsNorm
+	rec  *dryRunRecorder
+	exec dbExecuter
+}
+
+func (e dryRunExecuter) record(ctx context.Context, kind StatementKind, sql string, args []any) {
+	if e.kn != nil && e.kn.logger != nil {
+		e.kn.logger.Info("dry_run", e.kn.makeLogFields(ctx, sql, args)...)
+	}
+	e.rec.mu.Lock()
+	defer e.rec.mu.Unlock()
+	e.rec.stmts = append(e.rec.stmts, Statement{Kind: kind, SQL: sql, Args: args})
+}
+
+func (e dryRunExecuter) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	e.record(ctx, StatementExec, sql, arguments)
+	verb, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	verb = strings.ToUpper(verb)
+	if verb == "INSERT" {
+		return pgconn.NewCommandTag("INSERT 0 0"), nil
+	}
+	return pgconn.NewCommandTag(verb + " 0"), nil
+}
+
+func (e dryRunExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if !isWriteSQL(sql) {
+		return e.exec.Query(ctx, sql, args...)
+	}
+	e.record(ctx, StatementQuery, sql, args)
+	return &dryRunRows{}, nil
+}
+
+// QueryRow of a write scans nothing and succeeds, leaving RETURNING destinations untouched
+func (e dryRunExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	if !isWriteSQL(sql) {
+		return e.exec.QueryRow(ctx, sql, args...)
+	}
+	e.record(ctx, StatementQueryRow, sql, args)
+	return errorRow{}
+}
+
+// Begin opens a pretend transaction, so multi-statement writes (chunked batches, cascades) are recorded too
+func (e dryRunExecuter) Begin(context.Context) (pgx.Tx, error) { return &dryRunTx{exec: e}, nil }
+
+// dryRunTx routes a pretend transaction's statements through the dry-run executer
+type dryRunTx struct {
+	pgx.Tx
+	exec dryRunExecuter
+}
+
+func (t *dryRunTx) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return t.exec.Exec(ctx, sql, arguments...)
+}
+func (t *dryRunTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return t.exec.Query(ctx, sql, args...)
+}
+func (t *dryRunTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return t.exec.QueryRow(ctx, sql, args...)
+}
+func (t *dryRunTx) Begin(ctx context.Context) (pgx.Tx, error) { return t.exec.Begin(ctx) }
+func (t *dryRunTx) Commit(context.Context) error              { return nil }
+func (t *dryRunTx) Rollback(context.Context) error            { return nil }
+
+// dryRunRows is the empty result of a recorded RETURNING statement
+type dryRunRows struct{}
+
+func (*dryRunRows) Close()                                       {}
+func (*dryRunRows) Err() error                                   { return nil }
+func (*dryRunRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (*dryRunRows) FieldDescriptions() []pgconn.FieldDescription { return nil }
+func (*dryRunRows) Next() bool                                   { return false }
+func (*dryRunRows) Scan(...any) error                            { return nil }
+func (*dryRunRows) Values() ([]any, error)                       { return nil, nil }
+func (*dryRunRows) RawValues() [][]byte                          { return nil }
+func (*dryRunRows) Conn() *pgx.Conn                              { return nil }
+
+// dryRunCopy records a CopyFrom as the COPY statement it would stream, with the rows as arguments
+func (r *repo[T]) dryRunCopy(ctx context.Context, columns []string, rows [][]any) int64 {
+	quoted := make([]string, len(columns))
+	for i, c := range columns {
+		quoted[i] = QuoteIdentifier(c)
+	}
+	args := make([]any, len(rows))
+	for i, row := range rows {
+		args[i] = row
+	}
+	sql := fmt.Sprintf("COPY %s (%s) FROM STDIN", r.tableName(), strings.Join(quoted, ", "))
+	dryRunExecuter{kn: r.kn, rec: r.dry}.record(ctx, StatementExec, sql, args)
+	return 0
+}
@@ -0,0 +1,70 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type auditRecorder struct{ entries []AuditEntry }
+
+func (a *auditRecorder) OnAudit(_ context.Context, e AuditEntry) { a.entries = append(a.entries, e) }
+
+func TestDryRun_RecordsWritesWithoutExecuting(t *testing.T) {
+	ex := &recExecRepo{}
+	audit := &auditRecorder{}
+	l := &infoLogger{}
+	r := NewRepositoryWithExecutor[repUser](&KintsNorm{auditHook: audit, logger: l}, ex)
+	dry := r.DryRun()
+	ctx := context.Background()
+
+	if err := dry.Update(ctx, &repUser{ID: 1, Name: "n", Version: 3}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := dry.Create(ctx, &repUser{Name: "c"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if n, err := dry.WithTrashed().DeleteWhere(ctx, Eq("name", "x")); err != nil || n != 0 {
+		t.Fatalf("delete where: n=%d err=%v", n, err)
+	}
+	if ex.lastSQL != "" {
+		t.Fatalf("write reached the executor: %q", ex.lastSQL)
+	}
+	stmts := dry.Statements()
+	if len(stmts) != 3 || !strings.HasPrefix(stmts[0].SQL, "UPDATE rep_users SET") || stmts[1].Kind != StatementQueryRow || !strings.HasPrefix(stmts[2].SQL, "DELETE FROM") {
+		t.Fatalf("statements: %+v", stmts)
+	}
+	if len(audit.entries) != 0 || len(l.infos) != 3 {
+		t.Fatalf("audit=%d logged=%d", len(audit.entries), len(l.infos))
+	}
+
+	if _, err := dry.Find(ctx, Eq("id", 1)); err != nil {
+		t.Fatalf("find: %v", err)
+	}
+	if !strings.HasPrefix(ex.lastSQL, "SELECT") {
+		t.Fatalf("read did not run: %q", ex.lastSQL)
+	}
+	dry.Reset()
+	if len(dry.Statements()) != 0 {
+		t.Fatal("reset kept statements")
+	}
+}
+
+func TestDryRun_ChunkedBatchOpensNoTransaction(t *testing.T) {
+	db := &batchDB{}
+	r := &repo[repUser]{kn: &KintsNorm{batchSize: 2}, exec: db}
+	dry := r.DryRun()
+	if err := dry.CreateBatch(context.Background(), []*repUser{{Name: "a"}, {Name: "b"}, {Name: "c"}}); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if len(db.sqls) != 0 || db.commits != 0 || len(dry.Statements()) != 2 {
+		t.Fatalf("sqls=%v commits=%d recorded=%d", db.sqls, db.commits, len(dry.Statements()))
+	}
+}
+
+type infoLogger struct {
+	testLogger
+	infos []string
+}
+
+func (l *infoLogger) Info(msg string, _ ...Field) { l.infos = append(l.infos, msg) }
@@ -0,0 +1,45 @@
+package norm
+
+import (
+	"context"
+	"testing"
+)
+
+// BaseModel is shared by embedding models
+type BaseModel struct {
+	ID        int64  `db:"id" norm:"primary_key,auto_increment"`
+	UpdatedAt string `db:"updated_at" norm:"on_update:now()"`
+	DeletedAt *int64 `db:"deleted_at"`
+}
+
+type embedPost struct {
+	BaseModel
+	Title string `db:"title"`
+}
+
+func TestRepo_EmbeddedBaseModel(t *testing.T) {
+	ex := &fakeExecRU{rows: [][]any{{int64(4)}}, fields: []string{"id"}}
+	r := &repo[embedPost]{kn: &KintsNorm{}, exec: ex}
+	p := &embedPost{Title: "hi"}
+	if err := r.CreateBatch(context.Background(), []*embedPost{p}); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if want := `INSERT INTO embed_posts ("updated_at", "deleted_at", "title") VALUES ($1, $2, $3) RETURNING "id"`; ex.lastSQL != want {
+		t.Fatalf("insert sql=%s", ex.lastSQL)
+	}
+	if p.ID != 4 {
+		t.Fatalf("id=%d", p.ID)
+	}
+	if err := r.Update(context.Background(), p); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if want := `UPDATE embed_posts SET "updated_at" = NOW(), "deleted_at" = $1, "title" = $2 WHERE "id" = $3`; ex.lastSQL != want {
+		t.Fatalf("update sql=%s", ex.lastSQL)
+	}
+	if _, err := r.Find(context.Background()); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if want := `SELECT * FROM embed_posts WHERE deleted_at IS NULL`; ex.lastSQL != want {
+		t.Fatalf("soft delete from embedded field not detected: %s", ex.lastSQL)
+	}
+}
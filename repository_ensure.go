@@ -0,0 +1,137 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// EnsureAll inserts reference rows (tags, categories, ...) that do not exist yet and populates every entity
+// with its stored row, including generated IDs. New rows are inserted in a single statement using
+// ON CONFLICT (conflictCols) DO NOTHING RETURNING *; rows that already existed are loaded by a follow-up select.
+// The returned slice is entities itself, in the same order.
+func (r *repo[T]) EnsureAll(ctx context.Context, entities []*T, conflictCols []string) ([]*T, error) {
+	if len(entities) == 0 {
+		return entities, nil
+	}
+	if len(conflictCols) == 0 {
+		return nil, &ORMError{Code: ErrCodeValidation, Message: "EnsureAll requires conflict columns"}
+	}
+	var t T
+	typ := reflect.TypeOf(t)
+	mapper := core.StructMapper(typ)
+	conflictCols = resolveColumns(mapper, conflictCols)
+	keyFields := make([][]int, len(conflictCols))
+	for i, c := range conflictCols {
+		fi, ok := mapper.FieldsByColumn[strings.ToLower(c)]
+		if !ok {
+			return nil, &ORMError{Code: ErrCodeInvalidColumn, Message: fmt.Sprintf("unknown column: %s", c)}
+		}
+		keyFields[i] = fi.Index
+	}
+	keyOf := func(v reflect.Value) string {
+		parts := make([]string, len(keyFields))
+		for i, idx := range keyFields {
+			parts[i] = fmt.Sprint(v.FieldByIndex(idx).Interface())
+		}
+		return strings.Join(parts, "\x00")
+	}
+
+	fields := writableFields(typ)
+	cols := make([]string, len(fields))
+	for i, f := range fields {
+		cols[i] = quoteQualified(f.col)
+	}
+	var sb strings.Builder
+	args := make([]any, 0, len(entities)*len(fields))
+	for ei, e := range entities {
+		if e == nil {
+			return nil, &ORMError{Code: ErrCodeValidation, Message: "nil entity"}
+		}
+		if ei > 0 {
+			sb.WriteString(", ")
+		}
+		val := reflect.ValueOf(e).Elem()
+		sb.WriteByte('(')
+		for fi, f := range fields {
+			if fi > 0 {
+				sb.WriteString(", ")
+			}
+			fv := val.FieldByIndex(f.index)
+			if (f.hasDefault || f.omitEmpty) && fv.IsZero() {
+				sb.WriteString("DEFAULT")
+				continue
+			}
+			args = append(args, fv.Interface())
+			fmt.Fprintf(&sb, "$%d", len(args))
+		}
+		sb.WriteByte(')')
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT (%s) DO NOTHING RETURNING *", r.tableName(), strings.Join(cols, ", "), sb.String(), strings.Join(quoteIdentifiers(conflictCols), ", "))
+
+	stored := make(map[string]T, len(entities))
+	rows, err := r.exec.Query(ctx, query, args...)
+	if err != nil {
+		r.audit(ctx, AuditActionUpsert, nil, entities, query, err)
+		return nil, wrapPgError(err, query, args)
+	}
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			rows.Close()
+			return nil, wrapPgError(err, query, args)
+		}
+		fds := rows.FieldDescriptions()
+		var row T
+		ptr := reflect.ValueOf(&row)
+		for i, v := range vals {
+			if fi, ok := mapper.FieldsByColumn[strings.ToLower(string(fds[i].Name))]; ok {
+				core.SetField(ptr, fi, v)
+			}
+		}
+		stored[keyOf(ptr.Elem())] = row
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, wrapPgError(err, query, args)
+	}
+
+	// load rows that already existed (conflicted) by their conflict key
+	missing := make([]Condition, 0)
+	seen := map[string]struct{}{}
+	for _, e := range entities {
+		val := reflect.ValueOf(e).Elem()
+		k := keyOf(val)
+		if _, ok := stored[k]; ok {
+			continue
+		}
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		conds := make([]Condition, len(conflictCols))
+		for i, c := range conflictCols {
+			conds[i] = Eq(quoteQualified(c), val.FieldByIndex(keyFields[i]).Interface())
+		}
+		missing = append(missing, And(conds...))
+	}
+	if len(missing) > 0 {
+		var existing []T
+		if err := r.query().Table(r.tableName()).WhereCond(Or(missing...)).Find(ctx, &existing); err != nil {
+			return nil, err
+		}
+		for i := range existing {
+			stored[keyOf(reflect.ValueOf(&existing[i]).Elem())] = existing[i]
+		}
+	}
+	for _, e := range entities {
+		if row, ok := stored[keyOf(reflect.ValueOf(e).Elem())]; ok {
+			*e = row
+		}
+	}
+	r.audit(ctx, AuditActionUpsert, nil, entities, query, nil)
+	return entities, nil
+}
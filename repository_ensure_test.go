@@ -0,0 +1,58 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// seqExec answers successive Query calls with successive row sets
+type seqExec struct {
+	fields  []string
+	results [][][]any
+	sqls    []string
+}
+
+func (s *seqExec) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	s.sqls = append(s.sqls, sql)
+	return pgconn.CommandTag{}, nil
+}
+func (s *seqExec) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	s.sqls = append(s.sqls, sql)
+	var rows [][]any
+	if len(s.results) > 0 {
+		rows, s.results = s.results[0], s.results[1:]
+	}
+	return &fakeRowsRU{rows: rows, fields: s.fields}, nil
+}
+func (s *seqExec) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row {
+	s.sqls = append(s.sqls, sql)
+	return errorRow{}
+}
+
+type ensureTag struct {
+	ID   int64  `db:"id" norm:"primary_key,auto_increment"`
+	Name string `db:"name" norm:"unique"`
+}
+
+func TestRepo_EnsureAll(t *testing.T) {
+	ex := &seqExec{fields: []string{"id", "name"}, results: [][][]any{{{int64(1), "go"}}, {{int64(2), "rust"}}}}
+	r := &repo[ensureTag]{kn: &KintsNorm{}, exec: ex}
+	tags := []*ensureTag{{Name: "go"}, {Name: "rust"}, {Name: "go"}}
+	out, err := r.EnsureAll(context.Background(), tags, []string{"name"})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if out[0].ID != 1 || out[1].ID != 2 || out[2].ID != 1 {
+		t.Fatalf("ids=%d,%d,%d", out[0].ID, out[1].ID, out[2].ID)
+	}
+	if ex.sqls[0] != `INSERT INTO ensure_tags ("name") VALUES ($1), ($2), ($3) ON CONFLICT ("name") DO NOTHING RETURNING *` {
+		t.Fatalf("sql=%s", ex.sqls[0])
+	}
+	if len(ex.sqls) != 2 || !strings.Contains(ex.sqls[1], `"name" = $1`) {
+		t.Fatalf("follow-up=%v", ex.sqls)
+	}
+}
@@ -0,0 +1,61 @@
+package norm
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+)
+
+// maxPooledFindRows caps the capacity of a pooled Find buffer so one huge result does not stay pinned in memory
+const maxPooledFindRows = 10000
+
+var findBufferPools sync.Map // map[reflect.Type]*sync.Pool holding *[]T
+
+func findBufferPool[T any]() *sync.Pool {
+	typ := reflect.TypeFor[T]()
+	if p, ok := findBufferPools.Load(typ); ok {
+		return p.(*sync.Pool)
+	}
+	p, _ := findBufferPools.LoadOrStore(typ, &sync.Pool{New: func() any { return new([]T) }})
+	return p.(*sync.Pool)
+}
+
+// findRows scans qb into []*T backed by a single exact-size []T. sizeHint > 0 (e.g. derived from a Count)
+// pre-sizes the scan slice; otherwise, with WithFindBufferPool, rows are scanned into a pooled buffer and
+// copied out, so the garbage from append growth is recycled across calls. The pooled buffer never escapes.
+func (r *repo[T]) findRows(ctx context.Context, qb *QueryBuilder, sizeHint int) ([]*T, error) {
+	var rows []T
+	switch {
+	case sizeHint > 0:
+		rows = make([]T, 0, sizeHint)
+		if err := qb.Find(ctx, &rows); err != nil {
+			return nil, err
+		}
+	case r.kn != nil && r.kn.findBufferPool:
+		pool := findBufferPool[T]()
+		bufp := pool.Get().(*[]T)
+		buf := (*bufp)[:0]
+		err := qb.Find(ctx, &buf)
+		if err == nil {
+			rows = slices.Clone(buf)
+		}
+		clear(buf) // drop references held by the rows before pooling
+		if cap(buf) <= maxPooledFindRows {
+			*bufp = buf[:0]
+			pool.Put(bufp)
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
+		if err := qb.Find(ctx, &rows); err != nil {
+			return nil, err
+		}
+	}
+	out := make([]*T, len(rows))
+	for i := range rows {
+		out[i] = &rows[i]
+	}
+	return out, nil
+}
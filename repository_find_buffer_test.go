@@ -0,0 +1,33 @@
+package norm
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRepo_Find_PooledBufferDoesNotAlias(t *testing.T) {
+	ex := &fakeExecRU{rows: [][]any{{int64(1), "a"}, {int64(2), "b"}}, fields: []string{"id", "name"}}
+	r := &repo[repUser]{kn: &KintsNorm{findBufferPool: true}, exec: ex}
+	first, err := r.Find(context.Background())
+	if err != nil || len(first) != 2 {
+		t.Fatalf("err=%v len=%d", err, len(first))
+	}
+	ex.rows = [][]any{{int64(3), "c"}}
+	second, err := r.Find(context.Background())
+	if err != nil || len(second) != 1 || second[0].Name != "c" {
+		t.Fatalf("err=%v second=%v", err, second)
+	}
+	if first[0].ID != 1 || first[1].Name != "b" {
+		t.Fatalf("earlier results were overwritten: %+v %+v", first[0], first[1])
+	}
+}
+
+func TestRepo_FindRows_SizeHint(t *testing.T) {
+	ex := &fakeExecRU{rows: [][]any{{int64(1), "a"}, {int64(2), "b"}, {int64(3), "c"}}, fields: []string{"id", "name"}}
+	r := &repo[repUser]{kn: &KintsNorm{}, exec: ex}
+	// a stale hint only affects capacity, never the rows returned
+	out, err := r.findRows(context.Background(), r.query(), 2)
+	if err != nil || len(out) != 3 || out[2].Name != "c" {
+		t.Fatalf("err=%v out=%v", err, out)
+	}
+}
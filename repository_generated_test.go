@@ -0,0 +1,53 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type genUser struct {
+	ID         int64  `db:"id" norm:"primary_key,auto_increment"`
+	Email      string `db:"email"`
+	EmailLower string `db:"email_lower" norm:"generated:(lower(email)) stored"`
+}
+
+func TestRepo_GeneratedColumnsAreNotWritten(t *testing.T) {
+	rex := &recExec2{}
+	r := &repo[genUser]{kn: &KintsNorm{}, exec: rex}
+	_ = r.Update(context.Background(), &genUser{ID: 1, Email: "A@x", EmailLower: "stale"})
+	if rex.lastSQL != `UPDATE gen_users SET "email" = $1 WHERE "id" = $2` {
+		t.Fatalf("sql=%s", rex.lastSQL)
+	}
+	_ = r.Upsert(context.Background(), &genUser{Email: "a@x"}, []string{"email"}, []string{"email"})
+	if strings.Contains(rex.lastSQL, "email_lower") {
+		t.Fatalf("upsert wrote generated column: %s", rex.lastSQL)
+	}
+	for _, f := range writableFields(reflect.TypeFor[genUser]()) {
+		if f.col == "email_lower" {
+			t.Fatalf("generated column is writable")
+		}
+	}
+	var oe *ORMError
+	if err := r.UpdatePartial(context.Background(), 1, map[string]any{"email_lower": "x"}); !errors.As(err, &oe) || oe.Code != ErrCodeValidation {
+		t.Fatalf("err=%v", err)
+	}
+}
+
+func TestRepo_CreateBatchReadsGeneratedColumnsBack(t *testing.T) {
+	ex := &seqExec{fields: []string{"id", "email_lower"}, results: [][][]any{{{int64(1), "a@x"}, {int64(2), "b@x"}}}}
+	r := &repo[genUser]{kn: &KintsNorm{}, exec: ex}
+	users := []*genUser{{Email: "A@x"}, {Email: "B@x"}}
+	if err := r.CreateBatch(context.Background(), users); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := `INSERT INTO gen_users ("email") VALUES ($1), ($2) RETURNING "id", "email_lower"`
+	if len(ex.sqls) != 1 || ex.sqls[0] != want {
+		t.Fatalf("sqls=%v", ex.sqls)
+	}
+	if users[0].EmailLower != "a@x" || users[1].EmailLower != "b@x" || users[1].ID != 2 {
+		t.Fatalf("users=%+v %+v", users[0], users[1])
+	}
+}
@@ -0,0 +1,48 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type immUser struct {
+	ID        int64     `db:"id" norm:"primary_key,auto_increment"`
+	TenantID  int64     `db:"tenant_id" norm:"immutable"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at" norm:"immutable,default:now()"`
+}
+
+func TestRepo_ImmutableColumns(t *testing.T) {
+	rex := &recExec2{}
+	r := &repo[immUser]{kn: &KintsNorm{}, exec: rex}
+	_ = r.Update(context.Background(), &immUser{ID: 1, TenantID: 9, Name: "b"})
+	if rex.lastSQL != `UPDATE imm_users SET "name" = $1 WHERE "id" = $2` {
+		t.Fatalf("sql=%s", rex.lastSQL)
+	}
+
+	rex.lastSQL = ""
+	err := r.UpdatePartial(context.Background(), int64(1), map[string]any{"name": "c", "tenant_id": 2})
+	var oe *ORMError
+	if !errors.As(err, &oe) || oe.Code != ErrCodeValidation || rex.lastSQL != "" {
+		t.Fatalf("err=%v sql=%s", err, rex.lastSQL)
+	}
+	if err := r.Upsert(context.Background(), &immUser{Name: "d"}, []string{"name"}, []string{"created_at"}); err == nil {
+		t.Fatalf("expected immutable error from Upsert")
+	}
+	if err := r.UpsertBatch(context.Background(), []*immUser{{Name: "d"}}, []string{"name"}, []string{"tenant_id"}); err == nil {
+		t.Fatalf("expected immutable error from UpsertBatch")
+	}
+}
+
+func TestUpdateStructByPK_SkipsImmutableColumns(t *testing.T) {
+	ex := &recExecQB{}
+	qb := (&QueryBuilder{kn: &KintsNorm{}, exec: ex}).Table("imm_users")
+	if _, err := qb.UpdateStructByPK(context.Background(), &immUser{ID: 1, TenantID: 9, Name: "b"}, "id"); err != nil {
+		t.Fatal(err)
+	}
+	if ex.lastSQL != `UPDATE imm_users SET "name" = $1 WHERE "id" = $2` {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+}
@@ -18,8 +18,11 @@ func (r *recExecRepo) Exec(_ context.Context, sql string, args ...any) (pgconn.C
 	return pgconn.CommandTag{}, nil
 }
 
-// Satisfy dbExecuter for repository paths that do not call Query
-func (r *recExecRepo) Query(_ context.Context, _ string, _ ...any) (pgx.Rows, error) { return nil, nil }
+// Query records like Exec and returns no rows
+func (r *recExecRepo) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	r.lastSQL, r.lastArgs = sql, args
+	return &fakeRowsRU{}, nil
+}
 func (r *recExecRepo) QueryRow(_ context.Context, _ string, _ ...any) pgx.Row {
 	return errorRow{err: nil}
 }
@@ -0,0 +1,48 @@
+package norm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+type omitUser struct {
+	ID       int64   `db:"id" norm:"primary_key,auto_increment"`
+	Name     string  `db:"name"`
+	Nickname string  `db:"nickname,omitempty"`
+	Score    *int64  `db:"score,omitempty"`
+	Rank     float64 `db:",omitempty"`
+}
+
+func TestColumnName_IgnoresDBTagOptions(t *testing.T) {
+	m := core.StructMapper(reflect.TypeFor[omitUser]())
+	fi, ok := m.FieldsByColumn["nickname"]
+	if !ok || !fi.OmitEmpty || !m.FieldsByColumn["rank"].OmitEmpty || m.FieldsByColumn["name"].OmitEmpty {
+		t.Fatalf("mapping %+v", m.FieldsByColumn)
+	}
+}
+
+func TestRepo_OmitEmptyWrites(t *testing.T) {
+	rex := &recExec2{}
+	r := &repo[omitUser]{kn: &KintsNorm{}, exec: rex}
+	ctx := context.Background()
+
+	_ = r.Update(ctx, &omitUser{ID: 1, Name: ""})
+	if rex.lastSQL != `UPDATE omit_users SET "name" = $1 WHERE "id" = $2` {
+		t.Fatalf("update sql=%s", rex.lastSQL)
+	}
+	_ = r.Update(ctx, &omitUser{ID: 1, Name: "a", Nickname: "al"})
+	if rex.lastSQL != `UPDATE omit_users SET "name" = $1, "nickname" = $2 WHERE "id" = $3` {
+		t.Fatalf("update sql=%s", rex.lastSQL)
+	}
+	_ = r.Upsert(ctx, &omitUser{Name: "a"}, []string{"name"}, []string{"name"})
+	if want := `INSERT INTO omit_users ("name") VALUES ($1) ON CONFLICT ("name") DO UPDATE SET "name" = EXCLUDED."name"`; rex.lastSQL != want {
+		t.Fatalf("upsert sql=%s", rex.lastSQL)
+	}
+	values, args := insertValues([]*omitUser{{Name: "a"}, {Name: "b", Nickname: "bb"}}, writableFields(reflect.TypeFor[omitUser]()))
+	if values != "($1, DEFAULT, DEFAULT, DEFAULT), ($2, $3, DEFAULT, DEFAULT)" || len(args) != 3 {
+		t.Fatalf("values=%s args=%v", values, args)
+	}
+}
@@ -0,0 +1,49 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+type pageNote struct {
+	ID        int64  `db:"id" norm:"primary_key"`
+	CreatedAt string `db:"created_at"`
+}
+
+type pageLog struct {
+	Line string `db:"line"`
+}
+
+func pageSQL(t *testing.T, r interface {
+	FindPage(context.Context, PageRequest, ...Condition) (Page[pageNote], error)
+}, ex *seqExec, page PageRequest) string {
+	t.Helper()
+	ex.sqls, ex.results = nil, [][][]any{{{int64(0)}}}
+	if _, err := r.FindPage(context.Background(), page); err != nil {
+		t.Fatal(err)
+	}
+	return ex.sqls[len(ex.sqls)-1]
+}
+
+func TestRepo_FindPage_DefaultOrder(t *testing.T) {
+	ex := &seqExec{fields: []string{"count"}}
+	base := &repo[pageNote]{kn: &KintsNorm{}, exec: ex}
+	if sql := pageSQL(t, base, ex, PageRequest{Limit: 10}); !strings.HasSuffix(sql, `ORDER BY "id" ASC LIMIT 10`) {
+		t.Fatalf("primary key fallback: %s", sql)
+	}
+	byDate := base.DefaultOrder("created_at DESC, id DESC")
+	if sql := pageSQL(t, byDate, ex, PageRequest{Limit: 10}); !strings.HasSuffix(sql, "ORDER BY created_at DESC, id DESC LIMIT 10") {
+		t.Fatalf("default order: %s", sql)
+	}
+	if sql := pageSQL(t, byDate, ex, PageRequest{Limit: 10, OrderBy: "id ASC"}); !strings.HasSuffix(sql, "ORDER BY id ASC LIMIT 10") {
+		t.Fatalf("request order: %s", sql)
+	}
+	ro := (&readOnlyRepo[pageNote]{r: base}).DefaultOrder("id DESC")
+	if sql := pageSQL(t, ro, ex, PageRequest{}); !strings.HasSuffix(sql, "ORDER BY id DESC") {
+		t.Fatalf("read-only: %s", sql)
+	}
+	if got := (&repo[pageLog]{}).pageOrder(PageRequest{}); got != "" {
+		t.Fatalf("no primary key: %q", got)
+	}
+}
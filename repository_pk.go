@@ -0,0 +1,155 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/kintsdev/norm/internal/core"
+)
+
+// keyColumns returns the primary key columns of mapping, defaulting to id
+func keyColumns(mapping core.StructMapping) []string {
+	if len(mapping.PrimaryColumns) > 0 {
+		return mapping.PrimaryColumns
+	}
+	return []string{"id"}
+}
+
+// keyValues splits id into one value per key column. A single-column key takes id as is; a composite
+// key takes a []any in column order or a map[string]any keyed by column name.
+func keyValues(cols []string, id any) ([]any, error) {
+	missing := &ORMError{Code: ErrCodeValidation, Message: "missing primary key value"}
+	if len(cols) == 1 {
+		if isMissingKey(id) {
+			return nil, missing
+		}
+		return []any{id}, nil
+	}
+	switch v := id.(type) {
+	case []any:
+		if len(v) != len(cols) {
+			return nil, &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("composite primary key (%s) needs %d values, got %d", strings.Join(cols, ", "), len(cols), len(v))}
+		}
+		if slices.ContainsFunc(v, isMissingKey) {
+			return nil, missing
+		}
+		return v, nil
+	case map[string]any:
+		out := make([]any, len(cols))
+		for i, c := range cols {
+			val, ok := v[c]
+			if !ok || isMissingKey(val) {
+				return nil, &ORMError{Code: ErrCodeValidation, Message: "missing primary key value: " + c}
+			}
+			out[i] = val
+		}
+		return out, nil
+	case nil:
+		return nil, missing
+	}
+	return nil, &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("composite primary key (%s) takes []any or map[string]any, got %T", strings.Join(cols, ", "), id)}
+}
+
+// isMissingKey reports whether a key value is absent: nil, a nil pointer or the zero value of its type
+// (0, "", a zero UUID), which no stored row can be addressed by
+func isMissingKey(v any) bool {
+	if v == nil {
+		return true
+	}
+	return reflect.ValueOf(v).IsZero()
+}
+
+// keyPredicate renders "a = $n AND b = $n+1" for the key columns, numbering from n
+func keyPredicate(cols []string, n int) string {
+	parts := make([]string, len(cols))
+	for i, c := range cols {
+		parts[i] = fmt.Sprintf("%s = $%d", c, n+i)
+	}
+	return strings.Join(parts, " AND ")
+}
+
+// keyCondition matches the row whose key columns equal vals
+func keyCondition(cols []string, vals []any) Condition {
+	if len(cols) == 1 {
+		return Eq(quoteColumn(cols[0]), vals[0])
+	}
+	return TupleEq(quoteColumns(cols), vals)
+}
+
+// FindByPK is GetByID taking the key values as arguments, in the order of the key columns:
+// users.FindByPK(ctx, id) or lines.FindByPK(ctx, orderID, lineNo) for a composite key
+func (r *repo[T]) FindByPK(ctx context.Context, key ...any) (*T, error) {
+	if len(key) == 1 {
+		return r.GetByID(ctx, key[0])
+	}
+	return r.GetByID(ctx, key)
+}
+
+// GetByIDs loads the rows with the given ids in one query. The result is aligned with ids: out[i] is the
+// row of ids[i], or nil when there is no such row (or it is filtered out by the soft-delete mode or scopes).
+// Composite keys are given like GetByID's, as []any or map[string]any values.
+func (r *repo[T]) GetByIDs(ctx context.Context, ids []any) ([]*T, error) {
+	out := make([]*T, len(ids))
+	if len(ids) == 0 {
+		return out, nil
+	}
+	typ := reflect.TypeFor[T]()
+	mapping := core.StructMapper(typ)
+	cols := keyColumns(mapping)
+	keys := make([][]any, len(ids))
+	for i, id := range ids {
+		vals, err := keyValues(cols, id)
+		if err != nil {
+			return nil, err
+		}
+		keys[i] = vals
+	}
+	var cond Condition
+	if len(cols) == 1 {
+		flat := make([]any, len(keys))
+		for i, k := range keys {
+			flat[i] = k[0]
+		}
+		cond = In(quoteColumn(cols[0]), flat)
+	} else {
+		cond = TupleIn(quoteColumns(cols), keys)
+	}
+	rows, err := r.Find(ctx, cond)
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]core.StructFieldInfo, len(cols))
+	for i, c := range cols {
+		fi, ok := mapping.FieldsByColumn[strings.ToLower(c)]
+		if !ok {
+			return nil, &ORMError{Code: ErrCodeInvalidColumn, Message: fmt.Sprintf("primary key column not found in %s: %s", typ.Name(), c)}
+		}
+		fields[i] = fi
+	}
+	byKey := make(map[string]*T, len(rows))
+	for _, row := range rows {
+		v := reflect.ValueOf(row).Elem()
+		vals := make([]any, len(fields))
+		for i, fi := range fields {
+			vals[i] = v.FieldByIndex(fi.Index).Interface()
+		}
+		byKey[printedKey(vals)] = row
+	}
+	for i, k := range keys {
+		out[i] = byKey[printedKey(k)]
+	}
+	return out, nil
+}
+
+// printedKey renders key values for matching; ids may be typed differently from the key fields (int vs
+// int64), so they are compared on their printed form
+func printedKey(vals []any) string {
+	parts := make([]string, len(vals))
+	for i, v := range vals {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,119 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type orderLine struct {
+	OrderID int64  `db:"order_id" norm:"primary_key:pk"`
+	LineNo  int    `db:"line_no" norm:"primary_key:pk"`
+	SKU     string `db:"sku"`
+}
+
+func TestGetByIDs_SingleKeyAlignedWithIDs(t *testing.T) {
+	f := &fakeExec{fields: []string{"id", "name"}, rows: [][]any{{int64(3), "c"}, {int64(1), "a"}}}
+	r := NewRepositoryWithExecutor[repUser](&KintsNorm{}, f)
+	got, err := r.GetByIDs(context.Background(), []any{1, 2, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.lastSQL != "SELECT * FROM rep_users WHERE id IN ($1, $2, $3)" {
+		t.Fatalf("sql=%s", f.lastSQL)
+	}
+	if len(got) != 3 || got[0].Name != "a" || got[1] != nil || got[2].Name != "c" {
+		t.Fatalf("got %+v", got)
+	}
+}
+
+func TestCompositeKey_GetByIDsAndFindByPK(t *testing.T) {
+	f := &fakeExec{fields: []string{"order_id", "line_no", "sku"}, rows: [][]any{{int64(7), int32(2), "b"}}}
+	r := NewRepositoryWithExecutor[orderLine](&KintsNorm{}, f)
+	got, err := r.GetByIDs(context.Background(), []any{[]any{int64(7), 1}, map[string]any{"order_id": 7, "line_no": 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.lastSQL != "SELECT * FROM order_lines WHERE (order_id, line_no) IN (($1, $2), ($3, $4))" {
+		t.Fatalf("sql=%s", f.lastSQL)
+	}
+	if got[0] != nil || got[1] == nil || got[1].SKU != "b" {
+		t.Fatalf("got %+v", got)
+	}
+
+	if _, err := r.FindByPK(context.Background(), int64(7), 2); err != nil {
+		t.Fatal(err)
+	}
+	if f.lastSQL != "SELECT * FROM order_lines WHERE (order_id, line_no) = ($1, $2) LIMIT 1" || len(f.lastArgs) != 2 {
+		t.Fatalf("sql=%s args=%v", f.lastSQL, f.lastArgs)
+	}
+	var oe *ORMError
+	if _, err := r.GetByID(context.Background(), int64(7)); !errors.As(err, &oe) || oe.Code != ErrCodeValidation {
+		t.Fatalf("single value for composite key: %v", err)
+	}
+}
+
+func TestCompositeKey_UpdateAndDelete(t *testing.T) {
+	ex := &recExecRepo{}
+	r := NewRepositoryWithExecutor[orderLine](&KintsNorm{}, ex)
+	if err := r.Update(context.Background(), &orderLine{OrderID: 7, LineNo: 2, SKU: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if ex.lastSQL != `UPDATE order_lines SET "sku" = $1 WHERE "order_id" = $2 AND "line_no" = $3` || len(ex.lastArgs) != 3 {
+		t.Fatalf("sql=%s args=%v", ex.lastSQL, ex.lastArgs)
+	}
+	if err := r.Delete(context.Background(), []any{int64(7), 2}); err != nil {
+		t.Fatal(err)
+	}
+	if ex.lastSQL != "DELETE FROM order_lines WHERE order_id = $1 AND line_no = $2" || len(ex.lastArgs) != 2 {
+		t.Fatalf("sql=%s args=%v", ex.lastSQL, ex.lastArgs)
+	}
+}
+
+type uuidDoc struct {
+	UUID      string  `db:"uuid" norm:"primary_key"`
+	Title     string  `db:"title"`
+	DeletedAt *string `db:"deleted_at"`
+}
+
+func TestNonIDKey_PartialUpdateSoftDeleteRestore(t *testing.T) {
+	ex := &recExecRepo{}
+	r := NewRepositoryWithExecutor[uuidDoc](&KintsNorm{}, ex)
+	ctx := context.Background()
+	const id = "0b58a9d8-3c9f-4a43-9a9e-6d2f0f6c1d11"
+	if err := r.UpdatePartial(ctx, id, map[string]any{"title": "t"}); err != nil {
+		t.Fatal(err)
+	}
+	if ex.lastSQL != `UPDATE uuid_docs SET "title" = $1 WHERE "uuid" = $2` || ex.lastArgs[1] != id {
+		t.Fatalf("sql=%s args=%v", ex.lastSQL, ex.lastArgs)
+	}
+	if err := r.SoftDelete(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if ex.lastSQL != "UPDATE uuid_docs SET deleted_at = NOW() WHERE uuid = $1" {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+	if err := r.Restore(ctx, id); err != nil {
+		t.Fatal(err)
+	}
+	if ex.lastSQL != "UPDATE uuid_docs SET deleted_at = NULL WHERE uuid = $1" {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+
+	ex.lastSQL = ""
+	var oe *ORMError
+	for name, err := range map[string]error{
+		"update partial": r.UpdatePartial(ctx, "", map[string]any{"title": "t"}),
+		"soft delete":    r.SoftDelete(ctx, nil),
+		"restore":        r.Restore(ctx, ""),
+		"delete":         r.Delete(ctx, nil),
+		"update":         r.Update(ctx, &uuidDoc{Title: "t"}),
+	} {
+		if !errors.As(err, &oe) || oe.Code != ErrCodeValidation {
+			t.Fatalf("%s without key: %v", name, err)
+		}
+	}
+	if ex.lastSQL != "" {
+		t.Fatalf("statement sent without key: %s", ex.lastSQL)
+	}
+}
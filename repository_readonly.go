@@ -0,0 +1,90 @@
+package norm
+
+import (
+	"context"
+	"iter"
+	"time"
+)
+
+// ReadOnlyRepository exposes only the read operations of Repository for type T.
+// Use it in reporting/analytics code to make mutations impossible at compile time.
+type ReadOnlyRepository[T any] interface {
+	GetByID(ctx context.Context, id any) (*T, error)
+	Find(ctx context.Context, conditions ...Condition) ([]*T, error)
+	FindOne(ctx context.Context, conditions ...Condition) (*T, error)
+	FindEach(ctx context.Context, fn func(row *T) error, conditions ...Condition) error
+	Stream(ctx context.Context, conditions ...Condition) iter.Seq2[*T, error]
+	Count(ctx context.Context, conditions ...Condition) (int64, error)
+	Exists(ctx context.Context, conditions ...Condition) (bool, error)
+	FindPage(ctx context.Context, page PageRequest, conditions ...Condition) (Page[T], error)
+	WithTrashed() ReadOnlyRepository[T]
+	OnlyTrashed() ReadOnlyRepository[T]
+	Scoped(scopes ...Scope) ReadOnlyRepository[T]
+	DefaultOrder(orderBy string) ReadOnlyRepository[T]
+	Cached(ttl time.Duration) ReadOnlyRepository[T]
+}
+
+// readOnlyRepo wraps repo and forwards read methods only
+type readOnlyRepo[T any] struct{ r *repo[T] }
+
+// NewReadOnlyRepository creates a read-only repository routed to the read pool (falls back to primary)
+func NewReadOnlyRepository[T any](kn *KintsNorm, opts ...RepositoryOption) ReadOnlyRepository[T] {
+	o := applyRepoOptions(opts)
+	return &readOnlyRepo[T]{r: &repo[T]{kn: kn, exec: withTimeout(wrapExec(kn, kn.ReadPool()), o.timeout), schema: o.schema, timeout: o.timeout}}
+}
+
+func (ro *readOnlyRepo[T]) GetByID(ctx context.Context, id any) (*T, error) {
+	return ro.r.GetByID(ctx, id)
+}
+
+func (ro *readOnlyRepo[T]) Find(ctx context.Context, conditions ...Condition) ([]*T, error) {
+	return ro.r.Find(ctx, conditions...)
+}
+
+func (ro *readOnlyRepo[T]) FindOne(ctx context.Context, conditions ...Condition) (*T, error) {
+	return ro.r.FindOne(ctx, conditions...)
+}
+
+func (ro *readOnlyRepo[T]) FindEach(ctx context.Context, fn func(row *T) error, conditions ...Condition) error {
+	return ro.r.FindEach(ctx, fn, conditions...)
+}
+
+func (ro *readOnlyRepo[T]) Stream(ctx context.Context, conditions ...Condition) iter.Seq2[*T, error] {
+	return ro.r.Stream(ctx, conditions...)
+}
+
+func (ro *readOnlyRepo[T]) Count(ctx context.Context, conditions ...Condition) (int64, error) {
+	return ro.r.Count(ctx, conditions...)
+}
+
+func (ro *readOnlyRepo[T]) Exists(ctx context.Context, conditions ...Condition) (bool, error) {
+	return ro.r.Exists(ctx, conditions...)
+}
+
+func (ro *readOnlyRepo[T]) FindPage(ctx context.Context, page PageRequest, conditions ...Condition) (Page[T], error) {
+	return ro.r.FindPage(ctx, page, conditions...)
+}
+
+func (ro *readOnlyRepo[T]) WithTrashed() ReadOnlyRepository[T] {
+	nr := *ro.r
+	nr.mode = softModeWithTrashed
+	return &readOnlyRepo[T]{r: &nr}
+}
+
+func (ro *readOnlyRepo[T]) OnlyTrashed() ReadOnlyRepository[T] {
+	nr := *ro.r
+	nr.mode = softModeOnlyTrashed
+	return &readOnlyRepo[T]{r: &nr}
+}
+
+func (ro *readOnlyRepo[T]) Scoped(scopes ...Scope) ReadOnlyRepository[T] {
+	return &readOnlyRepo[T]{r: ro.r.Scoped(scopes...).(*repo[T])}
+}
+
+func (ro *readOnlyRepo[T]) DefaultOrder(orderBy string) ReadOnlyRepository[T] {
+	return &readOnlyRepo[T]{r: ro.r.DefaultOrder(orderBy).(*repo[T])}
+}
+
+func (ro *readOnlyRepo[T]) Cached(ttl time.Duration) ReadOnlyRepository[T] {
+	return &readOnlyRepo[T]{r: ro.r.Cached(ttl).(*repo[T])}
+}
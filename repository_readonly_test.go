@@ -0,0 +1,30 @@
+package norm
+
+import (
+	"context"
+	"testing"
+)
+
+type roItem struct {
+	ID        int64  `db:"id"`
+	Name      string `db:"name"`
+	DeletedAt *int64 `db:"deleted_at"`
+}
+
+func TestReadOnlyRepository_UsesRepoExecutor(t *testing.T) {
+	f := &fakeExecRU{rows: [][]any{{int64(1), "a", nil}}, fields: []string{"id", "name", "deleted_at"}}
+	var ro ReadOnlyRepository[roItem] = &readOnlyRepo[roItem]{r: &repo[roItem]{kn: &KintsNorm{}, exec: f}}
+	items, err := ro.Find(context.Background(), Eq("name", "a"))
+	if err != nil || len(items) != 1 || items[0].Name != "a" {
+		t.Fatalf("items=%v err=%v", items, err)
+	}
+	if f.lastSQL != "SELECT * FROM ro_items WHERE name = $1 AND deleted_at IS NULL" {
+		t.Fatalf("sql=%s", f.lastSQL)
+	}
+	if _, err := ro.OnlyTrashed().Find(context.Background()); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if f.lastSQL != "SELECT * FROM ro_items WHERE deleted_at IS NOT NULL" {
+		t.Fatalf("sql=%s", f.lastSQL)
+	}
+}
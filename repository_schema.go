@@ -0,0 +1,27 @@
+package norm
+
+import "time"
+
+// RepositoryOption configures a repository at construction time
+type RepositoryOption func(*repoOptions)
+
+type repoOptions struct {
+	schema  string
+	timeout time.Duration
+}
+
+// InSchema binds the repository to a schema: its statements target schema.table, overriding a
+// `norm:"schema:..."` model tag. Useful when the same model lives in several schemas.
+func InSchema(schema string) RepositoryOption {
+	return func(o *repoOptions) { o.schema = schema }
+}
+
+func applyRepoOptions(opts []RepositoryOption) repoOptions {
+	var o repoOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&o)
+		}
+	}
+	return o
+}
@@ -0,0 +1,95 @@
+package norm
+
+import (
+	"context"
+	"iter"
+	"reflect"
+	"strings"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// defaultStreamPageSize is the number of rows Stream fetches per keyset page
+const defaultStreamPageSize = 500
+
+// Stream returns an iterator over matching rows that pages through the table by primary key
+// (keyset pagination, defaultStreamPageSize rows per query), so no connection is held between pages
+// and preloads are applied per page. Iteration stops when ctx is cancelled, yielding ctx.Err() last.
+// Models without a primary key fall back to a single streamed query.
+//
+//	for u, err := range repo.Stream(ctx, norm.Eq("is_active", true)) { ... }
+func (r *repo[T]) Stream(ctx context.Context, conditions ...Condition) iter.Seq2[*T, error] {
+	return func(yield func(*T, error) bool) {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		newQuery := func() *QueryBuilder {
+			qb := r.scopedQuery()
+			for _, c := range conditions {
+				qb = qb.Where(c.Expr, c.Args...)
+			}
+			var t T
+			if core.ModelHasSoftDelete(reflect.TypeOf(t)) {
+				switch r.mode {
+				case softModeOnlyTrashed:
+					qb = qb.Where("deleted_at IS NOT NULL")
+				case softModeWithTrashed:
+					// no filter
+				default:
+					qb = qb.Where("deleted_at IS NULL")
+				}
+			}
+			return qb
+		}
+		mapping := core.StructMapper(reflect.TypeFor[T]())
+		pkField, ok := mapping.FieldsByColumn[strings.ToLower(mapping.PrimaryColumn)]
+		if mapping.PrimaryColumn == "" || !ok {
+			for row, err := range Iterate[T](ctx, newQuery()) {
+				if err == nil {
+					err = ctx.Err()
+				}
+				if !yield(row, err) || err != nil {
+					return
+				}
+			}
+			return
+		}
+		var last any
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+			qb := newQuery().OrderBy(quoteColumn(mapping.PrimaryColumn) + " ASC").Limit(defaultStreamPageSize)
+			if last != nil {
+				qb = qb.After(mapping.PrimaryColumn, last)
+			}
+			var page []T
+			if err := qb.Find(ctx, &page); err != nil {
+				yield(nil, err)
+				return
+			}
+			rows := make([]*T, len(page))
+			for i := range page {
+				rows[i] = &page[i]
+			}
+			if err := r.applyPreloads(ctx, rows); err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, row := range rows {
+				if err := ctx.Err(); err != nil {
+					yield(nil, err)
+					return
+				}
+				if !yield(row, nil) {
+					return
+				}
+			}
+			if len(page) < defaultStreamPageSize {
+				return
+			}
+			last = reflect.ValueOf(&page[len(page)-1]).Elem().FieldByIndex(pkField.Index).Interface()
+		}
+	}
+}
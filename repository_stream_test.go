@@ -0,0 +1,52 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRepo_Stream_PagesByPrimaryKey(t *testing.T) {
+	first := make([][]any, defaultStreamPageSize)
+	for i := range first {
+		first[i] = []any{int64(i + 1), "t"}
+	}
+	ex := &seqExec{fields: []string{"id", "name"}, results: [][][]any{first, {{int64(defaultStreamPageSize + 1), "last"}}}}
+	r := &repo[ensureTag]{kn: &KintsNorm{}, exec: ex}
+	n := 0
+	var lastName string
+	for tag, err := range r.Stream(context.Background(), Eq("name", "t")) {
+		if err != nil {
+			t.Fatalf("err=%v", err)
+		}
+		n++
+		lastName = tag.Name
+	}
+	if n != defaultStreamPageSize+1 || lastName != "last" {
+		t.Fatalf("n=%d last=%q", n, lastName)
+	}
+	if len(ex.sqls) != 2 || !strings.Contains(ex.sqls[0], "ORDER BY id ASC LIMIT 500") || !strings.Contains(ex.sqls[1], `"id" > $2`) {
+		t.Fatalf("sqls=%v", ex.sqls)
+	}
+}
+
+func TestRepo_Stream_StopsOnCancel(t *testing.T) {
+	ex := &seqExec{fields: []string{"id", "name"}, results: [][][]any{{{int64(1), "a"}, {int64(2), "b"}}}}
+	r := &repo[ensureTag]{kn: &KintsNorm{}, exec: ex}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var got []int64
+	var gotErr error
+	for tag, err := range r.Stream(ctx) {
+		if err != nil {
+			gotErr = err
+			break
+		}
+		got = append(got, tag.ID)
+		cancel()
+	}
+	if len(got) != 1 || !errors.Is(gotErr, context.Canceled) {
+		t.Fatalf("got=%v err=%v", got, gotErr)
+	}
+}
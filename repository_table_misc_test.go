@@ -1,6 +1,8 @@
 package norm
 
 import (
+	"context"
+	"strings"
 	"testing"
 )
 
@@ -15,3 +17,52 @@ func TestRepo_TableNamePluralization(t *testing.T) {
 		t.Fatalf("table: %s", r.tableName())
 	}
 }
+
+type tagPerson struct {
+	_    struct{} `norm:"table:people"`
+	ID   int64    `db:"id" norm:"primary_key,auto_increment"`
+	Name string   `db:"name"`
+}
+
+func TestRepo_TableTag(t *testing.T) {
+	ex := &recExecRepo{}
+	r := &repo[tagPerson]{kn: &KintsNorm{}, exec: ex}
+	if _, err := r.Find(context.Background(), Eq("name", "a")); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if ex.lastSQL != "SELECT * FROM people WHERE name = $1" {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+	if err := r.CreateBatch(context.Background(), []*tagPerson{{Name: "a"}}); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if !strings.HasPrefix(ex.lastSQL, `INSERT INTO people ("name")`) {
+		t.Fatalf("insert sql=%s", ex.lastSQL)
+	}
+}
+
+type schemaInvoice struct {
+	_  struct{} `norm:"schema:billing"`
+	ID int64    `db:"id" norm:"primary_key,auto_increment"`
+}
+
+func TestRepo_Schema(t *testing.T) {
+	ex := &recExecRepo{}
+	r := NewRepositoryWithExecutor[schemaInvoice](&KintsNorm{}, ex)
+	if _, err := r.Find(context.Background()); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if ex.lastSQL != "SELECT * FROM billing.schema_invoices" {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+	r = NewRepositoryWithExecutor[schemaInvoice](&KintsNorm{}, ex, InSchema("tenant_7"))
+	if _, err := r.Find(context.Background()); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if ex.lastSQL != "SELECT * FROM tenant_7.schema_invoices" {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+	if got := (&repo[simpleModel]{schema: "archive"}).tableName(); got != "archive.simple_models" {
+		t.Fatalf("table=%s", got)
+	}
+}
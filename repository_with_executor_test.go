@@ -0,0 +1,28 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestRepo_WithExecutorKeepsModeAndBreaker(t *testing.T) {
+	kn := &KintsNorm{breaker: newCircuitBreaker(circuitBreakerConfig{failureThreshold: 5})}
+	base := NewRepository[softUser](kn).OnlyTrashed().Preload("X")
+	ex := &recExec{}
+	r := base.WithExecutor(ex).(*repo[softUser])
+	if r.mode != softModeOnlyTrashed || len(r.preloads) != 1 {
+		t.Fatalf("mode/preloads lost: %+v", r)
+	}
+	if b, ok := r.exec.(breakerExecuter); !ok || b.exec != ex {
+		t.Fatalf("executor not breaker-wrapped: %T", r.exec)
+	}
+	_, _ = r.Find(context.Background())
+	if !strings.Contains(ex.lastSQL, "deleted_at IS NOT NULL") {
+		t.Fatalf("sql=%s", ex.lastSQL)
+	}
+	// already wrapped executors are not wrapped twice
+	if w := withBreaker(kn, r.exec); w != r.exec {
+		t.Fatalf("double wrap")
+	}
+}
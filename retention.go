@@ -0,0 +1,243 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	core "github.com/kintsdev/norm/internal/core"
+)
+
+// retentionBatchSize bounds the rows removed per DELETE so cleanup never holds long locks
+const retentionBatchSize = 10000
+
+// RetentionPolicy removes rows whose Column is older than MaxAge from Table.
+// Declare it on a model with a blank field: _ struct{} `norm_table:"retention:90d,column:created_at"`.
+type RetentionPolicy struct {
+	Table  string
+	Column string
+	MaxAge time.Duration
+}
+
+// RetentionResult reports what ApplyRetention removed from one table
+type RetentionResult struct {
+	Table             string
+	Deleted           int64
+	DroppedPartitions []string
+}
+
+type retentionRegistry struct {
+	mu       sync.Mutex
+	policies []RetentionPolicy
+}
+
+func (r *retentionRegistry) add(ps ...RetentionPolicy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range ps {
+		replaced := false
+		for i := range r.policies {
+			if r.policies[i].Table == p.Table {
+				r.policies[i], replaced = p, true
+			}
+		}
+		if !replaced {
+			r.policies = append(r.policies, p)
+		}
+	}
+}
+
+func (r *retentionRegistry) list() []RetentionPolicy {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RetentionPolicy(nil), r.policies...)
+}
+
+// RetentionPolicies parses the norm_table retention tags of models; models without one are skipped
+func RetentionPolicies(models ...any) ([]RetentionPolicy, error) {
+	var out []RetentionPolicy
+	for _, m := range models {
+		t := reflect.TypeOf(m)
+		if t == nil {
+			continue
+		}
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct {
+			continue
+		}
+		for _, f := range core.Fields(t) {
+			tag, ok := f.Tag.Lookup("norm_table")
+			if !ok {
+				continue
+			}
+			p, found, err := parseRetentionTag(tag)
+			if err != nil {
+				return nil, &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("%s: %v", t.Name(), err), Internal: err}
+			}
+			if found {
+				p.Table = core.Qualify(core.SchemaName(t), core.TableNameOf(m))
+				out = append(out, p)
+			}
+		}
+	}
+	return out, nil
+}
+
+func parseRetentionTag(tag string) (RetentionPolicy, bool, error) {
+	var p RetentionPolicy
+	found := false
+	for tok := range strings.SplitSeq(tag, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(tok), ":")
+		switch strings.ToLower(k) {
+		case "retention":
+			d, err := parseRetentionAge(v)
+			if err != nil {
+				return p, false, err
+			}
+			p.MaxAge, found = d, true
+		case "column":
+			p.Column = strings.TrimSpace(v)
+		}
+	}
+	if found && p.Column == "" {
+		p.Column = "created_at"
+	}
+	return p, found, nil
+}
+
+// parseRetentionAge accepts Go durations plus day (d) and week (w) suffixes, e.g. 90d, 2w, 36h
+func parseRetentionAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit > 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid retention %q", s)
+		}
+		return time.Duration(n) * unit, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid retention %q", s)
+	}
+	return d, nil
+}
+
+// RegisterRetention records the retention policies of models for ApplyRetention; AutoMigrate registers its models too
+func (kn *KintsNorm) RegisterRetention(models ...any) error {
+	ps, err := RetentionPolicies(models...)
+	if err != nil {
+		return err
+	}
+	kn.retention.add(ps...)
+	return nil
+}
+
+// ApplyRetention enforces every registered retention policy. On partitioned tables, partitions lying entirely
+// before the cutoff are dropped first; remaining expired rows are deleted in batches.
+func (kn *KintsNorm) ApplyRetention(ctx context.Context) ([]RetentionResult, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return applyRetention(ctx, wrapExec(kn, kn.pool), kn.retention.list(), time.Now())
+}
+
+func applyRetention(ctx context.Context, exec dbExecuter, policies []RetentionPolicy, now time.Time) ([]RetentionResult, error) {
+	out := make([]RetentionResult, 0, len(policies))
+	for _, p := range policies {
+		cutoff := now.Add(-p.MaxAge)
+		res := RetentionResult{Table: p.Table}
+		dropped, err := dropExpiredPartitions(ctx, exec, p.Table, cutoff)
+		if err != nil {
+			return out, err
+		}
+		res.DroppedPartitions = dropped
+		table, col := quoteQualified(p.Table), QuoteIdentifier(p.Column)
+		// the outer predicate keeps ctid matches from other partitions of a partitioned table out of scope
+		q := fmt.Sprintf("DELETE FROM %s WHERE %s < $1 AND ctid IN (SELECT ctid FROM %s WHERE %s < $1 LIMIT %d)", table, col, table, col, retentionBatchSize)
+		for {
+			tag, err := exec.Exec(ctx, q, cutoff)
+			if err != nil {
+				return out, wrapPgError(err, q, []any{cutoff})
+			}
+			res.Deleted += tag.RowsAffected()
+			if tag.RowsAffected() < retentionBatchSize {
+				break
+			}
+		}
+		out = append(out, res)
+	}
+	return out, nil
+}
+
+var partitionUpperBound = regexp.MustCompile(`TO \('([^']+)'\)`)
+
+// dropExpiredPartitions drops range partitions of table whose upper bound is not after cutoff
+func dropExpiredPartitions(ctx context.Context, exec dbExecuter, table string, cutoff time.Time) ([]string, error) {
+	const q = `SELECT c.oid::regclass::text, pg_get_expr(c.relpartbound, c.oid) FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid WHERE i.inhparent = to_regclass($1)`
+	rows, err := exec.Query(ctx, q, table)
+	if err != nil {
+		return nil, wrapPgError(err, q, []any{table})
+	}
+	var expired []string
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			rows.Close()
+			return nil, wrapPgError(err, q, []any{table})
+		}
+		name, _ := vals[0].(string)
+		bound, _ := vals[1].(string)
+		if m := partitionUpperBound.FindStringSubmatch(bound); m != nil {
+			if upper, ok := parsePartitionBound(m[1]); ok && !upper.After(cutoff) {
+				expired = append(expired, name)
+			}
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, wrapPgError(err, q, []any{table})
+	}
+	for _, name := range expired {
+		// name is already quoted as needed by regclass::text
+		drop := "DROP TABLE " + name
+		if _, err := exec.Exec(ctx, drop); err != nil {
+			return nil, wrapPgError(err, drop, nil)
+		}
+	}
+	return expired, nil
+}
+
+func parsePartitionBound(s string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02 15:04:05-07", "2006-01-02 15:04:05.999999-07", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// RetentionCronSQL renders pg_cron jobs (one cron.schedule call per policy) running the retention DELETE on schedule,
+// for deployments that prefer cleanup inside the database. Partition dropping is not included.
+func RetentionCronSQL(schedule string, policies ...RetentionPolicy) []string {
+	out := make([]string, 0, len(policies))
+	for _, p := range policies {
+		job := "norm_retention_" + strings.ReplaceAll(p.Table, ".", "_")
+		del := fmt.Sprintf("DELETE FROM %s WHERE %s < now() - interval '%d seconds'", quoteQualified(p.Table), QuoteIdentifier(p.Column), int64(p.MaxAge/time.Second))
+		out = append(out, fmt.Sprintf("SELECT cron.schedule(%s, %s, %s)", quoteSessionValue(job), quoteSessionValue(schedule), "$norm$"+del+"$norm$"))
+	}
+	return out
+}
@@ -0,0 +1,95 @@
+package norm
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type retainedEvent struct {
+	_  struct{} `norm_table:"retention:90d,column:occurred_at"`
+	ID int64    `db:"id" norm:"primary_key"`
+}
+
+type auditRow struct {
+	_ struct{} `norm_table:"retention:36h"`
+}
+
+func (auditRow) TableName() string { return "ops.audit" }
+
+func TestRetentionPolicies_ParsesTags(t *testing.T) {
+	ps, err := RetentionPolicies(&retainedEvent{}, auditRow{}, &streamUser{})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if len(ps) != 2 {
+		t.Fatalf("policies=%v", ps)
+	}
+	if ps[0] != (RetentionPolicy{Table: "retained_events", Column: "occurred_at", MaxAge: 90 * 24 * time.Hour}) {
+		t.Fatalf("p0=%+v", ps[0])
+	}
+	if ps[1] != (RetentionPolicy{Table: "ops.audit", Column: "created_at", MaxAge: 36 * time.Hour}) {
+		t.Fatalf("p1=%+v", ps[1])
+	}
+	type bad struct {
+		_ struct{} `norm_table:"retention:soon"`
+	}
+	if _, err := RetentionPolicies(bad{}); err == nil {
+		t.Fatalf("expected error for invalid retention")
+	}
+}
+
+// retentionExec serves one partition listing and counts deleted rows per batch
+type retentionExec struct {
+	partitions [][]any
+	batches    []int64
+	execs      []string
+}
+
+func (e *retentionExec) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	e.execs = append(e.execs, sql)
+	if strings.HasPrefix(sql, "DELETE") && len(e.batches) > 0 {
+		n := e.batches[0]
+		e.batches = e.batches[1:]
+		return pgconn.NewCommandTag("DELETE " + strconv.FormatInt(n, 10)), nil
+	}
+	return pgconn.NewCommandTag("DELETE 0"), nil
+}
+func (e *retentionExec) Query(_ context.Context, _ string, _ ...any) (pgx.Rows, error) {
+	return &fakeRowsRU{rows: e.partitions, fields: []string{"name", "bound"}}, nil
+}
+func (e *retentionExec) QueryRow(_ context.Context, _ string, _ ...any) pgx.Row { return errorRow{} }
+
+func TestApplyRetention_DropsPartitionsThenDeletesInBatches(t *testing.T) {
+	now := time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC)
+	ex := &retentionExec{
+		partitions: [][]any{
+			{"events_2026_01", "FOR VALUES FROM ('2026-01-01 00:00:00+00') TO ('2026-02-01 00:00:00+00')"},
+			{"events_2026_06", "FOR VALUES FROM ('2026-06-01 00:00:00+00') TO ('2026-07-01 00:00:00+00')"},
+		},
+		batches: []int64{retentionBatchSize, 7},
+	}
+	res, err := applyRetention(context.Background(), ex, []RetentionPolicy{{Table: "events", Column: "created_at", MaxAge: 90 * 24 * time.Hour}}, now)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if len(res) != 1 || res[0].Deleted != retentionBatchSize+7 || len(res[0].DroppedPartitions) != 1 || res[0].DroppedPartitions[0] != "events_2026_01" {
+		t.Fatalf("res=%+v", res)
+	}
+	if ex.execs[0] != "DROP TABLE events_2026_01" || !strings.Contains(ex.execs[1], `DELETE FROM "events" WHERE "created_at" < $1 AND ctid IN`) {
+		t.Fatalf("execs=%v", ex.execs)
+	}
+}
+
+func TestRetentionCronSQL(t *testing.T) {
+	out := RetentionCronSQL("0 3 * * *", RetentionPolicy{Table: "ops.audit", Column: "created_at", MaxAge: time.Hour})
+	want := `SELECT cron.schedule('norm_retention_ops_audit', '0 3 * * *', $norm$DELETE FROM "ops"."audit" WHERE "created_at" < now() - interval '3600 seconds'$norm$)`
+	if len(out) != 1 || out[0] != want {
+		t.Fatalf("got %v", out)
+	}
+}
@@ -3,12 +3,20 @@ package norm
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"maps"
+	"slices"
 	"strings"
+
+	"github.com/jackc/pgx/v5"
 )
 
 // SetSessionVar sets a PostgreSQL session variable (e.g., `SET app.current_user = 'user123'`).
 // Useful for Row-Level Security (RLS) policies that reference session variables.
 // The value is properly quoted to prevent injection.
+//
+// Deprecated: the variable is set on an arbitrary pooled connection, not necessarily the one running the
+// next query. Use AcquireRLS or WithRLS instead.
 func (kn *KintsNorm) SetSessionVar(ctx context.Context, key, value string) error {
 	query := fmt.Sprintf("SET %s = %s", quoteSessionKey(key), quoteSessionValue(value))
 	_, err := kn.pool.Exec(ctx, query)
@@ -30,6 +38,8 @@ func (kn *KintsNorm) ResetSessionVar(ctx context.Context, key string) error {
 
 // SetRole executes `SET ROLE <role>` to switch the current session role.
 // Useful for RLS enforcement where queries should run as a specific database role.
+//
+// Deprecated: like SetSessionVar it affects an arbitrary pooled connection. Use AcquireRLS or WithRLS instead.
 func (kn *KintsNorm) SetRole(ctx context.Context, role string) error {
 	query := fmt.Sprintf("SET ROLE %s", quoteSessionValue(role))
 	_, err := kn.pool.Exec(ctx, query)
@@ -62,33 +72,140 @@ func (kn *KintsNorm) WithRLS(ctx context.Context, rls RLSContext, fn func(tx Tra
 	if err != nil {
 		return err
 	}
-	exec := txx.Exec()
+	// SET LOCAL scopes role and variables to the transaction
+	if err := applyRLS(ctx, txx.Exec(), rls, true); err != nil {
+		_ = txx.Rollback(ctx)
+		return err
+	}
+	if t, ok := txx.(*txImpl); ok {
+		t.cacheScope = rlsCacheScope(rls)
+	}
+
+	// Execute user function
+	if err := fn(txx); err != nil {
+		_ = txx.Rollback(ctx)
+		return err
+	}
+
+	return txx.Commit(ctx)
+}
+
+// WithLocalRLS runs fn under rls for part of the transaction, e.g. while an admin acts as a tenant. It opens
+// a savepoint, applies rls with SET LOCAL and, once fn returns, puts back the role and variables the
+// transaction had before, so later statements run under the previous RLS context again. If fn fails, its
+// statements are rolled back to the savepoint and the settings revert with them. fn must not commit or roll
+// back the Transaction it receives.
+func (t *txImpl) WithLocalRLS(ctx context.Context, rls RLSContext, fn func(tx Transaction) error) error {
+	return withLocalRLS(ctx, t.kn, t.tx, rls, fn)
+}
+
+func withLocalRLS(ctx context.Context, kn *KintsNorm, tx pgx.Tx, rls RLSContext, fn func(tx Transaction) error) error {
+	prev, err := currentRLS(ctx, tx, rls)
+	if err != nil {
+		return err
+	}
+	sp, err := tx.Begin(ctx)
+	if err != nil {
+		return &ORMError{Code: ErrCodeTransaction, Message: fmt.Sprintf("savepoint: %s", err.Error()), Internal: err}
+	}
+	if err := applyRLS(ctx, sp, rls, true); err != nil {
+		_ = sp.Rollback(ctx)
+		return err
+	}
+	if err := fn(&txImpl{kn: kn, tx: sp, cacheScope: rlsCacheScope(rls)}); err != nil {
+		_ = sp.Rollback(ctx)
+		return err
+	}
+	// SET LOCAL outlives RELEASE SAVEPOINT, so the previous values are set again explicitly
+	if err := restoreRLS(ctx, sp, prev); err != nil {
+		_ = sp.Rollback(ctx)
+		return err
+	}
+	return sp.Commit(ctx)
+}
 
-	// Set role if specified
+// savedRLS holds the settings an RLSContext is about to change; nil values were never set
+type savedRLS struct {
+	role *string
+	vars map[string]*string
+}
+
+// currentRLS reads the current values of the role and variables rls sets
+func currentRLS(ctx context.Context, exec dbExecuter, rls RLSContext) (savedRLS, error) {
+	prev := savedRLS{vars: make(map[string]*string, len(rls.SessionVars))}
 	if rls.Role != "" {
-		query := fmt.Sprintf("SET LOCAL ROLE %s", quoteSessionValue(rls.Role))
-		if _, err := exec.Exec(ctx, query); err != nil {
-			_ = txx.Rollback(ctx)
-			return &ORMError{Code: ErrCodeInternal, Message: fmt.Sprintf("set role in tx: %s", err.Error()), Internal: err}
+		if err := exec.QueryRow(ctx, "SELECT current_setting('role')").Scan(&prev.role); err != nil {
+			return prev, &ORMError{Code: ErrCodeInternal, Message: fmt.Sprintf("read role: %s", err.Error()), Internal: err}
 		}
 	}
+	for key := range rls.SessionVars {
+		var v *string
+		if err := exec.QueryRow(ctx, "SELECT current_setting($1, true)", key).Scan(&v); err != nil {
+			return prev, &ORMError{Code: ErrCodeInternal, Message: fmt.Sprintf("read session var %s: %s", key, err.Error()), Internal: err}
+		}
+		prev.vars[key] = v
+	}
+	return prev, nil
+}
 
-	// Set session variables (using SET LOCAL so they're scoped to the transaction)
-	for key, value := range rls.SessionVars {
-		query := fmt.Sprintf("SET LOCAL %s = %s", quoteSessionKey(key), quoteSessionValue(value))
+// restoreRLS sets the values saved by currentRLS back with SET LOCAL
+func restoreRLS(ctx context.Context, exec dbExecuter, prev savedRLS) error {
+	if prev.role != nil {
+		query := "SET LOCAL ROLE NONE"
+		if *prev.role != "none" {
+			query = "SET LOCAL ROLE " + quoteSessionValue(*prev.role)
+		}
 		if _, err := exec.Exec(ctx, query); err != nil {
-			_ = txx.Rollback(ctx)
-			return &ORMError{Code: ErrCodeInternal, Message: fmt.Sprintf("set session var %s in tx: %s", key, err.Error()), Internal: err}
+			return &ORMError{Code: ErrCodeInternal, Message: fmt.Sprintf("restore role: %s", err.Error()), Internal: err}
 		}
 	}
+	for _, key := range slices.Sorted(maps.Keys(prev.vars)) {
+		query := "SET LOCAL " + quoteSessionKey(key) + " TO DEFAULT"
+		if v := prev.vars[key]; v != nil {
+			query = "SET LOCAL " + quoteSessionKey(key) + " = " + quoteSessionValue(*v)
+		}
+		if _, err := exec.Exec(ctx, query); err != nil {
+			return &ORMError{Code: ErrCodeInternal, Message: fmt.Sprintf("restore session var %s: %s", key, err.Error()), Internal: err}
+		}
+	}
+	return nil
+}
 
-	// Execute user function
-	if err := fn(txx); err != nil {
-		_ = txx.Rollback(ctx)
-		return err
+// rlsCacheScope names the cache scope of builders running under rls: a hash of its role and variables,
+// so two RLS contexts (tenants) never share cached results
+func rlsCacheScope(rls RLSContext) string {
+	if rls.Role == "" && len(rls.SessionVars) == 0 {
+		return ""
+	}
+	h := fnv.New64a()
+	h.Write([]byte(rls.Role))
+	for _, key := range slices.Sorted(maps.Keys(rls.SessionVars)) {
+		h.Write([]byte{0})
+		h.Write([]byte(key + "=" + rls.SessionVars[key]))
 	}
+	return fmt.Sprintf("rls:%016x", h.Sum64())
+}
 
-	return txx.Commit(ctx)
+// applyRLS sets the role and session variables of rls on exec, with SET LOCAL when local
+func applyRLS(ctx context.Context, exec dbExecuter, rls RLSContext, local bool) error {
+	set := "SET "
+	if local {
+		set = "SET LOCAL "
+	}
+	if rls.Role != "" {
+		if _, err := exec.Exec(ctx, set+"ROLE "+quoteSessionValue(rls.Role)); err != nil {
+			return &ORMError{Code: ErrCodeInternal, Message: fmt.Sprintf("set role: %s", err.Error()), Internal: err}
+		}
+	}
+	// sorted so the statements are deterministic
+	keys := slices.Sorted(maps.Keys(rls.SessionVars))
+	for _, key := range keys {
+		query := set + quoteSessionKey(key) + " = " + quoteSessionValue(rls.SessionVars[key])
+		if _, err := exec.Exec(ctx, query); err != nil {
+			return &ORMError{Code: ErrCodeInternal, Message: fmt.Sprintf("set session var %s: %s", key, err.Error()), Internal: err}
+		}
+	}
+	return nil
 }
 
 // quoteSessionKey validates and returns a safe session key (dotted identifier like app.current_user)
@@ -0,0 +1,104 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// pooledConn is the part of *pgxpool.Conn used by RLSConn; CopyFrom lets CreateCopyFrom run on it
+type pooledConn interface {
+	dbExecuter
+	copier
+	Release()
+	Conn() *pgx.Conn
+}
+
+// RLSConn is a connection held out of the pool with the role and session variables of an RLSContext
+// applied, so every statement issued through it is evaluated under the same RLS context, unlike
+// SetSessionVar/SetRole which affect an arbitrary pooled connection. Release it when done.
+type RLSConn struct {
+	kn   *KintsNorm
+	conn pooledConn
+	rls  RLSContext
+}
+
+// AcquireRLS acquires a dedicated connection and applies rls to it (session-level SET). The settings
+// are reset when the connection is released; if resetting fails the connection is closed instead of
+// being returned to the pool.
+//
+//	c, err := db.AcquireRLS(ctx, norm.RLSContext{SessionVars: map[string]string{"app.tenant_id": "42"}})
+//	defer c.Release(ctx)
+//	docs := norm.NewRepositoryWithExecutor[Doc](db, c.Exec())
+func (kn *KintsNorm) AcquireRLS(ctx context.Context, rls RLSContext) (*RLSConn, error) {
+	conn, err := kn.acquire(ctx, kn.pool)
+	if err != nil {
+		return nil, &ORMError{Code: ErrCodeConnection, Message: fmt.Sprintf("acquire connection: %s", err.Error()), Internal: err}
+	}
+	return newRLSConn(ctx, kn, conn, rls)
+}
+
+func newRLSConn(ctx context.Context, kn *KintsNorm, conn pooledConn, rls RLSContext) (*RLSConn, error) {
+	c := &RLSConn{kn: kn, conn: conn, rls: rls}
+	if err := applyRLS(ctx, conn, rls, false); err != nil {
+		c.Release(ctx)
+		return nil, err
+	}
+	return c, nil
+}
+
+// WithRLSConn runs fn with a connection carrying rls (see AcquireRLS) and releases it afterwards.
+// Unlike WithRLS, statements are not wrapped in a single transaction.
+func (kn *KintsNorm) WithRLSConn(ctx context.Context, rls RLSContext, fn func(c *RLSConn) error) error {
+	c, err := kn.AcquireRLS(ctx, rls)
+	if err != nil {
+		return err
+	}
+	defer c.Release(ctx)
+	return fn(c)
+}
+
+// Exec returns an executor bound to the connection, e.g. for NewRepositoryWithExecutor
+func (c *RLSConn) Exec() dbExecuter { return wrapExec(c.kn, c.conn) }
+
+// Query returns a query builder bound to the connection; its cache keys are scoped to the RLS context
+func (c *RLSConn) Query() *QueryBuilder {
+	qb := c.kn.Query()
+	qb.exec = c.Exec()
+	qb.cacheScope = rlsCacheScope(c.rls)
+	return qb
+}
+
+// Release resets the role and session variables and returns the connection to the pool.
+// It is safe to call more than once.
+func (c *RLSConn) Release(ctx context.Context) {
+	if c.conn == nil {
+		return
+	}
+	conn := c.conn
+	c.conn = nil
+	if err := resetRLS(ctx, conn, c.rls); err != nil {
+		// never hand a connection with a foreign RLS context back to the pool
+		if pc := conn.Conn(); pc != nil {
+			_ = pc.Close(ctx)
+		}
+	}
+	conn.Release()
+}
+
+func resetRLS(ctx context.Context, exec dbExecuter, rls RLSContext) error {
+	if rls.Role != "" {
+		if _, err := exec.Exec(ctx, "RESET ROLE"); err != nil {
+			return err
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(rls.SessionVars)) {
+		if _, err := exec.Exec(ctx, "RESET "+quoteSessionKey(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
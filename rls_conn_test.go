@@ -0,0 +1,98 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// fakePooledConn records statements and whether it went back to the pool
+type fakePooledConn struct {
+	sqls     []string
+	failOn   string
+	released int
+}
+
+func (c *fakePooledConn) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	c.sqls = append(c.sqls, sql)
+	if sql == c.failOn {
+		return pgconn.CommandTag{}, errors.New("boom")
+	}
+	return pgconn.CommandTag{}, nil
+}
+func (c *fakePooledConn) Query(_ context.Context, sql string, _ ...any) (pgx.Rows, error) {
+	c.sqls = append(c.sqls, sql)
+	return &fakeRowsRU{}, nil
+}
+func (c *fakePooledConn) QueryRow(_ context.Context, sql string, _ ...any) pgx.Row {
+	c.sqls = append(c.sqls, sql)
+	return errorRow{}
+}
+func (c *fakePooledConn) CopyFrom(_ context.Context, table pgx.Identifier, _ []string, src pgx.CopyFromSource) (int64, error) {
+	c.sqls = append(c.sqls, "COPY "+table.Sanitize())
+	n := int64(0)
+	for src.Next() {
+		n++
+	}
+	return n, nil
+}
+func (c *fakePooledConn) Release()        { c.released++ }
+func (c *fakePooledConn) Conn() *pgx.Conn { return nil }
+
+func TestRLSConn_AppliesAndResets(t *testing.T) {
+	ctx := context.Background()
+	conn := &fakePooledConn{}
+	rls := RLSContext{Role: "app_user", SessionVars: map[string]string{"app.user_id": "7", "app.tenant_id": "o'k"}}
+	c, err := newRLSConn(ctx, &KintsNorm{}, conn, rls)
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if _, err := c.Query().Table("docs").Count(ctx); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	c.Release(ctx)
+	c.Release(ctx)
+	want := []string{
+		"SET ROLE 'app_user'",
+		`SET "app"."tenant_id" = 'o''k'`,
+		`SET "app"."user_id" = '7'`,
+		"SELECT count(*) FROM docs",
+		"RESET ROLE",
+		`RESET "app"."tenant_id"`,
+		`RESET "app"."user_id"`,
+	}
+	if !reflect.DeepEqual(conn.sqls, want) || conn.released != 1 {
+		t.Fatalf("sqls=%q released=%d", conn.sqls, conn.released)
+	}
+}
+
+func TestRLSConn_ApplyFailureReleases(t *testing.T) {
+	conn := &fakePooledConn{failOn: "SET ROLE 'nope'"}
+	if _, err := newRLSConn(context.Background(), &KintsNorm{}, conn, RLSContext{Role: "nope"}); err == nil {
+		t.Fatalf("expected error")
+	}
+	if conn.released != 1 {
+		t.Fatalf("released=%d", conn.released)
+	}
+}
+
+func TestRLSConn_CreateCopyFromUsesTheConnection(t *testing.T) {
+	ctx := context.Background()
+	conn := &fakePooledConn{}
+	c, err := newRLSConn(ctx, &KintsNorm{}, conn, RLSContext{Role: "app_user"})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	defer c.Release(ctx)
+	repo := NewRepositoryWithExecutor[repUser](c.kn, c.Exec())
+	if n, err := repo.CreateCopyFrom(ctx, []*repUser{{Name: "a"}, {Name: "b"}}, "name"); err != nil || n != 2 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	if want := []string{"SET ROLE 'app_user'", `COPY "rep_users"`}; !reflect.DeepEqual(conn.sqls, want) {
+		t.Fatalf("sqls=%q", conn.sqls)
+	}
+}
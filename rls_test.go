@@ -0,0 +1,98 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// rlsTx records statements; current holds the settings current_setting reports, savepoints are recorded
+// as SAVEPOINT, RELEASE and ROLLBACK TO
+type rlsTx struct {
+	pgx.Tx
+	sqls    []string
+	current map[string]*string
+}
+
+type settingRow struct{ v *string }
+
+func (r settingRow) Scan(dest ...any) error {
+	*(dest[0].(**string)) = r.v
+	return nil
+}
+
+func (t *rlsTx) Exec(_ context.Context, sql string, _ ...any) (pgconn.CommandTag, error) {
+	t.sqls = append(t.sqls, sql)
+	return pgconn.CommandTag{}, nil
+}
+func (t *rlsTx) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	t.sqls = append(t.sqls, sql)
+	if len(args) == 0 {
+		return settingRow{t.current["role"]}
+	}
+	return settingRow{t.current[args[0].(string)]}
+}
+func (t *rlsTx) Begin(context.Context) (pgx.Tx, error) {
+	t.sqls = append(t.sqls, "SAVEPOINT")
+	return &rlsSavepoint{rlsTx: t}, nil
+}
+
+type rlsSavepoint struct{ *rlsTx }
+
+func (s *rlsSavepoint) Commit(context.Context) error {
+	s.sqls = append(s.sqls, "RELEASE")
+	return nil
+}
+func (s *rlsSavepoint) Rollback(context.Context) error {
+	s.sqls = append(s.sqls, "ROLLBACK TO")
+	return nil
+}
+
+func TestWithLocalRLS_RestoresPreviousSettings(t *testing.T) {
+	role, tenant := "none", "1"
+	tx := &rlsTx{current: map[string]*string{"role": &role, "app.tenant_id": &tenant}}
+	txx := &txImpl{kn: &KintsNorm{}, tx: tx}
+	rls := RLSContext{Role: "admin", SessionVars: map[string]string{"app.tenant_id": "42", "app.actor": "root"}}
+	err := txx.WithLocalRLS(context.Background(), rls, func(tx Transaction) error {
+		_, err := tx.Exec().Exec(context.Background(), "UPDATE docs SET a = 1 WHERE id = 1")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"SELECT current_setting('role')",
+		"SELECT current_setting($1, true)",
+		"SELECT current_setting($1, true)",
+		"SAVEPOINT",
+		"SET LOCAL ROLE 'admin'",
+		`SET LOCAL "app"."actor" = 'root'`,
+		`SET LOCAL "app"."tenant_id" = '42'`,
+		"UPDATE docs SET a = 1 WHERE id = 1",
+		"SET LOCAL ROLE NONE",
+		`SET LOCAL "app"."actor" TO DEFAULT`,
+		`SET LOCAL "app"."tenant_id" = '1'`,
+		"RELEASE",
+	}
+	if !reflect.DeepEqual(tx.sqls, want) {
+		t.Fatalf("sqls:\n%q", tx.sqls)
+	}
+}
+
+func TestWithLocalRLS_RollsBackOnError(t *testing.T) {
+	tx := &rlsTx{current: map[string]*string{}}
+	boom := errors.New("boom")
+	err := (&txImpl{kn: &KintsNorm{}, tx: tx}).WithLocalRLS(context.Background(), RLSContext{SessionVars: map[string]string{"app.tenant_id": "42"}}, func(Transaction) error {
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Fatalf("err=%v", err)
+	}
+	if last := tx.sqls[len(tx.sqls)-1]; last != "ROLLBACK TO" {
+		t.Fatalf("sqls: %q", tx.sqls)
+	}
+}
@@ -0,0 +1,27 @@
+package norm
+
+// Scope is a reusable, named query modifier (typically filters or joins), e.g.
+//
+//	func ActiveOnly() norm.Scope { return norm.WhereScope(norm.Eq("is_active", true)) }
+//	func ForTenant(id int64) norm.Scope { return norm.WhereScope(norm.Eq("tenant_id", id)) }
+type Scope func(qb *QueryBuilder) *QueryBuilder
+
+// WhereScope returns a Scope adding the given conditions
+func WhereScope(conds ...Condition) Scope {
+	return func(qb *QueryBuilder) *QueryBuilder {
+		for _, c := range conds {
+			qb = qb.Where(c.Expr, c.Args...)
+		}
+		return qb
+	}
+}
+
+// Scopes applies scopes to the builder in order
+func (qb *QueryBuilder) Scopes(scopes ...Scope) *QueryBuilder {
+	for _, s := range scopes {
+		if s != nil {
+			qb = s(qb)
+		}
+	}
+	return qb
+}
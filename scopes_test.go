@@ -0,0 +1,37 @@
+package norm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func activeOnly() Scope { return WhereScope(Eq("is_active", true)) }
+func forTenant(id int64) Scope {
+	return func(qb *QueryBuilder) *QueryBuilder { return qb.Where("tenant_id = ?", id) }
+}
+
+func TestRepo_ScopedAppliesToReads(t *testing.T) {
+	ex := &fakeExecRU{rows: [][]any{{int64(2)}}, fields: []string{"count"}}
+	r := &repo[softUser]{kn: &KintsNorm{}, exec: ex}
+	scoped := r.Scoped(activeOnly(), forTenant(5))
+	_, _ = scoped.Find(context.Background(), Eq("id", 1))
+	if !strings.Contains(ex.lastSQL, "is_active = $1 AND tenant_id = $2 AND id = $3 AND deleted_at IS NULL") {
+		t.Fatalf("find sql=%s", ex.lastSQL)
+	}
+	if len(ex.lastArgs) != 3 || ex.lastArgs[1] != int64(5) {
+		t.Fatalf("args=%v", ex.lastArgs)
+	}
+	if n, err := scoped.Count(context.Background()); err != nil || n != 2 || !strings.Contains(ex.lastSQL, "tenant_id = $2") {
+		t.Fatalf("count n=%d err=%v sql=%s", n, err, ex.lastSQL)
+	}
+	// the original repository is unaffected
+	_, _ = r.Find(context.Background())
+	if strings.Contains(ex.lastSQL, "tenant_id") {
+		t.Fatalf("scope leaked: %s", ex.lastSQL)
+	}
+	// read-only repositories accept scopes too
+	if ro := NewReadOnlyRepository[softUser](&KintsNorm{}).Scoped(activeOnly()); ro == nil {
+		t.Fatalf("read-only scoped")
+	}
+}
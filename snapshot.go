@@ -0,0 +1,72 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// snapshotTxMode is required both to export and to import a snapshot; READ ONLY keeps workers from writing
+const snapshotTxMode = "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ, READ ONLY"
+
+// ExportSnapshot opens a read-only REPEATABLE READ transaction, exports its snapshot with pg_export_snapshot()
+// and calls fn with the snapshot id. The snapshot can be imported by other connections (see WithSnapshot)
+// only while fn runs, so fn should wait for its workers before returning.
+func (kn *KintsNorm) ExportSnapshot(ctx context.Context, fn func(ctx context.Context, snapshotID string) error) error {
+	return exportSnapshot(ctx, kn.pool, fn)
+}
+
+// WithSnapshot runs fn in a read-only REPEATABLE READ transaction that imports the exported snapshot
+// (SET TRANSACTION SNAPSHOT), so every worker sees the same data as the exporting transaction.
+func (kn *KintsNorm) WithSnapshot(ctx context.Context, snapshotID string, fn func(tx Transaction) error) error {
+	return withSnapshot(ctx, kn, kn.pool, snapshotID, fn)
+}
+
+func exportSnapshot(ctx context.Context, db txBeginner, fn func(ctx context.Context, snapshotID string) error) error {
+	tx, err := beginSnapshotTx(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	var id string
+	if err := tx.QueryRow(ctx, "SELECT pg_export_snapshot()").Scan(&id); err != nil {
+		return &ORMError{Code: ErrCodeTransaction, Message: fmt.Sprintf("export snapshot: %s", err.Error()), Internal: err}
+	}
+	if err := fn(ctx, id); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func withSnapshot(ctx context.Context, kn *KintsNorm, db txBeginner, snapshotID string, fn func(tx Transaction) error) error {
+	if strings.TrimSpace(snapshotID) == "" {
+		return &ORMError{Code: ErrCodeValidation, Message: "snapshot id is required"}
+	}
+	tx, err := beginSnapshotTx(ctx, db)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	// SET TRANSACTION SNAPSHOT does not accept bind parameters
+	if _, err := tx.Exec(ctx, "SET TRANSACTION SNAPSHOT "+quoteSessionValue(snapshotID)); err != nil {
+		return &ORMError{Code: ErrCodeTransaction, Message: fmt.Sprintf("import snapshot %s: %s", snapshotID, err.Error()), Internal: err}
+	}
+	if err := fn(&txImpl{kn: kn, tx: tx}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func beginSnapshotTx(ctx context.Context, db txBeginner) (pgx.Tx, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, &ORMError{Code: ErrCodeTransaction, Message: fmt.Sprintf("begin snapshot transaction: %s", err.Error()), Internal: err}
+	}
+	if _, err := tx.Exec(ctx, snapshotTxMode); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, &ORMError{Code: ErrCodeTransaction, Message: fmt.Sprintf("set snapshot isolation: %s", err.Error()), Internal: err}
+	}
+	return tx, nil
+}
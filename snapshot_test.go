@@ -0,0 +1,104 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// snapDB records the statements of every snapshot transaction it opens
+type snapDB struct {
+	sqls      []string
+	commits   int
+	rollbacks int
+}
+
+func (d *snapDB) Begin(ctx context.Context) (pgx.Tx, error) { return &snapTx{db: d}, nil }
+
+type snapTx struct {
+	pgx.Tx
+	db   *snapDB
+	done bool
+}
+
+type snapRow string
+
+func (s snapRow) Scan(dest ...any) error {
+	*(dest[0].(*string)) = string(s)
+	return nil
+}
+
+func (t *snapTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	t.db.sqls = append(t.db.sqls, sql)
+	return pgconn.CommandTag{}, nil
+}
+func (t *snapTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	t.db.sqls = append(t.db.sqls, sql)
+	return snapRow("00000003-0000001B-1")
+}
+func (t *snapTx) CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error) {
+	t.db.sqls = append(t.db.sqls, "COPY "+table.Sanitize())
+	n := int64(0)
+	for src.Next() {
+		n++
+	}
+	return n, nil
+}
+func (t *snapTx) Commit(ctx context.Context) error {
+	t.done = true
+	t.db.commits++
+	return nil
+}
+func (t *snapTx) Rollback(ctx context.Context) error {
+	if !t.done {
+		t.done = true
+		t.db.rollbacks++
+	}
+	return nil
+}
+
+func TestSnapshot_ExportAndImport(t *testing.T) {
+	db := &snapDB{}
+	ctx := context.Background()
+	err := exportSnapshot(ctx, db, func(ctx context.Context, id string) error {
+		if id != "00000003-0000001B-1" {
+			t.Fatalf("id=%s", id)
+		}
+		return withSnapshot(ctx, &KintsNorm{}, db, id, func(tx Transaction) error {
+			_, err := tx.Exec().Exec(ctx, "SELECT 1")
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := []string{
+		snapshotTxMode,
+		"SELECT pg_export_snapshot()",
+		snapshotTxMode,
+		"SET TRANSACTION SNAPSHOT '00000003-0000001B-1'",
+		"SELECT 1",
+	}
+	if !reflect.DeepEqual(db.sqls, want) {
+		t.Fatalf("sqls=%q", db.sqls)
+	}
+	if db.commits != 2 || db.rollbacks != 0 {
+		t.Fatalf("commits=%d rollbacks=%d", db.commits, db.rollbacks)
+	}
+}
+
+func TestSnapshot_ImportRollsBackOnError(t *testing.T) {
+	db := &snapDB{}
+	boom := errors.New("boom")
+	err := withSnapshot(context.Background(), &KintsNorm{}, db, "snap", func(tx Transaction) error { return boom })
+	if !errors.Is(err, boom) || db.commits != 0 || db.rollbacks != 1 {
+		t.Fatalf("err=%v commits=%d rollbacks=%d", err, db.commits, db.rollbacks)
+	}
+	if err := withSnapshot(context.Background(), &KintsNorm{}, db, " ", func(tx Transaction) error { return nil }); err == nil {
+		t.Fatalf("expected error for empty snapshot id")
+	}
+}
@@ -0,0 +1,92 @@
+package norm
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// defaultStatsInterval is used by WithStatsCallback when no interval is given
+const defaultStatsInterval = 15 * time.Second
+
+// PoolStats is a snapshot of a connection pool
+type PoolStats struct {
+	Acquired     int32 // connections currently in use
+	Idle         int32
+	Constructing int32
+	Total        int32
+	Max          int32
+	// AcquireCount counts successful acquires; EmptyAcquireCount those that had to wait for a connection
+	AcquireCount         int64
+	EmptyAcquireCount    int64
+	CanceledAcquireCount int64
+	AcquireDuration      time.Duration // total time spent acquiring
+	EmptyAcquireWaitTime time.Duration // part of AcquireDuration spent waiting on an exhausted pool
+}
+
+// Stats is a runtime snapshot of a KintsNorm instance
+type Stats struct {
+	Primary PoolStats
+	// Read is nil unless a read replica is configured
+	Read *PoolStats
+	// CircuitState is "closed", "open" or "half_open"; empty when the breaker is disabled
+	CircuitState       string
+	QueryTemplateCache QueryTemplateCacheStats
+}
+
+// Stats returns pool, breaker and query template cache statistics without touching pgxpool directly
+func (kn *KintsNorm) Stats() Stats {
+	s := Stats{QueryTemplateCache: kn.QueryTemplateCacheStats()}
+	if kn.pool != nil {
+		s.Primary = poolStats(kn.pool)
+	}
+	if kn.readPool != nil {
+		rs := poolStats(kn.readPool)
+		s.Read = &rs
+	}
+	if kn.breaker != nil {
+		s.CircuitState = kn.breaker.stateName()
+	}
+	return s
+}
+
+func poolStats(p *pgxpool.Pool) PoolStats {
+	st := p.Stat()
+	return PoolStats{
+		Acquired:             st.AcquiredConns(),
+		Idle:                 st.IdleConns(),
+		Constructing:         st.ConstructingConns(),
+		Total:                st.TotalConns(),
+		Max:                  st.MaxConns(),
+		AcquireCount:         st.AcquireCount(),
+		EmptyAcquireCount:    st.EmptyAcquireCount(),
+		CanceledAcquireCount: st.CanceledAcquireCount(),
+		AcquireDuration:      st.AcquireDuration(),
+		EmptyAcquireWaitTime: st.EmptyAcquireWaitTime(),
+	}
+}
+
+// startStatsLoop runs fn every interval until Close (no-op when fn is nil)
+func (kn *KintsNorm) startStatsLoop(interval time.Duration, fn func(Stats)) {
+	if fn == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = defaultStatsInterval
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	kn.stopStats = cancel
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				fn(kn.Stats())
+			}
+		}
+	}()
+}
@@ -0,0 +1,34 @@
+package norm
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestStats_BreakerAndTemplates(t *testing.T) {
+	kn := &KintsNorm{templates: newQueryTemplateCache(4)}
+	if s := kn.Stats(); s.CircuitState != "" || s.Read != nil {
+		t.Fatalf("stats=%+v", s)
+	}
+	kn.breaker = newCircuitBreaker(circuitBreakerConfig{failureThreshold: 1, openTimeout: time.Minute})
+	kn.breaker.after(errors.New("boom"))
+	kn.templates.get("missing")
+	s := kn.Stats()
+	if s.CircuitState != "open" || s.QueryTemplateCache.Misses != 1 {
+		t.Fatalf("stats=%+v", s)
+	}
+}
+
+func TestStatsLoop_CallsUntilClose(t *testing.T) {
+	kn := &KintsNorm{}
+	got := make(chan Stats, 16)
+	kn.startStatsLoop(time.Millisecond, func(s Stats) { got <- s })
+	select {
+	case <-got:
+	case <-time.After(time.Second):
+		t.Fatalf("callback not called")
+	}
+	_ = kn.Close()
+	_ = kn.Close()
+}
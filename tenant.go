@@ -0,0 +1,273 @@
+package norm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// defaultTenantSchemaPrefix keeps tenant schemas apart from public and pg_* schemas
+const defaultTenantSchemaPrefix = "tenant_"
+
+// TenantSchema returns the schema holding tenant's tables (see WithTenantSchemaPrefix)
+func (kn *KintsNorm) TenantSchema(tenant string) string {
+	return kn.tenantSchemaPrefix + tenant
+}
+
+// Tenant is a handle whose statements run with search_path set to the tenant's schema, then public.
+// Unqualified table names therefore resolve to the tenant's tables, falling back to shared ones in public.
+type Tenant struct {
+	kn     *KintsNorm
+	db     txBeginner
+	name   string
+	schema string
+}
+
+// ForTenant returns a handle for tenant. Each statement issued through it runs in a short transaction
+// that first applies SET LOCAL search_path, so pooled connections never keep a tenant's path.
+//
+//	acme := db.ForTenant("acme")
+//	users := norm.NewRepositoryWithExecutor[User](db, acme.Exec())
+func (kn *KintsNorm) ForTenant(tenant string) *Tenant {
+	return &Tenant{kn: kn, db: kn.poolExec(kn.pool), name: tenant, schema: kn.TenantSchema(tenant)}
+}
+
+// Name returns the tenant name given to ForTenant
+func (t *Tenant) Name() string { return t.name }
+
+// Schema returns the tenant's schema
+func (t *Tenant) Schema() string { return t.schema }
+
+func (t *Tenant) searchPathSQL() string {
+	return tenantSearchPathSQL(t.schema)
+}
+
+func tenantSearchPathSQL(schema string) string {
+	return "SET LOCAL search_path TO " + QuoteIdentifier(schema) + ", public"
+}
+
+// Exec returns an executor scoped to the tenant, e.g. for NewRepositoryWithExecutor. Transactions it
+// begins (multi-chunk batches, cascades, idempotent writes) apply the tenant's search_path first.
+func (t *Tenant) Exec() dbExecuter {
+	return wrapExec(t.kn, tenantExecuter{db: t.db, searchPath: t.searchPathSQL()})
+}
+
+// Query returns a query builder scoped to the tenant. Its cache and invalidation keys are prefixed with
+// tenant:<name>: so tenants never share cached results.
+func (t *Tenant) Query() *QueryBuilder {
+	qb := t.kn.Query()
+	qb.exec = t.Exec()
+	qb.cacheScope = tenantCacheScope(t.name)
+	return qb
+}
+
+func tenantCacheScope(tenant string) string { return "tenant:" + tenant }
+
+// WithTransaction runs fn in one transaction with the tenant's search_path applied (SET LOCAL), so
+// several statements share it without a transaction per statement
+func (t *Tenant) WithTransaction(ctx context.Context, fn func(tx Transaction) error) error {
+	return withTenantTx(ctx, t.kn, t.db, t.searchPathSQL(), tenantCacheScope(t.name), fn)
+}
+
+func withTenantTx(ctx context.Context, kn *KintsNorm, db txBeginner, searchPath, cacheScope string, fn func(tx Transaction) error) error {
+	tx, err := beginTenantTx(ctx, db, searchPath)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if err := fn(&txImpl{kn: kn, tx: tx, cacheScope: cacheScope}); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func beginTenantTx(ctx context.Context, db txBeginner, searchPath string) (pgx.Tx, error) {
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return nil, &ORMError{Code: ErrCodeTransaction, Message: fmt.Sprintf("begin tenant transaction: %s", err.Error()), Internal: err}
+	}
+	if _, err := tx.Exec(ctx, searchPath); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, &ORMError{Code: ErrCodeTransaction, Message: fmt.Sprintf("set tenant search_path: %s", err.Error()), Internal: err}
+	}
+	return tx, nil
+}
+
+// tenantExecuter runs every statement in its own transaction with the tenant's search_path applied.
+// Rows from Query keep the transaction open until they are closed.
+type tenantExecuter struct {
+	db         txBeginner
+	searchPath string
+}
+
+// Begin opens a transaction with the tenant's search_path applied, so statements of the whole
+// transaction resolve to the tenant's tables
+func (e tenantExecuter) Begin(ctx context.Context) (pgx.Tx, error) {
+	return beginTenantTx(ctx, e.db, e.searchPath)
+}
+
+func (e tenantExecuter) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx, err := beginTenantTx(ctx, e.db, e.searchPath)
+	if err != nil {
+		return pgconn.CommandTag{}, err
+	}
+	tag, err := tx.Exec(ctx, sql, args...)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return tag, err
+	}
+	return tag, tx.Commit(ctx)
+}
+
+func (e tenantExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	tx, err := beginTenantTx(ctx, e.db, e.searchPath)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx, sql, args...)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return &tenantRows{Rows: rows, ctx: ctx, tx: tx}, nil
+}
+
+func (e tenantExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	tx, err := beginTenantTx(ctx, e.db, e.searchPath)
+	if err != nil {
+		return errorRow{err: err}
+	}
+	return &tenantRow{row: tx.QueryRow(ctx, sql, args...), ctx: ctx, tx: tx}
+}
+
+// tenantRows ends the statement transaction when the rows are closed
+type tenantRows struct {
+	pgx.Rows
+	ctx  context.Context
+	tx   pgx.Tx
+	done bool
+	err  error
+}
+
+func (r *tenantRows) Close() {
+	r.Rows.Close()
+	if r.done {
+		return
+	}
+	r.done = true
+	if r.Rows.Err() != nil {
+		_ = r.tx.Rollback(r.ctx)
+		return
+	}
+	r.err = r.tx.Commit(r.ctx)
+}
+
+func (r *tenantRows) Err() error {
+	if err := r.Rows.Err(); err != nil {
+		return err
+	}
+	return r.err
+}
+
+// tenantRow ends the statement transaction after Scan
+type tenantRow struct {
+	row pgx.Row
+	ctx context.Context
+	tx  pgx.Tx
+}
+
+func (r *tenantRow) Scan(dest ...any) error {
+	if err := r.row.Scan(dest...); err != nil {
+		_ = r.tx.Rollback(r.ctx)
+		return err
+	}
+	return r.tx.Commit(r.ctx)
+}
+
+// TenantManager creates, migrates and drops the per-tenant schemas of a set of models
+type TenantManager struct {
+	kn     *KintsNorm
+	models []any
+}
+
+// TenantManager returns a manager migrating models into each tenant's schema
+func (kn *KintsNorm) TenantManager(models ...any) *TenantManager {
+	return &TenantManager{kn: kn, models: models}
+}
+
+// Create creates the tenant's schema if missing and migrates the models into it. It is idempotent,
+// so it also brings an existing tenant up to date.
+func (m *TenantManager) Create(ctx context.Context, tenant string) error {
+	if strings.TrimSpace(tenant) == "" {
+		return &ORMError{Code: ErrCodeValidation, Message: "tenant name is required"}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if err := m.kn.migrator.InSchema(m.kn.TenantSchema(tenant)).AutoMigrate(ctx, m.models...); err != nil {
+		return &ORMError{Code: ErrCodeMigration, Message: fmt.Sprintf("migrate tenant %s: %s", tenant, err.Error()), Internal: err}
+	}
+	return nil
+}
+
+// MigrateAll migrates every existing tenant (see List), stopping at the first failure
+func (m *TenantManager) MigrateAll(ctx context.Context) error {
+	tenants, err := m.List(ctx)
+	if err != nil {
+		return err
+	}
+	for _, t := range tenants {
+		if err := m.Create(ctx, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns the tenants that have a schema, sorted by name
+func (m *TenantManager) List(ctx context.Context) ([]string, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return listTenants(ctx, wrapExec(m.kn, m.kn.pool), m.kn.tenantSchemaPrefix)
+}
+
+func listTenants(ctx context.Context, exec dbExecuter, prefix string) ([]string, error) {
+	const q = `SELECT nspname FROM pg_namespace WHERE starts_with(nspname, $1) AND nspname <> 'public' AND nspname NOT LIKE 'pg\_%' AND nspname <> 'information_schema' ORDER BY nspname`
+	rows, err := exec.Query(ctx, q, prefix)
+	if err != nil {
+		return nil, wrapPgError(err, q, []any{prefix})
+	}
+	defer rows.Close()
+	var out []string
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			return nil, wrapPgError(err, q, []any{prefix})
+		}
+		name, _ := vals[0].(string)
+		out = append(out, strings.TrimPrefix(name, prefix))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapPgError(err, q, []any{prefix})
+	}
+	return out, nil
+}
+
+// Drop removes the tenant's schema and everything in it (DROP SCHEMA ... CASCADE)
+func (m *TenantManager) Drop(ctx context.Context, tenant string) error {
+	if strings.TrimSpace(tenant) == "" {
+		return &ORMError{Code: ErrCodeValidation, Message: "tenant name is required"}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	q := "DROP SCHEMA IF EXISTS " + QuoteIdentifier(m.kn.TenantSchema(tenant)) + " CASCADE"
+	if _, err := wrapExec(m.kn, m.kn.pool).Exec(ctx, q); err != nil {
+		return wrapPgError(err, q, nil)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestTenantExecuter_ScopesEachStatement(t *testing.T) {
+	kn := &KintsNorm{tenantSchemaPrefix: defaultTenantSchemaPrefix}
+	acme := kn.ForTenant("acme")
+	if acme.Schema() != "tenant_acme" || acme.Name() != "acme" {
+		t.Fatalf("schema=%s", acme.Schema())
+	}
+	db := &snapDB{}
+	ex := tenantExecuter{db: db, searchPath: acme.searchPathSQL()}
+	ctx := context.Background()
+	if _, err := ex.Exec(ctx, "DELETE FROM users"); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	var v string
+	if err := ex.QueryRow(ctx, "SELECT name FROM users").Scan(&v); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := []string{
+		`SET LOCAL search_path TO "tenant_acme", public`, "DELETE FROM users",
+		`SET LOCAL search_path TO "tenant_acme", public`, "SELECT name FROM users",
+	}
+	if !reflect.DeepEqual(db.sqls, want) || db.commits != 2 {
+		t.Fatalf("sqls=%q commits=%d", db.sqls, db.commits)
+	}
+}
+
+func TestTenant_WithTransaction(t *testing.T) {
+	db := &snapDB{}
+	ctx := context.Background()
+	err := withTenantTx(ctx, &KintsNorm{}, db, tenantSearchPathSQL("acme"), tenantCacheScope("acme"), func(tx Transaction) error {
+		_, err := tx.Exec().Exec(ctx, "UPDATE users SET active = true")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	if !reflect.DeepEqual(db.sqls, []string{`SET LOCAL search_path TO "acme", public`, "UPDATE users SET active = true"}) || db.commits != 1 {
+		t.Fatalf("sqls=%q commits=%d", db.sqls, db.commits)
+	}
+}
+
+func TestListTenants_StripsPrefix(t *testing.T) {
+	ex := &seqExec{fields: []string{"nspname"}, results: [][][]any{{{"tenant_acme"}, {"tenant_globex"}}}}
+	got, err := listTenants(context.Background(), ex, "tenant_")
+	if err != nil || !reflect.DeepEqual(got, []string{"acme", "globex"}) {
+		t.Fatalf("got=%v err=%v", got, err)
+	}
+}
+
+type tenantItem struct {
+	Code string `db:"code" norm:"primary_key"`
+	Name string `db:"name"`
+}
+
+func TestTenantExec_MultiChunkCreateBatch(t *testing.T) {
+	kn := &KintsNorm{tenantSchemaPrefix: defaultTenantSchemaPrefix, batchSize: 1}
+	acme := kn.ForTenant("acme")
+	db := &snapDB{}
+	acme.db = db
+	repo := NewRepositoryWithExecutor[tenantItem](kn, acme.Exec())
+	items := []*tenantItem{{Code: "a", Name: "A"}, {Code: "b", Name: "B"}}
+	if err := repo.CreateBatch(context.Background(), items); err != nil {
+		t.Fatalf("err=%v", err)
+	}
+	want := []string{
+		`SET LOCAL search_path TO "tenant_acme", public`,
+		`INSERT INTO tenant_items ("code", "name") VALUES ($1, $2)`,
+		`INSERT INTO tenant_items ("code", "name") VALUES ($1, $2)`,
+	}
+	if !reflect.DeepEqual(db.sqls, want) || db.commits != 1 {
+		t.Fatalf("sqls=%q commits=%d", db.sqls, db.commits)
+	}
+}
+
+func TestTenantExec_CreateCopyFromUsesTenantSearchPath(t *testing.T) {
+	kn := &KintsNorm{tenantSchemaPrefix: defaultTenantSchemaPrefix}
+	acme := kn.ForTenant("acme")
+	db := &snapDB{}
+	acme.db = db
+	repo := NewRepositoryWithExecutor[tenantItem](kn, acme.Exec())
+	n, err := repo.CreateCopyFrom(context.Background(), []*tenantItem{{Code: "a", Name: "A"}, {Code: "b", Name: "B"}})
+	if err != nil || n != 2 {
+		t.Fatalf("n=%d err=%v", n, err)
+	}
+	want := []string{`SET LOCAL search_path TO "tenant_acme", public`, `COPY "tenant_items"`}
+	if !reflect.DeepEqual(db.sqls, want) || db.commits != 1 {
+		t.Fatalf("sqls=%q commits=%d", db.sqls, db.commits)
+	}
+
+	// executors that cannot COPY fail instead of falling back to the shared pool
+	other := NewRepositoryWithExecutor[tenantItem](kn, &recExecRepo{})
+	var oe *ORMError
+	if _, err := other.CreateCopyFrom(context.Background(), []*tenantItem{{Code: "c"}}); !errors.As(err, &oe) || oe.Code != ErrCodeValidation {
+		t.Fatalf("err=%v", err)
+	}
+}
@@ -19,6 +19,7 @@ type Transaction interface {
 	Repository() Repository[map[string]any]
 	Exec() dbExecuter
 	Query() *QueryBuilder
+	WithLocalRLS(ctx context.Context, rls RLSContext, fn func(tx Transaction) error) error
 }
 
 type txManager struct{ kn *KintsNorm }
@@ -26,16 +27,27 @@ type txManager struct{ kn *KintsNorm }
 func (kn *KintsNorm) Tx() TxManager { return &txManager{kn: kn} }
 
 type txImpl struct {
-	kn *KintsNorm
-	tx pgx.Tx
+	kn  *KintsNorm
+	tx  pgx.Tx
+	obs *txObserver // nil unless transactions are observed (TxMetrics or WithTxTracer)
+	// cacheScope of the transaction's builders (tenant or RLS context; see QueryBuilder.cacheKeyFor)
+	cacheScope string
 }
 
 func (m *txManager) WithTransaction(ctx context.Context, fn func(tx Transaction) error) error {
-	txx, err := m.BeginTx(ctx, &TxOptions{})
+	return m.run(ctx, func(_ context.Context, tx Transaction) error { return fn(tx) })
+}
+
+// run is WithTransaction handing fn the context carrying the transaction span, if any
+func (m *txManager) run(ctx context.Context, fn func(ctx context.Context, tx Transaction) error) error {
+	ctx, txx, err := m.begin(ctx)
 	if err != nil {
 		return err
 	}
-	if err := fn(txx); err != nil {
+	if err := fn(ctx, txx); err != nil {
+		if txx.obs != nil {
+			txx.obs.cause = err
+		}
 		_ = txx.Rollback(ctx)
 		return err
 	}
@@ -43,32 +55,52 @@ func (m *txManager) WithTransaction(ctx context.Context, fn func(tx Transaction)
 }
 
 func (m *txManager) BeginTx(ctx context.Context, opts *TxOptions) (Transaction, error) {
-	tx, err := m.kn.pool.Begin(ctx)
+	_, tx, err := m.begin(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &txImpl{kn: m.kn, tx: tx}, nil
+	return tx, nil
+}
+
+func (m *txManager) begin(ctx context.Context) (context.Context, *txImpl, error) {
+	ctx, obs := m.kn.observeTx(ctx)
+	tx, err := m.kn.poolExec(m.kn.pool).Begin(ctx)
+	if err != nil {
+		obs.finish(TxRolledBack, err)
+		return ctx, nil, err
+	}
+	if obs == nil {
+		return ctx, &txImpl{kn: m.kn, tx: tx}, nil
+	}
+	return ctx, &txImpl{kn: m.kn, tx: countedTx{Tx: tx, n: &obs.statements}, obs: obs}, nil
+}
+
+func (t *txImpl) Commit(ctx context.Context) error {
+	err := t.tx.Commit(ctx)
+	if err != nil {
+		t.obs.finish(TxCommitFailed, err)
+	} else {
+		t.obs.finish(TxCommitted, nil)
+	}
+	return err
 }
 
-func (t *txImpl) Commit(ctx context.Context) error   { return t.tx.Commit(ctx) }
-func (t *txImpl) Rollback(ctx context.Context) error { return t.tx.Rollback(ctx) }
+func (t *txImpl) Rollback(ctx context.Context) error {
+	err := t.tx.Rollback(ctx)
+	t.obs.finish(TxRolledBack, err)
+	return err
+}
 
 func (t *txImpl) Repository() Repository[map[string]any] {
 	return NewRepositoryWithExecutor[map[string]any](t.kn, t.tx)
 }
 
 func (t *txImpl) Exec() dbExecuter {
-	if t.kn.breaker != nil {
-		return breakerExecuter{kn: t.kn, exec: t.tx}
-	}
-	return t.tx
+	return wrapExec(t.kn, t.tx)
 }
 func (t *txImpl) Query() *QueryBuilder {
 	qb := t.kn.Query()
-	if t.kn.breaker != nil {
-		qb.exec = breakerExecuter{kn: t.kn, exec: t.tx}
-	} else {
-		qb.exec = t.tx
-	}
+	qb.exec = wrapExec(t.kn, t.tx)
+	qb.cacheScope = t.cacheScope
 	return qb
 }
@@ -0,0 +1,58 @@
+package norm
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type txContextKey struct{}
+
+// WithTx returns a context carrying tx. Repositories created with NewRepository and builders from
+// Query()/Model() run their statements inside tx whenever they are called with this context.
+func WithTx(ctx context.Context, tx Transaction) context.Context {
+	return context.WithValue(ctx, txContextKey{}, tx)
+}
+
+// TxFromContext returns the transaction stored by WithTx, if any
+func TxFromContext(ctx context.Context) (Transaction, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tx, ok := ctx.Value(txContextKey{}).(Transaction)
+	return tx, ok && tx != nil
+}
+
+// WithContextTransaction runs fn in a transaction propagated through ctx (see WithTx), so nested service
+// calls pick it up without passing executors around. If ctx already carries a transaction it is reused.
+func (kn *KintsNorm) WithContextTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := TxFromContext(ctx); ok {
+		return fn(ctx)
+	}
+	return (&txManager{kn: kn}).run(ctx, func(ctx context.Context, tx Transaction) error {
+		return fn(WithTx(ctx, tx))
+	})
+}
+
+// ctxTxExecuter uses the transaction carried by the call's context, falling back to base
+type ctxTxExecuter struct{ base dbExecuter }
+
+func (e ctxTxExecuter) pick(ctx context.Context) dbExecuter {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx.Exec()
+	}
+	return e.base
+}
+
+func (e ctxTxExecuter) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return e.pick(ctx).Exec(ctx, sql, arguments...)
+}
+
+func (e ctxTxExecuter) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return e.pick(ctx).Query(ctx, sql, args...)
+}
+
+func (e ctxTxExecuter) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	return e.pick(ctx).QueryRow(ctx, sql, args...)
+}
@@ -0,0 +1,60 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// ctxTx is a Transaction whose executor records statements
+type ctxTx struct{ ex *recExecRepo }
+
+func (c ctxTx) Commit(context.Context) error           { return nil }
+func (c ctxTx) Rollback(context.Context) error         { return nil }
+func (c ctxTx) Repository() Repository[map[string]any] { return nil }
+func (c ctxTx) Exec() dbExecuter                       { return c.ex }
+func (c ctxTx) Query() *QueryBuilder                   { return nil }
+func (c ctxTx) WithLocalRLS(context.Context, RLSContext, func(Transaction) error) error {
+	return nil
+}
+
+func TestWithTx_RepositoryAndBuilderUseContextTx(t *testing.T) {
+	kn := &KintsNorm{}
+	ex := &recExecRepo{}
+	ctx := WithTx(context.Background(), ctxTx{ex: ex})
+	if _, ok := TxFromContext(ctx); !ok {
+		t.Fatalf("tx not found in ctx")
+	}
+	r := NewRepository[repUser](kn)
+	if err := r.UpdatePartial(ctx, 7, map[string]any{"name": "n"}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !strings.HasPrefix(ex.lastSQL, "UPDATE") || len(ex.lastArgs) != 2 || ex.lastArgs[1] != 7 {
+		t.Fatalf("repo did not use ctx tx: %q %v", ex.lastSQL, ex.lastArgs)
+	}
+	if err := kn.Query().Raw("SELECT pg_notify($1, $2)", "c", "p").Exec(ctx); err != nil {
+		t.Fatalf("exec: %v", err)
+	}
+	if !strings.Contains(ex.lastSQL, "pg_notify") {
+		t.Fatalf("builder did not use ctx tx: %q", ex.lastSQL)
+	}
+}
+
+func TestTxFromContext_Absent(t *testing.T) {
+	if _, ok := TxFromContext(context.Background()); ok {
+		t.Fatalf("unexpected tx")
+	}
+	// an existing context transaction is reused instead of starting a new one
+	kn := &KintsNorm{}
+	ctx := WithTx(context.Background(), ctxTx{ex: &recExecRepo{}})
+	want := errors.New("boom")
+	if err := kn.WithContextTransaction(ctx, func(inner context.Context) error {
+		if _, ok := TxFromContext(inner); !ok {
+			t.Fatalf("tx missing in nested ctx")
+		}
+		return want
+	}); !errors.Is(err, want) {
+		t.Fatalf("got %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package norm
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// TxResult tells how a transaction ended
+type TxResult string
+
+const (
+	TxCommitted    TxResult = "commit"
+	TxRolledBack   TxResult = "rollback"
+	TxCommitFailed TxResult = "commit_failed"
+)
+
+// TxStats describes a finished transaction begun through Tx()
+type TxStats struct {
+	Duration time.Duration
+	// Statements counts Exec, Query, QueryRow, SendBatch and CopyFrom calls made in the transaction
+	Statements int
+	Result     TxResult
+	// Err is the commit or rollback error, or the error that made WithTransaction roll back
+	Err error
+}
+
+// TxMetrics can optionally be implemented by a Metrics collector to observe transactions as a whole, apart
+// from the queries they run
+type TxMetrics interface {
+	TransactionDone(stats TxStats)
+}
+
+// TxTracer starts a span around each transaction begun through Tx() (an OpenTelemetry adapter, for example).
+// The returned context carries the span: Begin, Commit and Rollback use it, and WithContextTransaction passes
+// it to its callback so statement spans nest under the transaction. end is called once, when it ends.
+type TxTracer interface {
+	StartTransaction(ctx context.Context) (spanCtx context.Context, end func(TxStats))
+}
+
+// WithTxTracer sets the tracer wrapping Tx() transactions in a parent span
+func WithTxTracer(t TxTracer) Option { return func(o *options) { o.txTracer = t } }
+
+// txObserver reports a transaction to TxMetrics and the TxTracer once it ends
+type txObserver struct {
+	started    time.Time
+	statements atomic.Int64
+	metrics    TxMetrics
+	end        func(TxStats)
+	done       atomic.Bool
+	cause      error
+}
+
+// observeTx returns nil when neither transaction metrics nor a tracer are configured
+func (kn *KintsNorm) observeTx(ctx context.Context) (context.Context, *txObserver) {
+	m, _ := kn.metrics.(TxMetrics)
+	if m == nil && kn.txTracer == nil {
+		return ctx, nil
+	}
+	o := &txObserver{started: time.Now(), metrics: m}
+	if kn.txTracer != nil {
+		ctx, o.end = kn.txTracer.StartTransaction(ctx)
+	}
+	return ctx, o
+}
+
+func (o *txObserver) finish(result TxResult, err error) {
+	if o == nil || !o.done.CompareAndSwap(false, true) {
+		return
+	}
+	if err == nil {
+		err = o.cause
+	}
+	s := TxStats{Duration: time.Since(o.started), Statements: int(o.statements.Load()), Result: result, Err: err}
+	if o.metrics != nil {
+		o.metrics.TransactionDone(s)
+	}
+	if o.end != nil {
+		o.end(s)
+	}
+}
+
+// countedTx counts the statements of an observed transaction. It still is a pgx.Tx, so code detecting a
+// transaction underneath executors keeps working.
+type countedTx struct {
+	pgx.Tx
+	n *atomic.Int64
+}
+
+func (t countedTx) Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	t.n.Add(1)
+	return t.Tx.Exec(ctx, sql, args...)
+}
+
+func (t countedTx) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	t.n.Add(1)
+	return t.Tx.Query(ctx, sql, args...)
+}
+
+func (t countedTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	t.n.Add(1)
+	return t.Tx.QueryRow(ctx, sql, args...)
+}
+
+func (t countedTx) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	t.n.Add(1)
+	return t.Tx.SendBatch(ctx, b)
+}
+
+func (t countedTx) CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, src pgx.CopyFromSource) (int64, error) {
+	t.n.Add(1)
+	return t.Tx.CopyFrom(ctx, table, columns, src)
+}
+
+// Begin starts a savepoint whose statements count towards the outer transaction
+func (t countedTx) Begin(ctx context.Context) (pgx.Tx, error) {
+	tx, err := t.Tx.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return countedTx{Tx: tx, n: t.n}, nil
+}
@@ -0,0 +1,66 @@
+package norm
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type txRecMetrics struct {
+	NoopMetrics
+	stats []TxStats
+}
+
+func (m *txRecMetrics) TransactionDone(s TxStats) { m.stats = append(m.stats, s) }
+
+type txRecTracer struct {
+	ended []TxStats
+}
+
+type txSpanKey struct{}
+
+func (t *txRecTracer) StartTransaction(ctx context.Context) (context.Context, func(TxStats)) {
+	return context.WithValue(ctx, txSpanKey{}, "span"), func(s TxStats) { t.ended = append(t.ended, s) }
+}
+
+func TestTxObserver_CountsStatementsAndReportsOnce(t *testing.T) {
+	rec, tracer := &txRecMetrics{}, &txRecTracer{}
+	kn := &KintsNorm{metrics: rec, txTracer: tracer}
+	ctx, obs := kn.observeTx(context.Background())
+	if ctx.Value(txSpanKey{}) != "span" || obs == nil {
+		t.Fatal("tracer context not returned")
+	}
+	db := &batchDB{}
+	tx := &txImpl{kn: kn, tx: countedTx{Tx: &batchTx{db: db}, n: &obs.statements}, obs: obs}
+	_, _ = tx.Exec().Exec(ctx, "UPDATE a SET x = 1")
+	_, _ = tx.Query().Table("a").Where("x = ?", 1).Delete(ctx)
+	if !inTx(ctx, tx.Exec()) {
+		t.Fatal("counted transaction not detected as a transaction")
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	_ = tx.Rollback(ctx)
+	if len(rec.stats) != 1 || len(tracer.ended) != 1 {
+		t.Fatalf("reported %d/%d times", len(rec.stats), len(tracer.ended))
+	}
+	if s := rec.stats[0]; s.Result != TxCommitted || s.Statements != 2 || s.Err != nil {
+		t.Fatalf("stats %+v", s)
+	}
+}
+
+func TestTxObserver_RollbackCause(t *testing.T) {
+	rec := &txRecMetrics{}
+	kn := &KintsNorm{metrics: rec}
+	_, obs := kn.observeTx(context.Background())
+	boom := errors.New("boom")
+	obs.cause = boom
+	tx := &txImpl{kn: kn, tx: countedTx{Tx: &batchTx{db: &batchDB{}}, n: &obs.statements}, obs: obs}
+	_ = tx.Rollback(context.Background())
+	if s := rec.stats[0]; s.Result != TxRolledBack || !errors.Is(s.Err, boom) {
+		t.Fatalf("stats %+v", s)
+	}
+	if _, obs := (&KintsNorm{metrics: NoopMetrics{}}).observeTx(context.Background()); obs != nil {
+		t.Fatal("observer without metrics or tracer")
+	}
+}
@@ -0,0 +1,89 @@
+package norm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/kintsdev/norm/internal/core"
+)
+
+// generateUUIDs fills the zero-valued norm:"uuid:v4" / "uuid:v7" fields of entity, so Create knows the key
+// before the insert instead of reading a database default back
+func generateUUIDs(entity any) error {
+	val := reflect.Indirect(reflect.ValueOf(entity))
+	if !val.IsValid() {
+		return nil
+	}
+	for col, fi := range core.StructMapper(val.Type()).FieldsByColumn {
+		if fi.UUID == "" {
+			continue
+		}
+		fv := val.FieldByIndex(fi.Index)
+		if !fv.IsZero() {
+			continue
+		}
+		var u [16]byte
+		switch fi.UUID {
+		case "v4":
+			u = newUUIDv4()
+		case "v7":
+			u = newUUIDv7(time.Now())
+		default:
+			return &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("column %s: unknown uuid version %q (want v4 or v7)", col, fi.UUID)}
+		}
+		if err := setUUID(fv, u); err != nil {
+			return &ORMError{Code: ErrCodeValidation, Message: fmt.Sprintf("column %s: %v", col, err)}
+		}
+	}
+	return nil
+}
+
+// newUUIDv4 returns a random (version 4) UUID
+func newUUIDv4() [16]byte {
+	var u [16]byte
+	_, _ = rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return u
+}
+
+// newUUIDv7 returns a time-ordered (version 7, RFC 9562) UUID: a 48-bit Unix millisecond timestamp
+// followed by random bits, so keys generated later sort later and index inserts stay append-mostly
+func newUUIDv7(now time.Time) [16]byte {
+	u := newUUIDv4()
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(now.UnixMilli()))
+	copy(u[:6], ts[2:])
+	u[6] = u[6]&0x0f | 0x70
+	return u
+}
+
+// setUUID assigns u to a string (canonical text form), [16]byte-like or []byte field, allocating pointers
+func setUUID(fv reflect.Value, u [16]byte) error {
+	if fv.Kind() == reflect.Pointer {
+		fv.Set(reflect.New(fv.Type().Elem()))
+		fv = fv.Elem()
+	}
+	switch {
+	case fv.Kind() == reflect.String:
+		var buf [36]byte
+		hex.Encode(buf[0:8], u[0:4])
+		hex.Encode(buf[9:13], u[4:6])
+		hex.Encode(buf[14:18], u[6:8])
+		hex.Encode(buf[19:23], u[8:10])
+		hex.Encode(buf[24:], u[10:])
+		buf[8], buf[13], buf[18], buf[23] = '-', '-', '-', '-'
+		fv.SetString(string(buf[:]))
+	case fv.Kind() == reflect.Array && fv.Len() == 16 && fv.Type().Elem().Kind() == reflect.Uint8:
+		reflect.Copy(fv, reflect.ValueOf(u[:]))
+	case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() == reflect.Uint8:
+		fv.SetBytes(u[:])
+	default:
+		return fmt.Errorf("uuid generation needs a string, [16]byte or []byte field, got %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package norm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+type uuidV7Item struct {
+	ID   string `db:"id" norm:"primary_key,uuid:v7"`
+	Name string `db:"name"`
+}
+
+type uuidBytesItem struct {
+	ID  [16]byte `db:"id" norm:"primary_key,uuid:v4"`
+	Ref *string  `db:"ref" norm:"uuid:v4"`
+}
+
+func TestNewUUIDv7_VersionAndOrder(t *testing.T) {
+	now := time.Now()
+	a, b := newUUIDv7(now), newUUIDv7(now.Add(time.Millisecond))
+	if a[6]>>4 != 7 || a[8]>>6 != 2 {
+		t.Fatalf("version/variant bits: %x", a)
+	}
+	if bytes.Compare(a[:], b[:]) >= 0 {
+		t.Fatalf("v7 not time-ordered: %x >= %x", a, b)
+	}
+	if u := newUUIDv4(); u[6]>>4 != 4 || u[8]>>6 != 2 {
+		t.Fatalf("v4 bits: %x", u)
+	}
+}
+
+func TestCreate_GeneratesUUIDKey(t *testing.T) {
+	ex := &recExecRepo{}
+	r := NewRepositoryWithExecutor[uuidV7Item](&KintsNorm{}, ex)
+	it := &uuidV7Item{Name: "a"}
+	if err := r.Create(context.Background(), it); err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(it.ID) {
+		t.Fatalf("id=%q", it.ID)
+	}
+	if ex.lastSQL != `INSERT INTO uuid_v7_items ("id", "name") VALUES ($1, $2)` || ex.lastArgs[0] != it.ID {
+		t.Fatalf("sql=%s args=%v", ex.lastSQL, ex.lastArgs)
+	}
+	// a key set by the caller is kept
+	if err := r.Create(context.Background(), &uuidV7Item{ID: "fixed"}); err != nil || ex.lastArgs[0] != "fixed" {
+		t.Fatalf("err=%v args=%v", err, ex.lastArgs)
+	}
+
+	var b uuidBytesItem
+	if err := generateUUIDs(&b); err != nil {
+		t.Fatal(err)
+	}
+	if b.ID == ([16]byte{}) || b.Ref == nil || len(*b.Ref) != 36 {
+		t.Fatalf("got %+v", b)
+	}
+}
+
+func TestGenerateUUIDs_Errors(t *testing.T) {
+	var oe *ORMError
+	type badVersion struct {
+		ID string `db:"id" norm:"uuid:v1"`
+	}
+	if err := generateUUIDs(&badVersion{}); !errors.As(err, &oe) || oe.Code != ErrCodeValidation {
+		t.Fatalf("version: %v", err)
+	}
+	type badType struct {
+		ID int64 `db:"id" norm:"uuid:v4"`
+	}
+	if err := generateUUIDs(&badType{}); !errors.As(err, &oe) || oe.Code != ErrCodeValidation {
+		t.Fatalf("type: %v", err)
+	}
+}